@@ -1,19 +1,148 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"sync"
 
-	"github.com/craftcms/nitro/protob"
+	"github.com/docker/docker/client"
 	"google.golang.org/grpc"
+
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/protob"
 )
 
 // NewClient is used for generating a new client to interact
 // with the gRPC API running in the proxy container
 func NewClient(ip, port string) (protob.NitroClient, error) {
-	cc, err := grpc.Dial(ip+":"+port, grpc.WithInsecure())
+	cc, err := grpc.Dial(net.JoinHostPort(ip, port), grpc.WithInsecure())
 	if err != nil {
 		return nil, fmt.Errorf("unable to create a gRPC client for nitrod, %w", err)
 	}
 
 	return protob.NewNitroClient(cc), nil
 }
+
+// NewProxyClient returns a client for the gRPC API that connects to the port
+// published by the running proxy container. The port is discovered before each
+// request, so a proxy that was recreated on a different port is still reachable.
+func NewProxyClient(docker client.ContainerAPIClient) protob.NitroClient {
+	return NewNamedProxyClient(docker, proxycontainer.ProxyName)
+}
+
+// NewNamedProxyClient returns a client for the gRPC API of the proxy container
+// with the name, it is used for the proxy of an environment (e.g. nitro-work-proxy).
+func NewNamedProxyClient(docker client.ContainerAPIClient, name string) protob.NitroClient {
+	return protob.NewNitroClient(&proxyConn{docker: docker, name: name})
+}
+
+// WaitForAPI pings the API until it is ready. It returns an error without
+// waiting when the proxy container is missing, stopped, or does not publish
+// the API port, since the API will never become ready.
+func WaitForAPI(ctx context.Context, nitrod protob.NitroClient) error {
+	for {
+		_, err := nitrod.Ping(ctx, &protob.PingRequest{})
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, proxycontainer.ErrNoProxyContainer), errors.Is(err, proxycontainer.ErrProxyNotRunning), errors.Is(err, proxycontainer.ErrNoAPIPort):
+			return err
+		case ctx.Err() != nil:
+			return ctx.Err()
+		}
+	}
+}
+
+var (
+	// ErrOutdatedProxy is returned when the proxy API is older than the API the CLI uses
+	ErrOutdatedProxy = errors.New("the proxy is older than the nitro CLI and may ignore parts of the config, run `nitro update` to recreate the proxy")
+
+	// ErrOutdatedCLI is returned when the proxy API is newer than the API the CLI uses
+	ErrOutdatedCLI = errors.New("the proxy is newer than the nitro CLI, run `nitro update --self` to update the CLI")
+)
+
+// CheckAPIVersion pings the API and returns ErrOutdatedProxy or ErrOutdatedCLI
+// when the proxy implements a different version of the API than the CLI. An
+// older proxy ignores the fields it does not know about, so sites could be
+// routed incorrectly without an error.
+func CheckAPIVersion(ctx context.Context, nitrod protob.NitroClient) error {
+	resp, err := nitrod.Ping(ctx, &protob.PingRequest{})
+	if err != nil {
+		return err
+	}
+
+	switch v := resp.GetApiVersion(); {
+	case v < protob.APIVersion:
+		return fmt.Errorf("%w (proxy API version %d, CLI API version %d)", ErrOutdatedProxy, v, protob.APIVersion)
+	case v > protob.APIVersion:
+		return fmt.Errorf("%w (proxy API version %d, CLI API version %d)", ErrOutdatedCLI, v, protob.APIVersion)
+	}
+
+	return nil
+}
+
+// proxyConn is a gRPC connection that dials the address of the API
+// published by the proxy container.
+type proxyConn struct {
+	docker client.ContainerAPIClient
+	name   string
+
+	mu   sync.Mutex
+	addr string
+	cc   *grpc.ClientConn
+}
+
+// conn returns the connection for the address the proxy container publishes
+// the API on, the connection is replaced when the address changes.
+func (p *proxyConn) conn(ctx context.Context) (*grpc.ClientConn, error) {
+	ip, port, err := proxycontainer.APIAddress(ctx, p.docker, p.name)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := net.JoinHostPort(ip, port)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cc != nil && p.addr == addr {
+		return p.cc, nil
+	}
+
+	// close the connection to the previous proxy
+	if p.cc != nil {
+		p.cc.Close()
+	}
+
+	cc, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a gRPC client for nitrod, %w", err)
+	}
+
+	p.addr = addr
+	p.cc = cc
+
+	return cc, nil
+}
+
+// Invoke sends the request to the API published by the proxy container.
+func (p *proxyConn) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	cc, err := p.conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	return cc.Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream creates a stream to the API published by the proxy container.
+func (p *proxyConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	cc, err := p.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return cc.NewStream(ctx, desc, method, opts...)
+}
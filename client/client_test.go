@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"google.golang.org/grpc"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/protob"
+)
+
+func TestWaitForAPI_FailsFast(t *testing.T) {
+	proxy := types.Container{ID: "proxy", Names: []string{"/nitro-proxy"}, State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "proxy"}}
+
+	tests := []struct {
+		name       string
+		containers []types.Container
+		wantErr    error
+	}{
+		{
+			name:    "missing proxies return an error",
+			wantErr: proxycontainer.ErrNoProxyContainer,
+		},
+		{
+			name:       "proxies without the API port return an error",
+			containers: []types.Container{proxy},
+			wantErr:    proxycontainer.ErrNoAPIPort,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			docker := dockertest.New(tt.containers, nil)
+
+			// Act
+			err := WaitForAPI(context.Background(), NewProxyClient(docker))
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("WaitForAPI() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type pingClient struct {
+	protob.NitroClient
+
+	version int32
+}
+
+func (c *pingClient) Ping(ctx context.Context, in *protob.PingRequest, opts ...grpc.CallOption) (*protob.PingResponse, error) {
+	return &protob.PingResponse{Pong: "pong", ApiVersion: c.version}, nil
+}
+
+func TestCheckAPIVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int32
+		wantErr error
+	}{
+		{
+			name:    "proxies with the same version are compatible",
+			version: protob.APIVersion,
+		},
+		{
+			name:    "proxies without a version are outdated",
+			version: 0,
+			wantErr: ErrOutdatedProxy,
+		},
+		{
+			name:    "proxies with a newer version require a newer cli",
+			version: protob.APIVersion + 1,
+			wantErr: ErrOutdatedCLI,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := CheckAPIVersion(context.Background(), &pingClient{version: tt.version})
+
+			// Assert
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("CheckAPIVersion() unexpected error = %v", err)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckAPIVersion() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/craftcms/nitro/command/nitro"
 )
 
 func main() {
+	// cancel the commands context on an interrupt so docker streams
+	// and exec sessions are closed before the process exits
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// execute the nitro root command
-	if err := nitro.NewCommand().Execute(); err != nil {
+	if err := nitro.NewCommand().ExecuteContext(ctx); err != nil {
+		stop()
 		os.Exit(1)
 	}
 }
@@ -84,7 +84,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			}
 
 			// prompt the user to add new alias
-			alias, err := output.Ask("Enter the alias domain for the site (use commas to enter multiple)", "", ":", &validate.HostnameValidator{})
+			alias, err := output.Ask("Enter the alias domain for the site (use commas to enter multiple)", "", ":", &validate.AliasValidator{})
 			if err != nil {
 				return err
 			}
@@ -116,6 +116,15 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				return fmt.Errorf("unable to save config, %w", err)
 			}
 
+			// hosts files do not support wildcards, so let the user know
+			for _, a := range strings.Split(alias, ",") {
+				if config.IsWildcard(a) {
+					output.Info("Wildcard aliases are not added to your hosts file, add each subdomain to your hosts file or use a local DNS resolver")
+
+					break
+				}
+			}
+
 			return nil
 		},
 	}
@@ -0,0 +1,247 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	nitroclient "github.com/craftcms/nitro/client"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/protob"
+)
+
+// allIncompatibleFlags are the flags that only make sense for a single environment
+var allIncompatibleFlags = []string{"site", "prune", "watch", "json"}
+
+// environment is a config file to apply. Each environment has its own network
+// and proxy, so the environments do not share routes.
+type environment struct {
+	// file is the config file, which is empty to load the config for the
+	// current directory or ~/.nitro/nitro.yaml (see config.Load)
+	file string
+
+	// network is the name of the network (e.g. nitro-network)
+	network string
+
+	// proxy is the name of the proxy container (e.g. nitro-proxy)
+	proxy string
+
+	// nitrod is the API client for the proxy container
+	nitrod protob.NitroClient
+
+	// services are the services enabled in the other environments, which
+	// are not removed when they are disabled in this environment
+	services config.Services
+
+	// hostnames are from the environments that were applied before, they are
+	// kept in the hosts file with the hostnames for this environment
+	hostnames []string
+
+	// all is set when applying every environment
+	all bool
+}
+
+// defaultEnvironment returns the environment for the default network and proxy.
+func defaultEnvironment(nitrod protob.NitroClient) environment {
+	return environment{network: "nitro-network", proxy: proxycontainer.ProxyName, nitrod: nitrod}
+}
+
+// environmentFor returns the environment for a config file when applying every
+// environment. The network and proxy are named after the file (e.g. work.yaml uses
+// nitro-work-network and nitro-work-proxy), nitro.yaml uses the default names.
+func environmentFor(file string, docker client.ContainerAPIClient, nitrod protob.NitroClient) environment {
+	env := defaultEnvironment(nitrod)
+	env.file = file
+	env.all = true
+
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if name == "nitro" {
+		return env
+	}
+
+	env.network = fmt.Sprintf("nitro-%s-network", name)
+	env.proxy = fmt.Sprintf("nitro-%s-proxy", name)
+	env.nitrod = nitroclient.NewNamedProxyClient(docker, env.proxy)
+
+	return env
+}
+
+// load returns the config for the environment.
+func (e environment) load(home string) (*config.Config, error) {
+	if e.file == "" {
+		return config.Load(home)
+	}
+
+	return config.LoadFile(e.file)
+}
+
+// environmentResult is the outcome of applying a single environment.
+type environmentResult struct {
+	file string
+	err  error
+}
+
+// applyAll applies every environment in the nitro directory (e.g. ~/.nitro/*.yaml)
+// one at a time. The host ports and hostnames are checked across the environments
+// before anything is changed. A failure in one environment does not stop the others,
+// the failures are shown in a summary and an error is returned if any environment failed.
+func applyAll(cmd *cobra.Command, args []string, home string, docker client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) error {
+	for _, f := range allIncompatibleFlags {
+		if cmd.Flags().Changed(f) {
+			return fmt.Errorf("the --%s flag cannot be used with --all", f)
+		}
+	}
+
+	files, err := config.Environments(home)
+	if err != nil {
+		return err
+	}
+
+	// load every environment to find conflicts before changing anything
+	var environments []*config.Config
+	for _, f := range files {
+		cfg, err := config.LoadFile(f)
+		if err != nil {
+			return fmt.Errorf("unable to load %s, %w", f, err)
+		}
+
+		environments = append(environments, cfg)
+	}
+
+	if err := config.ValidateEnvironmentPorts(environments); err != nil {
+		return err
+	}
+
+	if err := config.ValidateEnvironmentHostnames(environments); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// the containers for every environment are removed before any are created again
+	recreate, _ := cmd.Flags().GetString("recreate")
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); recreate == recreateAll && !dryRun {
+		output.Pending("removing containers to recreate")
+
+		checkSites, checkDatabases, checkServices := scope(cmd)
+		if _, err := removeForRecreate(ctx, verboseClient(cmd, docker), checkSites, checkDatabases, checkServices); err != nil {
+			output.Warning()
+			return err
+		}
+
+		output.Done()
+	}
+
+	// the hostnames and containers are kept for every environment
+	var hostnames []string
+	known := map[string]bool{}
+
+	var results []environmentResult
+	for i, f := range files {
+		output.Info("Applying", f+"…")
+
+		env := environmentFor(f, docker, nitrod)
+		env.services = otherServices(environments, i)
+		env.hostnames = hostnames
+
+		state, err := applyEnvironment(cmd, home, docker, output, env)
+		if err == nil {
+			err = cleanupEnvironment(cmd, args, home, docker, output, env, state)
+		}
+
+		hostnames = state.hostnames
+		for id := range state.known {
+			known[id] = true
+		}
+
+		if err != nil {
+			output.Info("Unable to apply", f+",", err.Error())
+		}
+
+		results = append(results, environmentResult{file: f, err: err})
+	}
+
+	// containers are only orphans when they are not in any environment
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); !dryRun {
+		containers, err := verboseClient(cmd, docker).ContainerList(ctx, types.ContainerListOptions{All: true, Filters: containerlabels.Environment()})
+		if err != nil {
+			return fmt.Errorf("unable to list the containers, %w", err)
+		}
+
+		checkSites, checkDatabases, checkServices := scope(cmd)
+
+		var orphans []string
+		for _, c := range containers {
+			if isOrphan(c, known, "", checkSites, checkDatabases, checkServices) {
+				orphans = append(orphans, strings.TrimLeft(c.Names[0], "/"))
+			}
+		}
+
+		if len(orphans) > 0 {
+			output.Info("Found containers that are not in any environment:", strings.Join(orphans, ", "))
+		}
+	}
+
+	lines, failed := environmentSummary(results)
+	for _, l := range lines {
+		output.Info(l)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("unable to apply %d of %d environments", failed, len(results))
+	}
+
+	return nil
+}
+
+// otherServices returns the services that are enabled in any environment
+// except the environment at the index.
+func otherServices(environments []*config.Config, index int) config.Services {
+	var services config.Services
+	for i, cfg := range environments {
+		if i == index {
+			continue
+		}
+
+		services.DynamoDB = services.DynamoDB || cfg.Services.DynamoDB
+		services.Elasticsearch = services.Elasticsearch || cfg.Services.Elasticsearch
+		services.Mailhog = services.Mailhog || cfg.Services.Mailhog
+		services.Meilisearch = services.Meilisearch || cfg.Services.Meilisearch
+		services.Minio = services.Minio || cfg.Services.Minio
+		services.Redis = services.Redis || cfg.Services.Redis
+	}
+
+	return services
+}
+
+// environmentSummary returns the lines shown after applying every environment
+// and the number of environments that failed.
+func environmentSummary(results []environmentResult) ([]string, int) {
+	lines := []string{"Environments:"}
+
+	failed := 0
+	for _, r := range results {
+		name := filepath.Base(r.file)
+
+		if r.err != nil {
+			failed++
+			lines = append(lines, fmt.Sprintf("  %s: failed, %s", name, r.err.Error()))
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s: applied", name))
+	}
+
+	return lines, failed
+}
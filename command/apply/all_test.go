@@ -0,0 +1,92 @@
+package apply
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+func Test_environmentSummary(t *testing.T) {
+	// Arrange
+	results := []environmentResult{
+		{file: "/home/nitro/.nitro/nitro.yaml"},
+		{file: "/home/nitro/.nitro/work.yaml", err: errors.New("unable to find the Dockerfile")},
+	}
+
+	// Act
+	lines, failed := environmentSummary(results)
+
+	// Assert
+	want := []string{
+		"Environments:",
+		"  nitro.yaml: applied",
+		"  work.yaml: failed, unable to find the Dockerfile",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("expected %v, got %v", want, lines)
+	}
+
+	if failed != 1 {
+		t.Errorf("expected 1 failed environment, got %d", failed)
+	}
+}
+
+func Test_environmentFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		wantNetwork string
+		wantProxy   string
+	}{
+		{
+			name:        "nitro.yaml uses the default network and proxy",
+			file:        "/home/nitro/.nitro/nitro.yaml",
+			wantNetwork: "nitro-network",
+			wantProxy:   "nitro-proxy",
+		},
+		{
+			name:        "other environments have their own network and proxy",
+			file:        "/home/nitro/.nitro/work.yaml",
+			wantNetwork: "nitro-work-network",
+			wantProxy:   "nitro-work-proxy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := environmentFor(tt.file, dockertest.New(nil, nil), nil)
+
+			if env.network != tt.wantNetwork {
+				t.Errorf("expected the network %s, got %s", tt.wantNetwork, env.network)
+			}
+
+			if env.proxy != tt.wantProxy {
+				t.Errorf("expected the proxy %s, got %s", tt.wantProxy, env.proxy)
+			}
+
+			if env.file != tt.file || !env.all {
+				t.Errorf("expected the environment for %s, got %+v", tt.file, env)
+			}
+		})
+	}
+}
+
+func Test_otherServices(t *testing.T) {
+	// Arrange
+	environments := []*config.Config{
+		{File: "nitro.yaml", Services: config.Services{Redis: true}},
+		{File: "other.yaml", Services: config.Services{Mailhog: true}},
+		{File: "work.yaml", Services: config.Services{Minio: true}},
+	}
+
+	// Act
+	got := otherServices(environments, 1)
+
+	// Assert
+	want := config.Services{Redis: true, Minio: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the services from the other environments %+v, got %+v", want, got)
+	}
+}
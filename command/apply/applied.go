@@ -0,0 +1,230 @@
+package apply
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/command/version"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+)
+
+// applied records the state of the environment after an apply, so the next
+// apply can skip checking every container when nothing has changed.
+type applied struct {
+	// Hash is the hash of the config, nitro version, and options that were applied
+	Hash string `json:"hash"`
+
+	// Containers are the containers in the environment using the container name
+	Containers map[string]appliedContainer `json:"containers"`
+}
+
+// appliedContainer is the container ID and image digest of a container that was applied.
+type appliedContainer struct {
+	ID    string `json:"id"`
+	Image string `json:"image"`
+}
+
+// appliedPath returns the path to the file that records the last apply of a config
+// file. The file is kept in the nitro directory, instead of next to a project config,
+// and is named using a hash of the config path (e.g. ~/.nitro/.applied/3f2a…json)
+// so each config has its own file.
+func appliedPath(home, file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+
+	sum := sha256.Sum256([]byte(file))
+
+	return filepath.Join(home, config.DirectoryName, ".applied", hex.EncodeToString(sum[:8])+".json")
+}
+
+// configHash returns the hash of the config, the files the config references,
+// the nitro version, and the options that change how the config is applied
+// (e.g. skipping the proxy).
+func configHash(home string, cfg *config.Config, options ...string) (string, error) {
+	content, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash the config, %w", err)
+	}
+
+	h := sha256.New()
+	h.Write(content)
+	h.Write([]byte(version.Version))
+
+	files, err := referencedFiles(home, cfg)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash the config, %w", err)
+	}
+
+	// the containers use the content of the files, so editing a file changes the hash
+	for _, f := range files {
+		h.Write([]byte("\n" + f + "=" + fileHash(f)))
+	}
+
+	for _, o := range options {
+		h.Write([]byte("\n" + o))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// referencedFiles returns the files the config uses to create the containers
+// (e.g. the .env, php.ini, nginx config, Dockerfile, and init SQL files).
+func referencedFiles(home string, cfg *config.Config) ([]string, error) {
+	var files []string
+	for _, s := range cfg.Sites {
+		for _, path := range []func(string) (string, error){s.GetDotenvPath, s.GetPHPIniPath, s.GetNginxConfigPath, s.GetDockerfilePath} {
+			f, err := path(home)
+			if err != nil {
+				return nil, err
+			}
+
+			if f != "" {
+				files = append(files, f)
+			}
+		}
+	}
+
+	for _, db := range cfg.Databases {
+		paths, err := db.GetInitSQLPaths(home)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, paths...)
+	}
+
+	for _, c := range cfg.Containers {
+		if c.EnvFile != "" {
+			files = append(files, filepath.Join(home, config.DirectoryName, "."+c.Name))
+		}
+	}
+
+	return files, nil
+}
+
+// fileHash returns the hash of the file content, or "missing" if the file
+// cannot be read.
+func fileHash(file string) string {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "missing"
+	}
+
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// readApplied returns the last apply from the file at the path, if there is no
+// file it returns nil.
+func readApplied(path string) (*applied, error) {
+	content, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("unable to read the last apply, %w", err)
+	}
+
+	var a applied
+	if err := json.Unmarshal(content, &a); err != nil {
+		// a corrupt file is the same as a missing one
+		return nil, nil
+	}
+
+	return &a, nil
+}
+
+// writeApplied records the containers in the environment and the hash of the
+// config that was applied.
+func writeApplied(ctx context.Context, docker client.ContainerAPIClient, path, hash string) error {
+	containers, err := environmentContainers(ctx, docker)
+	if err != nil {
+		return err
+	}
+
+	a := applied{Hash: hash, Containers: make(map[string]appliedContainer)}
+	for name, c := range containers {
+		a.Containers[name] = appliedContainer{ID: c.ID, Image: c.ImageID}
+	}
+
+	content, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to save the last apply, %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("unable to save the last apply, %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("unable to save the last apply, %w", err)
+	}
+
+	return nil
+}
+
+// removeApplied removes the last apply so the next apply checks every container.
+func removeApplied(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to remove the last apply, %w", err)
+	}
+
+	return nil
+}
+
+// matches returns true when the hash is the same as the last apply and every
+// container that was applied still exists, is running, and uses the same image.
+func (a *applied) matches(ctx context.Context, docker client.ContainerAPIClient, hash string) (bool, error) {
+	if a.Hash != hash || len(a.Containers) == 0 {
+		return false, nil
+	}
+
+	containers, err := environmentContainers(ctx, docker)
+	if err != nil {
+		return false, err
+	}
+
+	for name, last := range a.Containers {
+		c, ok := containers[name]
+		if !ok || c.ID != last.ID || c.ImageID != last.Image || c.State != "running" {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// environmentContainers returns the containers in the environment using the
+// container name. Composer and npm containers are created when running
+// commands, so they are not part of the applied environment.
+func environmentContainers(ctx context.Context, docker client.ContainerAPIClient) (map[string]types.Container, error) {
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: containerlabels.Environment()})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	named := make(map[string]types.Container)
+	for _, c := range containers {
+		switch c.Labels[containerlabels.Type] {
+		case "composer", "npm":
+			continue
+		}
+
+		named[strings.TrimLeft(c.Names[0], "/")] = c
+	}
+
+	return named, nil
+}
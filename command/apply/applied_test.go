@@ -0,0 +1,273 @@
+package apply
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+func appliedContainers() []types.Container {
+	return []types.Container{
+		{
+			ID:      "site",
+			Names:   []string{"/craft-dev.nitro"},
+			ImageID: "sha256:site",
+			State:   "running",
+			Labels:  map[string]string{containerlabels.Nitro: "true", containerlabels.Host: "craft-dev.nitro"},
+		},
+		{
+			ID:      "database",
+			Names:   []string{"/mysql-8.0-3306.database.nitro"},
+			ImageID: "sha256:mysql",
+			State:   "running",
+			Labels:  map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "database"},
+		},
+		{
+			ID:      "composer",
+			Names:   []string{"/composer"},
+			ImageID: "sha256:composer",
+			State:   "exited",
+			Labels:  map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "composer"},
+		},
+	}
+}
+
+func Test_applied(t *testing.T) {
+	tests := []struct {
+		name   string
+		hash   string
+		change func(docker *dockertest.Client)
+		want   bool
+	}{
+		{
+			name: "unchanged environments match",
+			hash: "hash",
+			want: true,
+		},
+		{
+			name: "a changed config does not match",
+			hash: "changed",
+			want: false,
+		},
+		{
+			name: "missing containers do not match",
+			hash: "hash",
+			change: func(docker *dockertest.Client) {
+				docker.Containers = docker.Containers[1:]
+			},
+			want: false,
+		},
+		{
+			name: "recreated containers do not match",
+			hash: "hash",
+			change: func(docker *dockertest.Client) {
+				docker.Containers[0].ID = "recreated"
+			},
+			want: false,
+		},
+		{
+			name: "containers with a new image do not match",
+			hash: "hash",
+			change: func(docker *dockertest.Client) {
+				docker.Containers[1].ImageID = "sha256:updated"
+			},
+			want: false,
+		},
+		{
+			name: "stopped containers do not match",
+			hash: "hash",
+			change: func(docker *dockertest.Client) {
+				docker.Containers[1].State = "exited"
+			},
+			want: false,
+		},
+		{
+			name: "composer and npm containers are ignored",
+			hash: "hash",
+			change: func(docker *dockertest.Client) {
+				docker.Containers = docker.Containers[:2]
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), "applied.json")
+			docker := dockertest.New(appliedContainers(), nil)
+
+			if err := writeApplied(context.Background(), docker, path, "hash"); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.change != nil {
+				tt.change(docker)
+			}
+
+			// Act
+			last, err := readApplied(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := last.matches(context.Background(), docker, tt.hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+
+			if _, ok := last.Containers["composer"]; ok {
+				t.Errorf("expected the composer container to not be in the last apply")
+			}
+		})
+	}
+}
+
+func Test_readApplied(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "applied.json")
+
+	// a missing last apply is not an error
+	last, err := readApplied(path)
+	if err != nil || last != nil {
+		t.Fatalf("expected no last apply, got %v, %v", last, err)
+	}
+
+	// a corrupt last apply is the same as a missing one
+	if err := ioutil.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err = readApplied(path)
+	if err != nil || last != nil {
+		t.Fatalf("expected no last apply, got %v, %v", last, err)
+	}
+
+	// removing the last apply makes the next apply check every container
+	if err := removeApplied(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the last apply to be removed, got %v", err)
+	}
+
+	if err := removeApplied(path); err != nil {
+		t.Errorf("expected removing a missing last apply to not return an error, got %v", err)
+	}
+}
+
+func Test_appliedPath(t *testing.T) {
+	home := filepath.Join("home", "nitro")
+	dir := filepath.Join(home, ".nitro", ".applied")
+
+	global := appliedPath(home, filepath.Join(home, ".nitro", "nitro.yaml"))
+	work := appliedPath(home, filepath.Join(home, ".nitro", "work.yaml"))
+	project := appliedPath(home, filepath.Join("dev", "craft", "nitro.yaml"))
+
+	// the last apply is kept in the nitro directory, even for a project config
+	for _, p := range []string{global, work, project} {
+		if filepath.Dir(p) != dir {
+			t.Errorf("expected the last apply to be saved in %s, got %s", dir, p)
+		}
+	}
+
+	if global == work || global == project {
+		t.Errorf("expected each config to have its own file, got %s, %s, and %s", global, work, project)
+	}
+}
+
+func Test_configHash(t *testing.T) {
+	home := t.TempDir()
+	cfg := &config.Config{Sites: []config.Site{{Hostname: "craft-dev.nitro", Version: "7.4"}}}
+
+	hash, err := configHash(home, cfg, "no-proxy=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again, _ := configHash(home, cfg, "no-proxy=false"); again != hash {
+		t.Errorf("expected the hash to be the same for the same config, got %s and %s", hash, again)
+	}
+
+	if option, _ := configHash(home, cfg, "no-proxy=true"); option == hash {
+		t.Errorf("expected the hash to change when the options change")
+	}
+
+	cfg.Sites[0].Version = "8.0"
+	if changed, _ := configHash(home, cfg, "no-proxy=false"); changed == hash {
+		t.Errorf("expected the hash to change when the config changes")
+	}
+}
+
+func Test_configHash_ReferencedFiles(t *testing.T) {
+	// Arrange
+	home := t.TempDir()
+	site := filepath.Join(home, "dev", "craft-dev")
+	if err := os.MkdirAll(site, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	dotenv := filepath.Join(site, ".env")
+	if err := ioutil.WriteFile(dotenv, []byte("DB_SERVER=mysql-8.0-3306.database.nitro"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Sites: []config.Site{{Hostname: "craft-dev.nitro", Path: site, Version: "7.4", Dotenv: ".env"}}}
+	path := filepath.Join(home, "applied.json")
+	docker := dockertest.New(appliedContainers(), nil)
+
+	hash, err := configHash(home, cfg, "no-proxy=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeApplied(context.Background(), docker, path, hash); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	if err := ioutil.WriteFile(dotenv, []byte("DB_SERVER=postgres-13-5432.database.nitro"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := configHash(home, cfg, "no-proxy=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := readApplied(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unchanged, err := last.matches(context.Background(), docker, changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if unchanged {
+		t.Errorf("expected the apply to not be skipped when only the .env file changes")
+	}
+
+	// removing the file also changes the hash
+	if err := os.Remove(dotenv); err != nil {
+		t.Fatal(err)
+	}
+
+	if removed, _ := configHash(home, cfg, "no-proxy=false"); removed == changed {
+		t.Errorf("expected the hash to change when the .env file is removed")
+	}
+}
@@ -9,39 +9,62 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
-	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 	"github.com/spf13/cobra"
 
+	nitroclient "github.com/craftcms/nitro/client"
 	"github.com/craftcms/nitro/command/apply/internal/customcontainer"
 	"github.com/craftcms/nitro/command/apply/internal/databasecontainer"
 	"github.com/craftcms/nitro/command/apply/internal/sitecontainer"
 	"github.com/craftcms/nitro/pkg/backup"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockerclient"
 	"github.com/craftcms/nitro/pkg/wsl"
 
-	"github.com/craftcms/nitro/pkg/datetime"
 	"github.com/craftcms/nitro/pkg/hostedit"
+	"github.com/craftcms/nitro/pkg/pathexists"
 	"github.com/craftcms/nitro/pkg/proxycontainer"
 	"github.com/craftcms/nitro/pkg/sudo"
 	"github.com/craftcms/nitro/pkg/svc/dynamodb"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
 	"github.com/craftcms/nitro/pkg/svc/mailhog"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
 	"github.com/craftcms/nitro/pkg/svc/minio"
 	"github.com/craftcms/nitro/pkg/svc/redis"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/craftcms/nitro/protob"
 )
 
-var (
-	defaultFile     = "/etc/hosts"
-	hostnames       []string
-	knownContainers = map[string]bool{}
-	isWSL           = false
-)
+var defaultFile = "/etc/hosts"
+
+// applyState is the state from applying an environment, it is returned by
+// applyEnvironment and used to clean up the environment.
+type applyState struct {
+	// hostnames are added to the hosts file
+	hostnames []string
+
+	// known are the IDs of the containers in the config, the other
+	// containers in the environment are orphans
+	known map[string]bool
+
+	// hash is the hash of the config when applying the entire environment,
+	// it is saved in the nitro directory after a successful apply (see appliedPath)
+	hash string
+
+	// appliedFile records the last apply of the config
+	appliedFile string
+
+	// wsl is set when the hosts file is edited in windows
+	wsl bool
+
+	// timings are how long each phase took
+	timings *stopwatch
+}
 
 const exampleText = `  # apply changes from a config
   nitro apply
@@ -49,477 +72,1201 @@ const exampleText = `  # apply changes from a config
   # skip editing the hosts file
   nitro apply --skip-hosts
 
-  # you can also set the environment variable "NITRO_EDIT_HOSTS" to "false"`
+  # only apply changes to sites
+  nitro apply --sites-only
+
+  # only apply changes to a single site
+  nitro apply --site craft-dev.nitro
+
+  # only apply changes to databases or services
+  nitro apply --databases-only
+  nitro apply --services-only
+
+  # pull the latest site images and recreate sites with outdated images
+  nitro apply --force-pull
+
+  # use the images from nitro pull without checking for updates
+  nitro apply --skip-pull
+
+  # give up on image pulls that take longer than 30 minutes on a slow network
+  nitro apply --pull-timeout 30m
+
+  # recreate the site containers, or every container, even if they match the config
+  nitro apply --recreate
+  nitro apply --recreate=all
+
+  # remove the containers for sites, databases, and services that are no longer in the config
+  nitro apply --prune
+
+  # only create the network and containers, without the proxy or hosts file (e.g. in CI)
+  nitro apply --no-proxy
+
+  # apply every environment in ~/.nitro (e.g. nitro.yaml and work.yaml)
+  nitro apply --all
+
+  # apply changes each time the config file is saved
+  nitro apply --watch
+
+  # log each docker API call to diagnose a failure
+  nitro apply --verbose
+
+  # show the changes without applying them
+  nitro apply --dry-run
+
+  # write the planned changes as JSON for other tools
+  nitro apply --dry-run --json
+
+  # you can also set "edit_hosts: false" in the config or
+  # set the environment variable "NITRO_EDIT_HOSTS" to "false"`
 
 // NewCommand returns the command used to apply configuration file changes to a nitro environment.
+// The config file is locked while applying, so other commands that save the config (e.g. add or edit)
+// wait for the apply to finish.
 func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) *cobra.Command {
+	// state is set by each run and used by the post run to clean up
+	var state *applyState
+
 	cmd := &cobra.Command{
 		Use:     "apply",
 		Short:   "Apply changes",
 		Example: exampleText,
-		PreRunE: func(cmd *cobra.Command, args []string) error {
-			// is the docker api alive?
-			if _, err := docker.Ping(cmd.Context()); err != nil {
-				return fmt.Errorf("Couldn’t connect to Docker; please make sure Docker is running.")
+		PostRunE: func(cmd *cobra.Command, args []string) error {
+			// each environment was cleaned up when it was applied
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				return nil
 			}
 
-			return nil
+			return cleanupEnvironment(cmd, args, home, docker, output, defaultEnvironment(nitrod), state)
 		},
-		PostRunE: func(cmd *cobra.Command, args []string) error {
-			// create a filter for the environment
-			filter := filters.NewArgs()
-			filter.Add("label", containerlabels.Nitro+"=true")
-
-			// look for a container for the site
-			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{All: true, Filters: filter})
-			if err != nil {
-				return fmt.Errorf("error getting a list of containers")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// apply each environment in turn
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				return applyAll(cmd, args, home, docker, nitrod, output)
 			}
 
-			if len(containers) > 0 {
-				output.Info("Cleaning up...")
+			var err error
+			state, err = applyEnvironment(cmd, home, docker, output, defaultEnvironment(nitrod))
+
+			return err
+		},
+	}
+
+	// add flag to skip pulling images
+	cmd.Flags().Bool("skip-hosts", false, "skip modifying the hosts file")
+	cmd.Flags().Bool("sites-only", false, "only apply changes to sites")
+	cmd.Flags().Bool("databases-only", false, "only apply changes to databases")
+	cmd.Flags().Bool("services-only", false, "only apply changes to services")
+	cmd.Flags().String("site", "", "only apply changes to a single site (e.g. craft-dev.nitro)")
+	cmd.Flags().Bool("prune", false, "remove containers that are not in the config")
+	cmd.Flags().Bool("no-proxy", false, "skip the proxy and hosts file, only create the network and containers (e.g. in CI)")
+	cmd.Flags().Bool("all", false, "apply every environment in the nitro directory (e.g. ~/.nitro/*.yaml)")
+	cmd.Flags().Bool("watch", false, "watch the config file and apply changes")
+	cmd.Flags().Bool("force-pull", false, "pull site images and recreate containers when the image has changed")
+	cmd.Flags().Bool("skip-pull", false, "do not pull images that already exist locally")
+	cmd.Flags().Duration("pull-timeout", dockerclient.DefaultPullTimeout, "cancel an image pull that takes longer than the timeout (e.g. 30m), 0 disables the timeout")
+	cmd.Flags().String("recreate", "", "recreate containers even if they match the config (sites or all), volumes are kept")
+	cmd.Flags().Lookup("recreate").NoOptDefVal = recreateSites
+	cmd.Flags().Bool("verbose", false, "log each docker API call with its parameters and errors")
+	cmd.Flags().Bool("dry-run", false, "show the changes without applying them")
+	cmd.Flags().Bool("json", false, "write the planned changes as JSON (e.g. for editors and CI)")
+
+	return cmd
+}
+
+// applyEnvironment checks the network, proxy, and containers for the environment and
+// updates the hosts file. The containers are created when they do not exist.
+func applyEnvironment(cmd *cobra.Command, home string, docker client.CommonAPIClient, output terminal.Outputer, env environment) (*applyState, error) {
+	state := &applyState{
+		hostnames: append([]string(nil), env.hostnames...),
+		known:     map[string]bool{},
+		timings:   newStopwatch(),
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		// when we call commands from other commands (e.g. init)
+		// the context could be nil, so we set it to the parent
+		// context just in case.
+		ctx = context.Background()
+	}
+
+	// log each docker call to diagnose failures and cancel pulls that hang
+	docker = pullTimeoutClient(cmd, verboseClient(cmd, docker))
+
+	// load the config
+	cfg, err := env.load(home)
+	if err != nil {
+		return state, err
+	}
+
+	// lock the config so it is not saved by another nitro process while applying
+	unlock, err := config.LockForApply(cfg.GetFile())
+	if err != nil {
+		return state, err
+	}
+	defer unlock()
+
+	// reload the config in case it was saved while waiting for the lock
+	cfg, err = env.load(home)
+	if err != nil {
+		return state, err
+	}
+
+	// show any warnings for the config, these do not stop applying
+	for _, w := range cfg.Validate() {
+		output.Info("Warning:", w.Error())
+	}
+
+	// make sure the hooks can run before changing the environment
+	if err := cfg.ValidateHooks(); err != nil {
+		return state, err
+	}
+
+	// make sure the ports can be bound to the addresses
+	if err := cfg.ValidateBindAddresses(); err != nil {
+		return state, err
+	}
+
+	// make sure the custom containers can be created
+	if err := cfg.ValidateContainers(); err != nil {
+		return state, err
+	}
+
+	// make sure the host ports are only used once
+	if err := cfg.ValidatePorts(); err != nil {
+		return state, err
+	}
+
+	// make sure the timezones exist
+	if err := cfg.ValidateTimezones(); err != nil {
+		return state, err
+	}
+
+	// make sure the images can be pulled from the registry mirror
+	if err := cfg.ValidateRegistry(); err != nil {
+		return state, err
+	}
+
+	// make sure redis supports the options
+	if err := cfg.Services.RedisConfig.Validate(); err != nil {
+		return state, err
+	}
+
+	// make sure the shared volumes are for services with a volume
+	if err := cfg.Services.ValidateSharedVolumes(); err != nil {
+		return state, err
+	}
+
+	for _, s := range cfg.Sites {
+		// make sure the custom labels do not replace the nitro labels
+		if err := containerlabels.Validate(s.Labels); err != nil {
+			return state, fmt.Errorf("unable to apply the labels for %s, %w", s.Hostname, err)
+		}
+
+		// make sure the webroot is inside the site
+		if _, err := s.GetWebrootPath(); err != nil {
+			return state, err
+		}
+
+		// make sure only one option chooses the sites image
+		if err := s.ValidateImage(); err != nil {
+			return state, err
+		}
+
+		// disabled sites are not built or mounted
+		if s.Disabled {
+			continue
+		}
+
+		// make sure the Dockerfile exists before building the sites image
+		dockerfilePath, err := s.GetDockerfilePath(home)
+		if err != nil {
+			return state, err
+		}
+
+		if dockerfilePath != "" && !pathexists.IsFile(dockerfilePath) {
+			return state, fmt.Errorf("unable to find the Dockerfile %q for %s", dockerfilePath, s.Hostname)
+		}
+	}
+
+	// determine which parts of the environment to check
+	checkSites, checkDatabases, checkServices := scope(cmd)
+
+	// find the sites to check, which can be limited to a single site
+	siteFlag, _ := cmd.Flags().GetString("site")
+	enabledSites, disabledSites, err := sitesToApply(cfg, siteFlag)
+	if err != nil {
+		return state, err
+	}
+
+	// the proxy is not needed in CI
+	noProxy, _ := cmd.Flags().GetBool("no-proxy")
+
+	// should the site images be pulled to check for changes
+	forcePull, _ := cmd.Flags().GetBool("force-pull")
+	skipPull, _ := cmd.Flags().GetBool("skip-pull")
+
+	// should containers be recreated even when they match the config
+	recreate, _ := cmd.Flags().GetString("recreate")
+	if err := validRecreate(recreate); err != nil {
+		return state, err
+	}
+
+	// only the first apply recreates containers when watching for changes
+	if watching {
+		recreate = ""
+	}
+
+	// show the changes before making them, a dry run stops after the plan
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	jsonFlag, _ := cmd.Flags().GetBool("json")
+	if (dryRun || jsonFlag) && !watching {
+		prune, _ := cmd.Flags().GetBool("prune")
+
+		actions, err := buildPlan(ctx, docker, home, cfg, planOptions{
+			sites:     checkSites,
+			databases: checkDatabases,
+			services:  checkServices,
+			enabled:   enabledSites,
+			disabled:  disabledSites,
+			site:      siteFlag,
+			recreate:  recreate,
+			prune:     prune,
+			noProxy:   noProxy,
+			proxy:     env.proxy,
+		})
+		if err != nil {
+			return state, err
+		}
+
+		if jsonFlag {
+			if err := writePlan(cmd.OutOrStdout(), dryRun, actions); err != nil {
+				return state, err
 			}
+		} else {
+			showPlan(actions, output)
+		}
 
-			for _, c := range containers {
-				// start the container if not running
-				if c.State != "running" {
-					for _, command := range cmd.Root().Commands() {
-						if command.Use == "start" {
-							if err := command.RunE(cmd, []string{}); err != nil {
-								return err
-							}
-						}
-					}
-				}
+		if dryRun {
+			return state, nil
+		}
+	}
 
-				if _, ok := knownContainers[c.ID]; !ok {
-					// don't remove the proxy container
-					if c.Labels[containerlabels.Proxy] != "" {
-						continue
-					}
+	// applying the entire environment is skipped if the config and containers
+	// have not changed since the last apply, the last apply is recorded for a single environment
+	if !env.all && checkSites && checkDatabases && checkServices && recreate == "" && !forcePull {
+		state.timings = newStopwatch()
+		state.timings.Start("last apply")
 
-					// set the container name
-					name := strings.TrimLeft(c.Names[0], "/")
-
-					output.Pending("removing", name)
-
-					// only perform a backup if the container is for databases
-					if c.Labels[containerlabels.DatabaseEngine] != "" {
-						// get all of the databases
-						databases, err := backup.Databases(cmd.Context(), docker, c.ID, c.Labels[containerlabels.DatabaseCompatibility])
-						if err != nil {
-							output.Warning()
-							output.Info("Unable to get the databases from", name, err.Error())
-							break
-						}
-
-						// backup each database
-						for _, db := range databases {
-							// create the database specific backup options
-							opts := &backup.Options{
-								BackupName:    fmt.Sprintf("%s-%s.sql", db, datetime.Parse(time.Now())),
-								ContainerID:   c.ID,
-								ContainerName: name,
-								Database:      db,
-								Home:          home,
-							}
-
-							// create the backup command based on the compatibility type
-							switch c.Labels[containerlabels.DatabaseCompatibility] {
-							case "postgres":
-								opts.Commands = []string{"pg_dump", "--username=nitro", db, "-f", "/tmp/" + opts.BackupName}
-							default:
-								opts.Commands = []string{"/usr/bin/mysqldump", "-h", "127.0.0.1", "-unitro", "--password=nitro", db, "--result-file=" + "/tmp/" + opts.BackupName}
-							}
-
-							output.Pending("creating backup", opts.BackupName)
-
-							// backup the container
-							if err := backup.Perform(cmd.Context(), docker, opts); err != nil {
-								output.Warning()
-								output.Info("Unable to backup database", db, err.Error())
-								break
-							}
-
-							output.Done()
-						}
-
-						// show where all backups are saved for this container
-						output.Info("Backups saved in", filepath.Join(home, config.DirectoryName, name), "💾")
-					}
+		state.appliedFile = appliedPath(home, cfg.GetFile())
 
-					// stop and remove a container we don't know about
-					if err := docker.ContainerStop(cmd.Context(), c.ID, nil); err != nil {
-						return err
-					}
+		hash, err := configHash(home, cfg, fmt.Sprintf("no-proxy=%t", noProxy), fmt.Sprintf("skip-hosts=%t", skipHosts(cmd, cfg)))
+		if err != nil {
+			return state, err
+		}
 
-					// remove container
-					if err := docker.ContainerRemove(cmd.Context(), c.ID, types.ContainerRemoveOptions{}); err != nil {
-						return err
-					}
+		last, err := readApplied(state.appliedFile)
+		if err != nil {
+			return state, err
+		}
 
-					output.Done()
+		if last != nil {
+			unchanged, err := last.matches(ctx, docker, hash)
+			if err != nil {
+				return state, err
+			}
+
+			if unchanged {
+				// the containers are known so they are not orphans
+				for _, c := range last.Containers {
+					state.known[c.ID] = true
 				}
+
+				state.hash = hash
+
+				output.Info("Nothing changed since the last apply, use `nitro apply --recreate` to recreate the containers")
+
+				return state, nil
 			}
 
-			if isWSL {
-				output.Info(fmt.Sprintf("For your hostnames to work, add the following to `%s`:", `C:\Windows\System32\Drivers\etc\hosts`))
-				output.Info("---- COPY BELOW ----")
-				output.Info(fmt.Sprintf(`# <nitro>
-%s %s
-# </nitro>`, "127.0.0.1", strings.Join(hostnames, " ")))
-				output.Info("---- COPY ABOVE ----")
+			// remove the last apply until the changes are applied
+			if err := removeApplied(state.appliedFile); err != nil {
+				return state, err
 			}
+		}
 
-			output.Info("Nitro is up and running 😃")
+		state.hash = hash
+	}
 
-			return nil
-		},
-		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := cmd.Context()
-			if ctx == nil {
-				// when we call commands from other commands (e.g. init)
-				// the context could be nil, so we set it to the parent
-				// context just in case.
-				ctx = context.Background()
-			}
+	// make sure the paths that are mounted exist before creating any containers
+	sources, err := mountSources(home, cfg, enabledSites)
+	if err != nil {
+		return state, err
+	}
 
-			// load the config
-			cfg, err := config.Load(home)
-			if err != nil {
-				return err
+	if err := preflight(sources, output); err != nil {
+		return state, err
+	}
+
+	// time each phase to show what is slow
+	state.timings = newStopwatch()
+	state.timings.Start("network")
+
+	output.Info("Checking network…")
+
+	// check the network and create it if this is a new environment
+	network, err := findOrCreateNetwork(ctx, docker, env.network, output)
+	if err != nil {
+		return state, err
+	}
+
+	output.Success("network ready")
+
+	// the proxy is only needed when checking sites
+	if checkSites && !noProxy {
+		state.timings.Start("proxy")
+
+		output.Info("Checking proxy…")
+
+		// check the proxy and ensure its started, a new environment will create the proxy
+		proxy, err := proxycontainer.FindOrCreate(ctx, docker, output, env.proxy, network.ID, cfg.Proxy, cfg.GetBindAddress(), cfg.Registry)
+		if err != nil {
+			return state, err
+		}
+
+		// make sure the proxy is using the ports and bind address from the config
+		if proxy.ID != "" {
+			if err := proxycontainer.VerifyPorts(ctx, docker, output, env.proxy, proxy.ID, network.ID, cfg.Proxy, cfg.GetBindAddress(), cfg.Registry); err != nil {
+				return state, err
 			}
+		}
 
-			// create a filter for the environment
-			filter := filters.NewArgs()
-			filter.Add("label", containerlabels.Nitro+"=true")
+		output.Success("proxy ready")
+	}
 
-			// add the filter for the network name
-			filter.Add("name", "nitro-network")
+	// remove the databases, services, and custom containers so they are created again, when
+	// applying every environment they are removed once before the first environment (see applyAll)
+	if recreate == recreateAll && (checkDatabases || checkServices) && !env.all {
+		output.Pending("removing containers to recreate")
 
-			output.Info("Checking network…")
+		if _, err := removeForRecreate(ctx, docker, checkSites, checkDatabases, checkServices); err != nil {
+			output.Warning()
+			return state, err
+		}
 
-			// check the network
-			var network types.NetworkResource
-			networks, err := docker.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
-			if err != nil {
-				return fmt.Errorf("unable to list docker networks\n%w", err)
+		output.Done()
+	}
+
+	switch checkDatabases {
+	case false:
+		// keep the database hostnames for the hosts file
+		for _, db := range cfg.Databases {
+			if hostname, err := db.GetHostname(); err == nil {
+				state.hostnames = append(state.hostnames, hostname)
 			}
 
-			// get the network for the environment
-			for _, n := range networks {
-				if n.Name == "nitro-network" {
-					network = n
-					break
+			if cfg.Replication && db.Replica != nil {
+				if hostname, err := db.GetReplicaHostname(); err == nil {
+					state.hostnames = append(state.hostnames, hostname)
 				}
 			}
+		}
+	default:
+		state.timings.Start("databases")
+
+		output.Info("Checking databases…")
+
+		// check the databases
+		for _, db := range cfg.Databases {
+			// replicas are ignored unless replication is enabled
+			if db.Replica != nil && !cfg.Replication {
+				n, _ := db.GetHostname()
+				output.Info("Skipping the replica for", n+", set `replication: true` in the config to enable replicas")
+
+				db.Replica = nil
+			}
 
-			// if the network is not found
-			if network.ID == "" {
-				output.Info("No network was found…\nrun `nitro init` to get started")
-				return nil
+			n, _ := db.GetHostname()
+			output.Pending("checking", n)
+
+			// start or create the database
+			id, hostname, err := databasecontainer.StartOrCreate(ctx, docker, home, network.ID, db, cfg.GetDatabaseAliases(db), cfg.GetDatabaseBindAddress(db), cfg.Registry, output)
+			if err != nil {
+				output.Warning()
+				return state, err
 			}
 
-			// remove the filter
-			filter.Del("name", "nitro-network")
+			// set the container as known
+			state.known[id] = true
 
-			output.Success("network ready")
+			// add the hostname to the hosts files
+			state.hostnames = append(state.hostnames, hostname)
 
-			output.Info("Checking proxy…")
+			output.Done()
 
-			// check the proxy and ensure its started
-			_, err = proxycontainer.FindAndStart(ctx, docker)
-			if errors.Is(err, proxycontainer.ErrNoProxyContainer) {
-				// create the proxy
-				if err := proxycontainer.Create(ctx, docker, output, network.ID); err != nil {
-					output.Info("unable to find the nitro proxy…\n run `nitro init` to resolve")
-					return err
-				}
+			if db.Replica == nil {
+				continue
 			}
-			if err != nil && !errors.Is(err, proxycontainer.ErrNoProxyContainer) {
-				return err
+
+			r, _ := db.GetReplicaHostname()
+			output.Pending("checking", r)
+
+			// start or create the replica after the database
+			replicaID, replicaHostname, err := databasecontainer.StartOrCreateReplica(ctx, docker, network.ID, id, db, cfg.GetDatabaseBindAddress(db), cfg.Registry, output)
+			if err != nil {
+				output.Warning()
+				return state, err
 			}
 
-			output.Success("proxy ready")
+			state.known[replicaID] = true
 
-			output.Info("Checking databases…")
+			state.hostnames = append(state.hostnames, replicaHostname)
 
-			// check the databases
-			for _, db := range cfg.Databases {
-				n, _ := db.GetHostname()
-				output.Pending("checking", n)
+			output.Done()
+		}
+	}
 
-				// start or create the database
-				id, hostname, err := databasecontainer.StartOrCreate(ctx, docker, network.ID, db, output)
-				if err != nil {
-					output.Warning()
-					return err
-				}
+	switch checkServices {
+	case false:
+		// keep the service hostnames for the hosts file
+		if cfg.Services.DynamoDB {
+			state.hostnames = append(state.hostnames, dynamodb.Host)
+		}
+
+		if cfg.Services.Elasticsearch {
+			state.hostnames = append(state.hostnames, elasticsearch.Host)
+		}
+
+		if cfg.Services.Mailhog {
+			state.hostnames = append(state.hostnames, mailhog.Host)
+		}
+
+		if cfg.Services.Meilisearch {
+			state.hostnames = append(state.hostnames, meilisearch.Host)
+		}
 
-				// set the container as known
-				knownContainers[id] = true
+		if cfg.Services.Minio {
+			state.hostnames = append(state.hostnames, minio.Host)
+		}
+
+		if cfg.Services.Redis {
+			state.hostnames = append(state.hostnames, redis.Host)
+		}
+	default:
+		state.timings.Start("services")
+
+		output.Info("Checking services…")
 
-				// add the hostname to the hosts files
-				hostnames = append(hostnames, hostname)
+		// services that are enabled in another environment are not removed
 
-				output.Done()
+		// check dynamodb service
+		switch cfg.Services.DynamoDB {
+		case false:
+			if env.services.DynamoDB {
+				break
 			}
 
-			output.Info("Checking services…")
+			output.Pending("checking dynamodb service")
 
-			// check dynamodb service
-			switch cfg.Services.DynamoDB {
-			case false:
-				output.Pending("checking dynamodb service")
+			if err := dynamodb.VerifyRemoved(ctx, docker, output); err != nil {
+				output.Warning()
+				return state, err
+			}
 
-				if err := dynamodb.VerifyRemoved(ctx, docker, output); err != nil {
-					output.Warning()
-					return err
-				}
+			output.Done()
+		default:
+			output.Pending("checking dynamodb service")
 
-				output.Done()
-			default:
-				output.Pending("checking dynamodb service")
+			id, hostname, err := dynamodb.VerifyCreated(ctx, docker, network.ID, cfg.GetServiceBindAddress(dynamodb.Label), cfg.Registry, output)
+			if err != nil {
+				return state, err
+			}
 
-				id, hostname, err := dynamodb.VerifyCreated(ctx, docker, network.ID, output)
-				if err != nil {
-					return err
-				}
+			if id != "" {
+				state.known[id] = true
+			}
 
-				if id != "" {
-					knownContainers[id] = true
-				}
+			if hostname != "" {
+				state.hostnames = append(state.hostnames, hostname)
+			}
 
-				if hostname != "" {
-					hostnames = append(hostnames, hostname)
-				}
+			output.Done()
+		}
 
-				output.Done()
+		// check elasticsearch service
+		switch cfg.Services.Elasticsearch {
+		case false:
+			if env.services.Elasticsearch {
+				break
 			}
 
-			// check mailhog service
-			switch cfg.Services.Mailhog {
-			case false:
-				output.Pending("checking mailhog service")
+			output.Pending("checking elasticsearch service")
 
-				// make sure the service container is removed
-				if err := mailhog.VerifyRemoved(ctx, docker, output); err != nil {
-					return err
-				}
+			// make sure the service container is removed, the volume is kept
+			if err := elasticsearch.VerifyRemoved(ctx, docker, output); err != nil {
+				output.Warning()
+				return state, err
+			}
 
-				output.Done()
-			default:
-				output.Pending("checking mailhog service")
+			output.Done()
+		default:
+			output.Pending("checking elasticsearch service")
 
-				// verify the mailhog container is created
-				id, hostname, err := mailhog.VerifyCreated(ctx, docker, network.ID, output)
-				if err != nil {
-					return err
-				}
+			id, hostname, err := elasticsearch.VerifyCreated(ctx, docker, network.ID, cfg.GetServiceBindAddress(elasticsearch.Label), cfg.Registry, output)
+			if err != nil {
+				output.Warning()
+				return state, err
+			}
 
-				if id != "" {
-					knownContainers[id] = true
-				}
+			state.known[id] = true
+			state.hostnames = append(state.hostnames, hostname)
 
-				if hostname != "" {
-					hostnames = append(hostnames, hostname)
-				}
+			output.Done()
+		}
 
-				output.Done()
+		// check mailhog service
+		switch cfg.Services.Mailhog {
+		case false:
+			if env.services.Mailhog {
+				break
 			}
 
-			// check minio service
-			switch cfg.Services.Minio {
-			case false:
-				// make sure the service container is removed
-				err := minio.VerifyRemoved(ctx, docker, output)
-				if err != nil {
-					return err
-				}
-			default:
-				output.Pending("checking minio service")
+			output.Pending("checking mailhog service")
 
-				// verify the minio container is created
-				id, hostname, err := minio.VerifyCreated(ctx, docker, network.ID, output)
-				if err != nil {
-					return err
-				}
+			// make sure the service container is removed
+			if err := mailhog.VerifyRemoved(ctx, docker, output); err != nil {
+				return state, err
+			}
 
-				if id != "" {
-					knownContainers[id] = true
-				}
+			output.Done()
+		default:
+			output.Pending("checking mailhog service")
 
-				if hostname != "" {
-					hostnames = append(hostnames, hostname)
-				}
+			// verify the mailhog container is created
+			id, hostname, err := mailhog.VerifyCreated(ctx, docker, network.ID, cfg.GetServiceBindAddress(mailhog.Label), cfg.Registry, output)
+			if err != nil {
+				return state, err
+			}
 
-				output.Done()
+			if id != "" {
+				state.known[id] = true
 			}
 
-			// check redis service
-			switch cfg.Services.Redis {
-			case false:
-				output.Pending("checking redis service")
+			if hostname != "" {
+				state.hostnames = append(state.hostnames, hostname)
+			}
 
-				if err := redis.VerifyRemoved(ctx, docker, output); err != nil {
-					return err
-				}
+			output.Done()
+		}
 
-				output.Done()
-			default:
-				output.Pending("checking redis service")
+		// check meilisearch service
+		switch cfg.Services.Meilisearch {
+		case false:
+			if env.services.Meilisearch {
+				break
+			}
 
-				id, hostname, err := redis.VerifyCreated(ctx, docker, network.ID, output)
-				if err != nil {
-					return err
-				}
+			output.Pending("checking meilisearch service")
 
-				if id != "" {
-					knownContainers[id] = true
-				}
+			// make sure the service container is removed, the volume is kept
+			if err := meilisearch.VerifyRemoved(ctx, docker, output); err != nil {
+				output.Warning()
+				return state, err
+			}
 
-				if hostname != "" {
-					hostnames = append(hostnames, hostname)
-				}
+			output.Done()
+		default:
+			output.Pending("checking meilisearch service")
 
-				output.Done()
+			id, hostname, err := meilisearch.VerifyCreated(ctx, docker, network.ID, cfg.GetServiceBindAddress(meilisearch.Label), cfg.Registry, output)
+			if err != nil {
+				output.Warning()
+				return state, err
 			}
 
-			if len(cfg.Containers) > 0 {
-				// get all of the containers
-				output.Info("Checking containers...")
+			state.known[id] = true
+			state.hostnames = append(state.hostnames, hostname)
 
-				for _, c := range cfg.Containers {
-					output.Pending("checking", fmt.Sprintf("%s.containers.nitro", c.Name))
+			output.Done()
+		}
 
-					// start, update or create the custom container
-					id, err := customcontainer.StartOrCreate(ctx, docker, home, network.ID, c)
-					if err != nil {
-						output.Warning()
-						return err
-					}
+		// check minio service
+		switch cfg.Services.Minio {
+		case false:
+			if env.services.Minio {
+				break
+			}
 
-					knownContainers[id] = true
+			// make sure the service container is removed
+			err := minio.VerifyRemoved(ctx, docker, output)
+			if err != nil {
+				return state, err
+			}
+		default:
+			output.Pending("checking minio service")
 
-					output.Done()
-				}
+			// verify the minio container is created
+			id, hostname, err := minio.VerifyCreated(ctx, docker, network.ID, cfg.GetServiceBindAddress(minio.Label), cfg.Registry, cfg.Services.IsShared(minio.Label), output)
+			if err != nil {
+				return state, err
 			}
 
-			if len(cfg.Sites) > 0 {
-				// get all of the sites, their local path, the php version, and the type of project (nginx or PHP-FPM)
-				output.Info("Checking sites…")
+			if id != "" {
+				state.known[id] = true
+			}
 
-				// get the envs for the sites
-				for _, site := range cfg.Sites {
-					output.Pending("checking", site.Hostname)
+			if hostname != "" {
+				state.hostnames = append(state.hostnames, hostname)
+			}
 
-					// start, update or create the site container
-					id, err := sitecontainer.StartOrCreate(ctx, docker, home, network.ID, site, cfg)
-					if err != nil {
-						output.Warning()
-						return err
-					}
+			output.Done()
+		}
 
-					knownContainers[id] = true
+		// check redis service
+		switch cfg.Services.Redis {
+		case false:
+			if env.services.Redis {
+				break
+			}
 
-					output.Done()
-				}
+			output.Pending("checking redis service")
+
+			if err := redis.VerifyRemoved(ctx, docker, output); err != nil {
+				return state, err
+			}
+
+			output.Done()
+		default:
+			output.Pending("checking redis service")
+
+			id, hostname, err := redis.VerifyCreated(ctx, docker, network.ID, cfg.GetServiceBindAddress(redis.Label), cfg.Registry, cfg.Services.RedisConfig, cfg.Services.IsShared(redis.Label), output)
+			if err != nil {
+				return state, err
 			}
 
-			output.Info("Checking proxy…")
+			if id != "" {
+				state.known[id] = true
+			}
+
+			if hostname != "" {
+				state.hostnames = append(state.hostnames, hostname)
+			}
+
+			output.Done()
+		}
+	}
+
+	// custom containers are only checked when applying everything
+	if checkSites && checkDatabases && checkServices && len(cfg.Containers) > 0 {
+		// get all of the containers
+		state.timings.Start("containers")
 
-			output.Pending("updating proxy")
+		output.Info("Checking containers...")
 
-			if err := updateProxy(ctx, docker, nitrod, cfg); err != nil {
+		for _, c := range cfg.Containers {
+			output.Pending("checking", fmt.Sprintf("%s.containers.nitro", c.Name))
+
+			// start, update or create the custom container
+			id, err := customcontainer.StartOrCreate(ctx, docker, home, network.ID, c, cfg.GetBindAddress(), skipPull, output)
+			if err != nil {
 				output.Warning()
-				return err
+				return state, err
 			}
 
+			state.known[id] = true
+
 			output.Done()
+		}
+	}
 
-			// should we update the hosts file?
-			if os.Getenv("NITRO_EDIT_HOSTS") == "false" || cmd.Flag("skip-hosts").Value.String() == "true" {
-				// skip updating the hosts file
-				return nil
+	if checkSites && len(cfg.Sites) > 0 {
+		// get all of the sites, their local path, the php version, and the type of project (nginx or PHP-FPM)
+		state.timings.Start("sites")
+
+		output.Info("Checking sites…")
+
+		opts := sitecontainer.Options{ForcePull: forcePull, SkipPull: skipPull, Recreate: recreate != ""}
+
+		// remove the containers for disabled sites, the config is kept so they can be enabled again
+		for _, site := range disabledSites {
+			removed, err := sitecontainer.Remove(ctx, docker, site.Hostname)
+			if err != nil {
+				return state, err
 			}
 
-			// get all possible hostnames
-			for _, s := range cfg.Sites {
-				hostnames = append(hostnames, s.Hostname)
-				hostnames = append(hostnames, s.Aliases...)
+			if removed {
+				output.Success(site.Hostname, "disabled")
+			}
+		}
+
+		// start, update or create the site containers, showing each site as it completes
+		var failed error
+		reconcileSites(ctx, enabledSites, siteConcurrency, output, func(ctx context.Context, site config.Site, output terminal.Outputer) (string, error) {
+			return sitecontainer.StartOrCreate(ctx, docker, home, network.ID, site, cfg, opts, output)
+		}, func(r siteResult) {
+			if r.err != nil {
+				output.Info("  \u2717", r.hostname, r.err.Error())
+
+				if failed == nil {
+					failed = r.err
+				}
+
+				return
 			}
 
-			// get custom container hostnames
-			for _, c := range cfg.Containers {
-				hostnames = append(hostnames, fmt.Sprintf("%s.containers.nitro", c.Name))
+			state.known[r.id] = true
+
+			output.Success(r.hostname, "ready")
+		})
+
+		if failed != nil {
+			return state, failed
+		}
+	}
+
+	// only update the proxy when the sites have been checked
+	if checkSites && !noProxy {
+		state.timings.Start("proxy")
+
+		output.Info("Checking proxy…")
+
+		output.Pending("updating proxy")
+
+		if err := updateProxy(ctx, docker, env.nitrod, cfg, output); err != nil {
+			output.Warning()
+			return state, err
+		}
+
+		output.Done()
+	}
+
+	// run the hooks for the sites that were applied
+	if hooks := afterApplyHooks(cfg, enabledSites); checkSites && len(hooks) > 0 {
+		state.timings.Start("hooks")
+
+		output.Info("Running hooks…")
+
+		if err := runHooks(ctx, docker, hooks, os.Stdout, os.Stderr, output); err != nil {
+			return state, err
+		}
+	}
+
+	// set the restart policy so containers come back after docker restarts
+	state.timings.Start("restart policies")
+	if err := updateRestartPolicies(ctx, docker, cfg.Restart); err != nil {
+		return state, err
+	}
+
+	// should we update the hosts file?
+	if skipHosts(cmd, cfg) {
+		// skip updating the hosts file
+		return state, nil
+	}
+
+	state.timings.Start("hosts")
+
+	// get all possible hostnames
+	for _, s := range cfg.EnabledSites() {
+		state.hostnames = append(state.hostnames, s.GetHostsEntries()...)
+	}
+
+	// get custom container hostnames
+	for _, c := range cfg.Containers {
+		state.hostnames = append(state.hostnames, fmt.Sprintf("%s.containers.nitro", c.Name))
+	}
+
+	if len(state.hostnames) > 0 {
+		// is this wsl?
+		state.wsl = wsl.IsWSL()
+
+		// set the hosts file based on the OS
+		if runtime.GOOS == "windows" {
+			defaultFile = `C:\Windows\System32\Drivers\etc\hosts`
+		}
+
+		// check if hosts is already up to date
+		updated, err := hostedit.IsUpdated(defaultFile, "127.0.0.1", state.hostnames...)
+		if err != nil {
+			return state, err
+		}
+
+		// if the hosts file is not updated
+		if !updated {
+			// get the executable
+			nitro, err := os.Executable()
+			if err != nil {
+				return state, fmt.Errorf("unable to locate the nitro path, %w", err)
 			}
 
-			if len(hostnames) > 0 {
-				// is this wsl?
-				isWSL = wsl.IsWSL()
+			// run the hosts command
+			switch runtime.GOOS {
+			case "windows":
+				// windows users should be running as admin, so just execute the hosts command
+				// as is
+				c := exec.Command(nitro, "hosts", "--hostnames="+strings.Join(state.hostnames, ","))
+
+				c.Stdout = os.Stdout
+				c.Stderr = os.Stderr
 
-				// set the hosts file based on the OS
-				if runtime.GOOS == "windows" {
-					defaultFile = `C:\Windows\System32\Drivers\etc\hosts`
+				if c.Run() != nil {
+					return state, err
 				}
+			default:
+				output.Info("Updating hosts file (you might be prompted for your password)")
 
-				// check if hosts is already up to date
-				updated, err := hostedit.IsUpdated(defaultFile, "127.0.0.1", hostnames...)
-				if err != nil {
-					return err
+				// add the hosts
+				if err := sudo.Run(nitro, "nitro", "hosts", "--hostnames="+strings.Join(state.hostnames, ",")); err != nil {
+					return state, err
 				}
+			}
+		}
+	}
+
+	return state, nil
+}
+
+// cleanupEnvironment finds the containers that are not in the config of the environment,
+// which are removed when pruning, and shows how to reach the sites.
+func cleanupEnvironment(cmd *cobra.Command, args []string, home string, docker client.CommonAPIClient, output terminal.Outputer, env environment, state *applyState) error {
+	// nothing was changed, so there is nothing to clean up
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return nil
+	}
+
+	docker = verboseClient(cmd, docker)
+
+	// look for all of the containers in the environment
+	containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{All: true, Filters: containerlabels.Environment()})
+	if err != nil {
+		return fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	state.timings.Start("cleanup")
 
-				// if the hosts file is not updated
-				if !updated {
-					// get the executable
-					nitro, err := os.Executable()
-					if err != nil {
-						return fmt.Errorf("unable to locate the nitro path, %w", err)
+	if len(containers) > 0 {
+		output.Info("Cleaning up...")
+	}
+
+	// determine which parts of the environment were checked
+	checkSites, checkDatabases, checkServices := scope(cmd)
+	site, _ := cmd.Flags().GetString("site")
+
+	// containers that are not in the config are only removed when pruning
+	prune, _ := cmd.Flags().GetBool("prune")
+
+	var orphans, removed []string
+	for _, c := range containers {
+		// start the container if not running
+		if c.State != "running" {
+			for _, command := range cmd.Root().Commands() {
+				if command.Use == "start" {
+					if err := command.RunE(cmd, []string{}); err != nil {
+						return err
 					}
+				}
+			}
+		}
+
+		// containers from the other environments are not orphans when applying every environment
+		if !env.all && isOrphan(c, state.known, site, checkSites, checkDatabases, checkServices) {
+			// set the container name
+			name := strings.TrimLeft(c.Names[0], "/")
+
+			if !prune {
+				orphans = append(orphans, name)
+				continue
+			}
+
+			output.Pending("removing", name)
+
+			// only perform a backup if the container is for databases
+			if c.Labels[containerlabels.DatabaseEngine] != "" {
+				// get all of the databases
+				databases, err := backup.Databases(cmd.Context(), docker, c.ID, c.Labels[containerlabels.DatabaseCompatibility])
+				if err != nil {
+					output.Warning()
+					output.Info("Unable to get the databases from", name, err.Error())
+					break
+				}
+
+				// backup each database
+				for _, db := range databases {
+					// create the database specific backup options
+					opts := backup.NewOptions(home, c.ID, name, c.Labels[containerlabels.DatabaseCompatibility], db)
 
-					// run the hosts command
-					switch runtime.GOOS {
-					case "windows":
-						// windows users should be running as admin, so just execute the hosts command
-						// as is
-						c := exec.Command(nitro, "hosts", "--hostnames="+strings.Join(hostnames, ","))
-
-						c.Stdout = os.Stdout
-						c.Stderr = os.Stderr
-
-						if c.Run() != nil {
-							return err
-						}
-					default:
-						output.Info("Updating hosts file (you might be prompted for your password)")
-
-						// add the hosts
-						if err := sudo.Run(nitro, "nitro", "hosts", "--hostnames="+strings.Join(hostnames, ",")); err != nil {
-							return err
-						}
+					output.Pending("creating backup", opts.BackupName)
+
+					// backup the container
+					if err := backup.Perform(cmd.Context(), docker, opts); err != nil {
+						output.Warning()
+						output.Info("Unable to backup database", db, err.Error())
+						break
 					}
+
+					output.Done()
 				}
+
+				// show where all backups are saved for this container
+				output.Info("Backups saved in", filepath.Join(home, config.DirectoryName, name), "💾")
+			}
+
+			// stop and remove a container we don't know about
+			if err := dockerclient.RemoveContainer(cmd.Context(), docker, c, nil, types.ContainerRemoveOptions{}); err != nil {
+				return fmt.Errorf("%w (%s)", err, c.Image)
+			}
+
+			output.Done()
+
+			removed = append(removed, name)
+		}
+	}
+
+	if len(removed) > 0 {
+		output.Info("Removed containers that are not in the config:", strings.Join(removed, ", "))
+	}
+
+	if len(orphans) > 0 {
+		output.Info("Found containers that are not in the config:", strings.Join(orphans, ", "))
+		output.Info("Run `nitro apply --prune` to remove them")
+	}
+
+	// record the environment so the next apply can be skipped if nothing changes
+	if state.hash != "" {
+		if err := writeApplied(cmd.Context(), docker, state.appliedFile, state.hash); err != nil {
+			output.Info("Unable to save the last apply,", err.Error())
+		}
+	}
+
+	if state.wsl {
+		output.Info(fmt.Sprintf("For your hostnames to work, add the following to `%s`:", `C:\Windows\System32\Drivers\etc\hosts`))
+		output.Info("---- COPY BELOW ----")
+		output.Info(fmt.Sprintf(`# <nitro>
+%s %s
+# </nitro>`, "127.0.0.1", strings.Join(state.hostnames, " ")))
+		output.Info("---- COPY ABOVE ----")
+	}
+
+	// show how to reach the sites when the proxy is not using the default ports
+	if noProxy, _ := cmd.Flags().GetBool("no-proxy"); noProxy {
+		output.Info("The proxy was skipped, sites are only reachable from the nitro network")
+	} else if cfg, err := env.load(home); err == nil {
+		httpPort, httpsPort := proxycontainer.HostPorts(cfg.Proxy)
+		if httpPort != "80" || httpsPort != "443" {
+			output.Info(fmt.Sprintf("Sites are available on HTTP port %s and HTTPS port %s (e.g. https://mysite.nitro:%s)", httpPort, httpsPort, httpsPort))
+		}
+	}
+
+	// show the URLs and credentials, unless the output is quiet
+	if quiet, _ := cmd.Flags().GetBool("quiet"); !quiet {
+		if cfg, err := env.load(home); err == nil {
+			noProxy, _ := cmd.Flags().GetBool("no-proxy")
+			for _, l := range summary(cfg, noProxy) {
+				output.Info(l)
 			}
+		}
+	}
+
+	// show how long each phase took
+	output.Info("Timing:")
+	for _, l := range state.timings.Summary() {
+		output.Info(l)
+	}
+
+	output.Info("Nitro is up and running 😃")
+
+	// keep applying changes when the config file changes
+	if watchFlag, _ := cmd.Flags().GetBool("watch"); watchFlag && !watching {
+		return watch(cmd, args, home, output)
+	}
+
+	return nil
+}
+
+// skipHosts returns true if the hosts file should not be edited. The
+// skip-hosts flag overrides the dnsmasq and edit_hosts config settings,
+// which override the NITRO_EDIT_HOSTS environment variable. The hosts
+// file is never edited without the proxy.
+func skipHosts(cmd *cobra.Command, cfg *config.Config) bool {
+	if noProxy, _ := cmd.Flags().GetBool("no-proxy"); noProxy {
+		return true
+	}
+
+	if cmd.Flags().Changed("skip-hosts") {
+		skip, _ := cmd.Flags().GetBool("skip-hosts")
+		return skip
+	}
+
+	// dnsmasq resolves the hostnames instead of the hosts file
+	if cfg.Dnsmasq {
+		return true
+	}
+
+	if cfg.EditHosts != nil {
+		return !*cfg.EditHosts
+	}
+
+	return os.Getenv("NITRO_EDIT_HOSTS") == "false"
+}
+
+// scope returns which parts of the environment should be checked based on the
+// sites-only, databases-only, and services-only flags. If none of the flags are
+// set, everything is checked. Applying a single site only checks the sites.
+func scope(cmd *cobra.Command) (sites bool, databases bool, services bool) {
+	if site, _ := cmd.Flags().GetString("site"); site != "" {
+		return true, false, false
+	}
+
+	sites, _ = cmd.Flags().GetBool("sites-only")
+	databases, _ = cmd.Flags().GetBool("databases-only")
+	services, _ = cmd.Flags().GetBool("services-only")
+
+	if !sites && !databases && !services {
+		return true, true, true
+	}
+
+	return sites, databases, services
+}
 
-			return nil
+// inScope takes the labels for a container and returns true if the container
+// is part of the environment that was checked.
+func inScope(labels map[string]string, sites, databases, services bool) bool {
+	switch labels[containerlabels.Type] {
+	case "database":
+		return databases
+	case dynamodb.Label, elasticsearch.Label, mailhog.Label, meilisearch.Label, minio.Label, redis.Label:
+		return services
+	}
+
+	// site containers are identified by the host label
+	if labels[containerlabels.Host] != "" {
+		return sites
+	}
+
+	// all other containers are only removed when checking everything
+	return sites && databases && services
+}
+
+// isOrphan returns true if the container was not created or checked by apply,
+// which means it is no longer in the config. The proxy, containers that were
+// not checked, and other sites when applying a single site are never orphans.
+func isOrphan(c types.Container, known map[string]bool, site string, sites, databases, services bool) bool {
+	if known[c.ID] {
+		return false
+	}
+
+	if c.Labels[containerlabels.Proxy] != "" {
+		return false
+	}
+
+	if !inScope(c.Labels, sites, databases, services) {
+		return false
+	}
+
+	if site != "" && c.Labels[containerlabels.Host] != site {
+		return false
+	}
+
+	return true
+}
+
+// verboseClient returns a docker client that logs each API call to stderr when
+// the verbose flag is set, otherwise the client is returned as is.
+func verboseClient(cmd *cobra.Command, docker client.CommonAPIClient) client.CommonAPIClient {
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+		return dockerclient.NewVerbose(docker, cmd.ErrOrStderr())
+	}
+
+	return docker
+}
+
+// pullTimeoutClient returns a docker client that cancels image pulls that take
+// longer than the pull timeout flag, a timeout of 0 never cancels a pull.
+func pullTimeoutClient(cmd *cobra.Command, docker client.CommonAPIClient) client.CommonAPIClient {
+	if timeout, _ := cmd.Flags().GetDuration("pull-timeout"); timeout > 0 {
+		return dockerclient.NewPullTimeout(docker, timeout)
+	}
+
+	return docker
+}
+
+// findOrCreateNetwork returns the network with the name for the environment
+// (e.g. nitro-network), if the network does not exist it will be created.
+func findOrCreateNetwork(ctx context.Context, docker client.NetworkAPIClient, name string, output terminal.Outputer) (types.NetworkResource, error) {
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("name", name)
+
+	networks, err := docker.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
+	if err != nil {
+		return types.NetworkResource{}, fmt.Errorf("unable to list docker networks\n%w", err)
+	}
+
+	// since the filter is fuzzy, do an exact match
+	for _, n := range networks {
+		if n.Name == name {
+			return n, nil
+		}
+	}
+
+	output.Pending("creating network")
+
+	resp, err := docker.NetworkCreate(ctx, name, types.NetworkCreate{
+		Driver:         "bridge",
+		Attachable:     true,
+		CheckDuplicate: true,
+		Labels: map[string]string{
+			containerlabels.Nitro:   "true",
+			containerlabels.Network: "true",
 		},
+	})
+	if err != nil {
+		output.Warning()
+		return types.NetworkResource{}, fmt.Errorf("unable to create the network, %w", err)
 	}
 
-	// add flag to skip pulling images
-	cmd.Flags().Bool("skip-hosts", false, "skip modifying the hosts file")
+	output.Done()
 
-	return cmd
+	return types.NetworkResource{ID: resp.ID, Name: name}, nil
 }
 
-func updateProxy(ctx context.Context, docker client.ContainerAPIClient, nitrod protob.NitroClient, cfg *config.Config) error {
+// updateRestartPolicies sets the restart policy on every nitro container based on
+// the restart config. Composer and npm containers are never restarted.
+func updateRestartPolicies(ctx context.Context, docker client.ContainerAPIClient, restart config.Restart) error {
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: containerlabels.Environment()})
+	if err != nil {
+		return fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	for _, c := range containers {
+		switch c.Labels[containerlabels.Type] {
+		case "composer", "npm":
+			continue
+		}
+
+		policy := container.RestartPolicy{Name: restart.Policy(c.Labels[containerlabels.Host] != "")}
+
+		if _, err := docker.ContainerUpdate(ctx, c.ID, container.UpdateConfig{RestartPolicy: policy}); err != nil {
+			return fmt.Errorf("unable to set the restart policy for %s, %w", strings.TrimLeft(c.Names[0], "/"), err)
+		}
+	}
+
+	return nil
+}
+
+// sitePort is the port nginx listens on in the site containers. The proxy
+// reaches the sites on the nitro network, so it does not change with the
+// HTTP and HTTPS ports the proxy binds to on the host (see proxycontainer.HostPorts).
+const sitePort = 8080
+
+func updateProxy(ctx context.Context, docker client.ContainerAPIClient, nitrod protob.NitroClient, cfg *config.Config, output terminal.Outputer) error {
 	// convert the sites into the gRPC API Apply request
 	sites := make(map[string]*protob.Site)
-	for _, s := range cfg.Sites {
+	for _, s := range cfg.EnabledSites() {
 		// create the site
 		sites[s.Hostname] = &protob.Site{
 			Hostname: s.Hostname,
 			Aliases:  strings.Join(s.Aliases, ","),
-			Port:     8080,
+			Port:     sitePort,
 		}
 	}
 
@@ -531,6 +1278,14 @@ func updateProxy(ctx context.Context, docker client.ContainerAPIClient, nitrod p
 		}
 	}
 
+	// check the meilisearch service, which has a web interface for searching
+	if cfg.Services.Meilisearch {
+		sites[meilisearch.Host] = &protob.Site{
+			Hostname: meilisearch.Host,
+			Port:     7700,
+		}
+	}
+
 	// check the minio service
 	if cfg.Services.Minio {
 		sites["minio.service.nitro"] = &protob.Site{
@@ -555,11 +1310,17 @@ func updateProxy(ctx context.Context, docker client.ContainerAPIClient, nitrod p
 	}
 
 	// wait for the api to be ready
-	for {
-		_, err := nitrod.Ping(ctx, &protob.PingRequest{})
-		if err == nil {
-			break
-		}
+	if err := nitroclient.WaitForAPI(ctx, nitrod); err != nil {
+		return err
+	}
+
+	// the sites are still sent to a proxy with a different api version, but
+	// warn that the proxy may not understand all of the request
+	switch err := nitroclient.CheckAPIVersion(ctx, nitrod); {
+	case errors.Is(err, nitroclient.ErrOutdatedProxy), errors.Is(err, nitroclient.ErrOutdatedCLI):
+		output.Info(fmt.Sprintf("Warning: %s", err))
+	case err != nil:
+		return err
 	}
 
 	// configure the proxy with the sites
@@ -0,0 +1,440 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/command/apply/internal/databasecontainer"
+	"github.com/craftcms/nitro/command/apply/internal/sitecontainer"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+type mockOutputer struct {
+	terminal.Outputer
+}
+
+func (m mockOutputer) Pending(s ...string) {}
+
+func (m mockOutputer) Done() {}
+
+func (m mockOutputer) Warning() {}
+
+func Test_skipHosts(t *testing.T) {
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name      string
+		flag      string
+		noProxy   bool
+		dnsmasq   bool
+		editHosts *bool
+		env       string
+		want      bool
+	}{
+		{
+			name: "hosts are edited by default",
+			want: false,
+		},
+		{
+			name: "the environment variable skips editing",
+			env:  "false",
+			want: true,
+		},
+		{
+			name:      "the config overrides the environment variable",
+			editHosts: &enabled,
+			env:       "false",
+			want:      false,
+		},
+		{
+			name:      "the config can disable editing",
+			editHosts: &disabled,
+			want:      true,
+		},
+		{
+			name:      "dnsmasq skips editing",
+			dnsmasq:   true,
+			editHosts: &enabled,
+			want:      true,
+		},
+		{
+			name:      "the flag overrides the config",
+			flag:      "false",
+			editHosts: &disabled,
+			want:      false,
+		},
+		{
+			name:      "the flag can skip editing",
+			flag:      "true",
+			editHosts: &enabled,
+			want:      true,
+		},
+		{
+			name:      "no proxy skips editing",
+			flag:      "false",
+			noProxy:   true,
+			editHosts: &enabled,
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := os.Getenv("NITRO_EDIT_HOSTS")
+			defer os.Setenv("NITRO_EDIT_HOSTS", original)
+			os.Setenv("NITRO_EDIT_HOSTS", tt.env)
+
+			cmd := &cobra.Command{}
+			cmd.Flags().Bool("skip-hosts", false, "skip modifying the hosts file")
+			cmd.Flags().Bool("no-proxy", tt.noProxy, "skip the proxy and hosts file")
+			if tt.flag != "" {
+				if err := cmd.Flags().Set("skip-hosts", tt.flag); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := skipHosts(cmd, &config.Config{Dnsmasq: tt.dnsmasq, EditHosts: tt.editHosts}); got != tt.want {
+				t.Errorf("skipHosts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_scope(t *testing.T) {
+	tests := []struct {
+		name          string
+		flags         map[string]string
+		wantSites     bool
+		wantDatabases bool
+		wantServices  bool
+	}{
+		{
+			name:          "no flags check everything",
+			wantSites:     true,
+			wantDatabases: true,
+			wantServices:  true,
+		},
+		{
+			name:      "sites only checks the sites",
+			flags:     map[string]string{"sites-only": "true"},
+			wantSites: true,
+		},
+		{
+			name:          "flags can be combined",
+			flags:         map[string]string{"databases-only": "true", "services-only": "true"},
+			wantDatabases: true,
+			wantServices:  true,
+		},
+		{
+			name:      "a single site only checks the sites",
+			flags:     map[string]string{"site": "craft-dev.nitro", "databases-only": "true"},
+			wantSites: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().Bool("sites-only", false, "")
+			cmd.Flags().Bool("databases-only", false, "")
+			cmd.Flags().Bool("services-only", false, "")
+			cmd.Flags().String("site", "", "")
+			for k, v := range tt.flags {
+				if err := cmd.Flags().Set(k, v); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			sites, databases, services := scope(cmd)
+			if sites != tt.wantSites || databases != tt.wantDatabases || services != tt.wantServices {
+				t.Errorf("scope() = %v, %v, %v, want %v, %v, %v", sites, databases, services, tt.wantSites, tt.wantDatabases, tt.wantServices)
+			}
+		})
+	}
+}
+
+func Test_inScope(t *testing.T) {
+	site := map[string]string{containerlabels.Host: "craft-dev.nitro"}
+	database := map[string]string{containerlabels.Type: "database"}
+	service := map[string]string{containerlabels.Type: "redis"}
+	unknown := map[string]string{containerlabels.Type: "custom"}
+
+	tests := []struct {
+		name                      string
+		labels                    map[string]string
+		sites, databases, service bool
+		want                      bool
+	}{
+		{
+			name:   "sites are in the sites scope",
+			labels: site,
+			sites:  true,
+			want:   true,
+		},
+		{
+			name:      "sites are not in the databases scope",
+			labels:    site,
+			databases: true,
+		},
+		{
+			name:      "databases are in a mixed scope",
+			labels:    database,
+			databases: true,
+			service:   true,
+			want:      true,
+		},
+		{
+			name:   "services are not in the sites scope",
+			labels: service,
+			sites:  true,
+		},
+		{
+			name:    "services are in the services scope",
+			labels:  service,
+			service: true,
+			want:    true,
+		},
+		{
+			name:      "unknown containers are not in a partial scope",
+			labels:    unknown,
+			sites:     true,
+			databases: true,
+		},
+		{
+			name:      "unknown containers are in the empty scope that checks everything",
+			labels:    unknown,
+			sites:     true,
+			databases: true,
+			service:   true,
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inScope(tt.labels, tt.sites, tt.databases, tt.service); got != tt.want {
+				t.Errorf("inScope() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_updateRestartPolicies(t *testing.T) {
+	containers := []types.Container{
+		{ID: "site", Names: []string{"/mysite.nitro"}, Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Host: "mysite.nitro"}},
+		{ID: "database", Names: []string{"/mysql-8.0-3306.database.nitro"}, Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "database"}},
+		{ID: "composer", Names: []string{"/composer"}, Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "composer"}},
+		{ID: "other", Names: []string{"/other"}},
+	}
+
+	tests := []struct {
+		name    string
+		restart config.Restart
+		want    map[string]string
+	}{
+		{
+			name: "sites are not restarted by default",
+			want: map[string]string{"site": "no", "database": "unless-stopped"},
+		},
+		{
+			name:    "sites can be restarted",
+			restart: config.Restart{Sites: true},
+			want:    map[string]string{"site": "unless-stopped", "database": "unless-stopped"},
+		},
+		{
+			name:    "restarting can be disabled",
+			restart: config.Restart{Disabled: true},
+			want:    map[string]string{"site": "no", "database": "no"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docker := dockertest.New(containers, nil)
+
+			if err := updateRestartPolicies(context.Background(), docker, tt.restart); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]string{}
+			for _, c := range docker.Calls("ContainerUpdate") {
+				got[c.Args[0].(string)] = c.Args[1].(container.UpdateConfig).RestartPolicy.Name
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("updateRestartPolicies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findOrCreateNetwork(t *testing.T) {
+	docker := dockertest.New(nil, nil)
+	output := mockOutputer{}
+
+	// apply the environment twice, the network should only be created once
+	first, err := findOrCreateNetwork(context.Background(), docker, "nitro-network", output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := findOrCreateNetwork(context.Background(), docker, "nitro-network", output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.ID == "" || first.ID != second.ID {
+		t.Errorf("expected the same network, got %q and %q", first.ID, second.ID)
+	}
+
+	calls := docker.Calls("NetworkCreate")
+	if len(calls) != 1 {
+		t.Fatalf("expected the network to be created once, got %d", len(calls))
+	}
+
+	opts := calls[0].Args[1].(types.NetworkCreate)
+	if opts.Labels[containerlabels.Network] != "true" || opts.Labels[containerlabels.Nitro] != "true" {
+		t.Errorf("expected the network labels to be set, got %v", opts.Labels)
+	}
+
+	// another environment has its own network
+	work, err := findOrCreateNetwork(context.Background(), docker, "nitro-work-network", output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if work.ID == first.ID || work.Name != "nitro-work-network" {
+		t.Errorf("expected a new network for the environment, got %v", work)
+	}
+}
+
+func Test_isOrphan(t *testing.T) {
+	known := map[string]bool{"known": true}
+
+	site := func(id, hostname string) types.Container {
+		return types.Container{ID: id, Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Host: hostname}}
+	}
+
+	tests := []struct {
+		name      string
+		container types.Container
+		site      string
+		sites     bool
+		databases bool
+		services  bool
+		want      bool
+	}{
+		{
+			name:      "containers in the config are not orphans",
+			container: site("known", "known.nitro"),
+			sites:     true,
+			databases: true,
+			services:  true,
+			want:      false,
+		},
+		{
+			name:      "sites that are not in the config are orphans",
+			container: site("deleted", "deleted.nitro"),
+			sites:     true,
+			databases: true,
+			services:  true,
+			want:      true,
+		},
+		{
+			name:      "databases that are not in the config are orphans",
+			container: types.Container{ID: "mysql", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "database"}},
+			sites:     true,
+			databases: true,
+			services:  true,
+			want:      true,
+		},
+		{
+			name:      "the proxy is never an orphan",
+			container: types.Container{ID: "proxy", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Proxy: "true"}},
+			sites:     true,
+			databases: true,
+			services:  true,
+			want:      false,
+		},
+		{
+			name:      "databases are not orphans when only sites are checked",
+			container: types.Container{ID: "mysql", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "database"}},
+			sites:     true,
+			want:      false,
+		},
+		{
+			name:      "other sites are not orphans when applying a single site",
+			container: site("other", "other.nitro"),
+			site:      "deleted.nitro",
+			sites:     true,
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOrphan(tt.container, known, tt.site, tt.sites, tt.databases, tt.services); got != tt.want {
+				t.Errorf("isOrphan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_lookupsUseIndependentFilters(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	db := config.Database{Engine: "mysql", Version: "8.0", Port: "3306"}
+	site := config.Site{Hostname: "mysite.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"}
+	cfg := &config.Config{Sites: []config.Site{site}}
+	docker := dockertest.New([]types.Container{
+		{
+			ID:    "database",
+			State: "running",
+			Labels: map[string]string{
+				containerlabels.Nitro:                 "true",
+				containerlabels.Type:                  "database",
+				containerlabels.DatabaseEngine:        db.Engine,
+				containerlabels.DatabaseVersion:       db.Version,
+				containerlabels.DatabasePort:          db.Port,
+				containerlabels.DatabaseCompatibility: "mysql",
+				containerlabels.DatabasePlatform:      "linux/amd64",
+			},
+		},
+	}, nil)
+
+	// the platform changed, so stopping the container returns in the middle of the database lookup
+	docker.Errors = map[string]error{"ContainerStop": errors.New("unable to stop")}
+
+	// Act
+	if _, _, err := databasecontainer.StartOrCreate(ctx, docker, t.TempDir(), "network", db, nil, "127.0.0.1", "", mockOutputer{}); err == nil {
+		t.Fatal("expected the database to return an error")
+	}
+
+	if _, err := sitecontainer.StartOrCreate(ctx, docker, t.TempDir(), "network", site, cfg, sitecontainer.Options{}, mockOutputer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	calls := docker.Calls("ContainerList")
+	if len(calls) != 2 {
+		t.Fatalf("expected two container lookups, got %d", len(calls))
+	}
+
+	got := calls[1].Args[0].(types.ContainerListOptions).Filters.Get("label")
+	sort.Strings(got)
+
+	want := []string{containerlabels.Host + "=mysite.nitro", containerlabels.Nitro + "=true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the site lookup to only filter by the site, got %v, want %v", got, want)
+	}
+
+	if created := docker.Calls("ContainerCreate"); len(created) != 1 {
+		t.Errorf("expected the site container to be created, got %d", len(created))
+	}
+}
@@ -0,0 +1,115 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// afterApplyHooks returns the after apply hooks for the sites, in the order
+// they are in the config.
+func afterApplyHooks(cfg *config.Config, sites []config.Site) []config.Hook {
+	applied := make(map[string]bool)
+	for _, s := range sites {
+		applied[s.Hostname] = true
+	}
+
+	var hooks []config.Hook
+	for _, h := range cfg.Hooks.AfterApply {
+		if applied[h.Site] {
+			hooks = append(hooks, h)
+		}
+	}
+
+	return hooks
+}
+
+// runHooks runs the hooks in order inside the site containers and streams the
+// output to stdout and stderr. A hook that exits with a non-zero code stops
+// the remaining hooks unless the hook can continue on error.
+func runHooks(ctx context.Context, docker client.ContainerAPIClient, hooks []config.Hook, stdout, stderr io.Writer, output terminal.Outputer) error {
+	for _, h := range hooks {
+		output.Info("  running", fmt.Sprintf("%q", h.Command), "for", h.Site)
+
+		exitCode, err := runHook(ctx, docker, h, stdout, stderr)
+		if err != nil {
+			return err
+		}
+
+		if exitCode != 0 {
+			if h.ContinueOnError {
+				output.Info("  ✗", h.Site, fmt.Sprintf("%q exited with code %d, continuing", h.Command, exitCode))
+				continue
+			}
+
+			return fmt.Errorf("the after_apply hook %q for %s exited with code %d", h.Command, h.Site, exitCode)
+		}
+
+		output.Success(h.Site, "hook complete")
+	}
+
+	return nil
+}
+
+// runHook runs the hook in the site container and returns the exit code.
+func runHook(ctx context.Context, docker client.ContainerAPIClient, h config.Hook, stdout, stderr io.Writer) (int, error) {
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: containerlabels.ByHost(h.Site)})
+	if err != nil {
+		return 0, fmt.Errorf("unable to list the containers for %s, %w", h.Site, err)
+	}
+
+	if len(containers) == 0 {
+		return 0, fmt.Errorf("unable to find a running container for %s to run the hook %q", h.Site, h.Command)
+	}
+
+	// create the exec
+	exec, err := docker.ContainerExecCreate(ctx, containers[0].ID, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          false,
+		WorkingDir:   "/app",
+		Cmd:          []string{"sh", "-c", h.Command},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to create the hook %q for %s, %w", h.Command, h.Site, err)
+	}
+
+	// attach to the container
+	attach, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{
+		Tty: false,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to attach to the hook %q for %s, %w", h.Command, h.Site, err)
+	}
+	defer attach.Close()
+
+	// stream the output of the hook
+	if _, err := stdcopy.StdCopy(stdout, stderr, attach.Reader); err != nil {
+		return 0, fmt.Errorf("unable to copy the output of the hook %q for %s, %w", h.Command, h.Site, err)
+	}
+
+	// start the exec
+	if err := docker.ContainerExecStart(ctx, exec.ID, types.ExecStartCheck{}); err != nil {
+		return 0, fmt.Errorf("unable to start the hook %q for %s, %w", h.Command, h.Site, err)
+	}
+
+	// wait for the hook to complete
+	for {
+		resp, err := docker.ContainerExecInspect(ctx, exec.ID)
+		if err != nil {
+			return 0, err
+		}
+
+		if !resp.Running {
+			return resp.ExitCode, nil
+		}
+	}
+}
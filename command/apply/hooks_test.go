@@ -0,0 +1,137 @@
+package apply
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+type hookOutputer struct {
+	mockOutputer
+	lines []string
+}
+
+func (h *hookOutputer) Info(s ...string) {
+	h.lines = append(h.lines, strings.Join(s, " "))
+}
+
+func (h *hookOutputer) Success(s ...string) {
+	h.lines = append(h.lines, strings.Join(s, " "))
+}
+
+func Test_afterApplyHooks(t *testing.T) {
+	cfg := &config.Config{
+		Hooks: config.Hooks{
+			AfterApply: []config.Hook{
+				{Site: "one.nitro", Command: "php craft migrate/all"},
+				{Site: "two.nitro", Command: "php craft migrate/all"},
+				{Site: "one.nitro", Command: "php craft clear-caches/all"},
+			},
+		},
+	}
+
+	got := afterApplyHooks(cfg, []config.Site{{Hostname: "one.nitro"}})
+
+	want := []config.Hook{
+		{Site: "one.nitro", Command: "php craft migrate/all"},
+		{Site: "one.nitro", Command: "php craft clear-caches/all"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("afterApplyHooks() = %v, want %v", got, want)
+	}
+}
+
+func Test_runHooks(t *testing.T) {
+	site := types.Container{
+		ID:    "site",
+		State: "running",
+		Labels: map[string]string{
+			containerlabels.Nitro: "true",
+			containerlabels.Host:  "mysite.nitro",
+		},
+	}
+
+	tests := []struct {
+		name         string
+		exitCode     int
+		hooks        []config.Hook
+		wantCommands []string
+		wantErr      bool
+	}{
+		{
+			name: "hooks run in order",
+			hooks: []config.Hook{
+				{Site: "mysite.nitro", Command: "php craft migrate/all"},
+				{Site: "mysite.nitro", Command: "php craft clear-caches/all"},
+			},
+			wantCommands: []string{"php craft migrate/all", "php craft clear-caches/all"},
+		},
+		{
+			name:     "failed hooks that continue on error do not stop the hooks",
+			exitCode: 1,
+			hooks: []config.Hook{
+				{Site: "mysite.nitro", Command: "php craft migrate/all", ContinueOnError: true},
+				{Site: "mysite.nitro", Command: "php craft clear-caches/all", ContinueOnError: true},
+			},
+			wantCommands: []string{"php craft migrate/all", "php craft clear-caches/all"},
+		},
+		{
+			name:     "failed hooks stop the remaining hooks",
+			exitCode: 1,
+			hooks: []config.Hook{
+				{Site: "mysite.nitro", Command: "php craft migrate/all"},
+				{Site: "mysite.nitro", Command: "php craft clear-caches/all"},
+			},
+			wantCommands: []string{"php craft migrate/all"},
+			wantErr:      true,
+		},
+		{
+			name: "hooks for sites without a container return an error",
+			hooks: []config.Hook{
+				{Site: "unknown.nitro", Command: "php craft migrate/all"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			docker := dockertest.New([]types.Container{site}, nil)
+			docker.ExecExitCode = tt.exitCode
+
+			// Act
+			err := runHooks(context.Background(), docker, tt.hooks, &bytes.Buffer{}, &bytes.Buffer{}, &hookOutputer{})
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Errorf("runHooks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var commands []string
+			for _, c := range docker.Calls("ContainerExecCreate") {
+				if id := c.Args[0].(string); id != "site" {
+					t.Errorf("expected the hook to run in the site container, got %s", id)
+				}
+
+				cfg := c.Args[1].(types.ExecConfig)
+				if cfg.WorkingDir != "/app" {
+					t.Errorf("expected the hook to run in /app, got %q", cfg.WorkingDir)
+				}
+
+				commands = append(commands, cfg.Cmd[len(cfg.Cmd)-1])
+			}
+
+			if !reflect.DeepEqual(commands, tt.wantCommands) {
+				t.Errorf("expected the commands %v, got %v", tt.wantCommands, commands)
+			}
+		})
+	}
+}
@@ -1,17 +1,21 @@
 package customcontainer
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/craftcms/nitro/command/apply/internal/match"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/images"
 	"github.com/craftcms/nitro/pkg/pathexists"
+	"github.com/craftcms/nitro/pkg/pullprogress"
+	"github.com/craftcms/nitro/pkg/registryauth"
+	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
@@ -22,7 +26,7 @@ import (
 	"github.com/docker/go-connections/nat"
 )
 
-func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, home, networkID string, c config.Container) (hostname string, err error) {
+func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, home, networkID string, c config.Container, bindAddress string, skipPull bool, output terminal.Outputer) (hostname string, err error) {
 	// set filters for the container
 	filter := filters.NewArgs()
 	filter.Add("label", containerlabels.Nitro+"=true")
@@ -31,12 +35,12 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, home, net
 	// look for a container for the site
 	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
 	if err != nil {
-		return "", fmt.Errorf("error getting a list of containers")
+		return "", fmt.Errorf("unable to list the containers for %s, %w", c.Name, err)
 	}
 
 	// if there are no containers we need to create one
 	if len(containers) == 0 {
-		return create(ctx, docker, home, networkID, c)
+		return create(ctx, docker, home, networkID, c, bindAddress, skipPull, output)
 	}
 
 	// there is a container, so inspect it and make sure it matched
@@ -45,50 +49,67 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, home, net
 	// start the container if not running
 	if container.State != "running" {
 		if err := docker.ContainerStart(ctx, container.ID, types.ContainerStartOptions{}); err != nil {
-			return "", err
+			return "", fmt.Errorf("unable to start the container %s (%s), %w", c.Name, container.Image, err)
 		}
 	}
 
 	// get the containers details that include environment variables
 	details, err := docker.ContainerInspect(ctx, container.ID)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("unable to inspect the container %s (%s), %w", c.Name, container.Image, err)
 	}
 
 	// if the container is out of date
-	if err := match.Container(home, c, details); err != nil {
-		fmt.Println(err)
-		fmt.Print("- updating… ")
+	if err := match.Container(home, c, details, bindAddress); err != nil {
+		output.Info(err.Error())
+		output.Pending("updating")
 
 		// stop container
 		if err := docker.ContainerStop(ctx, container.ID, nil); err != nil {
-			return "", err
+			return "", fmt.Errorf("unable to stop the container %s (%s), %w", c.Name, container.Image, err)
 		}
 
 		// remove container
 		if err := docker.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{}); err != nil {
-			return "", err
+			return "", fmt.Errorf("unable to remove the container %s (%s), %w", c.Name, container.Image, err)
 		}
 
-		return create(ctx, docker, home, networkID, c)
+		return create(ctx, docker, home, networkID, c, bindAddress, skipPull, output)
 	}
 
 	return container.ID, nil
 }
 
-func create(ctx context.Context, docker client.CommonAPIClient, home, networkID string, c config.Container) (string, error) {
+func create(ctx context.Context, docker client.CommonAPIClient, home, networkID string, c config.Container, bindAddress string, skipPull bool, output terminal.Outputer) (string, error) {
 	// create the container
-	image := fmt.Sprintf("%s:%s", c.Image, c.Tag)
+	image := images.Container(c)
 
-	// pull the image
-	rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false})
-	if err != nil {
-		return "", fmt.Errorf("unable to pull the image, %w", err)
+	// pull the image, unless it exists and pulling is skipped
+	exists := false
+	if skipPull {
+		var err error
+		exists, err = images.Exists(ctx, docker, image)
+		if err != nil {
+			return "", err
+		}
 	}
 
-	buf := &bytes.Buffer{}
-	if _, err := buf.ReadFrom(rdr); err != nil {
-		return "", fmt.Errorf("unable to read output from pulling image %s, %w", image, err)
+	if !exists {
+		// use the credentials from the docker config for private registries
+		auth, err := registryauth.New(home).ForImage(image)
+		if err != nil {
+			return "", err
+		}
+
+		rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false, RegistryAuth: auth})
+		if err != nil {
+			return "", fmt.Errorf("unable to pull the image %s, %w", image, err)
+		}
+
+		// wait for the pull to complete and show progress
+		if err := pullprogress.Wait(rdr, terminal.ProgressWriter(output)); err != nil {
+			return "", fmt.Errorf("unable to read output from pulling image %s, %w", image, err)
+		}
 	}
 
 	// get the containers custom environment variables from the file
@@ -114,90 +135,50 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 		}
 	}
 
-	labels := containerlabels.ForCustomContainer(c)
-
-	config := &container.Config{
-		Image:  image,
-		Labels: labels,
+	config, hostConfig, err := containerConfig(home, c, customEnvs, bindAddress)
+	if err != nil {
+		return "", err
 	}
 
-	if len(customEnvs) > 0 {
-		config.Env = customEnvs
+	// label the volumes for the container, without the config hash
+	volumeLabels := make(map[string]string)
+	for k, v := range config.Labels {
+		if k != containerlabels.NitroContainerHash {
+			volumeLabels[k] = v
+		}
 	}
 
 	// check for volumes and create if not found
-	var mounts []mount.Mount
-	if len(c.Volumes) > 0 {
-		for _, v := range c.Volumes {
-			// generate the volume name
-			name := fmt.Sprintf("nitro_%s_%s", c.Name, strings.Replace(v, "/", "_", -1))
-
-			// filter for the volume
-			volFilter := filters.NewArgs()
-			volFilter.Add("name", name)
-
-			// check for an existing volume
-			resp, err := docker.VolumeList(ctx, volFilter)
-			if err != nil {
-				return "", err
-			}
-
-			if len(resp.Volumes) == 0 {
-				vol, err := docker.VolumeCreate(ctx, volume.VolumeCreateBody{Driver: "local", Name: name, Labels: labels})
-				if err != nil {
-					return "", err
-				}
-
-				// append the mount
-				mounts = append(mounts, mount.Mount{
-					Type:   mount.TypeVolume,
-					Source: vol.Name,
-					Target: v,
-				})
-			}
+	for _, m := range hostConfig.Mounts {
+		if m.Type != mount.TypeVolume {
+			continue
 		}
-	}
 
-	// create the expose nat port settings and host config port bindings
-	portBindings := make(map[nat.Port][]nat.PortBinding)
-	portSettings := make(map[nat.Port]struct{})
-	if len(c.Ports) > 0 {
-		for _, p := range c.Ports {
-			parts := strings.Split(p, ":")
-			// get the host port
-			host := parts[0]
-			// get the container port
-			container := parts[1]
-
-			port, err := nat.NewPort("tcp", container)
-			if err != nil {
-				return "", fmt.Errorf("unable to create the port, %w", err)
-			}
+		// filter for the volume
+		volFilter := filters.NewArgs()
+		volFilter.Add("name", m.Source)
 
-			// create the port binding
-			portBindings[port] = []nat.PortBinding{{
-				HostIP:   "127.0.0.1",
-				HostPort: host,
-			}}
+		// check for an existing volume
+		resp, err := docker.VolumeList(ctx, volFilter)
+		if err != nil {
+			return "", err
+		}
 
-			// create the nat port setting
-			portSettings[port] = struct{}{}
+		if len(resp.Volumes) == 0 {
+			if _, err := docker.VolumeCreate(ctx, volume.VolumeCreateBody{Driver: "local", Name: m.Source, Labels: volumeLabels}); err != nil {
+				return "", err
+			}
 		}
 	}
 
-	config.ExposedPorts = portSettings
-
 	// create the container
 	resp, err := docker.ContainerCreate(
 		ctx,
 		config,
-		&container.HostConfig{
-			Mounts:       mounts,
-			PortBindings: portBindings,
-		},
+		hostConfig,
 		&network.NetworkingConfig{
 			EndpointsConfig: map[string]*network.EndpointSettings{
-				"nitro-network": {
+				networkID: {
 					NetworkID: networkID,
 				},
 			},
@@ -206,13 +187,96 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 		fmt.Sprintf("%s.containers.nitro", c.Name),
 	)
 	if err != nil {
-		return "", fmt.Errorf("unable to create the container, %w", err)
+		return "", fmt.Errorf("unable to create the container %s (%s), %w", c.Name, image, err)
 	}
 
 	// start the container
 	if err := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-		return "", fmt.Errorf("unable to start the container, %w", err)
+		return "", fmt.Errorf("unable to start the container %s (%s), %w", c.Name, image, err)
 	}
 
 	return resp.ID, nil
 }
+
+// containerConfig returns the container and host config for a custom container
+// using the command, entrypoint, environment variables, ports, and volumes from
+// the config. The envs from the env file are set before the config env.
+func containerConfig(home string, c config.Container, envs []string, bindAddress string) (*container.Config, *container.HostConfig, error) {
+	labels := containerlabels.ForCustomContainer(c)
+
+	// sort the env so the config is the same each time
+	keys := make([]string, 0, len(c.Env))
+	for k := range c.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		envs = append(envs, k+"="+c.Env[k])
+	}
+
+	cfg := &container.Config{
+		Image:  images.Container(c),
+		Labels: labels,
+	}
+
+	if len(envs) > 0 {
+		cfg.Env = envs
+	}
+
+	if len(c.Command) > 0 {
+		cfg.Cmd = c.Command
+	}
+
+	if len(c.Entrypoint) > 0 {
+		cfg.Entrypoint = c.Entrypoint
+	}
+
+	// create the expose nat port settings and host config port bindings
+	portBindings := make(map[nat.Port][]nat.PortBinding)
+	portSettings := make(map[nat.Port]struct{})
+	for _, p := range c.Ports {
+		parts := strings.Split(p, ":")
+		if len(parts) != 2 {
+			return nil, nil, fmt.Errorf("the port %q for the container %s must use the <host>:<container> syntax", p, c.Name)
+		}
+
+		port, err := nat.NewPort("tcp", parts[1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to create the port, %w", err)
+		}
+
+		// create the port binding
+		portBindings[port] = []nat.PortBinding{{
+			HostIP:   bindAddress,
+			HostPort: parts[0],
+		}}
+
+		// create the nat port setting
+		portSettings[port] = struct{}{}
+	}
+
+	cfg.ExposedPorts = portSettings
+
+	volumes, err := c.GetVolumes(home)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// mount the host paths and named volumes, named volumes are kept when the container is recreated
+	var mounts []mount.Mount
+	for _, v := range volumes {
+		if v.Source != "" {
+			mounts = append(mounts, mount.Mount{Type: mount.TypeBind, Source: v.Source, Target: v.Target})
+			continue
+		}
+
+		mounts = append(mounts, mount.Mount{
+			Type:   mount.TypeVolume,
+			Source: fmt.Sprintf("nitro_%s_%s", c.Name, strings.Replace(v.Target, "/", "_", -1)),
+			Target: v.Target,
+		})
+	}
+
+	return cfg, &container.HostConfig{Mounts: mounts, PortBindings: portBindings}, nil
+}
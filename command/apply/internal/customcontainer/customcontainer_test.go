@@ -0,0 +1,98 @@
+package customcontainer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/go-connections/nat"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+)
+
+func Test_containerConfig(t *testing.T) {
+	home := filepath.Join(string(filepath.Separator), "home", "nitro")
+
+	// Arrange
+	c := config.Container{
+		Name:       "queue",
+		Image:      "craftcms/php-fpm",
+		Tag:        "7.4-dev",
+		Command:    []string{"php", "craft", "queue/listen"},
+		Entrypoint: []string{"/bin/sh", "-c"},
+		Env:        map[string]string{"QUEUE": "default", "CRAFT_ENVIRONMENT": "dev"},
+		Ports:      []string{"8081:8080"},
+		Volumes:    []string{"/var/lib/queue", "~/dev/mysite:/app"},
+	}
+
+	// Act
+	cfg, hostConfig, err := containerConfig(home, c, []string{"FROM_FILE=true"}, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if cfg.Image != "craftcms/php-fpm:7.4-dev" {
+		t.Errorf("expected the image craftcms/php-fpm:7.4-dev, got %s", cfg.Image)
+	}
+
+	if want := (strslice.StrSlice{"php", "craft", "queue/listen"}); !reflect.DeepEqual(cfg.Cmd, want) {
+		t.Errorf("expected the command %v, got %v", want, cfg.Cmd)
+	}
+
+	if want := (strslice.StrSlice{"/bin/sh", "-c"}); !reflect.DeepEqual(cfg.Entrypoint, want) {
+		t.Errorf("expected the entrypoint %v, got %v", want, cfg.Entrypoint)
+	}
+
+	if want := ([]string{"FROM_FILE=true", "CRAFT_ENVIRONMENT=dev", "QUEUE=default"}); !reflect.DeepEqual(cfg.Env, want) {
+		t.Errorf("expected the env %v, got %v", want, cfg.Env)
+	}
+
+	if cfg.Labels[containerlabels.NitroContainer] != "queue" || cfg.Labels[containerlabels.NitroContainerHash] == "" {
+		t.Errorf("expected the container name and hash labels, got %v", cfg.Labels)
+	}
+
+	if _, ok := cfg.ExposedPorts["8080/tcp"]; !ok {
+		t.Errorf("expected port 8080 to be exposed, got %v", cfg.ExposedPorts)
+	}
+
+	if want := ([]nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "8081"}}); !reflect.DeepEqual(hostConfig.PortBindings["8080/tcp"], want) {
+		t.Errorf("expected the port bindings %v, got %v", want, hostConfig.PortBindings["8080/tcp"])
+	}
+
+	wantMounts := []mount.Mount{
+		{Type: mount.TypeVolume, Source: "nitro_queue__var_lib_queue", Target: "/var/lib/queue"},
+		{Type: mount.TypeBind, Source: filepath.Join(home, "dev", "mysite"), Target: "/app"},
+	}
+	if !reflect.DeepEqual(hostConfig.Mounts, wantMounts) {
+		t.Errorf("expected the mounts %v, got %v", wantMounts, hostConfig.Mounts)
+	}
+}
+
+func Test_containerConfig_Defaults(t *testing.T) {
+	// Arrange
+	c := config.Container{Name: "adminer", Image: "adminer", Tag: "latest"}
+
+	// Act
+	cfg, hostConfig, err := containerConfig("", c, nil, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if cfg.Cmd != nil || cfg.Entrypoint != nil || cfg.Env != nil {
+		t.Errorf("expected the image defaults to be used, got cmd %v, entrypoint %v, env %v", cfg.Cmd, cfg.Entrypoint, cfg.Env)
+	}
+
+	// containers created before the options were added are not recreated
+	if _, ok := cfg.Labels[containerlabels.NitroContainerHash]; ok {
+		t.Errorf("expected no hash label, got %v", cfg.Labels)
+	}
+
+	if len(hostConfig.Mounts) != 0 || len(hostConfig.PortBindings) != 0 {
+		t.Errorf("expected no mounts or ports, got %v and %v", hostConfig.Mounts, hostConfig.PortBindings)
+	}
+}
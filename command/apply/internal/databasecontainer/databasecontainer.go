@@ -1,15 +1,17 @@
 package databasecontainer
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/images"
+	"github.com/craftcms/nitro/pkg/pullprogress"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -20,39 +22,46 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 	_ "github.com/go-sql-driver/mysql"
-)
-
-var (
-	// DatabaseImage is used for determining the engine and version
-	DatabaseImage = "%s:%s"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 // StartOrCreate is used to find a specific database and start the container. If there is no container for the database,
-// it will create a new volume and container for the database.
-func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID string, db config.Database, output terminal.Outputer) (string, string, error) {
-	// create the filters for the database
-	filter := filters.NewArgs()
-	filter.Add("label", containerlabels.DatabaseEngine+"="+db.Engine)
-	filter.Add("label", containerlabels.DatabaseVersion+"="+db.Version)
-	filter.Add("label", containerlabels.DatabasePort+"="+db.Port)
-	filter.Add("label", containerlabels.Type+"=database")
-
+// it will create a new volume and container for the database. The init SQL files for the database are mounted into the
+// images docker-entrypoint-initdb.d directory, so they only run when the volume is created. The aliases are added to the
+// container on the network so sites can use a stable name for the database (e.g. mysql). The database port is bound to
+// the bind address on the host.
+func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, home, networkID string, db config.Database, aliases []string, bindAddress, registry string, output terminal.Outputer) (string, string, error) {
 	hostname, err := db.GetHostname()
 	if err != nil {
 		return "", "", err
 	}
 
-	// set the container database compatibility
-	if db.Engine == "mariadb" || db.Engine == "mysql" {
-		filter.Add("label", containerlabels.DatabaseCompatibility+"=mysql")
-	} else {
-		filter.Add("label", containerlabels.DatabaseCompatibility+"=postgres")
-	}
-
 	// get the containers for the database
-	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: containerlabels.ByDatabase(db)})
 	if err != nil {
-		return "", "", fmt.Errorf("error getting a list of containers")
+		return "", "", fmt.Errorf("unable to list the containers for %s, %w", hostname, err)
+	}
+
+	// replicas have the same labels as a database on the replicas port
+	containers = WithoutReplicas(containers)
+
+	// if the platform, replication, aliases, or bind address in the config have changed, remove the container so it is recreated, the volume is kept
+	if len(containers) == 1 && Outdated(containers[0], db, aliases, bindAddress) != "" {
+		output.Pending("updating", hostname)
+
+		if err := docker.ContainerStop(ctx, containers[0].ID, nil); err != nil {
+			output.Warning()
+			return "", "", fmt.Errorf("unable to stop the container %s (%s), %w", hostname, containers[0].Image, err)
+		}
+
+		if err := docker.ContainerRemove(ctx, containers[0].ID, types.ContainerRemoveOptions{}); err != nil {
+			output.Warning()
+			return "", "", fmt.Errorf("unable to remove the container %s (%s), %w", hostname, containers[0].Image, err)
+		}
+
+		output.Done()
+
+		containers = nil
 	}
 
 	// if there is a container, we should start it and return
@@ -61,7 +70,7 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 		if containers[0].State != "running" {
 			// start the container
 			if err := docker.ContainerStart(ctx, containers[0].ID, types.ContainerStartOptions{}); err != nil {
-				return "", "", err
+				return "", "", fmt.Errorf("unable to start the container %s (%s), %w", hostname, containers[0].Image, err)
 			}
 		}
 
@@ -77,6 +86,21 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 		containerlabels.DatabasePort:    db.Port,
 	}
 
+	// keep track of the platform from the config
+	if db.Platform != "" {
+		labels[containerlabels.DatabasePlatform] = db.Platform
+	}
+
+	// keep track of the database having a replica
+	if db.Replica != nil {
+		labels[containerlabels.DatabaseReplication] = "primary"
+	}
+
+	// keep track of the aliases on the network
+	if len(aliases) > 0 {
+		labels[containerlabels.Aliases] = strings.Join(aliases, ",")
+	}
+
 	// if the database is mysql or mariadb, mark them as
 	// mysql compatible (used for importing backups)
 	if db.Engine == "mariadb" || db.Engine == "mysql" {
@@ -90,14 +114,20 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 		labels[containerlabels.DatabaseCompatibility] = "postgres"
 	}
 
-	// create the volume
-	volume, err := docker.VolumeCreate(ctx, volumetypes.VolumeCreateBody{Driver: "local", Name: hostname, Labels: labels})
+	// find or create the volume
+	volume, err := findOrCreateVolume(ctx, docker, hostname, labels)
 	if err != nil {
-		return "", "", fmt.Errorf("unable to create the volume, %w", err)
+		return "", "", err
+	}
+
+	// find the init SQL files before creating anything
+	initSQL, err := db.GetInitSQLPaths(home)
+	if err != nil {
+		return "", "", err
 	}
 
 	// determine the image name
-	image := fmt.Sprintf(DatabaseImage, db.Engine, db.Version)
+	image := images.Database(registry, db)
 
 	// set mounts and environment based on the database type
 	target := "/var/lib/mysql"
@@ -109,33 +139,37 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 		envs = []string{"MYSQL_ROOT_PASSWORD=nitro", "MYSQL_DATABASE=nitro", "MYSQL_USER=nitro", "MYSQL_PASSWORD=nitro"}
 	}
 
-	// check if there is an image
-
-	// filter for the image ref
-	imageFilter := filters.NewArgs()
-	imageFilter.Add("reference", image)
+	// determine the platform for the image
+	platform, err := imagePlatform(ctx, docker, image, db, output)
+	if err != nil {
+		return "", "", err
+	}
 
-	// look for the image
-	images, err := docker.ImageList(ctx, types.ImageListOptions{Filters: imageFilter, All: true})
+	// check if there is an image
+	exists, err := images.Exists(ctx, docker, image)
 	if err != nil {
-		return "", "", fmt.Errorf("unable to get a list of images, %w", err)
+		return "", "", err
 	}
 
 	// if there are no images, pull one
-	if len(images) == 0 {
+	if !exists {
 		output.Pending("downloading", image)
 
 		// pull the image
-		rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false})
+		opts := types.ImagePullOptions{All: false}
+		if platform != nil {
+			opts.Platform = formatPlatform(platform)
+		}
+
+		rdr, err := docker.ImagePull(ctx, image, opts)
 		if err != nil {
 			output.Warning()
 
 			return "", "", fmt.Errorf("unable to pull image %s, %w", image, err)
 		}
 
-		// read the output to pull the image
-		buf := &bytes.Buffer{}
-		if _, err := buf.ReadFrom(rdr); err != nil {
+		// wait for the pull to complete and show progress
+		if err := pullprogress.Wait(rdr, terminal.ProgressWriter(output)); err != nil {
 			output.Warning()
 			return "", "", fmt.Errorf("unable to read output from pulling image %s, %w", image, err)
 		}
@@ -165,11 +199,19 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 		Env: envs,
 	}
 
+	// keep track of the bind address from the config
+	containerlabels.SetBindAddress(containerConfig.Labels, bindAddress)
+
 	// if the mysql engine is being used, override the cmd
 	if db.Engine == "mysql" {
 		containerConfig.Cmd = []string{"--character-set-server=utf8mb4", "--collation-server=utf8mb4_unicode_ci"}
 	}
 
+	// enable the binary log for the replica
+	if db.Replica != nil {
+		containerConfig.Cmd = append(containerConfig.Cmd, replicationArgs(db.Engine, "primary")...)
+	}
+
 	hostConfig := &container.HostConfig{
 		CapAdd: []string{"SYS_NICE"},
 		Mounts: []mount.Mount{
@@ -182,30 +224,41 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 		PortBindings: map[nat.Port][]nat.PortBinding{
 			port: {
 				{
-					HostIP:   "127.0.0.1",
+					HostIP:   bindAddress,
 					HostPort: db.Port,
 				},
 			},
 		},
 	}
 
+	// the images run the files in the init directory in alphabetical order, so prefix them to keep the config order
+	for i, p := range initSQL {
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   p,
+			Target:   fmt.Sprintf("/docker-entrypoint-initdb.d/%02d-%s", i+1, filepath.Base(p)),
+			ReadOnly: true,
+		})
+	}
+
 	networkConfig := &network.NetworkingConfig{
 		EndpointsConfig: map[string]*network.EndpointSettings{
-			"nitro-network": {
+			networkID: {
 				NetworkID: networkID,
+				Aliases:   aliases,
 			},
 		},
 	}
 
 	// create the container for the database
-	resp, err := docker.ContainerCreate(ctx, containerConfig, hostConfig, networkConfig, nil, hostname)
+	resp, err := docker.ContainerCreate(ctx, containerConfig, hostConfig, networkConfig, platform, hostname)
 	if err != nil {
-		return "", "", fmt.Errorf("unable to create the container, %w", err)
+		return "", "", fmt.Errorf("unable to create the container %s (%s), %w", hostname, image, err)
 	}
 
 	// start the container
 	if err := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-		return "", "", fmt.Errorf("unable to start the container, %w", err)
+		return "", "", fmt.Errorf("unable to start the container %s (%s), %w", hostname, image, err)
 	}
 
 	// if the container is mysql compatible
@@ -218,6 +271,373 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 	return resp.ID, hostname, nil
 }
 
+// StartOrCreateReplica is used to find the replica for a database and start the container. If there is no
+// container for the replica, it will create a volume and container that replicates from the database. The
+// database must be created first using StartOrCreate and the ID of the database container is required.
+func StartOrCreateReplica(ctx context.Context, docker client.CommonAPIClient, networkID, primaryID string, db config.Database, bindAddress, registry string, output terminal.Outputer) (string, string, error) {
+	hostname, err := db.GetReplicaHostname()
+	if err != nil {
+		return "", "", err
+	}
+
+	primary, err := db.GetHostname()
+	if err != nil {
+		return "", "", err
+	}
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: containerlabels.ByReplica(db)})
+	if err != nil {
+		return "", "", fmt.Errorf("unable to list the containers for %s, %w", hostname, err)
+	}
+
+	// if the bind address changed, remove the container so it is recreated, the volume is kept
+	if len(containers) > 0 && containerlabels.GetBindAddress(containers[0].Labels) != bindAddress {
+		if err := docker.ContainerRemove(ctx, containers[0].ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return "", "", fmt.Errorf("unable to remove the container %s (%s), %w", hostname, containers[0].Image, err)
+		}
+
+		containers = nil
+	}
+
+	// if there is a container, we should start it and return
+	if len(containers) > 0 {
+		if containers[0].State != "running" {
+			if err := docker.ContainerStart(ctx, containers[0].ID, types.ContainerStartOptions{}); err != nil {
+				return "", "", fmt.Errorf("unable to start the container %s (%s), %w", hostname, containers[0].Image, err)
+			}
+		}
+
+		return containers[0].ID, hostname, nil
+	}
+
+	labels := map[string]string{
+		containerlabels.Nitro:               "true",
+		containerlabels.DatabaseEngine:      db.Engine,
+		containerlabels.DatabaseVersion:     db.Version,
+		containerlabels.Type:                "database",
+		containerlabels.DatabasePort:        db.Replica.Port,
+		containerlabels.DatabaseReplication: "replica",
+	}
+
+	if db.Platform != "" {
+		labels[containerlabels.DatabasePlatform] = db.Platform
+	}
+
+	target := "/var/lib/mysql"
+	port := nat.Port("3306/tcp")
+	switch db.Engine {
+	case "postgres":
+		labels[containerlabels.DatabaseCompatibility] = "postgres"
+		target = "/var/lib/postgresql/data"
+		port = nat.Port("5432/tcp")
+	default:
+		labels[containerlabels.DatabaseCompatibility] = "mysql"
+	}
+
+	volume, err := findOrCreateVolume(ctx, docker, hostname, labels)
+	if err != nil {
+		return "", "", err
+	}
+
+	// the image was pulled for the database
+	image := images.Database(registry, db)
+
+	platform, err := imagePlatform(ctx, docker, image, db, output)
+	if err != nil {
+		return "", "", err
+	}
+
+	containerConfig := &container.Config{
+		Image:        image,
+		Labels:       labels,
+		ExposedPorts: nat.PortSet{port: struct{}{}},
+	}
+
+	containerlabels.SetBindAddress(containerConfig.Labels, bindAddress)
+
+	switch db.Engine {
+	case "postgres":
+		// allow the replica to connect to the database for streaming replication
+		if err := run(ctx, docker, primaryID, []string{"bash", "-c", postgresReplicationAccess}); err != nil {
+			return "", "", fmt.Errorf("unable to allow replication from %s, %w", primary, err)
+		}
+
+		// copy the database using pg_basebackup before postgres starts
+		containerConfig.Env = []string{"POSTGRES_USER=nitro", "POSTGRES_DB=nitro", "POSTGRES_PASSWORD=nitro", "PGPASSWORD=nitro"}
+		containerConfig.Entrypoint = []string{"bash", "-c", fmt.Sprintf(postgresReplicaEntrypoint, primary)}
+	default:
+		containerConfig.Env = []string{"MYSQL_ROOT_PASSWORD=nitro", "MYSQL_DATABASE=nitro", "MYSQL_USER=nitro", "MYSQL_PASSWORD=nitro"}
+
+		if db.Engine == "mysql" {
+			containerConfig.Cmd = []string{"--character-set-server=utf8mb4", "--collation-server=utf8mb4_unicode_ci"}
+		}
+
+		containerConfig.Cmd = append(containerConfig.Cmd, replicationArgs(db.Engine, "replica")...)
+	}
+
+	hostConfig := &container.HostConfig{
+		CapAdd: []string{"SYS_NICE"},
+		Mounts: []mount.Mount{
+			{
+				Type:   mount.TypeVolume,
+				Source: volume.Name,
+				Target: target,
+			},
+		},
+		PortBindings: map[nat.Port][]nat.PortBinding{
+			port: {
+				{
+					HostIP:   bindAddress,
+					HostPort: db.Replica.Port,
+				},
+			},
+		},
+	}
+
+	networkConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			networkID: {
+				NetworkID: networkID,
+			},
+		},
+	}
+
+	resp, err := docker.ContainerCreate(ctx, containerConfig, hostConfig, networkConfig, platform, hostname)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the container %s (%s), %w", hostname, image, err)
+	}
+
+	if err := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", "", fmt.Errorf("unable to start the container %s (%s), %w", hostname, image, err)
+	}
+
+	// mysql replicas are started after the server is ready
+	if db.Engine == "mysql" || db.Engine == "mariadb" {
+		if err := waitForMySQLContainer(ctx, docker, resp.ID, config.Database{Engine: db.Engine, Version: db.Version, Port: db.Replica.Port}); err != nil {
+			return "", "", err
+		}
+
+		if err := run(ctx, docker, resp.ID, []string{"mysql", "-uroot", "-pnitro", "-e", mysqlReplicaStatement(db, primary)}); err != nil {
+			return "", "", fmt.Errorf("unable to start replicating from %s, %w", primary, err)
+		}
+	}
+
+	return resp.ID, hostname, nil
+}
+
+// postgresReplicationAccess adds the replication entry to the pg_hba.conf of the database and reloads the config.
+const postgresReplicationAccess = `until pg_isready -U nitro -q; do sleep 1; done
+grep -q "^host replication" "$PGDATA/pg_hba.conf" && exit 0
+echo "host replication all all md5" >> "$PGDATA/pg_hba.conf"
+psql -U nitro -c "SELECT pg_reload_conf();"`
+
+// postgresReplicaEntrypoint copies the database into an empty data directory and starts postgres
+// as a standby, the -R option writes the connection settings for the database.
+const postgresReplicaEntrypoint = `set -e
+if [ ! -s "$PGDATA/PG_VERSION" ]; then
+  mkdir -p "$PGDATA" && chown -R postgres:postgres "$PGDATA" && chmod 700 "$PGDATA"
+  until gosu postgres pg_basebackup -h %s -U nitro -D "$PGDATA" -X stream -R; do sleep 1; done
+fi
+exec docker-entrypoint.sh postgres`
+
+// Outdated returns the reason the container for a database must be recreated to match
+// the config, or an empty string if the container is up to date. The volume is always kept.
+func Outdated(c types.Container, db config.Database, aliases []string, bindAddress string) string {
+	switch {
+	case c.Labels[containerlabels.DatabasePlatform] != db.Platform:
+		return "the platform changed"
+	case c.Labels[containerlabels.DatabaseReplication] != role(db):
+		return "the replication changed"
+	case c.Labels[containerlabels.Aliases] != strings.Join(aliases, ","):
+		return "the network aliases changed"
+	case containerlabels.GetBindAddress(c.Labels) != bindAddress:
+		return "the bind address changed"
+	}
+
+	return ""
+}
+
+// role returns the replication role label for the database.
+func role(db config.Database) string {
+	if db.Replica != nil {
+		return "primary"
+	}
+
+	return ""
+}
+
+// replicationArgs returns the server arguments for MySQL and MariaDB replication based on the
+// role (primary or replica). Postgres does not need arguments since wal_level defaults to replica.
+func replicationArgs(engine, role string) []string {
+	if engine == "postgres" {
+		return nil
+	}
+
+	switch role {
+	case "primary":
+		return []string{"--server-id=1", "--log-bin=mysql-bin", "--binlog-format=ROW"}
+	case "replica":
+		return []string{"--server-id=2", "--relay-log=mysql-relay-bin", "--read-only=ON"}
+	}
+
+	return nil
+}
+
+// mysqlReplicaStatement returns the statement to start replicating from the database.
+func mysqlReplicaStatement(db config.Database, primary string) string {
+	options := fmt.Sprintf("MASTER_HOST='%s', MASTER_PORT=3306, MASTER_USER='root', MASTER_PASSWORD='nitro'", primary)
+
+	// mysql 8.0 uses caching_sha2_password which requires the public key without TLS
+	if db.Engine == "mysql" && strings.HasPrefix(db.Version, "8") {
+		options += ", GET_MASTER_PUBLIC_KEY=1"
+	}
+
+	return fmt.Sprintf("STOP SLAVE; CHANGE MASTER TO %s; START SLAVE;", options)
+}
+
+// WithoutReplicas removes the replica containers from the list of containers.
+func WithoutReplicas(containers []types.Container) []types.Container {
+	var list []types.Container
+	for _, c := range containers {
+		if c.Labels[containerlabels.DatabaseReplication] == "replica" {
+			continue
+		}
+
+		list = append(list, c)
+	}
+
+	return list
+}
+
+// run executes the command in the container, waits for the command to complete,
+// and returns an error if the command did not exit successfully.
+func run(ctx context.Context, docker client.ContainerAPIClient, containerID string, cmd []string) error {
+	exec, err := docker.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return err
+	}
+	defer resp.Close()
+
+	if err := docker.ContainerExecStart(ctx, exec.ID, types.ExecStartCheck{}); err != nil {
+		return fmt.Errorf("unable to start the exec, %w", err)
+	}
+
+	for {
+		info, err := docker.ContainerExecInspect(ctx, exec.ID)
+		if err != nil {
+			return err
+		}
+
+		if info.Running {
+			continue
+		}
+
+		if info.ExitCode != 0 {
+			return fmt.Errorf("the command %q exited with code %d", strings.Join(cmd, " "), info.ExitCode)
+		}
+
+		return nil
+	}
+}
+
+// findOrCreateVolume takes the name of a volume and returns the existing volume or creates
+// a new volume with the labels. This allows apply to be run again if it failed after the
+// volume was created but before the container was created.
+func findOrCreateVolume(ctx context.Context, docker client.VolumeAPIClient, name string, labels map[string]string) (types.Volume, error) {
+	// look for an existing volume, the name filter matches partial names
+	filter := filters.NewArgs()
+	filter.Add("name", name)
+
+	volumes, err := docker.VolumeList(ctx, filter)
+	if err != nil {
+		return types.Volume{}, fmt.Errorf("unable to list the volumes, %w", err)
+	}
+
+	for _, v := range volumes.Volumes {
+		if v.Name == name {
+			return *v, nil
+		}
+	}
+
+	// create the volume
+	volume, err := docker.VolumeCreate(ctx, volumetypes.VolumeCreateBody{Driver: "local", Name: name, Labels: labels})
+	if err != nil {
+		return types.Volume{}, fmt.Errorf("unable to create the volume, %w", err)
+	}
+
+	return volume, nil
+}
+
+// imagePlatform returns the platform to use for the database image. If the database
+// has a platform in the config, it is always used. When the docker daemon is
+// running on arm64 (e.g. Apple Silicon) and the image does not have a native
+// arm64 variant, the amd64 platform is used and the user is warned that the
+// image will run using emulation. A nil platform uses the daemon default.
+func imagePlatform(ctx context.Context, docker client.CommonAPIClient, image string, db config.Database, output terminal.Outputer) (*specs.Platform, error) {
+	if db.Platform != "" {
+		return parsePlatform(db.Platform)
+	}
+
+	info, err := docker.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the docker info, %w", err)
+	}
+
+	if info.Architecture != "aarch64" && info.Architecture != "arm64" {
+		return nil, nil
+	}
+
+	// check the platforms from the registry, if the registry is not available use the default
+	dist, err := docker.DistributionInspect(ctx, image, "")
+	if err != nil {
+		return nil, nil
+	}
+
+	for _, p := range dist.Platforms {
+		if p.Architecture == "arm64" {
+			return nil, nil
+		}
+	}
+
+	output.Info(fmt.Sprintf("The image %s does not support arm64, using linux/amd64 emulation which may be slower", image))
+
+	return &specs.Platform{OS: "linux", Architecture: "amd64"}, nil
+}
+
+// parsePlatform takes a platform string (e.g. linux/amd64 or linux/arm64/v8)
+// and returns the platform.
+func parsePlatform(s string) (*specs.Platform, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid platform %q, expected os/arch (e.g. linux/amd64)", s)
+	}
+
+	platform := &specs.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		platform.Variant = parts[2]
+	}
+
+	return platform, nil
+}
+
+// formatPlatform returns the platform as a string for pulling images.
+func formatPlatform(p *specs.Platform) string {
+	if p.Variant != "" {
+		return p.OS + "/" + p.Architecture + "/" + p.Variant
+	}
+
+	return p.OS + "/" + p.Architecture
+}
+
 func waitForMySQLContainer(ctx context.Context, docker client.CommonAPIClient, containerID string, d config.Database) error {
 	// verify the mysql socket exists in the container
 	for {
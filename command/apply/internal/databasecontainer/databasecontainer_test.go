@@ -0,0 +1,477 @@
+package databasecontainer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+func Test_findOrCreateVolume(t *testing.T) {
+	type args struct {
+		name   string
+		labels map[string]string
+	}
+	tests := []struct {
+		name            string
+		args            args
+		volumes         []*types.Volume
+		want            types.Volume
+		wantCreateCalls int
+		wantErr         bool
+	}{
+		{
+			name: "existing volumes are returned without creating a new volume",
+			args: args{
+				name: "mysql-8.0-3306.database.nitro",
+			},
+			volumes: []*types.Volume{
+				{Name: "mysql-8.0-3306.database.nitro-old"},
+				{Name: "mysql-8.0-3306.database.nitro"},
+			},
+			want:            types.Volume{Name: "mysql-8.0-3306.database.nitro"},
+			wantCreateCalls: 0,
+		},
+		{
+			name: "volumes are created when they do not exist",
+			args: args{
+				name:   "mysql-8.0-3306.database.nitro",
+				labels: map[string]string{"com.craftcms.nitro": "true"},
+			},
+			volumes: []*types.Volume{
+				{Name: "mysql-8.0-3306.database.nitro-old"},
+			},
+			want:            types.Volume{Name: "mysql-8.0-3306.database.nitro", Driver: "local", Labels: map[string]string{"com.craftcms.nitro": "true"}},
+			wantCreateCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockVolumeClient{volumes: tt.volumes}
+
+			got, err := findOrCreateVolume(context.Background(), mock, tt.args.name, tt.args.labels)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("findOrCreateVolume() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findOrCreateVolume() = %v, want %v", got, tt.want)
+			}
+
+			if len(mock.createRequests) != tt.wantCreateCalls {
+				t.Errorf("expected %d calls to create the volume, got %d", tt.wantCreateCalls, len(mock.createRequests))
+			}
+		})
+	}
+}
+
+type mockVolumeClient struct {
+	client.VolumeAPIClient
+
+	volumes        []*types.Volume
+	createRequests []volumetypes.VolumeCreateBody
+}
+
+func (m *mockVolumeClient) VolumeList(ctx context.Context, filter filters.Args) (volumetypes.VolumeListOKBody, error) {
+	return volumetypes.VolumeListOKBody{Volumes: m.volumes}, nil
+}
+
+func (m *mockVolumeClient) VolumeCreate(ctx context.Context, options volumetypes.VolumeCreateBody) (types.Volume, error) {
+	m.createRequests = append(m.createRequests, options)
+
+	return types.Volume{Name: options.Name, Driver: options.Driver, Labels: options.Labels}, nil
+}
+
+func Test_imagePlatform(t *testing.T) {
+	tests := []struct {
+		name         string
+		db           config.Database
+		architecture string
+		platforms    []specs.Platform
+		distErr      error
+		want         *specs.Platform
+		wantWarning  bool
+		wantErr      bool
+	}{
+		{
+			name:         "amd64 daemons use the default platform",
+			db:           config.Database{Engine: "mysql", Version: "5.7"},
+			architecture: "x86_64",
+			want:         nil,
+		},
+		{
+			name:         "arm64 daemons use the default platform when the image supports arm64",
+			db:           config.Database{Engine: "mysql", Version: "8.0"},
+			architecture: "aarch64",
+			platforms:    []specs.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64"}},
+			want:         nil,
+		},
+		{
+			name:         "arm64 daemons use amd64 and warn when the image does not support arm64",
+			db:           config.Database{Engine: "mysql", Version: "5.7"},
+			architecture: "aarch64",
+			platforms:    []specs.Platform{{OS: "linux", Architecture: "amd64"}},
+			want:         &specs.Platform{OS: "linux", Architecture: "amd64"},
+			wantWarning:  true,
+		},
+		{
+			name:         "arm64 daemons use the default platform when the registry is not available",
+			db:           config.Database{Engine: "mysql", Version: "5.7"},
+			architecture: "aarch64",
+			distErr:      fmt.Errorf("registry unavailable"),
+			want:         nil,
+		},
+		{
+			name:         "platforms from the config are always used",
+			db:           config.Database{Engine: "mysql", Version: "8.0", Platform: "linux/amd64"},
+			architecture: "aarch64",
+			platforms:    []specs.Platform{{OS: "linux", Architecture: "arm64"}},
+			want:         &specs.Platform{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			name:    "invalid platforms from the config return an error",
+			db:      config.Database{Engine: "mysql", Version: "8.0", Platform: "amd64"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockPlatformClient{architecture: tt.architecture, platforms: tt.platforms, distErr: tt.distErr}
+			output := &spyOutputer{}
+
+			got, err := imagePlatform(context.Background(), mock, tt.db.Engine+":"+tt.db.Version, tt.db, output)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("imagePlatform() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("imagePlatform() = %v, want %v", got, tt.want)
+			}
+
+			if (len(output.infos) > 0) != tt.wantWarning {
+				t.Errorf("expected warning to be %v, got %v", tt.wantWarning, output.infos)
+			}
+		})
+	}
+}
+
+func Test_parsePlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		want     *specs.Platform
+		wantErr  bool
+	}{
+		{
+			name:     "os and architecture are parsed",
+			platform: "linux/amd64",
+			want:     &specs.Platform{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			name:     "variants are parsed",
+			platform: "linux/arm64/v8",
+			want:     &specs.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		},
+		{
+			name:     "missing architectures return an error",
+			platform: "linux",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePlatform(tt.platform)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePlatform() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePlatform() = %v, want %v", got, tt.want)
+			}
+
+			if got != nil && formatPlatform(got) != tt.platform {
+				t.Errorf("formatPlatform() = %v, want %v", formatPlatform(got), tt.platform)
+			}
+		})
+	}
+}
+
+type mockPlatformClient struct {
+	client.CommonAPIClient
+
+	architecture string
+	platforms    []specs.Platform
+	distErr      error
+}
+
+func (m *mockPlatformClient) Info(ctx context.Context) (types.Info, error) {
+	return types.Info{Architecture: m.architecture}, nil
+}
+
+func (m *mockPlatformClient) DistributionInspect(ctx context.Context, image, encodedRegistryAuth string) (registry.DistributionInspect, error) {
+	if m.distErr != nil {
+		return registry.DistributionInspect{}, m.distErr
+	}
+
+	return registry.DistributionInspect{Platforms: m.platforms}, nil
+}
+
+type spyOutputer struct {
+	terminal.Outputer
+
+	infos []string
+}
+
+func (spy *spyOutputer) Info(s ...string) {
+	spy.infos = append(spy.infos, strings.Join(s, " "))
+}
+
+func (spy *spyOutputer) Pending(s ...string) {}
+
+func (spy *spyOutputer) Done() {}
+
+func (spy *spyOutputer) Warning() {}
+
+func TestStartOrCreateReplica(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	db := config.Database{Engine: "postgres", Version: "13", Port: "5432", Platform: "linux/amd64", Replica: &config.Replica{Port: "5433"}}
+
+	// Act
+	id, hostname, err := StartOrCreateReplica(ctx, docker, "network", "primary", db, "127.0.0.1", "", &spyOutputer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if hostname != "postgres-13-5432-replica.database.nitro" {
+		t.Errorf("expected the replica hostname, got %s", hostname)
+	}
+
+	// replication is allowed on the database
+	execs := docker.Calls("ContainerExecCreate")
+	if len(execs) != 1 || execs[0].Args[0] != "primary" {
+		t.Fatalf("expected one exec in the database container, got %v", execs)
+	}
+
+	details, err := docker.ContainerInspect(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := details.Config.Labels[containerlabels.DatabaseReplication]; got != "replica" {
+		t.Errorf("expected the replica label, got %q", got)
+	}
+
+	if got := details.Config.Labels[containerlabels.DatabasePort]; got != "5433" {
+		t.Errorf("expected the replica port label, got %q", got)
+	}
+
+	if !strings.Contains(strings.Join(details.Config.Entrypoint, " "), "pg_basebackup -h postgres-13-5432.database.nitro") {
+		t.Errorf("expected the replica to copy the database, got %v", details.Config.Entrypoint)
+	}
+
+	if got := details.HostConfig.PortBindings["5432/tcp"][0].HostPort; got != "5433" {
+		t.Errorf("expected the replica to bind to 5433, got %s", got)
+	}
+
+	// the existing replica is returned
+	again, _, err := StartOrCreateReplica(ctx, docker, "network", "primary", db, "127.0.0.1", "", &spyOutputer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again != id || len(docker.Calls("ContainerCreate")) != 1 {
+		t.Errorf("expected the existing replica to be used")
+	}
+}
+
+func TestStartOrCreate_InitSQL(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	db := config.Database{Engine: "postgres", Version: "13", Port: "5432", Platform: "linux/amd64", InitSQL: []string{"~/dev/schema.sql", "~/dev/seed.sql.gz"}}
+
+	// Act
+	id, _, err := StartOrCreate(ctx, docker, "/Users/oli", "network", db, nil, "127.0.0.1", "", &spyOutputer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	details, err := docker.ContainerInspect(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, m := range details.HostConfig.Mounts {
+		if m.Type == mount.TypeBind {
+			got = append(got, fmt.Sprintf("%s:%s:%t", m.Source, m.Target, m.ReadOnly))
+		}
+	}
+
+	want := []string{
+		"/Users/oli/dev/schema.sql:/docker-entrypoint-initdb.d/01-schema.sql:true",
+		"/Users/oli/dev/seed.sql.gz:/docker-entrypoint-initdb.d/02-seed.sql.gz:true",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the init files to be mounted in order, got %v", got)
+	}
+}
+
+func TestStartOrCreate_Aliases(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	db := config.Database{Engine: "postgres", Version: "13", Port: "5432", Platform: "linux/amd64"}
+
+	// Act
+	if _, _, err := StartOrCreate(ctx, docker, t.TempDir(), "network", db, []string{"database", "postgres"}, "127.0.0.1", "", &spyOutputer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	creates := docker.Calls("ContainerCreate")
+	if len(creates) != 1 {
+		t.Fatalf("expected the container to be created, got %d", len(creates))
+	}
+
+	endpoint := creates[0].Args[2].(*network.NetworkingConfig).EndpointsConfig["network"]
+	if !reflect.DeepEqual(endpoint.Aliases, []string{"database", "postgres"}) {
+		t.Errorf("expected the aliases on the network, got %v", endpoint.Aliases)
+	}
+
+	// the same aliases keep the container
+	if _, _, err := StartOrCreate(ctx, docker, t.TempDir(), "network", db, []string{"database", "postgres"}, "127.0.0.1", "", &spyOutputer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(docker.Calls("ContainerRemove")) != 0 {
+		t.Errorf("expected the container with the same aliases to be kept")
+	}
+
+	// changing the aliases recreates the container
+	if _, _, err := StartOrCreate(ctx, docker, t.TempDir(), "network", db, []string{"postgres"}, "127.0.0.1", "", &spyOutputer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(docker.Calls("ContainerRemove")) != 1 || len(docker.Calls("ContainerCreate")) != 2 {
+		t.Errorf("expected the container to be recreated when the aliases change")
+	}
+}
+
+func TestStartOrCreate_BindAddress(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	db := config.Database{Engine: "postgres", Version: "13", Port: "5432", Platform: "linux/amd64"}
+
+	tests := []struct {
+		name         string
+		addr         string
+		wantRecreate bool
+	}{
+		{
+			name: "the port is bound to localhost",
+			addr: "127.0.0.1",
+		},
+		{
+			name:         "changing the address recreates the container",
+			addr:         "0.0.0.0",
+			wantRecreate: true,
+		},
+		{
+			name: "the same address keeps the container",
+			addr: "0.0.0.0",
+		},
+		{
+			name:         "the port is bound to IPv6 addresses",
+			addr:         "::1",
+			wantRecreate: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removes := len(docker.Calls("ContainerRemove"))
+
+			// Act
+			id, _, err := StartOrCreate(ctx, docker, t.TempDir(), "network", db, nil, tt.addr, "", &spyOutputer{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Assert
+			if recreated := len(docker.Calls("ContainerRemove")) > removes; recreated != tt.wantRecreate {
+				t.Errorf("expected the container to be recreated to be %v, got %v", tt.wantRecreate, recreated)
+			}
+
+			details, err := docker.ContainerInspect(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := details.HostConfig.PortBindings["5432/tcp"][0]; got.HostIP != tt.addr || got.HostPort != "5432" {
+				t.Errorf("expected the port to be bound to %s:5432, got %s:%s", tt.addr, got.HostIP, got.HostPort)
+			}
+		})
+	}
+}
+
+func TestWithoutReplicas(t *testing.T) {
+	containers := []types.Container{
+		{ID: "primary", Labels: map[string]string{containerlabels.DatabaseReplication: "primary"}},
+		{ID: "replica", Labels: map[string]string{containerlabels.DatabaseReplication: "replica"}},
+		{ID: "database"},
+	}
+
+	got := WithoutReplicas(containers)
+	if len(got) != 2 || got[0].ID != "primary" || got[1].ID != "database" {
+		t.Errorf("expected the replica to be removed, got %v", got)
+	}
+}
+
+func Test_mysqlReplicaStatement(t *testing.T) {
+	tests := []struct {
+		name string
+		db   config.Database
+		want string
+	}{
+		{
+			name: "mysql 8.0 requests the public key",
+			db:   config.Database{Engine: "mysql", Version: "8.0"},
+			want: "STOP SLAVE; CHANGE MASTER TO MASTER_HOST='primary', MASTER_PORT=3306, MASTER_USER='root', MASTER_PASSWORD='nitro', GET_MASTER_PUBLIC_KEY=1; START SLAVE;",
+		},
+		{
+			name: "mariadb does not request the public key",
+			db:   config.Database{Engine: "mariadb", Version: "10.5"},
+			want: "STOP SLAVE; CHANGE MASTER TO MASTER_HOST='primary', MASTER_PORT=3306, MASTER_USER='root', MASTER_PASSWORD='nitro'; START SLAVE;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mysqlReplicaStatement(tt.db, "primary"); got != tt.want {
+				t.Errorf("mysqlReplicaStatement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,17 +12,34 @@ import (
 
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dotenv"
+	"github.com/craftcms/nitro/pkg/images"
 )
 
+// PHPIniTarget is the path in the container a sites custom php.ini is mounted to
+const PHPIniTarget = "/usr/local/etc/php/conf.d/zz-nitro-custom.ini"
+
+// PHPTimezoneDir is the directory in the container the ini file for a sites timezone is copied to. The
+// file is named nitro-timezone.ini so it loads before the custom php.ini.
+const PHPTimezoneDir = "/usr/local/etc/php/conf.d"
+
+// NginxConfigTarget is the path in the container a sites custom nginx config is mounted to. The
+// craftcms/nginx image includes /etc/nginx/conf.d/*.conf in the http block, the zz- prefix loads
+// the file after the sites default.conf.
+const NginxConfigTarget = "/etc/nginx/conf.d/zz-nitro-custom.conf"
+
 var (
 	ErrMisMatchedImage  = fmt.Errorf("container image does not match")
 	ErrMisMatchedLabel  = fmt.Errorf("container label does not match")
+	ErrMisMatchedBind   = fmt.Errorf("container ports are not bound to the bind address")
+	ErrMisMatchedConfig = fmt.Errorf("container command, entrypoint, env, ports, or volumes do not match")
 	ErrEnvFileNotFound  = fmt.Errorf("unable to find the containers env file")
 	ErrMisMatchedEnvVar = fmt.Errorf("container environment variables do not match")
 )
 
-// Container checks if a custom container is up to date with the configuration
-func Container(home string, container config.Container, details types.ContainerJSON) error {
+// Container checks if a custom container is up to date with the configuration and
+// the ports are bound to the bind address.
+func Container(home string, container config.Container, details types.ContainerJSON, bindAddress string) error {
 	// check if the image does not match - this uses the image name, not ref
 	if fmt.Sprintf("%s:%s", container.Image, container.Tag) != details.Config.Image {
 		return ErrMisMatchedImage
@@ -33,6 +50,11 @@ func Container(home string, container config.Container, details types.ContainerJ
 		return ErrMisMatchedLabel
 	}
 
+	// check the command, entrypoint, env, ports, and volumes have not changed
+	if details.Config.Labels[containerlabels.NitroContainerHash] != containerlabels.ForCustomContainer(container)[containerlabels.NitroContainerHash] {
+		return ErrMisMatchedConfig
+	}
+
 	if container.EnvFile != "" {
 		customEnvs := make(map[string]string)
 
@@ -61,17 +83,35 @@ func Container(home string, container config.Container, details types.ContainerJ
 		}
 	}
 
-	// TODO(jasonmccallister) check the port mappings
-	// TODO(jasonmccallister) check the volumes
+	// check the ports are bound to the bind address
+	if details.ContainerJSONBase != nil && details.HostConfig != nil {
+		for _, bindings := range details.HostConfig.PortBindings {
+			for _, b := range bindings {
+				if b.HostIP != bindAddress {
+					return ErrMisMatchedBind
+				}
+			}
+		}
+	}
 
 	return nil
 }
 
 // Site takes the home directory, site, and a container to determine if they
-// match whats expected.
-func Site(home string, site config.Site, container types.ContainerJSON, blackfire config.Blackfire) bool {
+// match whats expected. The registry is the registry mirror from the config,
+// which can be empty. The timezone is the sites timezone from the config,
+// which can be empty to use the images default. The digest is the currently resolved digest of the
+// sites image, when it is not empty the container must have been created
+// from the same image digest.
+func Site(home string, site config.Site, container types.ContainerJSON, blackfire config.Blackfire, registry, timezone, digest string) bool {
 	// check if the image does not match - this uses the image name, not ref
-	if fmt.Sprintf("docker.io/craftcms/nginx:%s-dev", site.Version) != container.Config.Image {
+	if images.Site(registry, site) != container.Config.Image {
+		return false
+	}
+
+	// check if the image has changed since the container was created, sites
+	// with a Dockerfile always pass the ID of the built image
+	if digest != "" && container.Config.Labels[containerlabels.ImageDigest] != digest {
 		return false
 	}
 
@@ -98,6 +138,86 @@ func Site(home string, site config.Site, container types.ContainerJSON, blackfir
 		}
 	}
 
+	// check the custom php.ini mount
+	iniPath, err := site.GetPHPIniPath(home)
+	if err != nil {
+		return false
+	}
+
+	var iniSource string
+	for _, m := range container.Mounts {
+		if m.Destination == PHPIniTarget {
+			iniSource = m.Source
+		}
+	}
+
+	if iniPath != iniSource {
+		return false
+	}
+
+	// check the custom nginx config mount
+	nginxPath, err := site.GetNginxConfigPath(home)
+	if err != nil {
+		return false
+	}
+
+	var nginxSource string
+	for _, m := range container.Mounts {
+		if m.Destination == NginxConfigTarget {
+			nginxSource = m.Source
+		}
+	}
+
+	if nginxPath != nginxSource {
+		return false
+	}
+
+	// check if the sites .env file has changed
+	if site.Dotenv != "" || container.Config.Labels[containerlabels.DotenvHash] != "" {
+		dotenvPath, err := site.GetDotenvPath(home)
+		if err != nil {
+			return false
+		}
+
+		var hash string
+		if dotenvPath != "" {
+			hash, _ = dotenv.Hash(dotenvPath)
+		}
+
+		if container.Config.Labels[containerlabels.DotenvHash] != hash {
+			return false
+		}
+	}
+
+	// check the webroot, containers created before the env was set used the default
+	webroot, err := site.GetWebrootPath()
+	if err != nil {
+		return false
+	}
+
+	current := "/app/web"
+	for _, e := range container.Config.Env {
+		if strings.HasPrefix(e, "WEBROOT=") {
+			current = strings.TrimPrefix(e, "WEBROOT=")
+		}
+	}
+
+	if current != webroot {
+		return false
+	}
+
+	// check the custom labels, the list of keys catches removed labels
+	keys := containerlabels.Custom(site.Labels)
+	if container.Config.Labels[containerlabels.CustomLabels] != strings.Join(keys, ",") {
+		return false
+	}
+
+	for _, k := range keys {
+		if container.Config.Labels[k] != site.Labels[k] {
+			return false
+		}
+	}
+
 	// TODO(jasonmccallister) check the labels for php extensions and write tests
 	switch len(site.Extensions) > 0 {
 	case false:
@@ -110,6 +230,18 @@ func Site(home string, site config.Site, container types.ContainerJSON, blackfir
 		}
 	}
 
+	// check the timezone, containers without the env use the images default
+	var tz string
+	for _, e := range container.Config.Env {
+		if strings.HasPrefix(e, "TZ=") {
+			tz = strings.TrimPrefix(e, "TZ=")
+		}
+	}
+
+	if tz != timezone {
+		return false
+	}
+
 	// run the final check on the environment variables
 	return checkEnvs(site, blackfire, container.Config.Env)
 }
@@ -161,7 +293,11 @@ func checkEnvs(site config.Site, blackfire config.Blackfire, envs []string) bool
 					return false
 				}
 			case "PHP_OPCACHE_ENABLE":
-				if site.PHP.OpcacheEnable && val == config.DefaultEnvs[env] {
+				if site.PHP.OpcacheEnable && val != "1" {
+					return false
+				}
+
+				if !site.PHP.OpcacheEnable && val != config.DefaultEnvs[env] {
 					return false
 				}
 			case "PHP_OPCACHE_REVALIDATE_FREQ":
@@ -130,6 +130,43 @@ func Test_checkEnvs(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "opcache_enable returns false when opcache is disabled",
+			args: args{
+				site: config.Site{},
+				envs: []string{
+					"PHP_OPCACHE_ENABLE=1",
+				},
+			},
+			want: false,
+		},
+		{
+			name: "opcache default values return true",
+			args: args{
+				site: config.Site{},
+				envs: []string{
+					"PHP_OPCACHE_ENABLE=0",
+					"PHP_OPCACHE_REVALIDATE_FREQ=0",
+				},
+			},
+			want: true,
+		},
+		{
+			name: "opcache custom values return true",
+			args: args{
+				site: config.Site{
+					PHP: config.PHP{
+						OpcacheEnable:         true,
+						OpcacheRevalidateFreq: 2,
+					},
+				},
+				envs: []string{
+					"PHP_OPCACHE_ENABLE=1",
+					"PHP_OPCACHE_REVALIDATE_FREQ=2",
+				},
+			},
+			want: true,
+		},
 		{
 			name: "post_max_size returns false",
 			args: args{
@@ -235,6 +272,9 @@ func TestSite(t *testing.T) {
 		site      config.Site
 		container types.ContainerJSON
 		blackfire config.Blackfire
+		registry  string
+		timezone  string
+		digest    string
 	}
 	tests := []struct {
 		name string
@@ -302,6 +342,418 @@ func TestSite(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "changes to the dotenv file return false",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname: "example",
+					Path:     "testdata/example-site",
+					Version:  "7.4",
+					Dotenv:   ".gitignore",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:       "example",
+							containerlabels.DotenvHash: "outdated-hash",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "adding a custom php.ini returns false",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname: "example",
+					Path:     "testdata/example-site",
+					Version:  "7.4",
+					PHPIni:   ".gitignore",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host: "example",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "adding a custom nginx config returns false",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname:    "example",
+					Path:        "testdata/example-site",
+					Version:     "7.4",
+					NginxConfig: ".gitignore",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host: "example",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "matching image digests return true",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname: "example",
+					Path:     "testdata/example-site",
+					Version:  "7.4",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:        "example",
+							containerlabels.ImageDigest: "docker.io/craftcms/nginx@sha256:b1c2",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+				digest: "docker.io/craftcms/nginx@sha256:b1c2",
+			},
+			want: true,
+		},
+		{
+			name: "mismatched image digests return false",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname: "example",
+					Path:     "testdata/example-site",
+					Version:  "7.4",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:        "example",
+							containerlabels.ImageDigest: "docker.io/craftcms/nginx@sha256:a0b1",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+				digest: "docker.io/craftcms/nginx@sha256:b1c2",
+			},
+			want: false,
+		},
+		{
+			name: "changes to the image built from a Dockerfile return false",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname:   "example",
+					Path:       "testdata/example-site",
+					Version:    "7.4",
+					Dockerfile: "Dockerfile",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "nitro/example:latest",
+						Labels: map[string]string{
+							containerlabels.Host:        "example",
+							containerlabels.ImageDigest: "sha256:a0b1",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+				digest: "sha256:b1c2",
+			},
+			want: false,
+		},
+		{
+			name: "adding a Dockerfile returns false",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname:   "example",
+					Path:       "testdata/example-site",
+					Version:    "7.4",
+					Dockerfile: "Dockerfile",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host: "example",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+				digest: "sha256:b1c2",
+			},
+			want: false,
+		},
+		{
+			name: "sites using the image override return true",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname: "example",
+					Path:     "testdata/example-site",
+					Version:  "7.4",
+					Image:    "registry.example.com/team/php:7.4",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "registry.example.com/team/php:7.4",
+						Labels: map[string]string{
+							containerlabels.Host: "example",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "adding an image override returns false",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname: "example",
+					Path:     "testdata/example-site",
+					Version:  "7.4",
+					Image:    "registry.example.com/team/php:7.4",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host: "example",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "matching custom labels return true",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname: "example",
+					Path:     "testdata/example-site",
+					Version:  "7.4",
+					Labels: map[string]string{
+						"traefik.enable": "true",
+					},
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:         "example",
+							containerlabels.CustomLabels: "traefik.enable",
+							"traefik.enable":             "true",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "changed custom labels return false",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname: "example",
+					Path:     "testdata/example-site",
+					Version:  "7.4",
+					Labels: map[string]string{
+						"traefik.enable": "true",
+					},
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:         "example",
+							containerlabels.CustomLabels: "traefik.enable",
+							"traefik.enable":             "false",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "added custom labels return false",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname: "example",
+					Path:     "testdata/example-site",
+					Version:  "7.4",
+					Labels: map[string]string{
+						"traefik.enable": "true",
+					},
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host: "example",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "changed webroots return false",
+			args: args{
+				home: "testdata/example-site",
+				site: config.Site{
+					Hostname: "example",
+					Path:     "testdata/example-site",
+					Version:  "7.4",
+					Webroot:  "public",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host: "example",
+						},
+						Env: []string{"WEBROOT=/app/web"},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Source:      filepath.Join(wd, "testdata", "example-site"),
+							Destination: "/app",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "matching timezones return true",
+			args: args{
+				home:     "testdata/example-site",
+				site:     config.Site{Hostname: "example", Path: "testdata/example-site", Version: "7.4"},
+				timezone: "Europe/Berlin",
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image:  "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{containerlabels.Host: "example"},
+						Env:    []string{"TZ=Europe/Berlin", "PHP_DATE_TIMEZONE=Europe/Berlin"},
+					},
+					Mounts: []types.MountPoint{{Source: filepath.Join(wd, "testdata", "example-site"), Destination: "/app"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "changed timezones return false",
+			args: args{
+				home:     "testdata/example-site",
+				site:     config.Site{Hostname: "example", Path: "testdata/example-site", Version: "7.4"},
+				timezone: "America/Chicago",
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image:  "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{containerlabels.Host: "example"},
+						Env:    []string{"TZ=Europe/Berlin", "PHP_DATE_TIMEZONE=Europe/Berlin"},
+					},
+					Mounts: []types.MountPoint{{Source: filepath.Join(wd, "testdata", "example-site"), Destination: "/app"}},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "adding a timezone returns false",
+			args: args{
+				home:     "testdata/example-site",
+				site:     config.Site{Hostname: "example", Path: "testdata/example-site", Version: "7.4"},
+				timezone: "Europe/Berlin",
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image:  "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{containerlabels.Host: "example"},
+					},
+					Mounts: []types.MountPoint{{Source: filepath.Join(wd, "testdata", "example-site"), Destination: "/app"}},
+				},
+			},
+			want: false,
+		},
 		{
 			name: "mismatched images return false",
 			args: args{
@@ -319,9 +771,56 @@ func TestSite(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := Site(tt.args.home, tt.args.site, tt.args.container, tt.args.blackfire); got != tt.want {
+			if got := Site(tt.args.home, tt.args.site, tt.args.container, tt.args.blackfire, tt.args.registry, tt.args.timezone, tt.args.digest); got != tt.want {
 				t.Errorf("Site() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestContainer(t *testing.T) {
+	queue := config.Container{Name: "queue", Image: "craftcms/php-fpm", Tag: "7.4-dev", Command: []string{"php", "craft", "queue/listen"}}
+
+	tests := []struct {
+		name      string
+		container config.Container
+		labels    map[string]string
+		want      error
+	}{
+		{
+			name:      "matching containers return nil",
+			container: queue,
+			labels:    containerlabels.ForCustomContainer(queue),
+		},
+		{
+			name:      "a changed command returns an error",
+			container: config.Container{Name: "queue", Image: "craftcms/php-fpm", Tag: "7.4-dev", Command: []string{"php", "craft", "queue/run"}},
+			labels:    containerlabels.ForCustomContainer(queue),
+			want:      ErrMisMatchedConfig,
+		},
+		{
+			name:      "adding env returns an error",
+			container: config.Container{Name: "queue", Image: "craftcms/php-fpm", Tag: "7.4-dev", Command: queue.Command, Env: map[string]string{"QUEUE": "default"}},
+			labels:    containerlabels.ForCustomContainer(queue),
+			want:      ErrMisMatchedConfig,
+		},
+		{
+			name:      "a changed name returns an error",
+			container: config.Container{Name: "worker", Image: "craftcms/php-fpm", Tag: "7.4-dev", Command: queue.Command},
+			labels:    containerlabels.ForCustomContainer(queue),
+			want:      ErrMisMatchedLabel,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{HostConfig: &container.HostConfig{}},
+				Config:            &container.Config{Image: "craftcms/php-fpm:7.4-dev", Labels: tt.labels},
+			}
+
+			if got := Container("", tt.container, details, "127.0.0.1"); got != tt.want {
+				t.Errorf("Container() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
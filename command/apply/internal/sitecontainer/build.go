@@ -0,0 +1,167 @@
+package sitecontainer
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/docker/docker/pkg/jsonmessage"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/images"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// build builds the image for a site from the sites Dockerfile and returns the image ID. The
+// sites path is the build context and the PHP version is passed as the PHP_VERSION build
+// argument. The image is labeled with a hash of the Dockerfile and PHP version, so the image
+// is only rebuilt when they change.
+func build(ctx context.Context, docker client.ImageAPIClient, home string, site config.Site, output terminal.Outputer) (string, error) {
+	path, err := site.GetAbsPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	dockerfile, err := site.GetDockerfilePath(home)
+	if err != nil {
+		return "", err
+	}
+
+	// the Dockerfile must be in the build context
+	rel, err := filepath.Rel(path, dockerfile)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("the Dockerfile %q for %s must be inside the sites path", dockerfile, site.Hostname)
+	}
+
+	hash, err := buildHash(dockerfile, site.Version)
+	if err != nil {
+		return "", err
+	}
+
+	// the built image is not pulled, so it does not use the registry mirror
+	image := images.Site("", site)
+
+	// use the existing image if the Dockerfile has not changed
+	info, _, err := docker.ImageInspectWithRaw(ctx, image)
+	switch {
+	case err == nil && info.Config != nil && info.Config.Labels[containerlabels.DockerfileHash] == hash:
+		return info.ID, nil
+	case err != nil && !client.IsErrNotFound(err):
+		return "", fmt.Errorf("unable to inspect the image %s, %w", image, err)
+	}
+
+	// respect the .dockerignore file when creating the build context
+	excludes, err := ignored(path)
+	if err != nil {
+		return "", err
+	}
+
+	buildContext, err := archive.TarWithOptions(path, &archive.TarOptions{ExcludePatterns: excludes})
+	if err != nil {
+		return "", fmt.Errorf("unable to create the build context for %s, %w", site.Hostname, err)
+	}
+	defer buildContext.Close()
+
+	version := site.Version
+	resp, err := docker.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Tags:        []string{image},
+		Dockerfile:  filepath.ToSlash(rel),
+		BuildArgs:   map[string]*string{"PHP_VERSION": &version},
+		Labels:      map[string]string{containerlabels.DockerfileHash: hash},
+		Remove:      true,
+		ForceRemove: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to build the image %s, %w", image, err)
+	}
+	defer resp.Body.Close()
+
+	// show the build output, this also returns errors from the Dockerfile
+	if err := jsonmessage.DisplayJSONMessagesStream(resp.Body, terminal.ProgressWriter(output), 0, false, nil); err != nil {
+		return "", fmt.Errorf("unable to build the image %s, %w", image, err)
+	}
+
+	info, _, err = docker.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		return "", fmt.Errorf("unable to inspect the image %s, %w", image, err)
+	}
+
+	return info.ID, nil
+}
+
+// buildHash returns the hash of the Dockerfile and the PHP version used as the build argument.
+func buildHash(dockerfile, version string) (string, error) {
+	content, err := ioutil.ReadFile(dockerfile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the Dockerfile %q, %w", dockerfile, err)
+	}
+
+	sum := sha256.Sum256(append(content, []byte("\nPHP_VERSION="+version)...))
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ignored returns the patterns from the .dockerignore file in the build context.
+func ignored(path string) ([]string, error) {
+	f, err := os.Open(filepath.Join(path, ".dockerignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// each line is a pattern, comments and empty lines are skipped
+	var patterns []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		pattern := strings.TrimSpace(s.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		// exceptions start with ! and the pattern is cleaned like the docker cli
+		exception := strings.HasPrefix(pattern, "!")
+		if exception {
+			pattern = strings.TrimSpace(pattern[1:])
+			if pattern == "" {
+				return nil, fmt.Errorf("unable to use the exception %q in the .dockerignore file", s.Text())
+			}
+		}
+
+		pattern = filepath.Clean(pattern)
+		pattern = filepath.ToSlash(pattern)
+		if len(pattern) > 1 && pattern[0] == '/' {
+			pattern = pattern[1:]
+		}
+
+		if exception {
+			pattern = "!" + pattern
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read the .dockerignore file, %w", err)
+	}
+
+	// make sure the patterns are valid before creating the build context
+	if _, err := fileutils.NewPatternMatcher(patterns); err != nil {
+		return nil, fmt.Errorf("unable to use the patterns in the .dockerignore file, %w", err)
+	}
+
+	return patterns, nil
+}
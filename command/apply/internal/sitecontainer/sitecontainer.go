@@ -11,12 +11,20 @@ import (
 	"github.com/craftcms/nitro/command/apply/internal/nginx"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dotenv"
+	"github.com/craftcms/nitro/pkg/images"
+	"github.com/craftcms/nitro/pkg/packagecache"
+	"github.com/craftcms/nitro/pkg/pullprogress"
+	"github.com/craftcms/nitro/pkg/registryauth"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
+	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/stdcopy"
 )
@@ -26,25 +34,75 @@ type command struct {
 	Commands []string
 }
 
-var (
-	// NginxImage is the image used for sites, with the PHP version
-	NginxImage = "docker.io/craftcms/nginx:%s-dev"
-)
+// Options are used to control how the sites image is pulled.
+type Options struct {
+	// ForcePull pulls the image and recreates the container if the image digest has changed
+	ForcePull bool
+
+	// SkipPull does not pull the image when it already exists locally (e.g. after nitro pull)
+	SkipPull bool
+
+	// Recreate removes and creates the container even when it matches the config
+	Recreate bool
+}
+
+// Remove will stop and remove the container for a site, it is used when a site
+// is disabled. It returns false if the site does not have a container.
+func Remove(ctx context.Context, docker client.ContainerAPIClient, hostname string) (bool, error) {
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: containerlabels.ByHost(hostname)})
+	if err != nil {
+		return false, fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	for _, c := range containers {
+		if c.State == "running" {
+			if err := docker.ContainerStop(ctx, c.ID, nil); err != nil {
+				return false, fmt.Errorf("unable to stop the container for %s, %w", hostname, err)
+			}
+		}
+
+		if err := docker.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{}); err != nil {
+			return false, fmt.Errorf("unable to remove the container for %s, %w", hostname, err)
+		}
+	}
+
+	return len(containers) > 0, nil
+}
+
+// StartOrCreate will look for a sites container and start it, creating or
+// recreating the container if it does not match the config.
+func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, home, networkID string, site config.Site, cfg *config.Config, opts Options, output terminal.Outputer) (string, error) {
+	// make sure the site path exists, otherwise docker creates an empty directory for the mount
+	path, err := site.GetAbsPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("unable to find the path %q for %s, %w", path, site.Hostname, err)
+	}
 
-func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, home, networkID string, site config.Site, cfg *config.Config) (string, error) {
-	// set filters for the container
-	filter := filters.NewArgs()
-	filter.Add("label", containerlabels.Host+"="+site.Hostname)
+	// make sure the custom php.ini exists before checking the container
+	iniPath, err := site.GetPHPIniPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	if iniPath != "" {
+		if _, err := os.Stat(iniPath); err != nil {
+			return "", fmt.Errorf("unable to find the php.ini file %q for %s, %w", iniPath, site.Hostname, err)
+		}
+	}
 
 	// look for a container for the site
-	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: containerlabels.ByHost(site.Hostname)})
 	if err != nil {
-		return "", fmt.Errorf("error getting a list of containers")
+		return "", fmt.Errorf("unable to list the containers for %s, %w", site.Hostname, err)
 	}
 
 	// if there are no containers we need to create one
 	if len(containers) == 0 {
-		return create(ctx, docker, home, networkID, site, cfg)
+		return create(ctx, docker, home, networkID, site, cfg, opts, output)
 	}
 
 	// there is a container, so inspect it and make sure it matched
@@ -52,49 +110,106 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, home, net
 
 	if container.State != "running" {
 		if err := docker.ContainerStart(ctx, container.ID, types.ContainerStartOptions{}); err != nil {
-			return "", err
+			return "", fmt.Errorf("unable to start the container %s (%s), %w", site.Hostname, container.Image, err)
 		}
 	}
 
 	// get the containers details that include environment variables
 	details, err := docker.ContainerInspect(ctx, container.ID)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("unable to inspect the container %s (%s), %w", site.Hostname, container.Image, err)
+	}
+
+	// pull the image to check if the container is using the latest digest
+	var digest string
+	switch {
+	case site.Dockerfile != "":
+		// sites with a Dockerfile are rebuilt when the Dockerfile changes
+		digest, err = build(ctx, docker, home, site, output)
+		if err != nil {
+			return "", err
+		}
+	case opts.ForcePull:
+		image := images.Site(cfg.Registry, site)
+
+		if err := pull(ctx, docker, home, image, output); err != nil {
+			return "", err
+		}
+
+		digest, err = imageDigest(ctx, docker, image)
+		if err != nil {
+			return "", err
+		}
 	}
 
-	// if the container is out of date
-	if !match.Site(home, site, details, cfg.Blackfire) {
-		fmt.Print("- updating… ")
+	// if the container is out of date, or should be recreated anyway
+	if opts.Recreate || Outdated(home, site, cfg, details, digest) != "" {
+		output.Info("  - updating", site.Hostname+"…")
 
 		// stop container
 		if err := docker.ContainerStop(ctx, container.ID, nil); err != nil {
-			return "", err
+			return "", fmt.Errorf("unable to stop the container %s (%s), %w", site.Hostname, container.Image, err)
 		}
 
 		// remove container
 		if err := docker.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{}); err != nil {
-			return "", err
+			return "", fmt.Errorf("unable to remove the container %s (%s), %w", site.Hostname, container.Image, err)
 		}
 
-		return create(ctx, docker, home, networkID, site, cfg)
+		return create(ctx, docker, home, networkID, site, cfg, opts, output)
 	}
 
 	return container.ID, nil
 }
 
-func create(ctx context.Context, docker client.CommonAPIClient, home, networkID string, site config.Site, cfg *config.Config) (string, error) {
-	// create the container
-	image := fmt.Sprintf(NginxImage, site.Version)
+// Outdated returns the reason the container for a site must be recreated to
+// match the config, or an empty string if the container is up to date. The
+// digest is the resolved digest of the sites image, which can be empty.
+func Outdated(home string, site config.Site, cfg *config.Config, details types.ContainerJSON, digest string) string {
+	if !match.Site(home, site, details, cfg.Blackfire, cfg.Registry, cfg.GetSiteTimezone(site), digest) {
+		return "the container does not match the config"
+	}
 
-	// pull the image
-	rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false})
-	if err != nil {
-		return "", fmt.Errorf("unable to pull the image, %w", err)
+	if details.Config.Labels[containerlabels.Services] != servicesLabel(cfg.Services) {
+		return "the enabled services changed"
 	}
 
-	buf := &bytes.Buffer{}
-	if _, err := buf.ReadFrom(rdr); err != nil {
-		return "", fmt.Errorf("unable to read output from pulling image %s, %w", image, err)
+	return ""
+}
+
+func create(ctx context.Context, docker client.CommonAPIClient, home, networkID string, site config.Site, cfg *config.Config, opts Options, output terminal.Outputer) (string, error) {
+	// create the container
+	image := images.Site(cfg.Registry, site)
+
+	// record the digest so changes to the image can be detected
+	var digest string
+	var err error
+	if site.Dockerfile != "" {
+		// build the image from the sites Dockerfile instead of pulling
+		digest, err = build(ctx, docker, home, site, output)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		// pull the image, unless it exists and pulling is skipped
+		exists := false
+		if opts.SkipPull && !opts.ForcePull {
+			exists, err = images.Exists(ctx, docker, image)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if !exists {
+			if err := pull(ctx, docker, home, image, output); err != nil {
+				return "", err
+			}
+		}
+
+		digest, err = imageDigest(ctx, docker, image)
+		if err != nil {
+			return "", err
+		}
 	}
 
 	// get the sites path
@@ -106,12 +221,29 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 	// add the site itself and any aliases to the extra hosts
 	extraHosts := []string{fmt.Sprintf("%s:%s", site.Hostname, "127.0.0.1")}
 	for _, s := range site.Aliases {
+		// wildcards are not supported as extra hosts
+		if config.IsWildcard(s) {
+			continue
+		}
+
 		extraHosts = append(extraHosts, fmt.Sprintf("%s:%s", s, "127.0.0.1"))
 	}
 
 	// get the sites environment variables
 	envs := site.AsEnvs("host.docker.internal")
 
+	// set the webroot for the nginx image
+	webroot, err := site.GetWebrootPath()
+	if err != nil {
+		return "", err
+	}
+
+	envs = append(envs, "WEBROOT="+webroot)
+
+	// set the timezone for the container and PHP
+	timezone := cfg.GetSiteTimezone(site)
+	envs = append(envs, config.TimezoneEnvs(timezone)...)
+
 	// does the config have blackfire credentials
 	if cfg.Blackfire.ServerID != "" {
 		envs = append(envs, "BLACKFIRE_SERVER_ID="+cfg.Blackfire.ServerID)
@@ -121,48 +253,145 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 		envs = append(envs, "BLACKFIRE_SERVER_TOKEN="+cfg.Blackfire.ServerToken)
 	}
 
+	// let the site connect to the search services
+	envs = append(envs, serviceEnvs(cfg.Services)...)
+
+	// share the composer and npm downloads between sites
+	envs = append(envs, packagecache.Envs()...)
+
 	// set the labels
 	labels := containerlabels.ForSite(site)
+	labels[containerlabels.ImageDigest] = digest
 
-	// create the container
-	resp, err := docker.ContainerCreate(
-		ctx,
-		&container.Config{
-			Image:  image,
-			Labels: labels,
-			Env:    envs,
-		},
-		&container.HostConfig{
-			Mounts: []mount.Mount{
-				{
-					Type:   mount.TypeBind,
-					Source: path,
-					Target: "/app",
-				},
-			},
-			ExtraHosts: extraHosts,
+	// keep track of the services so enabling or disabling them recreates the container
+	if l := servicesLabel(cfg.Services); l != "" {
+		labels[containerlabels.Services] = l
+	}
+
+	// add the variables from the sites .env file
+	dotenvPath, err := site.GetDotenvPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	if dotenvPath != "" {
+		vars, err := dotenv.Read(dotenvPath)
+		if err != nil {
+			return "", err
+		}
+
+		// don't override the variables nitro manages
+		for _, e := range envs {
+			delete(vars, strings.SplitN(e, "=", 2)[0])
+		}
+
+		envs = append(envs, dotenv.Envs(vars)...)
+
+		// store the hash so changes to the file recreate the container
+		hash, err := dotenv.Hash(dotenvPath)
+		if err != nil {
+			return "", err
+		}
+
+		labels[containerlabels.DotenvHash] = hash
+	}
+
+	// set the mounts for the site
+	mounts := []mount.Mount{
+		{
+			Type:   mount.TypeBind,
+			Source: path,
+			Target: "/app",
 		},
-		&network.NetworkingConfig{
-			EndpointsConfig: map[string]*network.EndpointSettings{
-				"nitro-network": {
-					NetworkID: networkID,
-				},
+	}
+
+	// mount the cache for composer and npm downloads
+	mounts = append(mounts, packagecache.Mount())
+
+	// mount the sites custom php.ini
+	iniPath, err := site.GetPHPIniPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	if iniPath != "" {
+		mounts = append(mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   iniPath,
+			Target:   match.PHPIniTarget,
+			ReadOnly: true,
+		})
+	}
+
+	// mount the sites custom nginx config
+	nginxPath, err := site.GetNginxConfigPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	if nginxPath != "" {
+		mounts = append(mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   nginxPath,
+			Target:   match.NginxConfigTarget,
+			ReadOnly: true,
+		})
+	}
+
+	containerConfig := &container.Config{
+		Image:  image,
+		Labels: labels,
+		Env:    envs,
+	}
+	hostConfig := &container.HostConfig{
+		Mounts:     mounts,
+		ExtraHosts: extraHosts,
+	}
+	networkConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			networkID: {
+				NetworkID: networkID,
 			},
 		},
-		nil,
-		site.Hostname,
-	)
+	}
+
+	// create the container
+	resp, err := docker.ContainerCreate(ctx, containerConfig, hostConfig, networkConfig, nil, site.Hostname)
+	if errdefs.IsConflict(err) {
+		// the name is used by a container that was not found using the labels
+		if err := resolveConflict(ctx, docker, site.Hostname); err != nil {
+			return "", err
+		}
+
+		resp, err = docker.ContainerCreate(ctx, containerConfig, hostConfig, networkConfig, nil, site.Hostname)
+	}
 	if err != nil {
-		return "", fmt.Errorf("unable to create the container, %w", err)
+		return "", fmt.Errorf("unable to create the container %s (%s), %w", site.Hostname, image, err)
+	}
+
+	// set the PHP timezone before starting the container so PHP loads it, it
+	// loads before the custom php.ini so the timezone can still be changed
+	if timezone != "" {
+		tr, err := archive.Generate("nitro-timezone.ini", fmt.Sprintf("date.timezone = \"%s\"\n", timezone))
+		if err != nil {
+			return "", err
+		}
+
+		if err := docker.CopyToContainer(ctx, resp.ID, match.PHPTimezoneDir, tr, types.CopyToContainerOptions{}); err != nil {
+			return "", fmt.Errorf("unable to set the PHP timezone for %s, %w", site.Hostname, err)
+		}
 	}
 
 	// start the container
 	if err := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-		return "", fmt.Errorf("unable to start the container, %w", err)
+		return "", fmt.Errorf("unable to start the container %s (%s), %w", site.Hostname, image, err)
 	}
 
-	// post installation commands
-	var commands []command
+	// post installation commands, the cache directories are owned by the user running composer and npm
+	commands := []command{
+		{Commands: append([]string{"mkdir", "-p"}, packagecache.Dirs()...)},
+		{Commands: append([]string{"chown", "www-data:www-data", packagecache.Target}, packagecache.Dirs()...)},
+	}
 
 	// check for a custom root and copt the template to the container
 	if site.Webroot != "web" {
@@ -215,15 +444,18 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 		// if the option is for a php extension, don't show output
 		if strings.Contains(c.Name, "-extension") {
 			// read the output to pull the image
-			fmt.Print("installing ", c.Commands[len(c.Commands)-1], "… ")
+			output.Pending("installing", c.Commands[len(c.Commands)-1])
 
 			buf := &bytes.Buffer{}
 			if _, err := buf.ReadFrom(attach.Reader); err != nil {
+				output.Warning()
 				return "", fmt.Errorf("unable to read output from container exec attach, %w", err)
 			}
+
+			output.Done()
 		} else {
 			// show the output to stdout and stderr
-			if _, err := stdcopy.StdCopy(os.Stdout, os.Stderr, attach.Reader); err != nil {
+			if _, err := stdcopy.StdCopy(terminal.ProgressWriter(output), os.Stderr, attach.Reader); err != nil {
 				return "", fmt.Errorf("unable to copy the output of container, %w", err)
 			}
 		}
@@ -252,3 +484,99 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 
 	return resp.ID, nil
 }
+
+// resolveConflict handles a container that is using the name of a sites container but
+// was not found using the labels (e.g. a container left over from another config). Nitro
+// containers are removed so the site can take over the name, any other container returns
+// an error since nitro does not manage it.
+func resolveConflict(ctx context.Context, docker client.ContainerAPIClient, name string) error {
+	details, err := docker.ContainerInspect(ctx, name)
+	if err != nil {
+		return fmt.Errorf("unable to inspect the container %s using the sites name, %w", name, err)
+	}
+
+	if details.Config == nil || details.Config.Labels[containerlabels.Nitro] != "true" {
+		var image string
+		if details.Config != nil {
+			image = details.Config.Image
+		}
+
+		return fmt.Errorf("the container name %s is already used by a container that is not managed by nitro (%s), remove or rename the container to apply the site", name, image)
+	}
+
+	if err := docker.ContainerStop(ctx, details.ID, nil); err != nil {
+		return fmt.Errorf("unable to stop the container %s (%s), %w", name, details.Config.Image, err)
+	}
+
+	if err := docker.ContainerRemove(ctx, details.ID, types.ContainerRemoveOptions{}); err != nil {
+		return fmt.Errorf("unable to remove the container %s (%s), %w", name, details.Config.Image, err)
+	}
+
+	return nil
+}
+
+// pull will pull the image and wait for the pull to complete, the credentials
+// for private registries are read from the docker config in the home directory.
+func pull(ctx context.Context, docker client.ImageAPIClient, home, image string, output terminal.Outputer) error {
+	auth, err := registryauth.New(home).ForImage(image)
+	if err != nil {
+		return err
+	}
+
+	rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false, RegistryAuth: auth})
+	if err != nil {
+		return fmt.Errorf("unable to pull the image %s, %w", image, err)
+	}
+
+	// wait for the pull to complete and show progress
+	if err := pullprogress.Wait(rdr, terminal.ProgressWriter(output)); err != nil {
+		return fmt.Errorf("unable to read output from pulling image %s, %w", image, err)
+	}
+
+	return nil
+}
+
+// imageDigest returns the resolved digest for an image tag (e.g. docker.io/craftcms/nginx@sha256:...),
+// if the image does not have a repo digest the image ID is returned.
+func imageDigest(ctx context.Context, docker client.ImageAPIClient, image string) (string, error) {
+	info, _, err := docker.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		return "", fmt.Errorf("unable to inspect the image %s, %w", image, err)
+	}
+
+	if len(info.RepoDigests) > 0 {
+		return info.RepoDigests[0], nil
+	}
+
+	return info.ID, nil
+}
+
+// serviceEnvs returns the environment variables for the enabled search services
+// so sites can connect to them (e.g. ELASTICSEARCH_HOST=elasticsearch.service.nitro).
+func serviceEnvs(services config.Services) []string {
+	var envs []string
+	if services.Elasticsearch {
+		envs = append(envs, "ELASTICSEARCH_HOST="+elasticsearch.Host, "ELASTICSEARCH_PORT="+elasticsearch.Port)
+	}
+
+	if services.Meilisearch {
+		envs = append(envs, "MEILISEARCH_HOST="+meilisearch.Host, "MEILISEARCH_PORT="+meilisearch.Port)
+	}
+
+	return envs
+}
+
+// servicesLabel returns the enabled services that sites have environment
+// variables for (e.g. elasticsearch,meilisearch).
+func servicesLabel(services config.Services) string {
+	var names []string
+	if services.Elasticsearch {
+		names = append(names, elasticsearch.Label)
+	}
+
+	if services.Meilisearch {
+		names = append(names, meilisearch.Label)
+	}
+
+	return strings.Join(names, ",")
+}
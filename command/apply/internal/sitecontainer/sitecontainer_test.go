@@ -0,0 +1,477 @@
+package sitecontainer
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/packagecache"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+type spyOutputer struct {
+	terminal.Outputer
+
+	infos []string
+}
+
+func (spy *spyOutputer) Info(s ...string) {
+	spy.infos = append(spy.infos, strings.Join(s, " "))
+}
+
+func (spy *spyOutputer) Pending(s ...string) {}
+
+func (spy *spyOutputer) Done() {}
+
+func (spy *spyOutputer) Warning() {}
+
+func TestRemove(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New([]types.Container{
+		{ID: "enabled", Names: []string{"/enabled.nitro"}, State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Host: "enabled.nitro"}},
+		{ID: "disabled", Names: []string{"/disabled.nitro"}, State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Host: "disabled.nitro"}},
+	}, nil)
+
+	// Act
+	removed, err := Remove(ctx, docker, "disabled.nitro")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if !removed {
+		t.Errorf("expected the container to be removed")
+	}
+
+	if len(docker.Calls("ContainerStop")) != 1 {
+		t.Errorf("expected the running container to be stopped")
+	}
+
+	if len(docker.Containers) != 1 || docker.Containers[0].ID != "enabled" {
+		t.Errorf("expected only the enabled site container to remain, got %v", docker.Containers)
+	}
+
+	// disabling the site again does nothing
+	removed, err = Remove(ctx, docker, "disabled.nitro")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed {
+		t.Errorf("expected the container to already be removed")
+	}
+
+	if len(docker.Calls("ContainerRemove")) != 1 {
+		t.Errorf("expected the container to be removed once, got %d", len(docker.Calls("ContainerRemove")))
+	}
+}
+
+func TestStartOrCreate_MissingPath(t *testing.T) {
+	docker := dockertest.New(nil, nil)
+	site := config.Site{Hostname: "mysite.nitro", Path: "~/dev/missing", Webroot: "web", Version: "7.4"}
+	cfg := &config.Config{Sites: []config.Site{site}}
+
+	_, err := StartOrCreate(context.Background(), docker, t.TempDir(), "network", site, cfg, Options{}, &spyOutputer{})
+	if err == nil || !strings.Contains(err.Error(), "unable to find the path") {
+		t.Fatalf("expected an error for the missing path, got %v", err)
+	}
+
+	if len(docker.Calls("ContainerCreate")) != 0 {
+		t.Error("expected the container to not be created")
+	}
+}
+
+func TestStartOrCreate_VersionChangeRecreatesOneContainer(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	home := t.TempDir()
+	cfg := &config.Config{
+		Sites: []config.Site{
+			{Hostname: "one.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"},
+			{Hostname: "two.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"},
+		},
+	}
+
+	// create the containers for both sites
+	for _, s := range cfg.Sites {
+		if _, err := StartOrCreate(ctx, docker, home, "network", s, cfg, Options{}, &spyOutputer{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	created := len(docker.Calls("ContainerCreate"))
+
+	// Act
+	if err := cfg.SetSitePHPVersion("two.nitro", "8.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range cfg.Sites {
+		if _, err := StartOrCreate(ctx, docker, home, "network", s, cfg, Options{}, &spyOutputer{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Assert
+	if got := len(docker.Calls("ContainerRemove")); got != 1 {
+		t.Errorf("expected one container to be removed, got %d", got)
+	}
+
+	if got := len(docker.Calls("ContainerCreate")) - created; got != 1 {
+		t.Errorf("expected one container to be created, got %d", got)
+	}
+
+	if len(docker.Containers) != 2 {
+		t.Fatalf("expected two containers, got %d", len(docker.Containers))
+	}
+
+	for _, c := range docker.Containers {
+		want := "docker.io/craftcms/nginx:7.4-dev"
+		if c.Labels[containerlabels.Host] == "two.nitro" {
+			want = "docker.io/craftcms/nginx:8.0-dev"
+		}
+
+		if c.Image != want {
+			t.Errorf("expected %s to use the image %s, got %s", c.Labels[containerlabels.Host], want, c.Image)
+		}
+	}
+}
+
+func TestStartOrCreate_Recreate(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	home := t.TempDir()
+	site := config.Site{Hostname: "mysite.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"}
+	cfg := &config.Config{Sites: []config.Site{site}}
+
+	first, err := StartOrCreate(ctx, docker, home, "network", site, cfg, Options{}, &spyOutputer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	output := &spyOutputer{}
+	id, err := StartOrCreate(ctx, docker, home, "network", site, cfg, Options{Recreate: true}, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if id == first || len(docker.Calls("ContainerRemove")) != 1 {
+		t.Errorf("expected the matching container to be recreated")
+	}
+
+	if want := []string{"  - updating mysite.nitro…"}; !reflect.DeepEqual(output.infos, want) {
+		t.Errorf("expected the update to be shown using the outputer, got %v, want %v", output.infos, want)
+	}
+
+	if len(docker.Containers) != 1 {
+		t.Errorf("expected one container, got %d", len(docker.Containers))
+	}
+}
+
+func TestStartOrCreate_ServicesRecreateTheContainer(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	home := t.TempDir()
+	site := config.Site{Hostname: "mysite.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"}
+	cfg := &config.Config{Sites: []config.Site{site}}
+
+	if _, err := StartOrCreate(ctx, docker, home, "network", site, cfg, Options{}, &spyOutputer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	cfg.Services.Meilisearch = true
+
+	id, err := StartOrCreate(ctx, docker, home, "network", site, cfg, Options{}, &spyOutputer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if len(docker.Calls("ContainerRemove")) != 1 {
+		t.Fatal("expected enabling a service to recreate the container")
+	}
+
+	details, err := docker.ContainerInspect(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envs := strings.Join(details.Config.Env, " ")
+	if !strings.Contains(envs, "MEILISEARCH_HOST=meilisearch.service.nitro") || !strings.Contains(envs, "MEILISEARCH_PORT=7700") {
+		t.Errorf("expected the meilisearch envs, got %v", details.Config.Env)
+	}
+
+	if strings.Contains(envs, "ELASTICSEARCH_HOST") {
+		t.Errorf("expected no elasticsearch envs, got %v", details.Config.Env)
+	}
+
+	// the container matches once it has the services
+	if _, err := StartOrCreate(ctx, docker, home, "network", site, cfg, Options{}, &spyOutputer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(docker.Calls("ContainerRemove")) != 1 {
+		t.Error("expected the container with the services to match")
+	}
+}
+
+func TestStartOrCreate_TimezoneRecreatesTheContainer(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	home := t.TempDir()
+	site := config.Site{Hostname: "mysite.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"}
+	cfg := &config.Config{Sites: []config.Site{site}}
+
+	if _, err := StartOrCreate(ctx, docker, home, "network", site, cfg, Options{}, &spyOutputer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(docker.Calls("CopyToContainer")) != 0 {
+		t.Fatal("expected no timezone without a timezone in the config")
+	}
+
+	// Act
+	cfg.Timezone = "Europe/Berlin"
+
+	id, err := StartOrCreate(ctx, docker, home, "network", site, cfg, Options{}, &spyOutputer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if len(docker.Calls("ContainerRemove")) != 1 {
+		t.Fatal("expected setting the timezone to recreate the container")
+	}
+
+	details, err := docker.ContainerInspect(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envs := strings.Join(details.Config.Env, " ")
+	if !strings.Contains(envs, "TZ=Europe/Berlin") || !strings.Contains(envs, "PHP_DATE_TIMEZONE=Europe/Berlin") {
+		t.Errorf("expected the timezone envs, got %v", details.Config.Env)
+	}
+
+	copies := docker.Calls("CopyToContainer")
+	if len(copies) != 1 || copies[0].Args[2] != "nitro-timezone.ini: date.timezone = \"Europe/Berlin\"\n" {
+		t.Fatalf("expected the PHP timezone to be copied to the container, got %v", copies)
+	}
+
+	// the container matches once it has the timezone
+	if _, err := StartOrCreate(ctx, docker, home, "network", site, cfg, Options{}, &spyOutputer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(docker.Calls("ContainerRemove")) != 1 {
+		t.Error("expected the container with the timezone to match")
+	}
+}
+
+func TestStartOrCreate_DockerfileIsOnlyRebuiltWhenChanged(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	home := t.TempDir()
+	path := t.TempDir()
+
+	dockerfile := filepath.Join(path, "Dockerfile")
+	if err := ioutil.WriteFile(dockerfile, []byte("FROM craftcms/nginx:${PHP_VERSION}-dev\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	site := config.Site{Hostname: "mysite.nitro", Path: path, Webroot: "web", Version: "7.4", Dockerfile: "Dockerfile"}
+	cfg := &config.Config{Sites: []config.Site{site}}
+
+	// Act
+	for i := 0; i < 2; i++ {
+		if _, err := StartOrCreate(ctx, docker, home, "network", site, cfg, Options{}, &spyOutputer{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Assert
+	builds := docker.Calls("ImageBuild")
+	if len(builds) != 1 {
+		t.Fatalf("expected the image to be built once, got %d", len(builds))
+	}
+
+	if opts := builds[0].Args[0].(types.ImageBuildOptions); opts.Dockerfile != "Dockerfile" || *opts.BuildArgs["PHP_VERSION"] != "7.4" {
+		t.Errorf("expected the Dockerfile and PHP version to be used, got %q and %q", opts.Dockerfile, *opts.BuildArgs["PHP_VERSION"])
+	}
+
+	if len(docker.Calls("ImagePull")) != 0 {
+		t.Errorf("expected the image to not be pulled")
+	}
+
+	if got := len(docker.Calls("ContainerCreate")); got != 1 {
+		t.Errorf("expected the container to be created once, got %d", got)
+	}
+
+	// change the Dockerfile
+	if err := ioutil.WriteFile(dockerfile, []byte("FROM craftcms/nginx:${PHP_VERSION}-dev\nRUN echo changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := StartOrCreate(ctx, docker, home, "network", site, cfg, Options{}, &spyOutputer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(docker.Calls("ImageBuild")); got != 2 {
+		t.Errorf("expected the image to be rebuilt, got %d builds", got)
+	}
+
+	if got := len(docker.Calls("ContainerCreate")); got != 2 {
+		t.Errorf("expected the container to be recreated, got %d", got)
+	}
+
+	if len(docker.Containers) != 1 || docker.Containers[0].Image != "nitro/mysite.nitro:latest" {
+		t.Errorf("expected one container using the built image, got %v", docker.Containers)
+	}
+}
+
+func TestStartOrCreate_NameConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  types.Container
+		wantErr   string
+		wantImage string
+	}{
+		{
+			name: "nitro containers using the name are replaced",
+			existing: types.Container{
+				ID:     "leftover",
+				Names:  []string{"/mysite.nitro"},
+				Image:  "docker.io/craftcms/nginx:7.3-dev",
+				State:  "exited",
+				Labels: map[string]string{containerlabels.Nitro: "true"},
+			},
+			wantImage: "docker.io/craftcms/nginx:7.4-dev",
+		},
+		{
+			name: "other containers using the name return an error",
+			existing: types.Container{
+				ID:    "other",
+				Names: []string{"/mysite.nitro"},
+				Image: "nginx:latest",
+				State: "running",
+			},
+			wantErr:   "not managed by nitro (nginx:latest)",
+			wantImage: "nginx:latest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			docker := dockertest.New([]types.Container{tt.existing}, nil)
+			site := config.Site{Hostname: "mysite.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"}
+			cfg := &config.Config{Sites: []config.Site{site}}
+
+			// Act
+			_, err := StartOrCreate(context.Background(), docker, t.TempDir(), "network", site, cfg, Options{}, &spyOutputer{})
+
+			// Assert
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatal(err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("expected the error to contain %q, got %v", tt.wantErr, err)
+			}
+
+			if len(docker.Containers) != 1 || docker.Containers[0].Image != tt.wantImage {
+				t.Errorf("expected one container using %s, got %v", tt.wantImage, docker.Containers)
+			}
+		})
+	}
+}
+
+func TestStartOrCreate_PackageCache(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	site := config.Site{Hostname: "mysite.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"}
+	cfg := &config.Config{Sites: []config.Site{site}}
+
+	// Act
+	id, err := StartOrCreate(ctx, docker, t.TempDir(), "network", site, cfg, Options{}, &spyOutputer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	details, err := docker.ContainerInspect(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found bool
+	for _, m := range details.HostConfig.Mounts {
+		if m.Source == packagecache.Volume && m.Target == packagecache.Target {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected the package cache volume to be mounted, got %v", details.HostConfig.Mounts)
+	}
+
+	envs := strings.Join(details.Config.Env, " ")
+	if !strings.Contains(envs, "COMPOSER_CACHE_DIR="+packagecache.ComposerDir) || !strings.Contains(envs, "npm_config_cache="+packagecache.NpmDir) {
+		t.Errorf("expected the cache envs, got %v", details.Config.Env)
+	}
+
+	var chowned bool
+	for _, c := range docker.Calls("ContainerExecCreate") {
+		exec := c.Args[1].(types.ExecConfig)
+		if exec.User == "root" && strings.Join(exec.Cmd, " ") == "chown www-data:www-data "+strings.Join(append([]string{packagecache.Target}, packagecache.Dirs()...), " ") {
+			chowned = true
+		}
+	}
+
+	if !chowned {
+		t.Errorf("expected the cache directories to be owned by www-data, got %v", docker.Calls("ContainerExecCreate"))
+	}
+}
+
+func Test_ignored(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	content := "# dependencies\nnode_modules\n\n/storage/logs/\n!storage/logs/.gitkeep\n  vendor/../web/cpresources  \n"
+	if err := ioutil.WriteFile(filepath.Join(dir, ".dockerignore"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	got, err := ignored(dir)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"node_modules", "storage/logs", "!storage/logs/.gitkeep", "web/cpresources"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the patterns %v, got %v", want, got)
+	}
+
+	// a missing .dockerignore file does not exclude anything
+	if got, err := ignored(t.TempDir()); err != nil || got != nil {
+		t.Errorf("expected no patterns without a .dockerignore file, got %v and %v", got, err)
+	}
+}
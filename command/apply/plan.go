@@ -0,0 +1,337 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/command/apply/internal/databasecontainer"
+	"github.com/craftcms/nitro/command/apply/internal/match"
+	"github.com/craftcms/nitro/command/apply/internal/sitecontainer"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/svc/dynamodb"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
+	"github.com/craftcms/nitro/pkg/svc/mailhog"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
+	"github.com/craftcms/nitro/pkg/svc/minio"
+	"github.com/craftcms/nitro/pkg/svc/redis"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const (
+	actionCreate   = "create"
+	actionRecreate = "recreate"
+	actionStart    = "start"
+	actionRemove   = "remove"
+)
+
+// action is a change apply makes to a container in the environment.
+type action struct {
+	// Type is create, recreate, start, or remove
+	Type string `json:"type"`
+
+	// Kind is what the container is for (e.g. site, database, service, container, or proxy)
+	Kind string `json:"kind"`
+
+	// Target is the hostname of the container
+	Target string `json:"target"`
+
+	// Reason explains why the change is made
+	Reason string `json:"reason"`
+}
+
+// plan is the list of changes apply makes to reconcile the environment with
+// the config, it is written as JSON for other tools when using --json.
+type plan struct {
+	DryRun  bool     `json:"dry_run"`
+	Actions []action `json:"actions"`
+}
+
+// planOptions are the flags that change which parts of the environment are checked.
+type planOptions struct {
+	sites, databases, services bool
+	enabled, disabled          []config.Site
+	site                       string
+	recreate                   string
+	prune                      bool
+	noProxy                    bool
+
+	// proxy is the name of the proxy container for the environment (e.g. nitro-proxy)
+	proxy string
+}
+
+// buildPlan compares the containers in the environment with the config and
+// returns the changes apply would make, without changing anything. Images are
+// not pulled or built, so changes to an image are not part of the plan.
+func buildPlan(ctx context.Context, docker client.ContainerAPIClient, home string, cfg *config.Config, opts planOptions) ([]action, error) {
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: containerlabels.Environment()})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	// track the containers that are in the config to find the orphans
+	known := map[string]bool{}
+	actions := []action{}
+
+	// find returns the first container that matches the label filters
+	find := func(f filters.Args) *types.Container {
+		list := withLabels(containers, f.Get("label"))
+		if len(list) == 0 {
+			return nil
+		}
+
+		known[list[0].ID] = true
+
+		return &list[0]
+	}
+
+	// check returns the action to start or create a container that is not recreated
+	check := func(c *types.Container, kind, target string) {
+		switch {
+		case c == nil:
+			actions = append(actions, action{Type: actionCreate, Kind: kind, Target: target, Reason: "the container does not exist"})
+		case opts.recreate == recreateAll:
+			actions = append(actions, action{Type: actionRecreate, Kind: kind, Target: target, Reason: "--recreate=all"})
+		case c.State != "running":
+			actions = append(actions, action{Type: actionStart, Kind: kind, Target: target, Reason: "the container is not running"})
+		}
+	}
+
+	if opts.sites && !opts.noProxy {
+		// each environment has its own proxy, so the proxy is found by name
+		var proxy *types.Container
+		for _, c := range withLabels(containers, containerlabels.ByType("proxy").Get("label")) {
+			for _, n := range c.Names {
+				if strings.TrimLeft(n, "/") == opts.proxy {
+					c := c
+					proxy = &c
+				}
+			}
+		}
+
+		switch {
+		case proxy == nil:
+			actions = append(actions, action{Type: actionCreate, Kind: "proxy", Target: opts.proxy, Reason: "the container does not exist"})
+		default:
+			details, err := docker.ContainerInspect(ctx, proxy.ID)
+			if err != nil {
+				return nil, fmt.Errorf("unable to inspect the proxy, %w", err)
+			}
+
+			if !proxycontainer.PortsMatch(details, cfg.Proxy, cfg.GetBindAddress()) {
+				actions = append(actions, action{Type: actionRecreate, Kind: "proxy", Target: opts.proxy, Reason: "the ports changed"})
+			} else if proxy.State != "running" {
+				actions = append(actions, action{Type: actionStart, Kind: "proxy", Target: opts.proxy, Reason: "the container is not running"})
+			}
+		}
+	}
+
+	if opts.databases {
+		for _, db := range cfg.Databases {
+			hostname, err := db.GetHostname()
+			if err != nil {
+				return nil, err
+			}
+
+			// replicas have the same labels as a database on the replicas port
+			var c *types.Container
+			if list := databasecontainer.WithoutReplicas(withLabels(containers, containerlabels.ByDatabase(db).Get("label"))); len(list) > 0 {
+				c = &list[0]
+				known[c.ID] = true
+			}
+
+			// the container is recreated when the platform, replication, aliases, or bind address changed
+			aliases := cfg.GetDatabaseAliases(db)
+			bindAddress := cfg.GetDatabaseBindAddress(db)
+			if c != nil && opts.recreate != recreateAll && databasecontainer.Outdated(*c, db, aliases, bindAddress) != "" {
+				actions = append(actions, action{Type: actionRecreate, Kind: "database", Target: hostname, Reason: databasecontainer.Outdated(*c, db, aliases, bindAddress)})
+			} else {
+				check(c, "database", hostname)
+			}
+
+			if db.Replica == nil || !cfg.Replication {
+				continue
+			}
+
+			replica, err := db.GetReplicaHostname()
+			if err != nil {
+				return nil, err
+			}
+
+			r := find(containerlabels.ByReplica(db))
+			if r != nil && opts.recreate != recreateAll && containerlabels.GetBindAddress(r.Labels) != bindAddress {
+				actions = append(actions, action{Type: actionRecreate, Kind: "database", Target: replica, Reason: "the bind address changed"})
+			} else {
+				check(r, "database", replica)
+			}
+		}
+	}
+
+	if opts.services {
+		services := []struct {
+			label, host string
+			enabled     bool
+		}{
+			{dynamodb.Label, dynamodb.Host, cfg.Services.DynamoDB},
+			{elasticsearch.Label, elasticsearch.Host, cfg.Services.Elasticsearch},
+			{mailhog.Label, mailhog.Host, cfg.Services.Mailhog},
+			{meilisearch.Label, meilisearch.Host, cfg.Services.Meilisearch},
+			{minio.Label, minio.Host, cfg.Services.Minio},
+			{redis.Label, redis.Host, cfg.Services.Redis},
+		}
+
+		for _, s := range services {
+			c := find(containerlabels.ByType(s.label))
+			switch {
+			case !s.enabled && c != nil:
+				actions = append(actions, action{Type: actionRemove, Kind: "service", Target: s.host, Reason: "the service is disabled"})
+			case s.enabled && c != nil && opts.recreate != recreateAll && containerlabels.GetBindAddress(c.Labels) != cfg.GetServiceBindAddress(s.label):
+				actions = append(actions, action{Type: actionRecreate, Kind: "service", Target: s.host, Reason: "the bind address changed"})
+			case s.enabled:
+				check(c, "service", s.host)
+			}
+		}
+	}
+
+	// custom containers are only checked when applying everything
+	if opts.sites && opts.databases && opts.services {
+		for _, ctr := range cfg.Containers {
+			target := fmt.Sprintf("%s.containers.nitro", ctr.Name)
+
+			c := find(filters.NewArgs(filters.Arg("label", containerlabels.NitroContainer+"="+ctr.Name)))
+			if c == nil || opts.recreate == recreateAll {
+				check(c, "container", target)
+				continue
+			}
+
+			details, err := docker.ContainerInspect(ctx, c.ID)
+			if err != nil {
+				return nil, fmt.Errorf("unable to inspect the container %s, %w", target, err)
+			}
+
+			if err := match.Container(home, ctr, details, cfg.GetBindAddress()); err != nil {
+				actions = append(actions, action{Type: actionRecreate, Kind: "container", Target: target, Reason: err.Error()})
+				continue
+			}
+
+			check(c, "container", target)
+		}
+	}
+
+	if opts.sites {
+		for _, site := range opts.disabled {
+			if c := find(containerlabels.ByHost(site.Hostname)); c != nil {
+				actions = append(actions, action{Type: actionRemove, Kind: "site", Target: site.Hostname, Reason: "the site is disabled"})
+			}
+		}
+
+		for _, site := range opts.enabled {
+			c := find(containerlabels.ByHost(site.Hostname))
+			switch {
+			case c == nil:
+				actions = append(actions, action{Type: actionCreate, Kind: "site", Target: site.Hostname, Reason: "the container does not exist"})
+				continue
+			case opts.recreate != "":
+				actions = append(actions, action{Type: actionRecreate, Kind: "site", Target: site.Hostname, Reason: "--recreate"})
+				continue
+			}
+
+			details, err := docker.ContainerInspect(ctx, c.ID)
+			if err != nil {
+				return nil, fmt.Errorf("unable to inspect the container %s, %w", site.Hostname, err)
+			}
+
+			if reason := sitecontainer.Outdated(home, site, cfg, details, ""); reason != "" {
+				actions = append(actions, action{Type: actionRecreate, Kind: "site", Target: site.Hostname, Reason: reason})
+				continue
+			}
+
+			check(c, "site", site.Hostname)
+		}
+	}
+
+	// containers that are not in the config are only removed when pruning
+	if opts.prune {
+		for _, c := range containers {
+			if known[c.ID] || c.Labels[containerlabels.Proxy] != "" || !inScope(c.Labels, opts.sites, opts.databases, opts.services) {
+				continue
+			}
+
+			if opts.site != "" && c.Labels[containerlabels.Host] != opts.site {
+				continue
+			}
+
+			name := c.ID
+			if len(c.Names) > 0 {
+				name = strings.TrimLeft(c.Names[0], "/")
+			}
+
+			actions = append(actions, action{Type: actionRemove, Kind: kindOf(c.Labels), Target: name, Reason: "the container is not in the config"})
+		}
+	}
+
+	return actions, nil
+}
+
+// withLabels returns the containers that have each of the label filters (e.g. nitro.type=database).
+func withLabels(containers []types.Container, labels []string) []types.Container {
+	var list []types.Container
+	for _, c := range containers {
+		found := true
+		for _, l := range labels {
+			parts := strings.SplitN(l, "=", 2)
+			if v, ok := c.Labels[parts[0]]; !ok || (len(parts) == 2 && v != parts[1]) {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			list = append(list, c)
+		}
+	}
+
+	return list
+}
+
+// kindOf returns the kind of container for a plan action based on the labels.
+func kindOf(labels map[string]string) string {
+	switch labels[containerlabels.Type] {
+	case "database":
+		return "database"
+	case dynamodb.Label, elasticsearch.Label, mailhog.Label, meilisearch.Label, minio.Label, redis.Label:
+		return "service"
+	}
+
+	if labels[containerlabels.Host] != "" {
+		return "site"
+	}
+
+	return "container"
+}
+
+// writePlan writes the plan as a single line of JSON.
+func writePlan(w io.Writer, dryRun bool, actions []action) error {
+	return json.NewEncoder(w).Encode(plan{DryRun: dryRun, Actions: actions})
+}
+
+// showPlan shows each of the actions in the plan.
+func showPlan(actions []action, output terminal.Outputer) {
+	if len(actions) == 0 {
+		output.Info("No changes, the environment matches the config")
+		return
+	}
+
+	output.Info("Planned changes:")
+	for _, a := range actions {
+		output.Info(fmt.Sprintf("  %s %s %s (%s)", a.Type, a.Kind, a.Target, a.Reason))
+	}
+}
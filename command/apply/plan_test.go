@@ -0,0 +1,137 @@
+package apply
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+func Test_buildPlan(t *testing.T) {
+	// Arrange
+	docker := dockertest.New([]types.Container{
+		{ID: "old", Names: []string{"/old.nitro"}, Image: "docker.io/craftcms/nginx:7.3-dev", State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Host: "old.nitro"}},
+		{ID: "gone", Names: []string{"/gone.nitro"}, Image: "docker.io/craftcms/nginx:7.4-dev", State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Host: "gone.nitro"}},
+		{ID: "redis", Names: []string{"/redis.service.nitro"}, State: "exited", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "redis"}},
+		{ID: "mailhog", Names: []string{"/mailhog.service.nitro"}, State: "exited", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "mailhog"}},
+	}, nil)
+	cfg := &config.Config{
+		Services: config.Services{Mailhog: true},
+		Sites: []config.Site{
+			{Hostname: "new.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"},
+			{Hostname: "old.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"},
+		},
+	}
+
+	// Act
+	actions, err := buildPlan(context.Background(), docker, t.TempDir(), cfg, planOptions{
+		sites:     true,
+		databases: true,
+		services:  true,
+		enabled:   cfg.Sites,
+		prune:     true,
+		noProxy:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writePlan(buf, true, actions); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("expected the plan to be valid JSON, %v", err)
+	}
+
+	var want map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+		"dry_run": true,
+		"actions": [
+			{"type": "start", "kind": "service", "target": "mailhog.service.nitro", "reason": "the container is not running"},
+			{"type": "remove", "kind": "service", "target": "redis.service.nitro", "reason": "the service is disabled"},
+			{"type": "create", "kind": "site", "target": "new.nitro", "reason": "the container does not exist"},
+			{"type": "recreate", "kind": "site", "target": "old.nitro", "reason": "the container does not match the config"},
+			{"type": "remove", "kind": "site", "target": "gone.nitro", "reason": "the container is not in the config"}
+		]
+	}`), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the plan\n%v\ngot\n%v", want, got)
+	}
+
+	// the plan does not change the environment
+	for _, method := range []string{"ContainerCreate", "ContainerStart", "ContainerStop", "ContainerRemove", "ImagePull"} {
+		if calls := docker.Calls(method); len(calls) != 0 {
+			t.Errorf("expected no calls to %s, got %d", method, len(calls))
+		}
+	}
+}
+
+func Test_buildPlan_Recreate(t *testing.T) {
+	docker := dockertest.New([]types.Container{
+		{ID: "site", Image: "docker.io/craftcms/nginx:7.4-dev", State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Host: "mysite.nitro"}},
+		{ID: "redis", State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "redis"}},
+	}, nil)
+	cfg := &config.Config{
+		Services: config.Services{Redis: true},
+		Sites:    []config.Site{{Hostname: "mysite.nitro", Path: t.TempDir(), Webroot: "web", Version: "7.4"}},
+	}
+
+	tests := []struct {
+		name     string
+		recreate string
+		want     []string
+	}{
+		{
+			name:     "sites are recreated",
+			recreate: recreateSites,
+			want:     []string{"mysite.nitro"},
+		},
+		{
+			name:     "services and sites are recreated",
+			recreate: recreateAll,
+			want:     []string{"redis.service.nitro", "mysite.nitro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions, err := buildPlan(context.Background(), docker, t.TempDir(), cfg, planOptions{
+				sites:     true,
+				databases: true,
+				services:  true,
+				enabled:   cfg.Sites,
+				recreate:  tt.recreate,
+				noProxy:   true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, a := range actions {
+				if a.Type != actionRecreate {
+					t.Errorf("expected only recreate actions, got %s for %s", a.Type, a.Target)
+				}
+
+				got = append(got, a.Target)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v to be recreated, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package apply
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// mountSource is a path on the host that is bind mounted into a container.
+type mountSource struct {
+	// Path is the absolute path on the host
+	Path string
+
+	// Kind is what the path is used for (e.g. site directory or nginx config)
+	Kind string
+
+	// Owner is the hostname of the site or database that mounts the path
+	Owner string
+
+	// Dir is true when the path is a directory, otherwise it is a file
+	Dir bool
+}
+
+// mountSources returns the paths that are bind mounted for the sites and the
+// init SQL files for the databases.
+func mountSources(home string, cfg *config.Config, sites []config.Site) ([]mountSource, error) {
+	var sources []mountSource
+	for _, s := range sites {
+		path, err := s.GetAbsPath(home)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, mountSource{Path: path, Kind: "site directory", Owner: s.Hostname, Dir: true})
+
+		iniPath, err := s.GetPHPIniPath(home)
+		if err != nil {
+			return nil, err
+		}
+
+		if iniPath != "" {
+			sources = append(sources, mountSource{Path: iniPath, Kind: "php.ini", Owner: s.Hostname})
+		}
+
+		nginxPath, err := s.GetNginxConfigPath(home)
+		if err != nil {
+			return nil, err
+		}
+
+		if nginxPath != "" {
+			sources = append(sources, mountSource{Path: nginxPath, Kind: "nginx config", Owner: s.Hostname})
+		}
+	}
+
+	for _, db := range cfg.Databases {
+		paths, err := db.GetInitSQLPaths(home)
+		if err != nil {
+			return nil, err
+		}
+
+		hostname, _ := db.GetHostname()
+		for _, p := range paths {
+			sources = append(sources, mountSource{Path: p, Kind: "init_sql file", Owner: hostname})
+		}
+	}
+
+	return sources, nil
+}
+
+// preflight checks that each of the bind mount sources exist before any
+// containers are created, docker would otherwise create an empty directory
+// owned by root. Missing site directories can be created after a prompt, an
+// error listing the other missing paths is returned.
+func preflight(sources []mountSource, output terminal.Outputer) error {
+	var missing []string
+	for _, s := range sources {
+		stat, err := os.Stat(s.Path)
+		switch {
+		case os.IsNotExist(err) && s.Dir:
+			create, err := output.Confirm(fmt.Sprintf("The %s %s for %s does not exist, create it", s.Kind, s.Path, s.Owner), false, "?")
+			if err != nil {
+				return err
+			}
+
+			if !create {
+				missing = append(missing, fmt.Sprintf("%s (the %s for %s)", s.Path, s.Kind, s.Owner))
+				continue
+			}
+
+			if err := os.MkdirAll(s.Path, 0755); err != nil {
+				return fmt.Errorf("unable to create the directory %s, %w", s.Path, err)
+			}
+		case err != nil:
+			missing = append(missing, fmt.Sprintf("%s (the %s for %s)", s.Path, s.Kind, s.Owner))
+		case stat.IsDir() != s.Dir:
+			kind := "a file"
+			if s.Dir {
+				kind = "a directory"
+			}
+
+			missing = append(missing, fmt.Sprintf("%s (the %s for %s must be %s)", s.Path, s.Kind, s.Owner, kind))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("unable to find the paths to mount:\n  - %s", strings.Join(missing, "\n  - "))
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package apply
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+// confirmOutputer answers each prompt with confirm and records the prompts.
+type confirmOutputer struct {
+	mockOutputer
+	confirm bool
+	prompts []string
+}
+
+func (c *confirmOutputer) Confirm(message string, fallback bool, sep string) (bool, error) {
+	c.prompts = append(c.prompts, message)
+
+	return c.confirm, nil
+}
+
+func Test_mountSources(t *testing.T) {
+	// Arrange
+	cfg := &config.Config{
+		Databases: []config.Database{{Engine: "mysql", Version: "8.0", Port: "3306", InitSQL: []string{"/tmp/seed.sql"}}},
+	}
+	sites := []config.Site{{Hostname: "craft-dev.nitro", Path: "~/dev/craft-dev", PHPIni: "php.ini", NginxConfig: "nginx.conf"}}
+
+	// Act
+	got, err := mountSources("/Users/oli", cfg, sites)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	want := []mountSource{
+		{Path: "/Users/oli/dev/craft-dev", Kind: "site directory", Owner: "craft-dev.nitro", Dir: true},
+		{Path: "/Users/oli/dev/craft-dev/php.ini", Kind: "php.ini", Owner: "craft-dev.nitro"},
+		{Path: "/Users/oli/dev/craft-dev/nginx.conf", Kind: "nginx config", Owner: "craft-dev.nitro"},
+		{Path: "/tmp/seed.sql", Kind: "init_sql file", Owner: "mysql-8.0-3306.database.nitro"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mountSources() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_preflight(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	file := filepath.Join(dir, "seed.sql")
+	if err := ioutil.WriteFile(file, []byte("SELECT 1;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	site := filepath.Join(dir, "craft-dev")
+
+	tests := []struct {
+		name        string
+		sources     []mountSource
+		confirm     bool
+		wantPrompts int
+		wantErr     []string
+		wantDir     bool
+	}{
+		{
+			name:    "existing paths pass",
+			sources: []mountSource{{Path: dir, Kind: "site directory", Owner: "craft-dev.nitro", Dir: true}, {Path: file, Kind: "init_sql file"}},
+		},
+		{
+			name:        "missing site directories are created when confirmed",
+			sources:     []mountSource{{Path: site, Kind: "site directory", Owner: "craft-dev.nitro", Dir: true}},
+			confirm:     true,
+			wantPrompts: 1,
+			wantDir:     true,
+		},
+		{
+			name: "each missing path is listed",
+			sources: []mountSource{
+				{Path: filepath.Join(dir, "missing"), Kind: "site directory", Owner: "craft-dev.nitro", Dir: true},
+				{Path: filepath.Join(dir, "nginx.conf"), Kind: "nginx config", Owner: "craft-dev.nitro"},
+				{Path: dir, Kind: "php.ini", Owner: "craft-dev.nitro"},
+			},
+			wantPrompts: 1,
+			wantErr: []string{
+				filepath.Join(dir, "missing") + " (the site directory for craft-dev.nitro)",
+				filepath.Join(dir, "nginx.conf") + " (the nginx config for craft-dev.nitro)",
+				dir + " (the php.ini for craft-dev.nitro must be a file)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &confirmOutputer{confirm: tt.confirm}
+
+			// Act
+			err := preflight(tt.sources, output)
+
+			// Assert
+			if len(output.prompts) != tt.wantPrompts {
+				t.Errorf("expected %d prompts, got %v", tt.wantPrompts, output.prompts)
+			}
+
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			for _, w := range tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), w) {
+					t.Errorf("expected the error to list %q, got %v", w, err)
+				}
+			}
+
+			if tt.wantDir {
+				if stat, err := os.Stat(site); err != nil || !stat.IsDir() {
+					t.Errorf("expected the site directory to be created, got %v", err)
+				}
+			}
+		})
+	}
+}
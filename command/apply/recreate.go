@@ -0,0 +1,61 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockerclient"
+)
+
+const (
+	// recreateSites recreates the site containers, it is the default for --recreate
+	recreateSites = "sites"
+
+	// recreateAll recreates the site, database, service, and custom containers
+	recreateAll = "all"
+)
+
+// validRecreate returns an error if the value of the --recreate flag is not sites or all.
+func validRecreate(recreate string) error {
+	switch recreate {
+	case "", recreateSites, recreateAll:
+		return nil
+	}
+
+	return fmt.Errorf("invalid value %q for --recreate, must be %s or %s", recreate, recreateSites, recreateAll)
+}
+
+// removeForRecreate stops and removes the database, service, and custom
+// containers that are in scope so apply creates them again. The volumes are
+// kept so no data is lost. Sites are recreated by sitecontainer and the proxy
+// is never removed. It returns the number of containers that were removed.
+func removeForRecreate(ctx context.Context, docker client.ContainerAPIClient, sites, databases, services bool) (int, error) {
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: containerlabels.Environment()})
+	if err != nil {
+		return 0, fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	removed := 0
+	for _, c := range containers {
+		if c.Labels[containerlabels.Host] != "" || c.Labels[containerlabels.Proxy] != "" {
+			continue
+		}
+
+		if !inScope(c.Labels, sites, databases, services) {
+			continue
+		}
+
+		// the volumes are not removed
+		if err := dockerclient.RemoveContainer(ctx, docker, c, nil, types.ContainerRemoveOptions{}); err != nil {
+			return removed, err
+		}
+
+		removed++
+	}
+
+	return removed, nil
+}
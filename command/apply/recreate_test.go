@@ -0,0 +1,81 @@
+package apply
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+func Test_removeForRecreate(t *testing.T) {
+	containers := []types.Container{
+		{ID: "site", State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Host: "craft-dev.nitro"}},
+		{ID: "proxy", State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Proxy: "true"}},
+		{ID: "database", State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "database"}},
+		{ID: "mailhog", State: "exited", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "mailhog"}},
+		{ID: "custom", State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.NitroContainer: "elasticsearch"}},
+	}
+
+	tests := []struct {
+		name                      string
+		sites, databases, service bool
+		want                      []string
+	}{
+		{
+			name:      "everything except sites and the proxy is removed",
+			sites:     true,
+			databases: true,
+			service:   true,
+			want:      []string{"custom", "database", "mailhog"},
+		},
+		{
+			name:      "only the databases are removed when only checking databases",
+			databases: true,
+			want:      []string{"database"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			docker := dockertest.New(append([]types.Container{}, containers...), nil)
+
+			// Act
+			removed, err := removeForRecreate(context.Background(), docker, tt.sites, tt.databases, tt.service)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Assert
+			var got []string
+			for _, c := range docker.Calls("ContainerRemove") {
+				got = append(got, c.Args[0].(string))
+
+				if opts := c.Args[1].(types.ContainerRemoveOptions); opts.RemoveVolumes {
+					t.Errorf("expected the volumes for %s to be kept", c.Args[0])
+				}
+			}
+			sort.Strings(got)
+
+			if removed != len(tt.want) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v to be removed, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_validRecreate(t *testing.T) {
+	for _, v := range []string{"", recreateSites, recreateAll} {
+		if err := validRecreate(v); err != nil {
+			t.Errorf("expected %q to be valid, got %v", v, err)
+		}
+	}
+
+	if err := validRecreate("databases"); err == nil {
+		t.Error("expected databases to be invalid")
+	}
+}
@@ -0,0 +1,81 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// siteConcurrency is the number of sites that are checked at the same time
+var siteConcurrency = 4
+
+type siteResult struct {
+	hostname string
+	id       string
+	err      error
+}
+
+// reconcileSites calls check for each site concurrently, with no more than
+// limit sites at a time, and calls done with the result of each site as it
+// completes. Calls to done are never made concurrently. Each site is checked
+// with its own buffered outputer, the output is shown right before calling
+// done so the output for a site is not interleaved with the other sites. The
+// shared steps (e.g. the network and proxy) must be complete before calling.
+func reconcileSites(ctx context.Context, sites []config.Site, limit int, output terminal.Outputer, check func(context.Context, config.Site, terminal.Outputer) (string, error), done func(siteResult)) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	sem := make(chan struct{}, limit)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, site := range sites {
+		wg.Add(1)
+		go func(site config.Site) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			buf := terminal.NewBuffer(output)
+			id, err := check(ctx, site, buf)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			buf.Flush()
+			done(siteResult{hostname: site.Hostname, id: id, err: err})
+		}(site)
+	}
+
+	wg.Wait()
+}
+
+// sitesToApply returns the enabled and disabled sites to check. When the hostname
+// is not empty only that site is returned, which is used to apply changes to a
+// single site (e.g. changing the PHP version) without checking the other sites.
+func sitesToApply(cfg *config.Config, hostname string) ([]config.Site, []config.Site, error) {
+	var enabled, disabled []config.Site
+	for _, s := range cfg.Sites {
+		if hostname != "" && s.Hostname != hostname {
+			continue
+		}
+
+		if s.Disabled {
+			disabled = append(disabled, s)
+			continue
+		}
+
+		enabled = append(enabled, s)
+	}
+
+	if hostname != "" && len(enabled)+len(disabled) == 0 {
+		return nil, nil, fmt.Errorf("unable to find the site %s in the config", hostname)
+	}
+
+	return enabled, disabled, nil
+}
@@ -0,0 +1,249 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/craftcms/nitro/command/apply/internal/sitecontainer"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// lineOutputer records each line of output, it is safe to use concurrently
+// so tests can check the order of the output.
+type lineOutputer struct {
+	terminal.Outputer
+
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *lineOutputer) add(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lines = append(l.lines, line)
+}
+
+func (l *lineOutputer) Info(s ...string) {
+	l.add(strings.Join(s, " "))
+}
+
+func (l *lineOutputer) Success(s ...string) {
+	l.add("✓ " + strings.Join(s, " "))
+}
+
+func (l *lineOutputer) Pending(s ...string) {
+	l.add("… " + strings.Join(s, " "))
+}
+
+func (l *lineOutputer) Done() {
+	l.add("done")
+}
+
+func (l *lineOutputer) Warning() {
+	l.add("warning")
+}
+
+func Test_reconcileSites(t *testing.T) {
+	// Arrange
+	var sites []config.Site
+	for i := 0; i < 20; i++ {
+		sites = append(sites, config.Site{Hostname: fmt.Sprintf("site%d.nitro", i)})
+	}
+
+	limit := 3
+	var running, max int32
+
+	// Act
+	// done is not safe for concurrent use, so the race detector will catch concurrent calls
+	var results []siteResult
+	reconcileSites(context.Background(), sites, limit, mockOutputer{}, func(ctx context.Context, site config.Site, output terminal.Outputer) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+
+		if site.Hostname == "site5.nitro" {
+			return "", fmt.Errorf("unable to create the container")
+		}
+
+		return "id-" + site.Hostname, nil
+	}, func(r siteResult) {
+		results = append(results, r)
+	})
+
+	// Assert
+	if len(results) != len(sites) {
+		t.Fatalf("expected %d results, got %d", len(sites), len(results))
+	}
+
+	if max > int32(limit) {
+		t.Errorf("expected no more than %d sites at a time, got %d", limit, max)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].hostname < results[j].hostname })
+
+	var failed int
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			continue
+		}
+
+		if r.id != "id-"+r.hostname {
+			t.Errorf("expected the id for %s to be id-%s, got %s", r.hostname, r.hostname, r.id)
+		}
+	}
+
+	if failed != 1 {
+		t.Errorf("expected 1 failed site, got %d", failed)
+	}
+}
+
+func Test_reconcileSites_GroupsTheOutputForEachSite(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+	home := t.TempDir()
+
+	var sites []config.Site
+	for i := 0; i < 8; i++ {
+		sites = append(sites, config.Site{
+			Hostname:   fmt.Sprintf("site%d.nitro", i),
+			Path:       t.TempDir(),
+			Webroot:    "web",
+			Version:    "7.4",
+			Extensions: []string{"intl", "soap"},
+		})
+	}
+
+	cfg := &config.Config{Sites: sites}
+
+	// create the containers so each site is updated, which shows the most output
+	for _, site := range sites {
+		if _, err := sitecontainer.StartOrCreate(ctx, docker, home, "network", site, cfg, sitecontainer.Options{}, &lineOutputer{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := &lineOutputer{}
+	opts := sitecontainer.Options{Recreate: true}
+
+	// Act
+	reconcileSites(ctx, sites, 4, output, func(ctx context.Context, site config.Site, output terminal.Outputer) (string, error) {
+		return sitecontainer.StartOrCreate(ctx, docker, home, "network", site, cfg, opts, output)
+	}, func(r siteResult) {
+		if r.err != nil {
+			output.Info("  \u2717", r.hostname, r.err.Error())
+			return
+		}
+
+		output.Success(r.hostname, "ready")
+	})
+
+	// Assert
+	perSite := []string{
+		"  - updating %s…",
+		"… installing intl",
+		"done",
+		"… installing soap",
+		"done",
+		"✓ %s ready",
+	}
+	if len(output.lines) != len(sites)*len(perSite) {
+		t.Fatalf("expected %d lines, got %d: %q", len(sites)*len(perSite), len(output.lines), output.lines)
+	}
+
+	for i := 0; i < len(output.lines); i += len(perSite) {
+		hostname := strings.TrimSuffix(strings.TrimPrefix(output.lines[i], "  - updating "), "…")
+
+		for j, format := range perSite {
+			want := format
+			if strings.Contains(format, "%s") {
+				want = fmt.Sprintf(format, hostname)
+			}
+
+			if got := output.lines[i+j]; got != want {
+				t.Fatalf("expected the output for %s to be grouped, got %q at line %d, want %q: %q", hostname, got, i+j, want, output.lines)
+			}
+		}
+	}
+}
+
+func Test_sitesToApply(t *testing.T) {
+	cfg := &config.Config{
+		Sites: []config.Site{
+			{Hostname: "one.nitro"},
+			{Hostname: "two.nitro"},
+			{Hostname: "three.nitro", Disabled: true},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		hostname     string
+		wantEnabled  []string
+		wantDisabled []string
+		wantErr      bool
+	}{
+		{
+			name:         "no hostname returns every site",
+			wantEnabled:  []string{"one.nitro", "two.nitro"},
+			wantDisabled: []string{"three.nitro"},
+		},
+		{
+			name:        "hostname returns only that site",
+			hostname:    "two.nitro",
+			wantEnabled: []string{"two.nitro"},
+		},
+		{
+			name:         "disabled sites can be applied",
+			hostname:     "three.nitro",
+			wantDisabled: []string{"three.nitro"},
+		},
+		{
+			name:     "unknown sites return an error",
+			hostname: "unknown.nitro",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enabled, disabled, err := sitesToApply(cfg, tt.hostname)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sitesToApply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			hostnames := func(sites []config.Site) []string {
+				var h []string
+				for _, s := range sites {
+					h = append(h, s.Hostname)
+				}
+				return h
+			}
+
+			if got := hostnames(enabled); fmt.Sprint(got) != fmt.Sprint(tt.wantEnabled) {
+				t.Errorf("sitesToApply() enabled = %v, want %v", got, tt.wantEnabled)
+			}
+
+			if got := hostnames(disabled); fmt.Sprint(got) != fmt.Sprint(tt.wantDisabled) {
+				t.Errorf("sitesToApply() disabled = %v, want %v", got, tt.wantDisabled)
+			}
+		})
+	}
+}
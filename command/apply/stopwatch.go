@@ -0,0 +1,86 @@
+package apply
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// phase is the name and duration of a phase of apply
+type phase struct {
+	name     string
+	duration time.Duration
+}
+
+// stopwatch records how long each phase of apply takes. Starting a phase
+// will stop the current phase.
+type stopwatch struct {
+	phases  []phase
+	current string
+	start   time.Time
+	now     func() time.Time
+}
+
+func newStopwatch() *stopwatch {
+	return &stopwatch{now: time.Now}
+}
+
+// Start stops the current phase and starts timing the named phase.
+func (s *stopwatch) Start(name string) {
+	s.Stop()
+
+	s.current = name
+	s.start = s.now()
+}
+
+// Stop stops timing the current phase, if a phase was already timed the
+// duration is added to the existing phase.
+func (s *stopwatch) Stop() {
+	if s.current == "" {
+		return
+	}
+
+	d := s.now().Sub(s.start)
+	name := s.current
+	s.current = ""
+
+	for i, p := range s.phases {
+		if p.name == name {
+			s.phases[i].duration += d
+			return
+		}
+	}
+
+	s.phases = append(s.phases, phase{name: name, duration: d})
+}
+
+// Summary stops the current phase and returns a line for each phase with
+// the duration and the total.
+func (s *stopwatch) Summary() []string {
+	s.Stop()
+
+	pad := len("total")
+	for _, p := range s.phases {
+		if len(p.name) > pad {
+			pad = len(p.name)
+		}
+	}
+
+	var lines []string
+	var total time.Duration
+	for _, p := range s.phases {
+		total += p.duration
+		lines = append(lines, fmt.Sprintf("  %s%s  %s", p.name, strings.Repeat(" ", pad-len(p.name)), round(p.duration)))
+	}
+
+	return append(lines, fmt.Sprintf("  %s%s  %s", "total", strings.Repeat(" ", pad-len("total")), round(total)))
+}
+
+// round rounds the duration so short phases are still readable.
+func round(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+
+	return d.Round(100 * time.Millisecond)
+}
@@ -0,0 +1,32 @@
+package apply
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_stopwatch(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &stopwatch{now: func() time.Time { return now }}
+
+	s.Start("network")
+	now = now.Add(250 * time.Millisecond)
+
+	s.Start("sites")
+	now = now.Add(3 * time.Second)
+
+	// phases that run again are added to the existing phase
+	s.Start("network")
+	now = now.Add(250 * time.Millisecond)
+
+	want := []string{
+		"  network  500ms",
+		"  sites    3s",
+		"  total    3.5s",
+	}
+
+	if got := s.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
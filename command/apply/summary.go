@@ -0,0 +1,108 @@
+package apply
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/svc/dynamodb"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
+	"github.com/craftcms/nitro/pkg/svc/mailhog"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
+	"github.com/craftcms/nitro/pkg/svc/minio"
+	"github.com/craftcms/nitro/pkg/svc/redis"
+)
+
+// summary returns the lines shown after apply with the URLs for the sites,
+// the service UIs, and the connection details for the databases and services
+// from the host and from the sites. The site URLs are skipped when the proxy
+// is not used.
+func summary(cfg *config.Config, noProxy bool) []string {
+	var lines []string
+
+	if sites := cfg.EnabledSites(); len(sites) > 0 && !noProxy {
+		_, httpsPort := proxycontainer.HostPorts(cfg.Proxy)
+
+		lines = append(lines, "Sites:")
+		for _, s := range sites {
+			lines = append(lines, fmt.Sprintf("  %s: %s", s.Hostname, siteURL(s.Hostname, httpsPort)))
+		}
+	}
+
+	if len(cfg.Databases) > 0 {
+		lines = append(lines, "Databases (user nitro, password nitro):")
+		for _, db := range cfg.Databases {
+			hostname, err := db.GetHostname()
+			if err != nil {
+				continue
+			}
+
+			scheme, port := "mysql", "3306"
+			if containerlabels.Compatibility(db.Engine) == "postgres" {
+				scheme, port = "postgres", "5432"
+			}
+
+			host := hostAddress(cfg.GetDatabaseBindAddress(db))
+			lines = append(lines, fmt.Sprintf("  %s %s: %s://nitro:nitro@%s/nitro (sites use %s:%s)", db.Engine, db.Version, scheme, net.JoinHostPort(host, db.Port), hostname, port))
+		}
+	}
+
+	var services []string
+	if cfg.Services.DynamoDB {
+		services = append(services, fmt.Sprintf("  dynamodb: http://%s (sites use http://%s:8000)", serviceAddress(cfg, dynamodb.Label, "NITRO_DYNAMODB_PORT"), dynamodb.Host))
+	}
+
+	if cfg.Services.Elasticsearch {
+		services = append(services, fmt.Sprintf("  elasticsearch: http://%s (sites use http://%s:%s)", serviceAddress(cfg, elasticsearch.Label, "NITRO_ELASTICSEARCH_PORT"), elasticsearch.Host, elasticsearch.Port))
+	}
+
+	if cfg.Services.Mailhog {
+		services = append(services, fmt.Sprintf("  mailhog: http://%s (sites send mail to %s:1025)", serviceAddress(cfg, mailhog.Label, "NITRO_MAILHOG_HTTP_PORT"), mailhog.Host))
+	}
+
+	if cfg.Services.Meilisearch {
+		services = append(services, fmt.Sprintf("  meilisearch: http://%s (sites use http://%s:%s)", serviceAddress(cfg, meilisearch.Label, "NITRO_MEILISEARCH_PORT"), meilisearch.Host, meilisearch.Port))
+	}
+
+	if cfg.Services.Minio {
+		services = append(services, fmt.Sprintf("  minio: http://%s (user nitro, password nitropassword, sites use http://%s:9000)", serviceAddress(cfg, minio.Label, "NITRO_MINIO_PORT"), minio.Host))
+	}
+
+	if cfg.Services.Redis {
+		services = append(services, fmt.Sprintf("  redis: %s (sites use %s:6379)", serviceAddress(cfg, redis.Label, "NITRO_REDIS_PORT"), redis.Host))
+	}
+
+	if len(services) > 0 {
+		lines = append(lines, "Services:")
+		lines = append(lines, services...)
+	}
+
+	return lines
+}
+
+// siteURL returns the HTTPS URL for a site, the port is only added when the
+// proxy does not use the default HTTPS port.
+func siteURL(hostname, httpsPort string) string {
+	if httpsPort == "443" {
+		return "https://" + hostname
+	}
+
+	return "https://" + net.JoinHostPort(hostname, httpsPort)
+}
+
+// serviceAddress returns the host address and port for a service.
+func serviceAddress(cfg *config.Config, name, env string) string {
+	return net.JoinHostPort(hostAddress(cfg.GetServiceBindAddress(name)), config.ServicePort(env))
+}
+
+// hostAddress returns the address to connect to from the host, ports bound to
+// every address (e.g. 0.0.0.0) are reachable on the loopback address.
+func hostAddress(addr string) string {
+	if ip := net.ParseIP(addr); ip == nil || ip.IsUnspecified() {
+		return config.DefaultBindAddress
+	}
+
+	return addr
+}
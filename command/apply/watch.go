@@ -0,0 +1,140 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var (
+	// watching is used to prevent the post run from starting another watcher
+	watching = false
+
+	// debounceWait is how long to wait after the last change to the config
+	// file before applying, editors will often write the file multiple times
+	debounceWait = 500 * time.Millisecond
+)
+
+// watch will watch the config file for changes and re-run the apply command
+// each time the file is saved. Errors from applying are reported and the
+// watcher will wait for the next change. It stops when the context is canceled
+// (e.g. ctrl+c).
+func watch(cmd *cobra.Command, args []string, home string, output terminal.Outputer) error {
+	watching = true
+	defer func() { watching = false }()
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cfg, err := config.Load(home)
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Clean(cfg.GetFile())
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("unable to create the file watcher, %w", err)
+	}
+	defer watcher.Close()
+
+	// watch the directory since most editors replace the file when saving
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		return fmt.Errorf("unable to watch %s, %w", file, err)
+	}
+
+	output.Info("Watching", file, "for changes (press ctrl+c to stop)…")
+
+	changes := debounce(ctx, watcher.Events, file, debounceWait)
+
+	for {
+		select {
+		case <-ctx.Done():
+			output.Info("Stopped watching for changes")
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			output.Info("Unable to watch for changes,", err.Error())
+		case _, ok := <-changes:
+			if !ok {
+				return nil
+			}
+
+			output.Info("Config changed, applying…")
+
+			if err := reapply(cmd, args, home); err != nil {
+				output.Info("Unable to apply changes,", err.Error())
+			}
+
+			output.Info("Watching", file, "for changes (press ctrl+c to stop)…")
+		}
+	}
+}
+
+// reapply validates the config file and runs the apply command again.
+func reapply(cmd *cobra.Command, args []string, home string) error {
+	// make sure the config is valid before making changes
+	if _, err := config.Load(home); err != nil {
+		return fmt.Errorf("the config file is not valid, %w", err)
+	}
+
+	if err := cmd.RunE(cmd, args); err != nil {
+		return err
+	}
+
+	return cmd.PostRunE(cmd, args)
+}
+
+// debounce takes the events from the watcher and the name of the file and
+// returns a channel that receives once the file has stopped changing for
+// the wait duration.
+func debounce(ctx context.Context, events <-chan fsnotify.Event, name string, wait time.Duration) <-chan struct{} {
+	changes := make(chan struct{})
+
+	go func() {
+		defer close(changes)
+
+		var timer <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+
+				// ignore other files in the directory and chmod events
+				if filepath.Clean(e.Name) != name || e.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+
+				// reset the timer on each change
+				timer = time.After(wait)
+			case <-timer:
+				timer = nil
+
+				select {
+				case changes <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return changes
+}
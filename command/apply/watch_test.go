@@ -0,0 +1,50 @@
+package apply
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func Test_debounce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan fsnotify.Event)
+	changes := debounce(ctx, events, "/home/nitro/.nitro/nitro-dev.yaml", 50*time.Millisecond)
+
+	// rapid saves and unrelated files should only trigger a single change
+	events <- fsnotify.Event{Name: "/home/nitro/.nitro/nitro-dev.yaml", Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: "/home/nitro/.nitro/.nitro-dev.yaml.lock", Op: fsnotify.Create}
+	events <- fsnotify.Event{Name: "/home/nitro/.nitro/nitro-dev.yaml", Op: fsnotify.Chmod}
+	events <- fsnotify.Event{Name: "/home/nitro/.nitro/nitro-dev.yaml", Op: fsnotify.Create}
+
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatal("expected a change after the file was saved")
+	}
+
+	select {
+	case <-changes:
+		t.Error("expected only a single change for rapid saves")
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	// unrelated files should not trigger a change
+	events <- fsnotify.Event{Name: "/home/nitro/.nitro/other.yaml", Op: fsnotify.Write}
+
+	select {
+	case <-changes:
+		t.Error("expected no change for other files")
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	cancel()
+
+	if _, ok := <-changes; ok {
+		t.Error("expected the changes channel to be closed when the context is canceled")
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"strings"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	volumetypes "github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
@@ -18,6 +20,7 @@ import (
 
 	"github.com/craftcms/nitro/pkg/composer"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/packagecache"
 	"github.com/craftcms/nitro/pkg/pathexists"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/craftcms/nitro/pkg/volumename"
@@ -32,7 +35,10 @@ const exampleText = `  # run composer install in a current directory using a con
   nitro composer install
 
   # use composer (without local installation) to create a new project
-  nitro composer create-project craftcms/craft my-project`
+  nitro composer create-project craftcms/craft my-project
+
+  # clear the composer cache the sites share
+  nitro composer clear-cache`
 
 // NewCommand returns a new command that runs composer install or update for a directory.
 // This command allows users to skip installing composer on the host machine and will run
@@ -70,8 +76,8 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 
 			// determine the default action
 			action := args[0]
-			// if this is not a create project request, check for a composer.json
-			if action != "create-project" {
+			// if this is not a create project or clear cache request, check for a composer.json
+			if action != "create-project" && !isClearCache(action) {
 				// get the full file path
 				composerPath := filepath.Join(path, "composer.json")
 
@@ -114,6 +120,11 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 			// remove the image ref filter
 			filter.Del("reference", image)
 
+			// clear the cache the sites share instead of the cache for the path
+			if isClearCache(action) {
+				return clearCache(ctx, docker, image, output)
+			}
+
 			// find the network
 			networkFilter := filters.NewArgs()
 			networkFilter.Add("name", "nitro-network")
@@ -203,13 +214,32 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 			}
 			defer stream.Close()
 
+			// detach from the container when the command is interrupted
+			done := make(chan struct{})
+			defer close(done)
+			go func() {
+				select {
+				case <-ctx.Done():
+					stream.Close()
+				case <-done:
+				}
+			}()
+
 			// run the container
 			if err := docker.ContainerStart(ctx, container.ID, types.ContainerStartOptions{}); err != nil {
 				return fmt.Errorf("unable to start the container, %w", err)
 			}
 
 			// show the output to stdout and stderr
-			if _, err := stdcopy.StdCopy(os.Stdout, os.Stderr, stream.Reader); err != nil {
+			_, err = stdcopy.StdCopy(os.Stdout, os.Stderr, stream.Reader)
+
+			// if the command was interrupted, the context is done so use a new one to remove the container
+			if ctx.Err() != nil {
+				output.Info("composer", action, "interrupted, removing container…")
+
+				return docker.ContainerRemove(context.Background(), container.ID, types.ContainerRemoveOptions{Force: true})
+			}
+			if err != nil {
 				return fmt.Errorf("unable to copy the output of the container logs, %w", err)
 			}
 
@@ -230,6 +260,69 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 	return cmd
 }
 
+// isClearCache returns true if the action is the composer clear-cache command or one of its aliases.
+func isClearCache(action string) bool {
+	switch action {
+	case "clear-cache", "clearcache", "cc":
+		return true
+	}
+
+	return false
+}
+
+// clearCache removes the contents of the composer cache in the package cache
+// volume. The directory is kept, composer would recreate it as root and the
+// sites would not be able to write to it.
+func clearCache(ctx context.Context, docker client.CommonAPIClient, image string, output terminal.Outputer) error {
+	resp, err := docker.ContainerCreate(
+		ctx,
+		&container.Config{
+			Image:      image,
+			Entrypoint: packagecache.ClearCommand(packagecache.ComposerDir),
+			Labels: map[string]string{
+				containerlabels.Nitro: "true",
+				containerlabels.Type:  "composer",
+			},
+		},
+		&container.HostConfig{Mounts: []mount.Mount{packagecache.Mount()}},
+		nil,
+		nil,
+		"",
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create the composer container, %w", err)
+	}
+
+	// attach to the container
+	stream, err := docker.ContainerAttach(ctx, resp.ID, types.ContainerAttachOptions{
+		Stream: true,
+		Stdout: true,
+		Stderr: true,
+		Logs:   true,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to attach to container, %w", err)
+	}
+	defer stream.Close()
+
+	if err := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("unable to start the container, %w", err)
+	}
+
+	// wait for the files to be removed
+	if _, err := stdcopy.StdCopy(os.Stdout, os.Stderr, stream.Reader); err != nil {
+		return fmt.Errorf("unable to copy the output of the container logs, %w", err)
+	}
+
+	if err := docker.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+		return err
+	}
+
+	output.Info("composer cache cleared 🧹")
+
+	return nil
+}
+
 func versionFromArgs(args []string) (string, []string) {
 	var version string
 	var newArgs []string
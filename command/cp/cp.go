@@ -0,0 +1,213 @@
+package cp
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var (
+	// ErrNoSite is returned when neither path references a site container
+	ErrNoSite = fmt.Errorf("one of the paths must be a site (e.g. mysite.nitro:/app/file)")
+
+	// ErrBothSites is returned when both paths reference a site container
+	ErrBothSites = fmt.Errorf("copying between sites is not supported")
+)
+
+const exampleText = `  # copy a file into a site container
+  nitro cp ./backup.sql mysite.nitro:/app/backup.sql
+
+  # copy a file out of a site container
+  nitro cp mysite.nitro:/app/storage/logs/web.log ./web.log
+
+  # copy a directory into a site, relative container paths start at /app
+  nitro cp ./templates mysite.nitro:templates`
+
+// NewCommand returns the command to copy files and directories to and from a site container.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "cp SRC DEST",
+		Short:   "Copy files to or from a site",
+		Example: exampleText,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// load the config
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			srcSite, srcPath := splitPath(cfg, args[0])
+			dstSite, dstPath := splitPath(cfg, args[1])
+
+			switch {
+			case srcSite == "" && dstSite == "":
+				return ErrNoSite
+			case srcSite != "" && dstSite != "":
+				return ErrBothSites
+			}
+
+			// find the site container
+			hostname := srcSite
+			if hostname == "" {
+				hostname = dstSite
+			}
+
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Host+"="+hostname)
+
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+			if err != nil {
+				return fmt.Errorf("unable to list the containers, %w", err)
+			}
+
+			if len(containers) == 0 {
+				return fmt.Errorf("unable to find the container for %s, run `nitro apply` to create it", hostname)
+			}
+
+			id := containers[0].ID
+
+			// copy out of the container
+			if srcSite != "" {
+				output.Pending("copying", args[0], "to", dstPath)
+
+				if err := copyFromContainer(cmd, docker, id, srcPath, dstPath); err != nil {
+					output.Warning()
+					return err
+				}
+
+				output.Done()
+
+				return nil
+			}
+
+			output.Pending("copying", srcPath, "to", args[1])
+
+			if err := copyToContainer(cmd, docker, id, srcPath, dstPath); err != nil {
+				output.Warning()
+				return err
+			}
+
+			output.Done()
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// splitPath takes an argument and returns the site hostname and the path. If the
+// argument does not start with the hostname of a site in the config, the site
+// will be empty and the argument is treated as a local path. Relative paths in
+// the container are relative to /app.
+func splitPath(cfg *config.Config, arg string) (string, string) {
+	i := strings.Index(arg, ":")
+	if i <= 0 {
+		return "", arg
+	}
+
+	hostname := arg[:i]
+
+	var found bool
+	for _, s := range cfg.Sites {
+		if s.Hostname == hostname {
+			found = true
+			break
+		}
+	}
+
+	// this is a local path (e.g. C:\Users\nitro)
+	if !found {
+		return "", arg
+	}
+
+	p := arg[i+1:]
+	if !path.IsAbs(p) {
+		p = path.Join("/app", p)
+	}
+
+	return hostname, p
+}
+
+// copyToContainer streams the local file or directory as a tar archive into the container.
+func copyToContainer(cmd *cobra.Command, docker client.CommonAPIClient, id, src, dst string) error {
+	ctx := cmd.Context()
+
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+
+	// get the information about the source
+	srcInfo, err := archive.CopyInfoSourcePath(abs, false)
+	if err != nil {
+		return fmt.Errorf("unable to find %s, %w", src, err)
+	}
+
+	srcArchive, err := archive.TarResource(srcInfo)
+	if err != nil {
+		return fmt.Errorf("unable to create the archive for %s, %w", src, err)
+	}
+	defer srcArchive.Close()
+
+	// get the information about the destination, it may not exist yet
+	dstInfo := archive.CopyInfo{Path: dst}
+	if stat, err := docker.ContainerStatPath(ctx, id, dst); err == nil {
+		dstInfo.Exists = true
+		dstInfo.IsDir = stat.Mode.IsDir()
+	}
+
+	// rename the archive contents to match the destination
+	dstDir, content, err := archive.PrepareArchiveCopy(srcArchive, srcInfo, dstInfo)
+	if err != nil {
+		return fmt.Errorf("unable to prepare the archive, %w", err)
+	}
+	defer content.Close()
+
+	if err := docker.CopyToContainer(ctx, id, dstDir, content, types.CopyToContainerOptions{AllowOverwriteDirWithFile: false}); err != nil {
+		return fmt.Errorf("unable to copy %s to the container, %w", src, err)
+	}
+
+	return nil
+}
+
+// copyFromContainer streams the file or directory from the container and extracts it to the local destination.
+func copyFromContainer(cmd *cobra.Command, docker client.CommonAPIClient, id, src, dst string) error {
+	content, stat, err := docker.CopyFromContainer(cmd.Context(), id, src)
+	if err != nil {
+		return fmt.Errorf("unable to copy %s from the container, %w", src, err)
+	}
+	defer content.Close()
+
+	abs, err := filepath.Abs(dst)
+	if err != nil {
+		return err
+	}
+
+	srcInfo := archive.CopyInfo{
+		Path:   src,
+		Exists: true,
+		IsDir:  stat.Mode.IsDir(),
+	}
+
+	// the archive is extracted using the mode of each file
+	if err := archive.CopyTo(content, srcInfo, abs); err != nil {
+		return fmt.Errorf("unable to copy to %s, %w", dst, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package cp
+
+import (
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func Test_splitPath(t *testing.T) {
+	cfg := &config.Config{
+		Sites: []config.Site{{Hostname: "mysite.nitro"}},
+	}
+
+	tests := []struct {
+		name     string
+		arg      string
+		wantSite string
+		wantPath string
+	}{
+		{
+			name:     "absolute container paths are returned",
+			arg:      "mysite.nitro:/app/storage/logs/web.log",
+			wantSite: "mysite.nitro",
+			wantPath: "/app/storage/logs/web.log",
+		},
+		{
+			name:     "relative container paths start at the app directory",
+			arg:      "mysite.nitro:templates",
+			wantSite: "mysite.nitro",
+			wantPath: "/app/templates",
+		},
+		{
+			name:     "local paths do not return a site",
+			arg:      "./backup.sql",
+			wantPath: "./backup.sql",
+		},
+		{
+			name:     "windows paths do not return a site",
+			arg:      `C:\Users\nitro\backup.sql`,
+			wantPath: `C:\Users\nitro\backup.sql`,
+		},
+		{
+			name:     "unknown sites are treated as local paths",
+			arg:      "unknown.nitro:/app",
+			wantPath: "unknown.nitro:/app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site, path := splitPath(cfg, tt.arg)
+			if site != tt.wantSite {
+				t.Errorf("splitPath() site = %v, want %v", site, tt.wantSite)
+			}
+			if path != tt.wantPath {
+				t.Errorf("splitPath() path = %v, want %v", path, tt.wantPath)
+			}
+		})
+	}
+}
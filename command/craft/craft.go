@@ -1,19 +1,14 @@
 package craft
 
 import (
-	"context"
 	"fmt"
-	"os"
-	"os/exec"
+	"path"
 
-	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
-	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/spf13/cobra"
 
 	"github.com/craftcms/nitro/pkg/config"
-	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/siteexec"
 	"github.com/craftcms/nitro/pkg/terminal"
 )
 
@@ -23,6 +18,9 @@ const exampleText = `  # run craft console command in a sites container
   # view craft console help command
   nitro craft
 
+  # run a craft command for a specific site
+  nitro craft --hostname mysite.nitro migrate/all
+
   # enter the craft shell
   nitro craft shell`
 
@@ -34,11 +32,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 		Short:   "Run Craft console commands",
 		Example: exampleText,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// get the current working directory
-			wd, err := os.Getwd()
-			if err != nil {
-				return err
-			}
+			ctx := cmd.Context()
 
 			// load the config
 			cfg, err := config.Load(home)
@@ -46,164 +40,51 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				return err
 			}
 
-			// create a filter for the environment
-			filter := filters.NewArgs()
-			filter.Add("label", containerlabels.Nitro)
-
-			// get a context aware list of sites
-			sites := cfg.ListOfSitesByDirectory(home, wd)
-
-			// create the options for the sites
-			var options []string
-			for _, s := range sites {
-				options = append(options, s.Hostname)
-			}
-
-			var site config.Site
-			switch len(sites) {
-			case 0:
-				// prompt for the site
-				selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
-				if err != nil {
-					return err
-				}
-
-				// set the site we selected
-				site = sites[selected]
-
-				// add the label to get the site
-				filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
-			case 1:
-				output.Info("connecting to", sites[0].Hostname)
-
-				// set the site we selected
-				site = sites[0]
-
-				// add the label to get the site
-				filter.Add("label", containerlabels.Host+"="+sites[0].Hostname)
-			default:
-				// prompt for the site to ssh into
-				selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
-				if err != nil {
-					return err
-				}
-
-				// set the site we selected
-				site = sites[selected]
-
-				// add the label to get the site
-				filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
-			}
+			hostname, _ := cmd.Flags().GetString("hostname")
 
-			// find the containers but limited to the site label
-			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter, All: true})
+			site, err := siteexec.FindSite(cmd, home, hostname, cfg, output)
 			if err != nil {
 				return err
 			}
 
-			// are there any containers??
-			if len(containers) == 0 {
-				return fmt.Errorf("unable to find an matching site")
+			id, err := siteexec.Container(ctx, docker, site.Hostname)
+			if err != nil {
+				return err
 			}
 
-			// start the container if its not running
-			if containers[0].State != "running" {
-				for _, command := range cmd.Root().Commands() {
-					if command.Use == "start" {
-						if err := command.RunE(cmd, []string{}); err != nil {
-							return err
-						}
-					}
-				}
+			// make sure this is a craft project
+			wd := siteexec.WorkingDir(site)
+			if _, err := docker.ContainerStatPath(ctx, id, path.Join(wd, "craft")); err != nil {
+				return fmt.Errorf("unable to find the craft executable in %s for %s, is this a Craft project?", wd, site.Hostname)
 			}
 
-			// create the command for running the craft console
-			cmds := []string{"exec", "-it", containers[0].ID, "php"}
-
-			// get the container path
-			path := site.GetContainerPath()
-			if path != "" {
-				cmds = append(cmds, fmt.Sprintf("%s/%s", path, "craft"))
-			} else {
-				cmds = append(cmds, "craft")
+			// no args were provided, use the help command
+			if len(args) == 0 {
+				args = []string{"help"}
 			}
 
-			switch len(args) == 0 {
-			case true:
-				// no args were provided, use the help command
-				cmds = append(cmds, "help")
-			default:
-				// append the provided args to the command
-				cmds = append(cmds, args...)
+			opts := siteexec.Options{
+				Cmd:        append([]string{"php", "craft"}, args...),
+				WorkingDir: wd,
 			}
 
-			// find the docker executable
-			cli, err := exec.LookPath("docker")
+			code, err := siteexec.Run(ctx, docker, id, opts, cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
 			if err != nil {
 				return err
 			}
 
-			// create the command
-			c := exec.Command(cli, cmds...)
-
-			c.Stdin = cmd.InOrStdin()
-			c.Stderr = cmd.ErrOrStderr()
-			c.Stdout = cmd.OutOrStdout()
-
-			if err := c.Run(); err != nil {
-				return err
+			if code != 0 {
+				return fmt.Errorf("craft exited with code %d", code)
 			}
 
-			// attach
-
 			return nil
 		},
 	}
 
-	return cmd
-}
+	cmd.Flags().String("hostname", "", "hostname of the site to run the command in")
 
-func execCreate(ctx context.Context, docker client.ContainerAPIClient, containerID string, cmds []string, show bool) (bool, error) {
-	// create the exec
-	e, err := docker.ContainerExecCreate(ctx, containerID, types.ExecConfig{
-		AttachStdout: true,
-		AttachStderr: true,
-		Tty:          false,
-		Cmd:          cmds,
-	})
-	if err != nil {
-		return false, err
-	}
-
-	// attach to the container
-	resp, err := docker.ContainerExecAttach(ctx, e.ID, types.ExecStartCheck{
-		Tty: false,
-	})
-	defer resp.Close()
-
-	// should we display output?
-	if show {
-		// show the output to stdout and stderr
-		if _, err := stdcopy.StdCopy(os.Stdout, os.Stderr, resp.Reader); err != nil {
-			return false, fmt.Errorf("unable to copy the output of container, %w", err)
-		}
-	}
+	// pass all flags after the command to craft (e.g. nitro craft migrate/all --interactive=0)
+	cmd.Flags().SetInterspersed(false)
 
-	// start the exec
-	if err := docker.ContainerExecStart(ctx, e.ID, types.ExecStartCheck{}); err != nil {
-		return false, fmt.Errorf("unable to start the container, %w", err)
-	}
-
-	// wait for the container exec to complete
-	waiting := true
-	for waiting {
-		resp, err := docker.ContainerExecInspect(ctx, e.ID)
-		if err != nil {
-			return false, err
-		}
-
-		waiting = resp.Running
-	}
-
-	return true, nil
+	return cmd
 }
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
@@ -13,7 +14,9 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	nitroclient "github.com/craftcms/nitro/client"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/craftcms/nitro/pkg/validate"
 	"github.com/craftcms/nitro/protob"
@@ -68,40 +71,21 @@ func addCommand(docker client.CommonAPIClient, nitrod protob.NitroClient, output
 				return err
 			}
 
+			// the api runs in the proxy container, which is not created by apply --no-proxy
+			if _, err := proxycontainer.FindAndStart(cmd.Context(), docker); err != nil {
+				return err
+			}
+
 			output.Pending("creating database", db)
 
 			// wait for the api to be ready
-			for {
-				_, err := nitrod.Ping(cmd.Context(), &protob.PingRequest{})
-				if err == nil {
-					break
-				}
-			}
-
-			// get the containers details
-			engine := info.Config.Labels[containerlabels.DatabaseCompatibility]
-			hostname := strings.TrimLeft(info.Name, "/")
-			version := info.Config.Labels[containerlabels.DatabaseVersion]
-			var port string
-			// get the port from the container info
-			for p, bind := range info.HostConfig.PortBindings {
-				for _, v := range bind {
-					if v.HostPort != "" {
-						port = p.Port()
-					}
-				}
+			if err := nitroclient.WaitForAPI(cmd.Context(), nitrod); err != nil {
+				output.Warning()
+				return err
 			}
 
 			// create the database
-			resp, err := nitrod.AddDatabase(cmd.Context(), &protob.AddDatabaseRequest{
-				Database: &protob.DatabaseInfo{
-					Engine:   engine,
-					Hostname: hostname,
-					Version:  version,
-					Port:     port,
-					Database: db,
-				},
-			})
+			resp, err := addDatabase(cmd.Context(), nitrod, info, db, "", "")
 			// check if the error code is unimplemented
 			if code := status.Code(err); code == codes.Unimplemented {
 				output.Warning()
@@ -142,3 +126,35 @@ func addCommand(docker client.CommonAPIClient, nitrod protob.NitroClient, output
 
 	return cmd
 }
+
+// addDatabase takes the details of a database container and the name of a
+// database and uses the API to create the database in the container. The
+// charset and collation are only used by mysql and can be empty.
+func addDatabase(ctx context.Context, nitrod protob.NitroClient, info types.ContainerJSON, db, charset, collation string) (*protob.AddDatabaseResponse, error) {
+	// get the containers details
+	engine := info.Config.Labels[containerlabels.DatabaseCompatibility]
+	hostname := strings.TrimLeft(info.Name, "/")
+	version := info.Config.Labels[containerlabels.DatabaseVersion]
+	var port string
+	// get the port from the container info
+	for p, bind := range info.HostConfig.PortBindings {
+		for _, v := range bind {
+			if v.HostPort != "" {
+				port = p.Port()
+			}
+		}
+	}
+
+	// create the database
+	return nitrod.AddDatabase(ctx, &protob.AddDatabaseRequest{
+		Database: &protob.DatabaseInfo{
+			Engine:    engine,
+			Hostname:  hostname,
+			Version:   version,
+			Port:      port,
+			Database:  db,
+			Charset:   charset,
+			Collation: collation,
+		},
+	})
+}
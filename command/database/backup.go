@@ -1,12 +1,13 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
@@ -16,14 +17,28 @@ import (
 	"github.com/craftcms/nitro/pkg/backup"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
-	"github.com/craftcms/nitro/pkg/datetime"
+	"github.com/craftcms/nitro/pkg/helpers"
 	"github.com/craftcms/nitro/pkg/terminal"
 )
 
 var backupExampleText = `  # backup a database
-  nitro db backup`
+  nitro db backup
 
-// backupCommand is the command for backing up an individual database or
+  # backup every database in every engine
+  nitro db backup --all`
+
+// backupConcurrency is the number of databases that are backed up at the same time
+var backupConcurrency = 4
+
+// backupResult is the result of backing up a single database
+type backupResult struct {
+	container string
+	database  string
+	file      string
+	err       error
+}
+
+// backupCommand is the command for backing up an individual database or all of the databases.
 func backupCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "backup",
@@ -48,6 +63,10 @@ func backupCommand(home string, docker client.CommonAPIClient, output terminal.O
 				return containers[i].Names[0] < containers[j].Names[0]
 			})
 
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				return backupAllDatabases(ctx, docker, home, containers, output)
+			}
+
 			// generate a list of engines for the prompt
 			var containerList []string
 			for _, c := range containers {
@@ -65,21 +84,7 @@ func backupCommand(home string, docker client.CommonAPIClient, output terminal.O
 			output.Info("Preparing backup…")
 
 			// create the options for the backup
-			opts := &backup.Options{
-				BackupName:    fmt.Sprintf("%s-%s.sql", db, datetime.Parse(time.Now())),
-				ContainerID:   containerID,
-				ContainerName: containerName,
-				Database:      db,
-				Home:          home,
-			}
-
-			// create the backup command based on the compatibility type
-			switch compatibility {
-			case "postgres":
-				opts.Commands = []string{"pg_dump", "--username=nitro", db, "-f", "/tmp/" + opts.BackupName}
-			default:
-				opts.Commands = []string{"mysqldump", "--user=nitro", "-pnitro", db, "--result-file=" + "/tmp/" + opts.BackupName}
-			}
+			opts := backup.NewOptions(home, containerID, containerName, compatibility, db)
 
 			output.Pending("creating backup", opts.BackupName)
 
@@ -98,5 +103,119 @@ func backupCommand(home string, docker client.CommonAPIClient, output terminal.O
 		},
 	}
 
+	cmd.Flags().Bool("all", false, "backup every database in every database engine")
+
 	return cmd
 }
+
+// backupAllDatabases backs up every database in each of the containers and shows a summary. A failure
+// to backup one database does not stop the others from being backed up.
+func backupAllDatabases(ctx context.Context, docker client.ContainerAPIClient, home string, containers []types.Container, output terminal.Outputer) error {
+	if len(containers) == 0 {
+		output.Info("There are no running database engines to backup")
+
+		return nil
+	}
+
+	output.Info("Getting ready to backup all databases…")
+
+	jobs, failures := backupJobs(ctx, docker, home, containers)
+	for _, f := range failures {
+		output.Info("  \u2717", f.container, f.err.Error())
+	}
+
+	var files []string
+	backupAll(ctx, docker, jobs, func(r backupResult) {
+		if r.err != nil {
+			failures = append(failures, r)
+			output.Info("  \u2717", r.container, r.database, r.err.Error())
+			return
+		}
+
+		files = append(files, r.file)
+		output.Success("backed up", r.database, "from", r.container)
+	})
+
+	sort.Strings(files)
+
+	output.Info(fmt.Sprintf("Saved %d backups in %s 💾", len(files), filepath.Join(home, config.DirectoryName, "backups")))
+	for _, f := range files {
+		output.Info("  " + f)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("unable to backup %d databases", len(failures))
+	}
+
+	return nil
+}
+
+// backupJobs returns the options to backup each database in the containers. Containers where the databases
+// cannot be listed are returned as failures.
+func backupJobs(ctx context.Context, docker client.ContainerAPIClient, home string, containers []types.Container) ([]*backup.Options, []backupResult) {
+	var jobs []*backup.Options
+	var failures []backupResult
+	for _, c := range containers {
+		name := strings.TrimLeft(c.Names[0], "/")
+		compatibility := c.Labels[containerlabels.DatabaseCompatibility]
+
+		databases, err := backup.Databases(ctx, docker, c.ID, compatibility)
+		if err != nil {
+			failures = append(failures, backupResult{container: name, err: fmt.Errorf("unable to get the databases, %w", err)})
+			continue
+		}
+
+		// create the backup directories before the backups run at the same time
+		dir := filepath.Join(home, config.DirectoryName, "backups")
+		if err := helpers.MkdirIfNotExists(dir); err != nil {
+			failures = append(failures, backupResult{container: name, err: err})
+			continue
+		}
+
+		if err := helpers.MkdirIfNotExists(filepath.Join(dir, name)); err != nil {
+			failures = append(failures, backupResult{container: name, err: err})
+			continue
+		}
+
+		for _, db := range databases {
+			jobs = append(jobs, backup.NewOptions(home, c.ID, name, compatibility, db))
+		}
+	}
+
+	return jobs, failures
+}
+
+// backupAll performs the backups using a limited number of workers and calls done with the result
+// of each backup, done is never called at the same time.
+func backupAll(ctx context.Context, docker client.ContainerAPIClient, jobs []*backup.Options, done func(backupResult)) {
+	sem := make(chan struct{}, backupConcurrency)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, opts := range jobs {
+		wg.Add(1)
+		go func(opts *backup.Options) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			r := backupResult{
+				container: opts.ContainerName,
+				database:  opts.Database,
+				file:      filepath.Join(opts.ContainerName, opts.BackupName),
+			}
+
+			if err := backup.Perform(ctx, docker, opts); err != nil {
+				r.err = err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			done(r)
+		}(opts)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+func databaseContainers() []types.Container {
+	return []types.Container{
+		{
+			ID:    "mysql",
+			Names: []string{"/mysql-8.0-3306.database.nitro"},
+			State: "running",
+			Labels: map[string]string{
+				containerlabels.Nitro:                 "true",
+				containerlabels.Type:                  "database",
+				containerlabels.DatabaseCompatibility: "mysql",
+			},
+		},
+		{
+			ID:    "mariadb",
+			Names: []string{"/mariadb-10.5-3307.database.nitro"},
+			State: "running",
+			Labels: map[string]string{
+				containerlabels.Nitro:                 "true",
+				containerlabels.Type:                  "database",
+				containerlabels.DatabaseCompatibility: "mysql",
+			},
+		},
+	}
+}
+
+func Test_backupAll(t *testing.T) {
+	// Arrange
+	home := t.TempDir()
+	if err := os.Mkdir(filepath.Join(home, config.DirectoryName), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	docker := dockertest.New(databaseContainers(), nil)
+	docker.ExecOutput = "Database\ninformation_schema\nnitro\nproject\n"
+	docker.CopyOutput = "-- backup"
+
+	// Act
+	jobs, failures := backupJobs(context.Background(), docker, home, docker.Containers)
+
+	var got []string
+	backupAll(context.Background(), docker, jobs, func(r backupResult) {
+		if r.err != nil {
+			t.Errorf("unexpected error backing up %s, %v", r.database, r.err)
+			return
+		}
+
+		got = append(got, r.container+"/"+r.database)
+
+		content, err := ioutil.ReadFile(filepath.Join(home, config.DirectoryName, "backups", r.file))
+		if err != nil {
+			t.Errorf("expected the backup file to exist, %v", err)
+			return
+		}
+
+		if string(content) != "-- backup" {
+			t.Errorf("expected the backup content to be %q, got %q", "-- backup", string(content))
+		}
+	})
+
+	// Assert
+	if len(failures) != 0 {
+		t.Errorf("expected no failures, got %v", failures)
+	}
+
+	sort.Strings(got)
+	want := []string{
+		"mariadb-10.5-3307.database.nitro/nitro",
+		"mariadb-10.5-3307.database.nitro/project",
+		"mysql-8.0-3306.database.nitro/nitro",
+		"mysql-8.0-3306.database.nitro/project",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("backupAll() = %v, want %v", got, want)
+	}
+}
+
+func Test_backupAll_Failures(t *testing.T) {
+	// Arrange
+	home := t.TempDir()
+	if err := os.Mkdir(filepath.Join(home, config.DirectoryName), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	docker := dockertest.New(databaseContainers(), nil)
+	docker.ExecOutput = "Database\nnitro\n"
+	docker.Errors["CopyFromContainer"] = fmt.Errorf("no such file")
+
+	// Act
+	jobs, _ := backupJobs(context.Background(), docker, home, docker.Containers)
+
+	var failed int
+	backupAll(context.Background(), docker, jobs, func(r backupResult) {
+		if r.err != nil {
+			failed++
+		}
+	})
+
+	// Assert
+	if failed != 2 {
+		t.Errorf("expected each backup to fail without stopping the others, got %d failures", failed)
+	}
+}
+
+func Test_backupJobs_ListFailure(t *testing.T) {
+	// Arrange
+	docker := dockertest.New(databaseContainers(), nil)
+	docker.Errors["ContainerExecCreate"] = fmt.Errorf("container is not running")
+
+	// Act
+	jobs, failures := backupJobs(context.Background(), docker, t.TempDir(), docker.Containers)
+
+	// Assert
+	if len(jobs) != 0 {
+		t.Errorf("expected no jobs, got %d", len(jobs))
+	}
+
+	if len(failures) != 2 {
+		t.Errorf("expected a failure for each container, got %d", len(failures))
+	}
+}
@@ -14,8 +14,20 @@ const exampleText = `  # import a database from a backup
   # backup a database
   nitro db backup
 
+  # restore a database from a backup
+  nitro db restore
+
   # add a new database
-  nitro db add`
+  nitro db add
+
+  # add a new database engine
+  nitro db new
+
+  # remove a database engine
+  nitro db destroy
+
+  # run a SQL statement
+  nitro db query --execute "SHOW TABLES;"`
 
 // NewCommand returns the db commands for importing, backing up, and adding databases
 func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) *cobra.Command {
@@ -31,10 +43,13 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 	cmd.AddCommand(
 		importCommand(home, docker, nitrod, output),
 		backupCommand(home, docker, output),
+		restoreCommand(home, docker, output),
+		destroyCommand(home, docker, output),
 		addCommand(docker, nitrod, output),
 		sshCommand(home, docker, output),
 		removeCommand(docker, nitrod, output),
 		newCommand(home, docker, output),
+		queryCommand(docker, output),
 	)
 
 	return cmd
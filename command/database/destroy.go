@@ -0,0 +1,176 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockerclient"
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var destroyExampleText = `  # remove a database engine, its container, and volume
+  nitro db destroy`
+
+func destroyCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "destroy",
+		Short:   "Destroy a database engine",
+		Example: destroyExampleText,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.VerifyInit(cmd, args, home, output)
+		},
+		PostRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.RunApply(cmd, args, false, output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// load the config
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			// add filters to show only the environment and database containers
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Type+"=database")
+
+			// get a list of all the databases
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter, All: true})
+			if err != nil {
+				return err
+			}
+
+			if len(containers) == 0 {
+				return fmt.Errorf("there are no database engines to destroy")
+			}
+
+			// sort containers by the name
+			sort.SliceStable(containers, func(i, j int) bool {
+				return containers[i].Names[0] < containers[j].Names[0]
+			})
+
+			// generate a list of engines for the prompt
+			var containerList []string
+			for _, c := range containers {
+				containerList = append(containerList, strings.TrimLeft(c.Names[0], "/"))
+			}
+
+			// prompt the user for the engine to destroy
+			containerID, containerName, compatibility, err := backup.PromptEngine(cmd.InOrStdin(), output, containers, containerList)
+			if err != nil {
+				return err
+			}
+
+			name := strings.TrimLeft(containerName, "/")
+
+			// get the containers info
+			info, err := docker.ContainerInspect(ctx, containerID)
+			if err != nil {
+				return err
+			}
+
+			// create the database from the container labels
+			db := config.Database{
+				Engine:  info.Config.Labels[containerlabels.DatabaseEngine],
+				Version: info.Config.Labels[containerlabels.DatabaseVersion],
+				Port:    info.Config.Labels[containerlabels.DatabasePort],
+			}
+
+			// confirm the removal
+			confirm, err := output.Confirm(fmt.Sprintf("Are you sure (this will remove the container and volume for %s)", name), false, "")
+			if err != nil {
+				return err
+			}
+
+			if !confirm {
+				output.Info("skipping destroy, the database will remain 😅")
+
+				return nil
+			}
+
+			// the container needs to be running before we can backup the databases
+			if info.State != nil && !info.State.Running {
+				if err := docker.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
+					return fmt.Errorf("unable to start the container to begin backups, %w", err)
+				}
+			}
+
+			// get all of the databases
+			databases, err := backup.Databases(ctx, docker, containerID, compatibility)
+			if err != nil {
+				return fmt.Errorf("unable to get the databases from %s, %w", name, err)
+			}
+
+			// backup each database
+			for _, d := range databases {
+				opts := backup.NewOptions(home, containerID, name, compatibility, d)
+
+				output.Pending("creating backup", opts.BackupName)
+
+				if err := backup.Perform(ctx, docker, opts); err != nil {
+					output.Warning()
+
+					return fmt.Errorf("unable to backup database %s, %w", d, err)
+				}
+
+				output.Done()
+			}
+
+			if len(databases) > 0 {
+				output.Info("Backups saved in", filepath.Join(home, config.DirectoryName, "backups", name), "💾")
+			}
+
+			// remove the database from the config before the container, so the config
+			// never refers to a database that was removed
+			if err := cfg.RemoveDatabase(db); err != nil {
+				return err
+			}
+
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+
+			output.Pending("removing", name)
+
+			// stop and remove the container, it was started for the backups
+			if err := dockerclient.RemoveContainer(ctx, docker, types.Container{ID: containerID, Names: []string{name}, State: "running"}, nil, types.ContainerRemoveOptions{}); err != nil {
+				output.Warning()
+
+				return fmt.Errorf("%s was removed from the config, %w", name, err)
+			}
+
+			// the volume uses the same name as the database hostname
+			volume, err := db.GetHostname()
+			if err != nil {
+				output.Warning()
+
+				return fmt.Errorf("%s was removed from the config, but the volume was not removed, %w", name, err)
+			}
+
+			if err := docker.VolumeRemove(ctx, volume, true); err != nil {
+				output.Warning()
+
+				return fmt.Errorf("%s was removed from the config, but the volume %s was not removed, %w", name, volume, err)
+			}
+
+			output.Done()
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -10,7 +10,8 @@ import (
 	"github.com/docker/docker/pkg/stdcopy"
 )
 
-func execCreate(ctx context.Context, docker client.ContainerAPIClient, containerID string, cmds []string, show bool) (bool, error) {
+// execExitCode runs the commands in the container and returns the exit code of the commands.
+func execExitCode(ctx context.Context, docker client.ContainerAPIClient, containerID string, cmds []string, show bool) (int, error) {
 	// create the exec
 	e, err := docker.ContainerExecCreate(ctx, containerID, types.ExecConfig{
 		AttachStdout: true,
@@ -19,7 +20,7 @@ func execCreate(ctx context.Context, docker client.ContainerAPIClient, container
 		Cmd:          cmds,
 	})
 	if err != nil {
-		return false, err
+		return 1, err
 	}
 
 	// attach to the container
@@ -27,33 +28,46 @@ func execCreate(ctx context.Context, docker client.ContainerAPIClient, container
 		Tty: false,
 	})
 	if err != nil {
-		return false, err
+		return 1, err
 	}
 	defer resp.Close()
 
+	// detach from the exec when the command is interrupted
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			resp.Close()
+		case <-done:
+		}
+	}()
+
 	// should we display output?
 	if show {
 		// show the output to stdout and stderr
 		if _, err := stdcopy.StdCopy(os.Stdout, os.Stderr, resp.Reader); err != nil {
-			return false, fmt.Errorf("unable to copy the output of container, %w", err)
+			return 1, fmt.Errorf("unable to copy the output of container, %w", err)
 		}
 	}
 
 	// start the exec
 	if err := docker.ContainerExecStart(ctx, e.ID, types.ExecStartCheck{}); err != nil {
-		return false, fmt.Errorf("unable to start the container, %w", err)
+		return 1, fmt.Errorf("unable to start the container, %w", err)
 	}
 
 	// wait for the container exec to complete
+	var exitCode int
 	waiting := true
 	for waiting {
 		resp, err := docker.ContainerExecInspect(ctx, e.ID)
 		if err != nil {
-			return false, err
+			return 1, err
 		}
 
 		waiting = resp.Running
+		exitCode = resp.ExitCode
 	}
 
-	return true, nil
+	return exitCode, nil
 }
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+func Test_execExitCode_Cancel(t *testing.T) {
+	// Arrange
+	docker := dockertest.New(nil, nil)
+	docker.ExecHangs = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act
+	done := make(chan error, 1)
+	go func() {
+		_, err := execExitCode(ctx, docker, "mysql", []string{"mysql", "-e", "SELECT SLEEP(60)"}, true)
+		done <- err
+	}()
+
+	cancel()
+
+	// Assert
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected an error when the exec is interrupted")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the exec to be detached when the context is canceled")
+	}
+}
+
+func Test_execExitCode(t *testing.T) {
+	// Arrange
+	docker := dockertest.New(nil, nil)
+	docker.ExecExitCode = 1
+
+	// Act
+	code, err := execExitCode(context.Background(), docker, "mysql", []string{"mysql", "-e", "SELECT 1"}, false)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code != 1 {
+		t.Errorf("expected the exit code 1, got %d", code)
+	}
+}
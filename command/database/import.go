@@ -1,11 +1,13 @@
 package database
 
 import (
-	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -17,10 +19,12 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	nitroclient "github.com/craftcms/nitro/client"
+	"github.com/craftcms/nitro/pkg/backup"
 	"github.com/craftcms/nitro/pkg/containerlabels"
-	"github.com/craftcms/nitro/pkg/database"
 	"github.com/craftcms/nitro/pkg/filetype"
 	"github.com/craftcms/nitro/pkg/pathexists"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/craftcms/nitro/pkg/validate"
 	"github.com/craftcms/nitro/protob"
@@ -33,7 +37,41 @@ var importExampleText = `  # import a sql file into a database
   nitro db import ~/Desktop/backup.sql
 
   # use an absolute path
-  nitro db import /Users/oli/Desktop/backup.sql`
+  nitro db import /Users/oli/Desktop/backup.sql
+
+  # create the database if it does not exist before importing
+  nitro db import backup.sql --create-if-missing
+
+  # import a backup from stdin, compressed backups are detected
+  cat backup.sql | nitro db import - --database nitro
+  cat backup.sql.gz | nitro db import - --database nitro --hostname mysql-8.0-3306.database.nitro
+
+  # import a backup that does not look like a backup for the engine
+  nitro db import backup.sql --hostname postgres-13-5432.database.nitro --force
+
+  # set the charset and collation for mysql, the defaults are utf8mb4 and utf8mb4_unicode_ci
+  nitro db import backup.sql --charset latin1 --collation latin1_swedish_ci
+
+  # check the backup and database without importing
+  nitro db import backup.sql.gz --hostname mysql-8.0-3306.database.nitro --database nitro --dry-run
+
+  # import a directory of per-table dumps, four at a time in file name order
+  nitro db import ~/dumps --database nitro --parallel 4
+  nitro db import "~/dumps/*.sql.gz" --database nitro --parallel 4`
+
+const (
+	// DefaultCharset is the charset used to create and import mysql databases
+	DefaultCharset = "utf8mb4"
+
+	// DefaultCollation is the collation used to create mysql databases with the default charset
+	DefaultCollation = "utf8mb4_unicode_ci"
+)
+
+// charsetRegex matches the mysql charset and collation names (e.g. utf8mb4_unicode_ci)
+var charsetRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ErrEmptyStdin is returned when importing from stdin and there is no backup
+var ErrEmptyStdin = fmt.Errorf("no backup was provided on stdin, pipe a backup into the command (e.g. cat backup.sql | nitro db import -)")
 
 // importCommand is the command for creating new development environments
 func importCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) *cobra.Command {
@@ -54,6 +92,20 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 		},
 		Example: importExampleText,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// the backup is read from stdin
+			if args[0] == "-" {
+				if parallel, _ := cmd.Flags().GetInt("parallel"); parallel > 0 {
+					return fmt.Errorf("the --parallel flag can not be used when importing from stdin")
+				}
+
+				return nil
+			}
+
+			// the backups are found in the directory or using the pattern
+			if parallel, _ := cmd.Flags().GetInt("parallel"); parallel > 0 {
+				return nil
+			}
+
 			// make sure the file exists
 			if exists := pathexists.IsFile(args[0]); !exists {
 				output.Info(cmd.UsageString())
@@ -70,6 +122,40 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 				path = strings.Replace(path, "~", home, 1)
 			}
 
+			// save the backup from stdin so the type and engine can be detected
+			stdin := path == "-"
+			if stdin {
+				output.Pending("reading backup from stdin")
+
+				temp, err := stdinToFile(cmd.InOrStdin())
+				if err != nil {
+					output.Warning()
+					return err
+				}
+				defer os.Remove(temp)
+
+				output.Done()
+
+				path = temp
+			}
+
+			// import every backup in the directory or matching the pattern, the
+			// first backup is used to detect the engine
+			parallel, _ := cmd.Flags().GetInt("parallel")
+
+			var files []string
+			if parallel > 0 {
+				found, err := backupFiles(path)
+				if err != nil {
+					return err
+				}
+
+				output.Info(fmt.Sprintf("Found %d backups", len(found)))
+
+				files = found
+				path = files[0]
+			}
+
 			// check if this is a zip file
 			var compressed bool
 			kind, err := filetype.Determine(path)
@@ -89,30 +175,41 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 			if !compressed {
 				output.Pending("detecting backup type")
 
-				// determine the database engine
-				detected, err = database.DetermineEngine(path)
-				if errors.Is(err, database.ErrUnknownDatabaseEngine) {
+				// determine the database engine from the header of the backup
+				detected, err = backup.SniffFile(path)
+				switch {
+				case errors.Is(err, backup.ErrUnknownEngine):
 					output.Warning()
 
 					output.Info(strings.Title(err.Error()))
-				} else {
+				case err != nil:
+					output.Warning()
+
+					return err
+				default:
 					output.Done()
 
 					output.Info("Detected", detected, "backup")
 				}
 			}
 
+			hostnameFlag, _ := cmd.Flags().GetString("hostname")
+			force, _ := cmd.Flags().GetBool("force")
+
 			// add filters to show only the environment and database containers
 			filter := filters.NewArgs()
 			filter.Add("label", containerlabels.Nitro)
 			filter.Add("label", containerlabels.Type+"=database")
 
-			// if we detected the engine type, add the compatibility label to the filter
-			switch detected {
-			case "mysql":
-				filter.Add("label", containerlabels.DatabaseCompatibility+"=mysql")
-			case "postgres":
-				filter.Add("label", containerlabels.DatabaseCompatibility+"=postgres")
+			// if we detected the engine type, only prompt for compatible engines. When
+			// the engine is chosen with the hostname, the engine is checked after.
+			if hostnameFlag == "" && !force {
+				switch detected {
+				case "mysql":
+					filter.Add("label", containerlabels.DatabaseCompatibility+"=mysql")
+				case "postgres":
+					filter.Add("label", containerlabels.DatabaseCompatibility+"=postgres")
+				}
 			}
 
 			// get a list of all the databases
@@ -126,10 +223,13 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 				return containers[i].Names[0] < containers[j].Names[0]
 			})
 
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
 			// get all of the containers as a list
 			var options []string
 			for _, c := range containers {
-				if c.State != "running" {
+				// a dry run does not start the containers, it reports the engine is not running
+				if c.State != "running" && !dryRun {
 					for _, command := range cmd.Root().Commands() {
 						if command.Use == "start" {
 							if err := command.RunE(cmd, []string{}); err != nil {
@@ -142,9 +242,10 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 				options = append(options, strings.TrimLeft(c.Names[0], "/"))
 			}
 
-			// prompt the user for the engine to import the backup into
+			// prompt the user for the engine to import the backup into, stdin
+			// is used by the backup so the hostname flag is required to choose
 			var containerID string
-			selected, err := output.Select(os.Stdin, "Select a database engine: ", options)
+			selected, err := selectEngine(cmd, options, stdin, output)
 			if err != nil {
 				return err
 			}
@@ -156,8 +257,35 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 			}
 
 			// ask the user for the database to create
-			db, err := output.Ask("Enter the database name", "", ":", &validate.DatabaseName{})
-			if err != nil {
+			db, _ := cmd.Flags().GetString("database")
+			switch {
+			case db != "":
+				if err := (&validate.DatabaseName{}).Validate(db); err != nil {
+					return err
+				}
+			case stdin:
+				return fmt.Errorf("the --database flag is required when importing from stdin")
+			default:
+				db, err = output.Ask("Enter the database name", "", ":", &validate.DatabaseName{})
+				if err != nil {
+					return err
+				}
+			}
+
+			// show what would be imported without changing anything
+			if dryRun {
+				createIfMissing, _ := cmd.Flags().GetBool("create-if-missing")
+
+				name := args[0]
+				if stdin {
+					name = "stdin"
+				}
+
+				return dryRunImport(cmd.Context(), docker, containerID, name, detected, compressionType, db, createIfMissing, force, output)
+			}
+
+			// the api runs in the proxy container, which is not created by apply --no-proxy
+			if _, err := proxycontainer.FindAndStart(cmd.Context(), docker); err != nil {
 				return err
 			}
 
@@ -169,7 +297,12 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 				return err
 			}
 
-			// get the database compatability from the container labelsmake l
+			// make sure the backup is for the engine, a mismatch would fail part way through the import
+			if err := checkEngine(detected, info.Config.Labels[containerlabels.DatabaseCompatibility], strings.TrimLeft(info.Name, "/"), force, output); err != nil {
+				return err
+			}
+
+			// get the database compatability from the container labels
 			detected = info.Config.Labels[containerlabels.DatabaseCompatibility]
 			hostname := strings.TrimLeft(info.Name, "/")
 			version := info.Config.Labels[containerlabels.DatabaseVersion]
@@ -184,6 +317,60 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 				}
 			}
 
+			// mysql imports use a charset to avoid mojibake
+			charset, collation, err := charsetOptions(cmd, detected, output)
+			if err != nil {
+				return err
+			}
+
+			// create the database if it does not exist
+			if createIfMissing, _ := cmd.Flags().GetBool("create-if-missing"); createIfMissing {
+				// get the existing databases for the engine
+				databases, err := backup.Databases(cmd.Context(), docker, containerID, detected)
+				if err != nil {
+					return err
+				}
+
+				exists := false
+				for _, d := range databases {
+					if d == db {
+						exists = true
+						break
+					}
+				}
+
+				if !exists {
+					output.Pending("creating database", db)
+
+					// wait for the api to be ready
+					if err := nitroclient.WaitForAPI(cmd.Context(), nitrod); err != nil {
+						output.Warning()
+						return err
+					}
+
+					if _, err := addDatabase(cmd.Context(), nitrod, info, db, charset, collation); err != nil {
+						output.Warning()
+
+						return fmt.Errorf("unable to create the database %q, %w", db, err)
+					}
+
+					output.Done()
+				}
+			}
+
+			// each backup is imported using a separate stream
+			if parallel > 0 {
+				return importParallel(cmd.Context(), nitrod, files, parallel, &protob.DatabaseInfo{
+					Database:  db,
+					Engine:    detected,
+					Hostname:  hostname,
+					Port:      port,
+					Version:   version,
+					Charset:   charset,
+					Collation: collation,
+				}, output)
+			}
+
 			stream, err := nitrod.ImportDatabase(cmd.Context())
 			// check if the error code is unimplemented
 			if code := status.Code(err); code == codes.Unimplemented {
@@ -226,6 +413,8 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 						Hostname:        hostname,
 						Port:            port,
 						Version:         version,
+						Charset:         charset,
+						Collation:       collation,
 					},
 				},
 			})
@@ -262,57 +451,193 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 			// create a timer
 			start := time.Now()
 
-			// open the file
-			file, err := os.Open(path)
+			output.Pending(fmt.Sprintf("importing database %q into %q", db, hostname))
+
+			// stream to backup file to the api
+			message, err := sendBackup(stream, path)
 			if err != nil {
+				output.Warning()
+
 				return err
 			}
 
-			// create a buffer to handle large files more gracefully
-			buffer := make([]byte, 1024*20)
-			reader := bufio.NewReader(file)
+			output.Done()
 
-			output.Pending(fmt.Sprintf("importing database %q into %q", db, hostname))
+			output.Info(fmt.Sprintf("%s, took %.2f seconds 💪...", message, time.Since(start).Seconds()))
 
-			// stream to backup file to the api
-			for {
-				n, err := reader.Read(buffer)
-				if err == io.EOF {
-					break
-				}
-				if err != nil {
-					output.Warning()
+			return nil
+		},
+	}
 
-					return stream.RecvMsg(nil)
-				}
+	cmd.Flags().Bool("create-if-missing", false, "create the database if it does not exist")
+	cmd.Flags().String("database", "", "name of the database to import into")
+	cmd.Flags().String("hostname", "", "hostname of the database engine to import into (e.g. mysql-8.0-3306.database.nitro)")
+	cmd.Flags().String("charset", "", "charset used to create and import mysql databases (default "+DefaultCharset+")")
+	cmd.Flags().String("collation", "", "collation used to create mysql databases (default "+DefaultCollation+")")
+	cmd.Flags().Bool("force", false, "import the backup even if it does not look like a backup for the database engine")
+	cmd.Flags().Bool("dry-run", false, "check the backup and database without importing")
+	cmd.Flags().Int("parallel", 0, "import every backup in a directory or matching a pattern, with this many imports at a time")
 
-				// send the chunked file data in pieces
-				if err := stream.Send(&protob.ImportDatabaseRequest{
-					Payload: &protob.ImportDatabaseRequest_Data{
-						Data: buffer[:n],
-					},
-				}); err != nil {
-					output.Warning()
+	return cmd
+}
 
-					return err
-				}
-			}
+// dryRunImport checks the database engine is running and the database exists or will
+// be created, then shows a summary of the import. The backup is not copied or imported.
+func dryRunImport(ctx context.Context, docker client.ContainerAPIClient, containerID, backupName, detected, compression, db string, createIfMissing, force bool, output terminal.Outputer) error {
+	info, err := docker.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return err
+	}
 
-			// handle the response
-			reply, err := stream.CloseAndRecv()
-			if err != nil {
-				output.Warning()
+	hostname := strings.TrimLeft(info.Name, "/")
+	engine := info.Config.Labels[containerlabels.DatabaseCompatibility]
 
-				return stream.RecvMsg(nil)
+	// make sure the backup is for the engine
+	if err := checkEngine(detected, engine, hostname, force, output); err != nil {
+		return err
+	}
+
+	if info.State == nil || !info.State.Running {
+		return fmt.Errorf("the database engine %s is not running, run `nitro start` to start it", hostname)
+	}
+
+	databases, err := backup.Databases(ctx, docker, containerID, engine)
+	if err != nil {
+		return fmt.Errorf("unable to get the databases for %s, %w", hostname, err)
+	}
+
+	status := "does not exist, use --create-if-missing to create it"
+	if createIfMissing {
+		status = "does not exist and will be created"
+	}
+
+	for _, d := range databases {
+		if d == db {
+			status = "exists"
+			break
+		}
+	}
+
+	if compression == "" {
+		compression = "none"
+	}
+
+	if detected == "" {
+		detected = "unknown"
+	}
+
+	output.Info("Dry run, nothing was imported:")
+	output.Info("  backup:", backupName)
+	output.Info("  compression:", compression)
+	output.Info("  detected engine:", detected)
+	output.Info("  database engine:", hostname, "is running")
+	output.Info(fmt.Sprintf("  database: %q %s", db, status))
+
+	return nil
+}
+
+// selectEngine returns the index of the database engine to import into. The
+// hostname flag is used when set, otherwise the user is prompted. When the
+// backup is from stdin the user can not be prompted.
+func selectEngine(cmd *cobra.Command, options []string, stdin bool, output terminal.Outputer) (int, error) {
+	if len(options) == 0 {
+		return 0, fmt.Errorf("unable to find a database engine to import into")
+	}
+
+	if hostname, _ := cmd.Flags().GetString("hostname"); hostname != "" {
+		for i, o := range options {
+			if o == hostname {
+				return i, nil
 			}
+		}
 
-			output.Done()
+		return 0, fmt.Errorf("unable to find the database engine %s", hostname)
+	}
 
-			output.Info(fmt.Sprintf("%s, took %.2f seconds 💪...", reply.Message, time.Since(start).Seconds()))
+	if stdin && len(options) > 1 {
+		return 0, fmt.Errorf("the --hostname flag is required when importing from stdin, use one of %s", strings.Join(options, ", "))
+	}
 
-			return nil
-		},
+	return output.Select(os.Stdin, "Select a database engine: ", options)
+}
+
+// checkEngine returns an error if the engine detected from the backup does not match the
+// engine the backup is imported into. When force is true, a warning is shown instead.
+func checkEngine(detected, engine, hostname string, force bool, output terminal.Outputer) error {
+	if detected == "" || detected == engine {
+		return nil
 	}
 
-	return cmd
+	if force {
+		output.Info(fmt.Sprintf("Warning: the backup looks like a %s backup but %s is a %s database, importing anyway", detected, hostname, engine))
+
+		return nil
+	}
+
+	return fmt.Errorf("the backup looks like a %s backup but %s is a %s database, use --force to import anyway", detected, hostname, engine)
+}
+
+// charsetOptions returns the charset and collation for the import. Mysql compatible engines
+// default to utf8mb4, the default collation is only used with the default charset. The
+// options are ignored for postgres. The options are used in the CREATE DATABASE statement,
+// so an error is returned unless they only contain letters, numbers, and underscores.
+func charsetOptions(cmd *cobra.Command, engine string, output terminal.Outputer) (string, string, error) {
+	charset, _ := cmd.Flags().GetString("charset")
+	collation, _ := cmd.Flags().GetString("collation")
+
+	if engine != "mysql" {
+		if charset != "" || collation != "" {
+			output.Info("Ignoring --charset and --collation, they are only used for mysql and mariadb")
+		}
+
+		return "", "", nil
+	}
+
+	if charset != "" && !charsetRegex.MatchString(charset) {
+		return "", "", fmt.Errorf("the --charset %q is not valid, it can only contain letters, numbers, and underscores", charset)
+	}
+
+	if collation != "" && !charsetRegex.MatchString(collation) {
+		return "", "", fmt.Errorf("the --collation %q is not valid, it can only contain letters, numbers, and underscores", collation)
+	}
+
+	if charset == "" {
+		charset = DefaultCharset
+
+		if collation == "" {
+			collation = DefaultCollation
+		}
+	}
+
+	return charset, collation, nil
+}
+
+// stdinToFile copies the backup from stdin to a temp file and returns the path
+// to the file. It returns ErrEmptyStdin if stdin is a terminal or is empty.
+func stdinToFile(r io.Reader) (string, error) {
+	// a terminal means nothing was piped to the command
+	if f, ok := r.(*os.File); ok {
+		if stat, err := f.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+			return "", ErrEmptyStdin
+		}
+	}
+
+	temp, err := ioutil.TempFile(os.TempDir(), "nitro-import-stdin-")
+	if err != nil {
+		return "", fmt.Errorf("unable to create a temp file for the backup, %w", err)
+	}
+	defer temp.Close()
+
+	n, err := io.Copy(temp, r)
+	if err != nil {
+		os.Remove(temp.Name())
+		return "", fmt.Errorf("unable to read the backup from stdin, %w", err)
+	}
+
+	if n == 0 {
+		os.Remove(temp.Name())
+		return "", ErrEmptyStdin
+	}
+
+	return temp.Name(), nil
 }
@@ -0,0 +1,308 @@
+package database
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+func Test_stdinToFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{
+			name:  "backups are saved to a file",
+			input: "-- MySQL dump 10.13\nCREATE TABLE example;\n",
+		},
+		{
+			name:    "empty input returns an error",
+			wantErr: ErrEmptyStdin,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stdinToFile(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("stdinToFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+			defer os.Remove(got)
+
+			content, err := ioutil.ReadFile(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != tt.input {
+				t.Errorf("stdinToFile() content = %q, want %q", content, tt.input)
+			}
+		})
+	}
+}
+
+func Test_charsetOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		engine        string
+		flags         map[string]string
+		wantCharset   string
+		wantCollation string
+		wantInfo      bool
+		wantErr       bool
+	}{
+		{
+			name:          "mysql defaults to utf8mb4",
+			engine:        "mysql",
+			wantCharset:   "utf8mb4",
+			wantCollation: "utf8mb4_unicode_ci",
+		},
+		{
+			name:          "mysql uses the flags",
+			engine:        "mysql",
+			flags:         map[string]string{"charset": "latin1", "collation": "latin1_swedish_ci"},
+			wantCharset:   "latin1",
+			wantCollation: "latin1_swedish_ci",
+		},
+		{
+			name:          "mysql does not use the default collation with a different charset",
+			engine:        "mysql",
+			flags:         map[string]string{"charset": "latin1"},
+			wantCharset:   "latin1",
+			wantCollation: "",
+		},
+		{
+			name:    "mysql rejects a charset that is not a name",
+			engine:  "mysql",
+			flags:   map[string]string{"charset": "utf8mb4; DROP DATABASE nitro"},
+			wantErr: true,
+		},
+		{
+			name:    "mysql rejects a collation with spaces",
+			engine:  "mysql",
+			flags:   map[string]string{"collation": "utf8mb4 COLLATE latin1_bin"},
+			wantErr: true,
+		},
+		{
+			name:     "postgres ignores the flags with a note",
+			engine:   "postgres",
+			flags:    map[string]string{"charset": "utf8mb4"},
+			wantInfo: true,
+		},
+		{
+			name:   "postgres without flags does not show a note",
+			engine: "postgres",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := importCommand("", nil, nil, nil)
+			for k, v := range tt.flags {
+				if err := cmd.Flags().Set(k, v); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			output := &infoOutputer{}
+			charset, collation, err := charsetOptions(cmd, tt.engine, output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("charsetOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if charset != tt.wantCharset || collation != tt.wantCollation {
+				t.Errorf("charsetOptions() = %q, %q, want %q, %q", charset, collation, tt.wantCharset, tt.wantCollation)
+			}
+
+			if (len(output.info) > 0) != tt.wantInfo {
+				t.Errorf("expected a note to be shown %v, got %v", tt.wantInfo, output.info)
+			}
+		})
+	}
+}
+
+func Test_checkEngine(t *testing.T) {
+	tests := []struct {
+		name     string
+		detected string
+		engine   string
+		force    bool
+		wantErr  bool
+		wantInfo bool
+	}{
+		{
+			name:     "matching engines import",
+			detected: "mysql",
+			engine:   "mysql",
+		},
+		{
+			name:   "undetected engines import",
+			engine: "postgres",
+		},
+		{
+			name:     "mismatched engines return an error",
+			detected: "mysql",
+			engine:   "postgres",
+			wantErr:  true,
+		},
+		{
+			name:     "mismatched engines warn when forced",
+			detected: "postgres",
+			engine:   "mysql",
+			force:    true,
+			wantInfo: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &infoOutputer{}
+			err := checkEngine(tt.detected, tt.engine, "database.nitro", tt.force, output)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkEngine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if (len(output.info) > 0) != tt.wantInfo {
+				t.Errorf("expected a warning to be shown %v, got %v", tt.wantInfo, output.info)
+			}
+		})
+	}
+}
+
+type infoOutputer struct {
+	terminal.Outputer
+	info []string
+}
+
+func (o *infoOutputer) Info(s ...string) {
+	o.info = append(o.info, strings.Join(s, " "))
+}
+
+func (o *infoOutputer) Pending(s ...string) {}
+
+func (o *infoOutputer) Done() {}
+
+func (o *infoOutputer) Warning() {}
+
+func TestImportCommand_DryRun(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "backup.sql")
+	if err := ioutil.WriteFile(file, []byte("-- MySQL dump 10.13\nCREATE TABLE example;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// database returns a database engine container with the state
+	database := func(name, compatibility, state string) types.Container {
+		return types.Container{
+			ID:    name,
+			Names: []string{"/" + name},
+			State: state,
+			Labels: map[string]string{
+				containerlabels.Nitro:                 "true",
+				containerlabels.Type:                  "database",
+				containerlabels.DatabaseCompatibility: compatibility,
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		container types.Container
+		args      []string
+		wantInfo  string
+		wantErr   string
+	}{
+		{
+			name:      "existing databases are shown",
+			container: database("mysql-8.0-3306.database.nitro", "mysql", "running"),
+			args:      []string{"--database", "nitro"},
+			wantInfo:  `  database: "nitro" exists`,
+		},
+		{
+			name:      "missing databases are created with create-if-missing",
+			container: database("mysql-8.0-3306.database.nitro", "mysql", "running"),
+			args:      []string{"--database", "missing", "--create-if-missing"},
+			wantInfo:  `  database: "missing" does not exist and will be created`,
+		},
+		{
+			name:      "missing databases are shown",
+			container: database("mysql-8.0-3306.database.nitro", "mysql", "running"),
+			args:      []string{"--database", "missing"},
+			wantInfo:  `  database: "missing" does not exist, use --create-if-missing to create it`,
+		},
+		{
+			name:      "stopped engines return an error",
+			container: database("mysql-8.0-3306.database.nitro", "mysql", "exited"),
+			args:      []string{"--database", "nitro"},
+			wantErr:   "the database engine mysql-8.0-3306.database.nitro is not running, run `nitro start` to start it",
+		},
+		{
+			name:      "backups for a different engine return an error",
+			container: database("postgres-13-5432.database.nitro", "postgres", "running"),
+			args:      []string{"--database", "nitro"},
+			wantErr:   "the backup looks like a mysql backup but postgres-13-5432.database.nitro is a postgres database, use --force to import anyway",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			docker := dockertest.New([]types.Container{tt.container}, nil)
+			docker.ExecOutput = "Database\nnitro\n"
+			output := &infoOutputer{}
+
+			// the nitrod client is nil so any import would panic
+			cmd := importCommand(t.TempDir(), docker, nil, output)
+			cmd.SetArgs(append([]string{file, "--hostname", strings.TrimLeft(tt.container.Names[0], "/"), "--dry-run"}, tt.args...))
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			// Act
+			err := cmd.Execute()
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected the error %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantInfo != "" && !contains(output.info, tt.wantInfo) {
+				t.Errorf("expected the summary to contain %q, got %v", tt.wantInfo, output.info)
+			}
+
+			// only the databases are listed, nothing is started, copied, or created
+			for _, method := range []string{"ContainerStart", "CopyToContainer", "ContainerCreate"} {
+				if calls := docker.Calls(method); len(calls) != 0 {
+					t.Errorf("expected no calls to %s, got %d", method, len(calls))
+				}
+			}
+
+			if calls := docker.Calls("ContainerExecCreate"); len(calls) > 1 {
+				t.Errorf("expected at most one exec to list the databases, got %d", len(calls))
+			}
+		})
+	}
+}
+
+// contains returns true when the list has the value.
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
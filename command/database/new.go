@@ -79,11 +79,13 @@ func newCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			}
 
 			// add the database to the config
-			cfg.Databases = append(cfg.Databases, config.Database{
+			if err := cfg.AddDatabase(config.Database{
 				Engine:  engine,
 				Version: version,
 				Port:    port,
-			})
+			}); err != nil {
+				return err
+			}
 
 			// save the config
 			if err := cfg.Save(); err != nil {
@@ -0,0 +1,252 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/craftcms/nitro/pkg/filetype"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/protob"
+)
+
+// backupExtensions are the file extensions imported from a directory.
+var backupExtensions = []string{".sql", ".gz", ".zip", ".tar"}
+
+// importResult is the result of importing a single backup with --parallel.
+type importResult struct {
+	file     string
+	message  string
+	duration time.Duration
+	err      error
+}
+
+// backupFiles returns the backups in a directory, or the files matching a
+// glob (e.g. ~/dumps/*.sql.gz), sorted by the file name so backups with a
+// prefix (e.g. 01-users.sql) are started first.
+func backupFiles(path string) ([]string, error) {
+	var files []string
+
+	stat, err := os.Stat(path)
+	switch {
+	case err == nil && stat.IsDir():
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the directory %s, %w", path, err)
+		}
+
+		for _, e := range entries {
+			if e.IsDir() || !isBackup(e.Name()) {
+				continue
+			}
+
+			files = append(files, filepath.Join(path, e.Name()))
+		}
+	case err == nil:
+		files = []string{path}
+	default:
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to use the pattern %s, %w", path, err)
+		}
+
+		for _, m := range matches {
+			if stat, err := os.Stat(m); err == nil && !stat.IsDir() {
+				files = append(files, m)
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("unable to find any backups using %s", path)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return filepath.Base(files[i]) < filepath.Base(files[j])
+	})
+
+	return files, nil
+}
+
+// isBackup returns true if the file name uses one of the backup extensions.
+func isBackup(name string) bool {
+	for _, ext := range backupExtensions {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importAll calls fn for each file with no more than limit imports at a time.
+// The imports are started in the order of the files and done is called with
+// the result of each import as it completes, calls to done are never made
+// concurrently. The results are returned in the order of the files.
+func importAll(ctx context.Context, files []string, limit int, fn func(context.Context, string) (string, error), done func(importResult)) []importResult {
+	if limit < 1 {
+		limit = 1
+	}
+
+	sem := make(chan struct{}, limit)
+	results := make([]importResult, len(files))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, file := range files {
+		// wait for a slot before starting, so the files start in order
+		sem <- struct{}{}
+
+		wg.Add(1)
+		go func(i int, file string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			message, err := fn(ctx, file)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			results[i] = importResult{file: file, message: message, duration: time.Since(start), err: err}
+
+			done(results[i])
+		}(i, file)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// importParallel imports each of the files into the database using a new
+// stream for each file. It reports the result of each file and returns an
+// error if any of the files could not be imported.
+func importParallel(ctx context.Context, nitrod protob.NitroClient, files []string, limit int, info *protob.DatabaseInfo, output terminal.Outputer) error {
+	output.Info(fmt.Sprintf("Importing %d backups into %q on %s, %d at a time…", len(files), info.Database, info.Hostname, limit))
+
+	start := time.Now()
+
+	results := importAll(ctx, files, limit, func(ctx context.Context, file string) (string, error) {
+		return importFile(ctx, nitrod, file, info)
+	}, func(r importResult) {
+		if r.err != nil {
+			output.Info(fmt.Sprintf("  ✗ %s: %s", filepath.Base(r.file), r.err))
+			return
+		}
+
+		output.Info(fmt.Sprintf("  ✓ %s (%.2f seconds)", filepath.Base(r.file), r.duration.Seconds()))
+	})
+
+	var failed []string
+	for _, r := range results {
+		if r.err != nil {
+			failed = append(failed, filepath.Base(r.file))
+		}
+	}
+
+	output.Info(fmt.Sprintf("Imported %d of %d backups, took %.2f seconds 💪...", len(files)-len(failed), len(files), time.Since(start).Seconds()))
+
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to import %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// importFile detects the compression of the file, then streams the file to
+// the api to import it into the database.
+func importFile(ctx context.Context, nitrod protob.NitroClient, file string, info *protob.DatabaseInfo) (string, error) {
+	kind, err := filetype.Determine(file)
+	if err != nil {
+		return "", err
+	}
+
+	details := &protob.DatabaseInfo{
+		Database:  info.Database,
+		Engine:    info.Engine,
+		Hostname:  info.Hostname,
+		Port:      info.Port,
+		Version:   info.Version,
+		Charset:   info.Charset,
+		Collation: info.Collation,
+	}
+
+	switch kind {
+	case "zip", "tar":
+		details.Compressed = true
+		details.CompressionType = kind
+	}
+
+	stream, err := nitrod.ImportDatabase(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if err := stream.Send(&protob.ImportDatabaseRequest{Payload: &protob.ImportDatabaseRequest_Database{Database: details}}); err != nil {
+		return "", streamErr(stream, err)
+	}
+
+	return sendBackup(stream, file)
+}
+
+// sendBackup streams the backup file to the api in chunks and returns the
+// message from the api once the import is complete.
+func sendBackup(stream protob.Nitro_ImportDatabaseClient, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	// create a buffer to handle large files more gracefully
+	buffer := make([]byte, 1024*20)
+	reader := bufio.NewReader(file)
+
+	for {
+		n, err := reader.Read(buffer)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", streamErr(stream, err)
+		}
+
+		// send the chunked file data in pieces
+		if err := stream.Send(&protob.ImportDatabaseRequest{
+			Payload: &protob.ImportDatabaseRequest_Data{
+				Data: buffer[:n],
+			},
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	// handle the response
+	reply, err := stream.CloseAndRecv()
+	if err != nil {
+		return "", streamErr(stream, err)
+	}
+
+	return reply.Message, nil
+}
+
+// streamErr returns the error from the api, which explains why the import
+// failed, or err when the api did not return an error.
+func streamErr(stream grpc.ClientStream, err error) error {
+	if apiErr := stream.RecvMsg(nil); apiErr != nil {
+		return apiErr
+	}
+
+	return err
+}
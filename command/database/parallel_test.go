@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_backupFiles(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	for _, name := range []string{"03-orders.sql.gz", "01-users.sql", "02-entries.sql", "notes.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("-- dump"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "archive.sql"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "directories return the backups sorted by name",
+			path: dir,
+			want: []string{"01-users.sql", "02-entries.sql", "03-orders.sql.gz"},
+		},
+		{
+			name: "patterns return the matching files sorted by name",
+			path: filepath.Join(dir, "0*.sql"),
+			want: []string{"01-users.sql", "02-entries.sql"},
+		},
+		{
+			name: "files return the file",
+			path: filepath.Join(dir, "02-entries.sql"),
+			want: []string{"02-entries.sql"},
+		},
+		{
+			name:    "patterns without a match return an error",
+			path:    filepath.Join(dir, "*.zip"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := backupFiles(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("backupFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var names []string
+			for _, f := range got {
+				names = append(names, filepath.Base(f))
+			}
+
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("backupFiles() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func Test_importAll(t *testing.T) {
+	// Arrange
+	files := []string{"01-users.sql", "02-entries.sql", "03-orders.sql", "04-assets.sql", "05-logs.sql"}
+	limit := 2
+
+	var mu sync.Mutex
+	var started []string
+	running, maxRunning := 0, 0
+
+	fn := func(ctx context.Context, file string) (string, error) {
+		mu.Lock()
+		started = append(started, file)
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		if file == "03-orders.sql" {
+			return "", errors.New("table orders already exists")
+		}
+
+		return "imported " + file, nil
+	}
+
+	done := 0
+
+	// Act
+	results := importAll(context.Background(), files, limit, fn, func(r importResult) {
+		done++
+	})
+
+	// Assert
+	if maxRunning > limit {
+		t.Errorf("expected no more than %d imports at a time, got %d", limit, maxRunning)
+	}
+
+	if maxRunning < limit {
+		t.Errorf("expected %d imports to run at the same time, got %d", limit, maxRunning)
+	}
+
+	// a file is only started after the files before it have a slot
+	position := make(map[string]int)
+	for i, f := range started {
+		position[f] = i
+	}
+
+	for i := limit; i < len(files); i++ {
+		if position[files[i]] < position[files[i-limit]] {
+			t.Errorf("expected %s to start after %s, got %v", files[i], files[i-limit], started)
+		}
+	}
+
+	if done != len(files) {
+		t.Errorf("expected done to be called for each file, got %d", done)
+	}
+
+	for i, r := range results {
+		if r.file != files[i] {
+			t.Errorf("expected the results in the order of the files, got %s at %d", r.file, i)
+		}
+
+		if wantErr := r.file == "03-orders.sql"; (r.err != nil) != wantErr {
+			t.Errorf("expected the error for %s to be %v, got %v", r.file, wantErr, r.err)
+		}
+	}
+}
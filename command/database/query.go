@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var queryExampleText = `  # run a query against the default nitro database
+  nitro db query --execute "SELECT * FROM users;"
+
+  # run a query against a specific engine and database
+  nitro db query --engine mysql-8.0-3306.database.nitro --database craft --execute "SHOW TABLES;"
+
+  # run a statement without showing the output
+  nitro db query --database craft --execute "DELETE FROM sessions;" --show-output=false`
+
+func queryCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "query",
+		Short:   "Run a SQL statement",
+		Example: queryExampleText,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			statement := cmd.Flag("execute").Value.String()
+			if statement == "" {
+				return fmt.Errorf("the --execute flag is required")
+			}
+
+			db := cmd.Flag("database").Value.String()
+			engine := cmd.Flag("engine").Value.String()
+			show := cmd.Flag("show-output").Value.String() == "true"
+
+			// add filters to show only the environment and database containers
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Type+"=database")
+
+			// get a list of all the databases
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter})
+			if err != nil {
+				return err
+			}
+
+			// sort containers by the name
+			sort.SliceStable(containers, func(i, j int) bool {
+				return containers[i].Names[0] < containers[j].Names[0]
+			})
+
+			// generate a list of engines for the prompt
+			var containerList []string
+			for _, c := range containers {
+				containerList = append(containerList, strings.TrimLeft(c.Names[0], "/"))
+			}
+
+			if len(containerList) == 0 {
+				return fmt.Errorf("there are no running database engines")
+			}
+
+			// find the engine by the flag or prompt the user
+			selected := -1
+			switch engine {
+			case "":
+				selected, err = output.Select(cmd.InOrStdin(), "Which database engine? ", containerList)
+				if err != nil {
+					return err
+				}
+			default:
+				for i, c := range containerList {
+					if c == engine {
+						selected = i
+					}
+				}
+
+				if selected == -1 {
+					return fmt.Errorf("unable to find the database engine %q", engine)
+				}
+			}
+
+			// the statement is passed as a single argument so it does not need to be escaped
+			var commands []string
+			switch containers[selected].Labels[containerlabels.DatabaseCompatibility] {
+			case "postgres":
+				commands = []string{"psql", "--username=nitro", "--dbname=" + db, "--command", statement}
+			default:
+				commands = []string{"mysql", "-unitro", "-pnitro", db, "-e", statement}
+			}
+
+			// run the statement in the container
+			code, err := execExitCode(ctx, docker, containers[selected].ID, commands, show)
+			if err != nil {
+				return fmt.Errorf("unable to run the statement, %w", err)
+			}
+
+			// scripts need to know when the statement fails
+			if code != 0 {
+				return fmt.Errorf("the statement failed with exit code %d", code)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("execute", "e", "", "the SQL statement to execute")
+	cmd.Flags().String("database", "nitro", "the database to run the statement against")
+	cmd.Flags().String("engine", "", "the database engine hostname (e.g. mysql-8.0-3306.database.nitro)")
+	cmd.Flags().Bool("show-output", true, "show the output from the statement")
+
+	return cmd
+}
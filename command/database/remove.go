@@ -12,8 +12,10 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	nitroclient "github.com/craftcms/nitro/client"
 	"github.com/craftcms/nitro/pkg/backup"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/craftcms/nitro/protob"
 )
@@ -89,12 +91,15 @@ func removeCommand(docker client.CommonAPIClient, nitrod protob.NitroClient, out
 
 			db := databases[selected]
 
+			// the api runs in the proxy container, which is not created by apply --no-proxy
+			if _, err := proxycontainer.FindAndStart(cmd.Context(), docker); err != nil {
+				return err
+			}
+
 			// wait for the api to be ready
-			for {
-				_, err := nitrod.Ping(cmd.Context(), &protob.PingRequest{})
-				if err == nil {
-					break
-				}
+			if err := nitroclient.WaitForAPI(cmd.Context(), nitrod); err != nil {
+				output.Warning()
+				return err
 			}
 
 			output.Pending("removing", db)
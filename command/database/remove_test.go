@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"google.golang.org/grpc"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/protob"
+)
+
+type mockOutputer struct {
+	terminal.Outputer
+}
+
+func (m mockOutputer) Select(r io.Reader, msg string, opts []string) (int, error) {
+	return 0, nil
+}
+
+func (m mockOutputer) Pending(s ...string) {}
+
+func (m mockOutputer) Done() {}
+
+func (m mockOutputer) Info(s ...string) {}
+
+func (m mockOutputer) Success(s ...string) {}
+
+type mockNitroClient struct {
+	protob.NitroClient
+
+	removeRequests []*protob.RemoveDatabaseRequest
+}
+
+func (m *mockNitroClient) Ping(ctx context.Context, in *protob.PingRequest, opts ...grpc.CallOption) (*protob.PingResponse, error) {
+	return &protob.PingResponse{}, nil
+}
+
+func (m *mockNitroClient) RemoveDatabase(ctx context.Context, in *protob.RemoveDatabaseRequest, opts ...grpc.CallOption) (*protob.RemoveDatabaseResponse, error) {
+	m.removeRequests = append(m.removeRequests, in)
+
+	return &protob.RemoveDatabaseResponse{Message: "Removed database"}, nil
+}
+
+func Test_removeCommand(t *testing.T) {
+	// Arrange
+	docker := dockertest.New([]types.Container{
+		{
+			ID:    "mysql",
+			Names: []string{"/mysql-8.0-3306.database.nitro"},
+			State: "running",
+			Labels: map[string]string{
+				containerlabels.Nitro: "true",
+				containerlabels.Type:  "database",
+			},
+		},
+		{
+			ID:    "proxy",
+			Names: []string{"/nitro-proxy"},
+			State: "running",
+			Labels: map[string]string{
+				containerlabels.Nitro: "true",
+				containerlabels.Type:  "proxy",
+			},
+		},
+	}, nil)
+	docker.Details["mysql"] = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:   "mysql",
+			Name: "/mysql-8.0-3306.database.nitro",
+			HostConfig: &container.HostConfig{
+				PortBindings: nat.PortMap{
+					"3306/tcp": []nat.PortBinding{{HostPort: "3306"}},
+				},
+			},
+		},
+		Config: &container.Config{
+			Labels: map[string]string{
+				containerlabels.DatabaseCompatibility: "mysql",
+				containerlabels.DatabaseVersion:       "8.0",
+			},
+		},
+	}
+	docker.ExecOutput = "Database\ninformation_schema\nnitro\nproject\n"
+	nitrod := &mockNitroClient{}
+
+	// Act
+	cmd := removeCommand(docker, nitrod, mockOutputer{})
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	want := []*protob.RemoveDatabaseRequest{
+		{
+			Database: &protob.DatabaseInfo{
+				Engine:   "mysql",
+				Hostname: "mysql-8.0-3306.database.nitro",
+				Version:  "8.0",
+				Port:     "3306",
+				Database: "nitro",
+			},
+		},
+	}
+	if !reflect.DeepEqual(nitrod.removeRequests, want) {
+		t.Errorf("expected the remove requests to match, got %v, want %v", nitrod.removeRequests, want)
+	}
+
+	// the databases are listed using an exec in the container
+	calls := docker.Calls("ContainerExecCreate")
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 exec, got %d", len(calls))
+	}
+
+	if id := calls[0].Args[0]; id != "mysql" {
+		t.Errorf("expected the exec to use the mysql container, got %v", id)
+	}
+}
+
+func Test_removeCommand_NoProxy(t *testing.T) {
+	// Arrange
+	docker := dockertest.New([]types.Container{
+		{
+			ID:    "mysql",
+			Names: []string{"/mysql-8.0-3306.database.nitro"},
+			State: "running",
+			Labels: map[string]string{
+				containerlabels.Nitro: "true",
+				containerlabels.Type:  "database",
+			},
+		},
+	}, nil)
+	docker.Details["mysql"] = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:         "mysql",
+			Name:       "/mysql-8.0-3306.database.nitro",
+			HostConfig: &container.HostConfig{},
+		},
+		Config: &container.Config{
+			Labels: map[string]string{
+				containerlabels.DatabaseCompatibility: "mysql",
+				containerlabels.DatabaseVersion:       "8.0",
+			},
+		},
+	}
+	docker.ExecOutput = "Database\nnitro\n"
+	nitrod := &mockNitroClient{}
+
+	// Act
+	cmd := removeCommand(docker, nitrod, mockOutputer{})
+	err := cmd.RunE(cmd, []string{})
+
+	// Assert
+	if !errors.Is(err, proxycontainer.ErrNoProxyContainer) {
+		t.Errorf("expected the missing proxy error, got %v", err)
+	}
+
+	if len(nitrod.removeRequests) != 0 {
+		t.Errorf("expected no remove requests without the proxy, got %v", nitrod.removeRequests)
+	}
+}
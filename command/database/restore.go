@@ -0,0 +1,208 @@
+package database
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/pkg/validate"
+)
+
+var restoreExampleText = `  # restore a database from a backup
+  nitro db restore
+
+  # restore a backup for a specific database engine
+  nitro db restore --hostname mysql-8.0-3306.database.nitro
+
+  # restore into a different database than the backup was made from
+  nitro db restore --database project`
+
+// backupNameRegex matches the names of backups created by nitro (e.g. nitro-2021-01-02-080102.sql)
+var backupNameRegex = regexp.MustCompile(`^(.+)-\d{4}-\d{2}-\d{2}-\d{6}\.sql(\.gz)?$`)
+
+// restoreCommand is the command for restoring a database from a backup in ~/.nitro/backups.
+func restoreCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "restore",
+		Short:   "Restore a database from a backup",
+		Example: restoreExampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// add filters to show only the environment and database containers
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Type+"=database")
+
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter, All: true})
+			if err != nil {
+				return err
+			}
+
+			// sort containers by the name
+			sort.SliceStable(containers, func(i, j int) bool {
+				return containers[i].Names[0] < containers[j].Names[0]
+			})
+
+			var options []string
+			for _, c := range containers {
+				options = append(options, strings.TrimLeft(c.Names[0], "/"))
+			}
+
+			selected, err := selectEngine(cmd, options, false, output)
+			if err != nil {
+				return err
+			}
+
+			container := containers[selected]
+			hostname := options[selected]
+
+			// find the backups for the engine
+			dir := filepath.Join(home, config.DirectoryName, "backups", hostname)
+			backups, err := listBackups(dir)
+			if err != nil {
+				return err
+			}
+
+			if len(backups) == 0 {
+				return fmt.Errorf("there are no backups for %s in %s, run `nitro db backup` to create one", hostname, dir)
+			}
+
+			i, err := output.Select(os.Stdin, "Select a backup to restore: ", backups)
+			if err != nil {
+				return err
+			}
+
+			file := filepath.Join(dir, backups[i])
+
+			// use the database the backup was created from unless one was provided
+			db, _ := cmd.Flags().GetString("database")
+			switch {
+			case db != "":
+				if err := (&validate.DatabaseName{}).Validate(db); err != nil {
+					return err
+				}
+			case backupDatabaseName(backups[i]) != "":
+				db = backupDatabaseName(backups[i])
+			default:
+				db, err = output.Ask("Enter the database name", "", ":", &validate.DatabaseName{})
+				if err != nil {
+					return err
+				}
+			}
+
+			// the engine needs to be running to check the databases
+			if container.State != "running" {
+				if err := docker.ContainerStart(ctx, container.ID, types.ContainerStartOptions{}); err != nil {
+					return fmt.Errorf("unable to start %s, %w", hostname, err)
+				}
+			}
+
+			databases, err := backup.Databases(ctx, docker, container.ID, container.Labels[containerlabels.DatabaseCompatibility])
+			if err != nil {
+				return fmt.Errorf("unable to get the databases from %s, %w", hostname, err)
+			}
+
+			// confirm before overwriting an existing database
+			for _, d := range databases {
+				if d != db {
+					continue
+				}
+
+				confirm, err := output.Confirm(fmt.Sprintf("The database %q already exists in %s, overwrite it with the backup", db, hostname), false, "?")
+				if err != nil {
+					return err
+				}
+
+				if !confirm {
+					output.Info("Skipping the restore, the database will remain 😅")
+
+					return nil
+				}
+
+				break
+			}
+
+			output.Info("Restoring", backups[i], "into", db)
+
+			// use the import command to restore the backup
+			for _, c := range cmd.Parent().Commands() {
+				if c.Use != "import" {
+					continue
+				}
+
+				flags := map[string]string{
+					"create-if-missing": "true",
+					"database":          db,
+					"hostname":          hostname,
+				}
+				for name, value := range flags {
+					if err := c.Flags().Set(name, value); err != nil {
+						return err
+					}
+				}
+
+				return c.RunE(c, []string{file})
+			}
+
+			return fmt.Errorf("unable to find the import command")
+		},
+	}
+
+	cmd.Flags().String("database", "", "name of the database to restore into, defaults to the database the backup was created from")
+	cmd.Flags().String("hostname", "", "hostname of the database engine to restore (e.g. mysql-8.0-3306.database.nitro)")
+
+	return cmd
+}
+
+// listBackups returns the backup files in the directory with the newest backups first.
+func listBackups(dir string) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the backups in %s, %w", dir, err)
+	}
+
+	// sort the files by the modification time, newest first
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+
+	var backups []string
+	for _, f := range files {
+		// ignore directories and hidden files
+		if !f.Mode().IsRegular() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+
+		backups = append(backups, f.Name())
+	}
+
+	return backups, nil
+}
+
+// backupDatabaseName returns the name of the database from the backup file name, if the
+// file was not created by nitro an empty string is returned.
+func backupDatabaseName(file string) string {
+	matches := backupNameRegex.FindStringSubmatch(file)
+	if matches == nil {
+		return ""
+	}
+
+	return matches[1]
+}
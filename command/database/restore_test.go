@@ -0,0 +1,91 @@
+package database
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_listBackups(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]time.Duration{
+		"nitro-2021-01-02-080102.sql":   2 * time.Hour,
+		"nitro-2021-01-03-080102.sql":   time.Hour,
+		"project-2021-01-01-080102.sql": 3 * time.Hour,
+		".DS_Store":                     0,
+	}
+	for name, age := range files {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, []byte("-- backup"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		modified := time.Now().Add(-age)
+		if err := os.Chtimes(file, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := listBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"nitro-2021-01-03-080102.sql", "nitro-2021-01-02-080102.sql", "project-2021-01-01-080102.sql"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listBackups() = %v, want %v", got, want)
+	}
+
+	// a missing directory has no backups
+	got, err = listBackups(filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Errorf("expected no error for a missing directory, got %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no backups, got %v", got)
+	}
+}
+
+func Test_backupDatabaseName(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{
+			name: "backups created by nitro return the database",
+			file: "nitro-2021-01-02-080102.sql",
+			want: "nitro",
+		},
+		{
+			name: "databases with dashes are supported",
+			file: "my-project-2021-01-02-080102.sql",
+			want: "my-project",
+		},
+		{
+			name: "compressed backups return the database",
+			file: "nitro-2021-01-02-080102.sql.gz",
+			want: "nitro",
+		},
+		{
+			name: "other files return an empty string",
+			file: "backup.sql",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backupDatabaseName(tt.file); got != tt.want {
+				t.Errorf("backupDatabaseName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"sort"
@@ -64,25 +65,34 @@ func sshCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 
 			container := containerList[selected]
 
-			return containerConnect(output, container)
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			return containerConnect(ctx, output, container)
 		},
 	}
 
 	return cmd
 }
 
-func containerConnect(output terminal.Outputer, containerName string) error {
+func containerConnect(ctx context.Context, output terminal.Outputer, containerName string) error {
 	// find the docker executable
 	cli, err := exec.LookPath("docker")
 	if err != nil {
 		return err
 	}
 
-	c := exec.Command(cli, "exec", "-u", "root", "-it", containerName, "bash")
+	// the exec is killed when the command is interrupted
+	c := exec.CommandContext(ctx, cli, "exec", "-u", "root", "-it", containerName, "bash")
 
 	c.Stdin = os.Stdin
 	c.Stderr = os.Stderr
 	c.Stdout = os.Stdout
 
+	// restore the terminal in case the exec is killed while in raw mode
+	defer terminal.SaveState(c.Stdin)()
+
 	return c.Run()
 }
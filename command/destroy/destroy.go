@@ -17,8 +17,13 @@ import (
 	"github.com/craftcms/nitro/pkg/backup"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
-	"github.com/craftcms/nitro/pkg/datetime"
 	"github.com/craftcms/nitro/pkg/sudo"
+	"github.com/craftcms/nitro/pkg/svc/dynamodb"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
+	"github.com/craftcms/nitro/pkg/svc/mailhog"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
+	"github.com/craftcms/nitro/pkg/svc/minio"
+	"github.com/craftcms/nitro/pkg/svc/redis"
 	"github.com/craftcms/nitro/pkg/terminal"
 )
 
@@ -34,7 +39,10 @@ var (
 )
 
 const exampleText = `  # remove all resources (networks, containers, and volumes)
-  nitro destroy`
+  nitro destroy
+
+  # also remove the volumes shared by environments (e.g. minio)
+  nitro destroy --shared`
 
 // NewCommand is used to destroy all resources for an environment. It will prompt for
 // user verification and defaults to no. Part of the destroy process is to
@@ -86,6 +94,9 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				return err
 			}
 
+			// keep the shared volumes other environments use
+			volumes.Volumes = removableVolumes(volumes.Volumes, cmd.Flag("shared").Value.String() == "true")
+
 			// make sure there are volumes
 			if len(volumes.Volumes) == 0 {
 				output.Info(ErrNoVolumes.Error())
@@ -132,21 +143,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 						// backup each database
 						for _, db := range databases {
 							// create the database specific backup options
-							opts := &backup.Options{
-								BackupName:    fmt.Sprintf("%s-%s.sql", db, datetime.Parse(time.Now())),
-								ContainerID:   c.ID,
-								ContainerName: name,
-								Database:      db,
-								Home:          home,
-							}
-
-							// create the backup command based on the compatibility type
-							switch c.Labels[containerlabels.DatabaseCompatibility] {
-							case "postgres":
-								opts.Commands = []string{"pg_dump", "--username=nitro", db, "-f", "/tmp/" + opts.BackupName}
-							default:
-								opts.Commands = []string{"/usr/bin/mysqldump", "-h", "127.0.0.1", "-unitro", "--password=nitro", db, "--result-file=" + "/tmp/" + opts.BackupName}
-							}
+							opts := backup.NewOptions(home, c.ID, name, c.Labels[containerlabels.DatabaseCompatibility], db)
 
 							output.Pending("creating backup", opts.BackupName)
 
@@ -221,32 +218,34 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				}
 			}
 
-			// remove nitro hosts entries
-
-			// get the executable
-			nitro, err := os.Executable()
-			if err != nil {
-				return fmt.Errorf("unable to locate the nitro path, %w", err)
-			}
+			// remove nitro hosts entries, unless the config disables editing the hosts file
+			hosts := hostnames(cfg)
+			if len(hosts) > 0 && !cfg.Dnsmasq && (cfg.EditHosts == nil || *cfg.EditHosts) {
+				// get the executable
+				nitro, err := os.Executable()
+				if err != nil {
+					return fmt.Errorf("unable to locate the nitro path, %w", err)
+				}
 
-			// run the hosts command
-			switch runtime.GOOS {
-			case "windows":
-				// windows users should be running as admin, so just execute the hosts command as is
-				c := exec.Command(nitro, "hosts", "remove")
+				// run the hosts command
+				switch runtime.GOOS {
+				case "windows":
+					// windows users should be running as admin, so just execute the hosts command as is
+					c := exec.Command(nitro, "hosts", "--remove", "--hostnames="+strings.Join(hosts, ","))
 
-				c.Stdout = os.Stdout
-				c.Stderr = os.Stderr
+					c.Stdout = os.Stdout
+					c.Stderr = os.Stderr
 
-				if c.Run() != nil {
-					return err
-				}
-			default:
-				output.Info("Updating hosts file (you might be prompted for your password)")
+					if err := c.Run(); err != nil {
+						return err
+					}
+				default:
+					output.Info("Updating hosts file (you might be prompted for your password)")
 
-				// add the hosts
-				if err := sudo.Run(nitro, "nitro", "hosts", "remove"); err != nil {
-					return err
+					// remove the hosts
+					if err := sudo.Run(nitro, "nitro", "hosts", "--remove", "--hostnames="+strings.Join(hosts, ",")); err != nil {
+						return err
+					}
 				}
 			}
 
@@ -258,6 +257,70 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 
 	// add flags to the command
 	cmd.Flags().Bool("clean", false, "remove configuration file")
+	cmd.Flags().Bool("shared", false, "remove the volumes shared by environments")
 
 	return cmd
 }
+
+// removableVolumes returns the volumes to remove, shared volumes are only
+// removed when shared is true.
+func removableVolumes(volumes []*types.Volume, shared bool) []*types.Volume {
+	if shared {
+		return volumes
+	}
+
+	var removable []*types.Volume
+	for _, v := range volumes {
+		if containerlabels.IsSharedVolume(v.Labels) {
+			continue
+		}
+
+		removable = append(removable, v)
+	}
+
+	return removable
+}
+
+// hostnames returns all of the hostnames from the config that nitro adds to the hosts file.
+func hostnames(cfg *config.Config) []string {
+	var hosts []string
+	for _, s := range cfg.Sites {
+		hosts = append(hosts, s.GetHostsEntries()...)
+	}
+
+	for _, db := range cfg.Databases {
+		if h, err := db.GetHostname(); err == nil {
+			hosts = append(hosts, h)
+		}
+	}
+
+	if cfg.Services.DynamoDB {
+		hosts = append(hosts, dynamodb.Host)
+	}
+
+	if cfg.Services.Elasticsearch {
+		hosts = append(hosts, elasticsearch.Host)
+	}
+
+	if cfg.Services.Mailhog {
+		hosts = append(hosts, mailhog.Host)
+	}
+
+	if cfg.Services.Meilisearch {
+		hosts = append(hosts, meilisearch.Host)
+	}
+
+	if cfg.Services.Minio {
+		hosts = append(hosts, minio.Host)
+	}
+
+	if cfg.Services.Redis {
+		hosts = append(hosts, redis.Host)
+	}
+
+	for _, c := range cfg.Containers {
+		hosts = append(hosts, fmt.Sprintf("%s.containers.nitro", c.Name))
+	}
+
+	return hosts
+}
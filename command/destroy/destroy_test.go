@@ -0,0 +1,39 @@
+package destroy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+)
+
+func Test_removableVolumes(t *testing.T) {
+	mysql := &types.Volume{Name: "mysql_8.0_3306", Labels: map[string]string{containerlabels.Nitro: "true"}}
+	minio := &types.Volume{Name: "nitro_shared_minio", Labels: containerlabels.ForVolume("nitro_shared_minio", true)}
+	redis := &types.Volume{Name: "nitro_redis", Labels: containerlabels.ForVolume("nitro_redis", false)}
+
+	tests := []struct {
+		name   string
+		shared bool
+		want   []*types.Volume
+	}{
+		{
+			name: "shared volumes are kept",
+			want: []*types.Volume{mysql, redis},
+		},
+		{
+			name:   "shared volumes are removed when shared is true",
+			shared: true,
+			want:   []*types.Volume{mysql, minio, redis},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := removableVolumes([]*types.Volume{mysql, minio, redis}, tt.shared); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("removableVolumes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package dnsmasq
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/dnsmasq"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # create a dnsmasq config to resolve all .nitro hostnames
+  nitro dnsmasq
+
+  # write the dnsmasq config to a specific file
+  nitro dnsmasq --file /usr/local/etc/dnsmasq.d/nitro.conf`
+
+// NewCommand returns the command to generate a dnsmasq config fragment as an
+// alternative to editing the hosts file for each hostname.
+func NewCommand(home string, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dnsmasq",
+		Short:   "Generate a dnsmasq config",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			if file == "" {
+				file = filepath.Join(home, config.DirectoryName, "dnsmasq.conf")
+			}
+
+			abs, err := filepath.Abs(file)
+			if err != nil {
+				return err
+			}
+
+			// use the top level domain from the config
+			tld := dnsmasq.TLD
+			if cfg, err := config.Load(home); err == nil {
+				tld = cfg.GetTLD()
+			}
+
+			output.Pending("writing", abs)
+
+			if err := ioutil.WriteFile(abs, []byte(dnsmasq.Config(tld, "127.0.0.1")), 0644); err != nil {
+				output.Warning()
+				return fmt.Errorf("unable to write the dnsmasq config, %w", err)
+			}
+
+			output.Done()
+
+			steps := dnsmasq.Steps(runtime.GOOS, abs, tld)
+			if len(steps) == 0 {
+				output.Info(fmt.Sprintf("dnsmasq is not supported on %s, use `nitro hosts` instead", runtime.GOOS))
+				return nil
+			}
+
+			output.Info("To resolve all ." + tld + " hostnames with dnsmasq, run the following:")
+			for _, s := range steps {
+				output.Info("  " + s)
+			}
+
+			output.Info(`Then add "dnsmasq: true" to your config so apply stops editing the hosts file.`)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("file", "", "path to write the dnsmasq config")
+
+	return cmd
+}
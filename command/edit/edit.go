@@ -13,7 +13,9 @@ const exampleText = `  # edit the config file
   nitro edit`
 
 // NewCommand returns the command to edit a config file with the users default editor as defined by the
-// $EDITOR variable.
+// $EDITOR variable. A JSON schema for the config is written to ~/.nitro/schema.json and referenced from the
+// config file for editors that support the yaml language server. When apply is running, the editor is
+// opened once the apply is finished.
 func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "edit",
@@ -25,6 +27,16 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				return err
 			}
 
+			// write the schema and reference it so editors can validate the config
+			schema, err := config.WriteSchema(home)
+			if err != nil {
+				return err
+			}
+
+			if err := config.AddSchemaComment(cfg.GetFile(), schema); err != nil {
+				return err
+			}
+
 			_, err = editor.CaptureInputFromEditor(cfg.GetFile(), editor.GetPreferredEditorFromEnvironment)
 
 			return err
@@ -27,6 +27,9 @@ const exampleText = `  # enable services
   # enable minio for local s3 testing
   nitro enable minio
 
+  # enable meilisearch or elasticsearch for search
+  nitro enable meilisearch
+
   # enable dynamodb for local noSQL
   nitro enable dynamodb`
 
@@ -45,7 +48,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 
 			return nil
 		},
-		ValidArgs: []string{"dynamodb", "mailhog", "minio", "redis"},
+		ValidArgs: []string{"dynamodb", "elasticsearch", "mailhog", "meilisearch", "minio", "redis"},
 		Example:   exampleText,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// load the configuration
@@ -58,8 +61,12 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			switch args[0] {
 			case "dynamodb":
 				cfg.Services.DynamoDB = true
+			case "elasticsearch":
+				cfg.Services.Elasticsearch = true
 			case "mailhog":
 				cfg.Services.Mailhog = true
+			case "meilisearch":
+				cfg.Services.Meilisearch = true
 			case "minio":
 				cfg.Services.Minio = true
 			case "redis":
@@ -0,0 +1,80 @@
+package exec
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/siteexec"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # run a command in the site container for the current directory
+  nitro exec -- php craft migrate/all
+
+  # run a command in a specific site
+  nitro exec --hostname mysite.nitro -- ls -la
+
+  # run a command as root in a specific directory
+  nitro exec --root --workdir /app/storage -- chown -R www-data:www-data .`
+
+// NewCommand returns the exec command which runs any command in a sites container. The site
+// is found using the current directory or the hostname flag.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "exec -- COMMAND [ARGS...]",
+		Short:   "Run a command in a site",
+		Example: exampleText,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// load the config
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			hostname, _ := cmd.Flags().GetString("hostname")
+
+			site, err := siteexec.FindSite(cmd, home, hostname, cfg, output)
+			if err != nil {
+				return err
+			}
+
+			id, err := siteexec.Container(ctx, docker, site.Hostname)
+			if err != nil {
+				return err
+			}
+
+			opts := siteexec.Options{Cmd: args, WorkingDir: siteexec.WorkingDir(site)}
+
+			if wd, _ := cmd.Flags().GetString("workdir"); wd != "" {
+				opts.WorkingDir = wd
+			}
+
+			if root, _ := cmd.Flags().GetBool("root"); root {
+				opts.User = "root"
+			}
+
+			code, err := siteexec.Run(ctx, docker, id, opts, cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+
+			if code != 0 {
+				return fmt.Errorf("command exited with code %d", code)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("hostname", "", "hostname of the site to run the command in")
+	cmd.Flags().Bool("root", false, "run the command as the root user")
+	cmd.Flags().String("workdir", "", "working directory in the container (defaults to the site path)")
+
+	return cmd
+}
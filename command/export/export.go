@@ -0,0 +1,125 @@
+package export
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/bundle"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// DefaultFile is the file the environment is exported to when one is not provided.
+const DefaultFile = "nitro-export.yaml"
+
+const exampleText = `  # export the config for the environment to nitro-export.yaml
+  nitro export
+
+  # export the project config and database schemas to a file
+  nitro export my-project.yaml --config-source project --schemas
+
+  # include the blackfire credentials in the export
+  nitro export --include-secrets`
+
+// NewCommand returns the command to export the environment to a file that
+// can be shared and imported with nitro import.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "export [FILE]",
+		Short:   "Export the environment to a file",
+		Example: exampleText,
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			file := DefaultFile
+			if len(args) > 0 {
+				file = args[0]
+			}
+
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+
+			b, err := bundle.New(cfg, includeSecrets)
+			if err != nil {
+				return err
+			}
+
+			if schemas, _ := cmd.Flags().GetBool("schemas"); schemas {
+				for _, db := range cfg.Databases {
+					hostname, err := db.GetHostname()
+					if err != nil {
+						return err
+					}
+
+					containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: containerlabels.ByDatabase(db)})
+					if err != nil {
+						return fmt.Errorf("unable to find the database container for %s, %w", hostname, err)
+					}
+
+					if len(containers) == 0 {
+						output.Info("Skipping the schemas for", hostname, "because the container is not running")
+						continue
+					}
+
+					output.Pending("exporting schemas for", hostname)
+
+					compatibility := containerlabels.Compatibility(db.Engine)
+
+					databases, err := backup.Databases(ctx, docker, containers[0].ID, compatibility)
+					if err != nil {
+						return fmt.Errorf("unable to list the databases for %s, %w", hostname, err)
+					}
+
+					for _, database := range databases {
+						sql, err := backup.Schema(ctx, docker, containers[0].ID, compatibility, database)
+						if err != nil {
+							return err
+						}
+
+						b.AddSchema(hostname, database, sql)
+					}
+
+					output.Done()
+				}
+			}
+
+			f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+			if err != nil {
+				return fmt.Errorf("unable to create the export %s, %w", file, err)
+			}
+
+			if err := b.Write(f); err != nil {
+				f.Close()
+				return err
+			}
+
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("unable to write the export %s, %w", file, err)
+			}
+
+			output.Info("Exported the environment to", file)
+
+			for _, s := range b.Secrets {
+				output.Info("  the secret", s, "is not included, it can be set after the import with `nitro secret set "+s+"`")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("schemas", false, "include the database schemas, without any data")
+	cmd.Flags().Bool("include-secrets", false, "include the blackfire credentials instead of referencing a secret")
+
+	return cmd
+}
@@ -1,6 +1,7 @@
 package hosts
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -14,7 +15,10 @@ import (
 )
 
 const exampleText = `  # modify hosts file to match sites and aliases
-  nitro hosts`
+  nitro hosts
+
+  # remove hostnames from the hosts file
+  nitro hosts --remove --hostnames=mysite.nitro,othersite.nitro`
 
 // New returns a command used to modify the hosts file to point sites to the nitro proxy.
 func NewCommand(home string, output terminal.Outputer) *cobra.Command {
@@ -43,10 +47,36 @@ func NewCommand(home string, output terminal.Outputer) *cobra.Command {
 				defaultFile = `C:\Windows\System32\Drivers\etc\hosts`
 			}
 
-			// add the hosts
-			updated, err := hostedit.Update(defaultFile, "127.0.0.1", hostnames...)
-			if err != nil {
-				return err
+			remove, _ := cmd.Flags().GetBool("remove")
+
+			var (
+				updated string
+				err     error
+			)
+			switch remove {
+			case true:
+				// read the original file to see if there are changes
+				orig, err := ioutil.ReadFile(defaultFile)
+				if err != nil {
+					return err
+				}
+
+				// remove only the hosts from the nitro section
+				updated, err = hostedit.RemoveHosts(defaultFile, hostnames...)
+				if errors.Is(err, hostedit.ErrNotNitroEntries) || (err == nil && updated == string(orig)) {
+					output.Info("There are no entries to remove from the hosts file...")
+
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			default:
+				// add the hosts
+				updated, err = hostedit.Update(defaultFile, "127.0.0.1", hostnames...)
+				if err != nil {
+					return err
+				}
 			}
 
 			// if we are previewing, show the hosts file without saving
@@ -56,6 +86,10 @@ func NewCommand(home string, output terminal.Outputer) *cobra.Command {
 				output.Info(updated)
 
 				return nil
+			}
+
+			if remove {
+				output.Info("Removing sites from hosts file…")
 			} else {
 				output.Info("Adding sites to hosts file…")
 			}
@@ -83,6 +117,7 @@ func NewCommand(home string, output terminal.Outputer) *cobra.Command {
 	cmd.Flags().StringSlice("hostnames", nil, "list of hostnames to set")
 	cmd.MarkFlagRequired("hostnames")
 	cmd.Flags().Bool("preview", false, "preview hosts file change")
+	cmd.Flags().Bool("remove", false, "remove the hostnames from the hosts file")
 
 	cmd.AddCommand(removeCommand(home, output))
 
@@ -0,0 +1,135 @@
+package importenv
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/bundle"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/helpers"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # recreate the environment config from an export
+  nitro import nitro-export.yaml
+
+  # import to a project config in the current directory
+  nitro import nitro-export.yaml --config-source project
+
+  # overwrite the existing config without a prompt
+  nitro import nitro-export.yaml --force`
+
+// NewCommand returns the command to import an environment that was exported
+// with nitro export.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "import FILE",
+		Short:   "Import an exported environment",
+		Example: exampleText,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("unable to open the export %s, %w", args[0], err)
+			}
+			defer f.Close()
+
+			b, err := bundle.Read(f)
+			if err != nil {
+				return err
+			}
+
+			cfg := b.Config
+
+			// validate the config before writing it
+			if err := cfg.ValidateHooks(); err != nil {
+				return err
+			}
+
+			for _, s := range cfg.Sites {
+				if err := s.ValidateImage(); err != nil {
+					return err
+				}
+			}
+
+			for _, w := range cfg.Validate() {
+				output.Info("Warning:", w.Error())
+			}
+
+			file, err := destination(home)
+			if err != nil {
+				return err
+			}
+
+			// confirm before replacing an existing config
+			if stat, err := os.Stat(file); err == nil && stat.Size() > 0 {
+				if force, _ := cmd.Flags().GetBool("force"); !force {
+					confirm, err := output.Confirm(fmt.Sprintf("The config %s already exists, replace it with the import", file), false, "?")
+					if err != nil {
+						return err
+					}
+
+					if !confirm {
+						output.Info("Skipping the import…")
+						return nil
+					}
+				}
+			}
+
+			cfg.File = file
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("unable to save the config, %w", err)
+			}
+
+			output.Info("Imported the environment to", file)
+
+			// write the schemas so they can be imported into the databases
+			for _, s := range b.Schemas {
+				// the names come from the export, so they are not allowed to change the directory
+				dir := filepath.Join(home, config.DirectoryName, "schemas", filepath.Base(s.Engine))
+				if err := helpers.MkdirIfNotExists(dir); err != nil {
+					return err
+				}
+
+				path := filepath.Join(dir, filepath.Base(s.Database)+".sql")
+				if err := ioutil.WriteFile(path, []byte(s.SQL), 0644); err != nil {
+					return fmt.Errorf("unable to write the schema for %s, %w", s.Database, err)
+				}
+
+				output.Info("  the schema for", s.Database, "was saved to", path)
+			}
+
+			for _, s := range b.Secrets {
+				output.Info("  set the secret", s, "with `nitro secret set "+s+"`")
+			}
+
+			output.Info("Run `nitro apply` to create the environment")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("force", false, "replace the existing config without a prompt")
+
+	return cmd
+}
+
+// destination returns the config file to import to, which is the project
+// config in the current directory when the config source is project.
+func destination(home string) (string, error) {
+	if config.Source != config.SourceProject {
+		return filepath.Join(home, config.DirectoryName, config.FileName), nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("unable to get the current directory, %w", err)
+	}
+
+	return filepath.Join(wd, config.FileName), nil
+}
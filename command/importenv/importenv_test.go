@@ -0,0 +1,132 @@
+package importenv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/craftcms/nitro/command/export"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/secrets"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const testConfig = `blackfire:
+  server_id: my-server-id
+  server_token: ${BLACKFIRE_SERVER_TOKEN:-token}
+databases:
+  - engine: mysql
+    version: "8.0"
+    port: "3306"
+sites:
+  - hostname: craft-dev.nitro
+    path: ~/dev/craft-dev
+    version: "7.4"
+    webroot: web
+`
+
+func TestRoundTrip(t *testing.T) {
+	// Arrange
+	source := config.Source
+	config.Source = config.SourceHome
+	defer func() { config.Source = source }()
+
+	// the importing user sets the secret that is not exported
+	store := config.SecretStore
+	config.SecretStore = func() (secrets.Store, error) {
+		return secrets.Memory{"blackfire.server_id": "their-server-id"}, nil
+	}
+	defer func() { config.SecretStore = store }()
+
+	exporter, importer := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(exporter, config.DirectoryName), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(exporter, config.DirectoryName, config.FileName), []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "export.yaml")
+	docker := dockertest.New(nil, nil)
+
+	// Act
+	exp := export.NewCommand(exporter, docker, terminal.New())
+	exp.SetArgs([]string{file})
+	if err := exp.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	imp := NewCommand(importer, docker, terminal.New())
+	imp.SetArgs([]string{file, "--force"})
+	if err := imp.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	want, err := config.Load(exporter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := config.Load(importer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got.Sites, want.Sites) || !reflect.DeepEqual(got.Databases, want.Databases) {
+		t.Errorf("expected the imported config to match the export, got %+v", got)
+	}
+
+	if got.Blackfire.ServerID != "their-server-id" {
+		t.Errorf("expected the server id to use the importers secret, got %q", got.Blackfire.ServerID)
+	}
+
+	if got.Blackfire.ServerToken != "token" {
+		t.Errorf("expected the server token reference to be kept, got %q", got.Blackfire.ServerToken)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(importer, config.DirectoryName, config.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(b), "my-server-id") || !strings.Contains(string(b), "${secret:blackfire.server_id}") {
+		t.Errorf("expected the server id to reference a secret, got:\n%s", b)
+	}
+}
+
+func TestImport_InvalidConfig(t *testing.T) {
+	// Arrange
+	home := t.TempDir()
+	file := filepath.Join(t.TempDir(), "export.yaml")
+	export := `version: 1
+config:
+  sites:
+    - hostname: craft-dev.nitro
+      image: registry.example.com/team/php:7.4
+      dockerfile: Dockerfile
+`
+	if err := ioutil.WriteFile(file, []byte(export), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCommand(home, dockertest.New(nil, nil), terminal.New())
+	cmd.SetArgs([]string{file, "--force"})
+
+	// Act
+	err := cmd.Execute()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "both an image and a Dockerfile") {
+		t.Errorf("expected the import to be invalid, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(home, config.DirectoryName, config.FileName)); !os.IsNotExist(err) {
+		t.Errorf("expected the config to not be written, got %v", err)
+	}
+}
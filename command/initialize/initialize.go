@@ -19,7 +19,13 @@ import (
 )
 
 const exampleText = `  # setup nitro
-  nitro init`
+  nitro init
+
+  # setup nitro with only a mysql database
+  nitro init --database mysql
+
+  # setup nitro without a postgres database
+  nitro init --no-postgres`
 
 var skipApply, skipTrust bool
 
@@ -30,14 +36,6 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 		Short:         "Setup nitro",
 		Example:       exampleText,
 		SilenceErrors: false,
-		PreRunE: func(cmd *cobra.Command, args []string) error {
-			// is the docker api alive?
-			if _, err := docker.Ping(cmd.Context()); err != nil {
-				return fmt.Errorf("Couldn’t connect to Docker; please make sure Docker is running.")
-			}
-
-			return nil
-		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			if ctx == nil {
@@ -45,12 +43,28 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			}
 
 			// check if there is a config file
-			_, err := config.Load(home)
+			cfg, err := config.Load(home)
 			if errors.Is(err, config.ErrNoConfigFile) {
 				// walk the user through the first time setup
-				if err := setup.FirstTime(home, cmd.InOrStdin(), output); err != nil {
+				databases, _ := cmd.Flags().GetStringSlice("database")
+				noPostgres, _ := cmd.Flags().GetBool("no-postgres")
+
+				if err := setup.FirstTime(home, cmd.InOrStdin(), setup.Options{Databases: databases, NoPostgres: noPostgres}, output); err != nil {
 					return err
 				}
+
+				// load the new config
+				cfg, _ = config.Load(home)
+			}
+
+			// get the proxy settings from the config
+			var proxy config.Proxy
+			var registry string
+			bindAddress := config.DefaultBindAddress
+			if cfg != nil {
+				proxy = cfg.Proxy
+				bindAddress = cfg.GetBindAddress()
+				registry = cfg.Registry
 			}
 
 			output.Info("Checking Nitro…")
@@ -102,7 +116,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			}
 
 			// create the proxy container
-			if err := proxycontainer.Create(cmd.Context(), docker, output, networkID); err != nil {
+			if err := proxycontainer.Create(cmd.Context(), docker, output, proxycontainer.ProxyName, networkID, proxy, bindAddress, registry); err != nil {
 				return err
 			}
 
@@ -136,6 +150,8 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 	// set flags for the command
 	cmd.Flags().BoolVar(&skipApply, "skip-apply", false, "skip applying changes")
 	cmd.Flags().BoolVar(&skipTrust, "skip-trust", false, "skip trusting the root certificate")
+	cmd.Flags().StringSlice("database", nil, "only add the database engines during the first time setup (mysql, mariadb, or postgres)")
+	cmd.Flags().Bool("no-postgres", false, "do not add a postgres database during the first time setup")
 
 	return cmd
 }
@@ -43,6 +43,10 @@ func (spy spyOutputer) Warning() {
 
 }
 
+func (spy spyOutputer) Record(r terminal.Record) {
+
+}
+
 func (spy spyOutputer) Success(s ...string) {
 	fmt.Printf("  \u2713 %s\n", strings.Join(s, " "))
 }
@@ -0,0 +1,123 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// accessLog is an access log line from the proxy (caddy), only the
+// fields that are shown are decoded.
+type accessLog struct {
+	Request struct {
+		Method string `json:"method"`
+		Host   string `json:"host"`
+		URI    string `json:"uri"`
+	} `json:"request"`
+	Status   int     `json:"status"`
+	Duration float64 `json:"duration"`
+}
+
+// formatAccessLog takes a line from the proxy logs and returns the line
+// as METHOD host path -> status (duration), whether the line is an access
+// log, and whether the line should be shown. Lines that are not access logs
+// are returned as is. When host is not empty, access logs for other hosts
+// are not shown.
+func formatAccessLog(line, host string) (string, bool, bool) {
+	// remove the timestamp added by the --timestamps flag
+	var timestamp string
+	if i := strings.IndexByte(line, ' '); i > 0 {
+		if _, err := time.Parse(time.RFC3339Nano, line[:i]); err == nil {
+			timestamp, line = line[:i]+" ", line[i+1:]
+		}
+	}
+
+	entry := accessLog{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Request.Method == "" {
+		return timestamp + line, false, true
+	}
+
+	// remove the port from the host
+	requestHost := entry.Request.Host
+	if h, _, err := net.SplitHostPort(requestHost); err == nil {
+		requestHost = h
+	}
+
+	if host != "" && !strings.EqualFold(host, requestHost) {
+		return "", true, false
+	}
+
+	duration := time.Duration(entry.Duration * float64(time.Second))
+	if duration < time.Millisecond {
+		duration = duration.Round(time.Microsecond)
+	} else {
+		duration = duration.Round(time.Millisecond)
+	}
+
+	return fmt.Sprintf("%s%-7s %s %s -> %d (%s)", timestamp, entry.Request.Method, requestHost, entry.Request.URI, entry.Status, duration), true, true
+}
+
+// accessWriter buffers the proxy logs and writes each complete line using
+// formatAccessLog. Access logs are written to out, other lines are written
+// to raw (e.g. stderr).
+type accessWriter struct {
+	host string
+	out  io.Writer
+	raw  io.Writer
+	buf  []byte
+}
+
+func newAccessWriter(host string, out, raw io.Writer) *accessWriter {
+	return &accessWriter{host: host, out: out, raw: raw}
+}
+
+func (a *accessWriter) Write(b []byte) (int, error) {
+	a.buf = append(a.buf, b...)
+
+	for {
+		i := bytes.IndexByte(a.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		if err := a.writeLine(string(a.buf[:i])); err != nil {
+			return 0, err
+		}
+
+		a.buf = a.buf[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// Flush writes any remaining output that did not end with a new line.
+func (a *accessWriter) Flush() error {
+	if len(a.buf) == 0 {
+		return nil
+	}
+
+	err := a.writeLine(string(a.buf))
+	a.buf = nil
+
+	return err
+}
+
+func (a *accessWriter) writeLine(line string) error {
+	formatted, access, show := formatAccessLog(strings.TrimRight(line, "\r"), a.host)
+	if !show {
+		return nil
+	}
+
+	w := a.raw
+	if access {
+		w = a.out
+	}
+
+	_, err := fmt.Fprintln(w, formatted)
+
+	return err
+}
@@ -0,0 +1,109 @@
+package logs
+
+import (
+	"bytes"
+	"testing"
+)
+
+const accessLine = `{"level":"info","ts":1612345678.123,"logger":"http.log.access","msg":"handled request","request":{"remote_addr":"172.18.0.1:53412","proto":"HTTP/2.0","method":"GET","host":"craft-dev.nitro","uri":"/admin/dashboard","headers":{}},"duration":0.0421,"size":5120,"status":200,"resp_headers":{}}`
+
+func Test_formatAccessLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		host       string
+		want       string
+		wantAccess bool
+		wantShow   bool
+	}{
+		{
+			name:       "access logs are formatted",
+			line:       accessLine,
+			want:       "GET     craft-dev.nitro /admin/dashboard -> 200 (42ms)",
+			wantAccess: true,
+			wantShow:   true,
+		},
+		{
+			name:       "hosts with ports and fast requests are formatted",
+			line:       `{"msg":"handled request","request":{"method":"POST","host":"craft-dev.nitro:443","uri":"/index.php?p=actions"},"duration":0.000250,"status":302}`,
+			want:       "POST    craft-dev.nitro /index.php?p=actions -> 302 (250µs)",
+			wantAccess: true,
+			wantShow:   true,
+		},
+		{
+			name:       "access logs matching the host are shown",
+			line:       accessLine,
+			host:       "CRAFT-DEV.nitro",
+			want:       "GET     craft-dev.nitro /admin/dashboard -> 200 (42ms)",
+			wantAccess: true,
+			wantShow:   true,
+		},
+		{
+			name:       "access logs for other hosts are hidden",
+			line:       accessLine,
+			host:       "other.nitro",
+			want:       "",
+			wantAccess: true,
+			wantShow:   false,
+		},
+		{
+			name:       "timestamps are kept",
+			line:       "2021-02-03T10:01:18.123456789Z " + accessLine,
+			want:       "2021-02-03T10:01:18.123456789Z GET     craft-dev.nitro /admin/dashboard -> 200 (42ms)",
+			wantAccess: true,
+			wantShow:   true,
+		},
+		{
+			name:     "other json logs are returned as is",
+			line:     `{"level":"info","msg":"serving initial configuration"}`,
+			host:     "craft-dev.nitro",
+			want:     `{"level":"info","msg":"serving initial configuration"}`,
+			wantShow: true,
+		},
+		{
+			name:     "lines that are not json are returned as is",
+			line:     "2021/02/03 10:01:18 starting nitrod",
+			want:     "2021/02/03 10:01:18 starting nitrod",
+			wantShow: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, access, show := formatAccessLog(tt.line, tt.host)
+			if got != tt.want {
+				t.Errorf("formatAccessLog() got = %q, want %q", got, tt.want)
+			}
+			if access != tt.wantAccess {
+				t.Errorf("formatAccessLog() access = %v, want %v", access, tt.wantAccess)
+			}
+			if show != tt.wantShow {
+				t.Errorf("formatAccessLog() show = %v, want %v", show, tt.wantShow)
+			}
+		})
+	}
+}
+
+func Test_accessWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	raw := &bytes.Buffer{}
+	w := newAccessWriter("", out, raw)
+
+	// write partial lines to make sure they are buffered
+	if _, err := w.Write([]byte(accessLine[:40])); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(accessLine[40:] + "\nstarting nitrod")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "GET     craft-dev.nitro /admin/dashboard -> 200 (42ms)\n"; out.String() != want {
+		t.Errorf("expected the access log to be formatted, got %q want %q", out.String(), want)
+	}
+
+	if want := "starting nitrod\n"; raw.String() != want {
+		t.Errorf("expected the raw line to be written as is, got %q want %q", raw.String(), want)
+	}
+}
@@ -0,0 +1,177 @@
+package logs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/craftcms/nitro/command/version"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/redact"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// supportBundle is a zip file with everything needed to diagnose a problem,
+// each file is redacted before it is added.
+type supportBundle struct {
+	zw *zip.Writer
+
+	// problems are the parts that could not be collected
+	problems []string
+}
+
+// add redacts the content and adds it to the bundle.
+func (b *supportBundle) add(name string, content []byte) error {
+	w, err := b.zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("unable to add %s to the support bundle, %w", name, err)
+	}
+
+	if _, err := w.Write([]byte(redact.Text(string(content)))); err != nil {
+		return fmt.Errorf("unable to write %s to the support bundle, %w", name, err)
+	}
+
+	return nil
+}
+
+// addJSON adds the value to the bundle as indented JSON.
+func (b *supportBundle) addJSON(name string, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s for the support bundle, %w", name, err)
+	}
+
+	return b.add(name, content)
+}
+
+// problem records a part that could not be collected, so the rest of the
+// bundle is still exported when docker or the config has a problem.
+func (b *supportBundle) problem(part string, err error) {
+	b.problems = append(b.problems, fmt.Sprintf("%s: %s", part, err))
+}
+
+// export writes a support bundle to the file with the nitro version, the config,
+// the docker version and info, and the details and last lines of the logs for
+// each container in the environment. Secrets are redacted from every file.
+func export(ctx context.Context, docker client.CommonAPIClient, home, file string, lines int, output terminal.Outputer) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("unable to create the support bundle, %w", err)
+	}
+	defer f.Close()
+
+	b := &supportBundle{zw: zip.NewWriter(f)}
+
+	output.Info("Creating support bundle…")
+
+	if err := b.add("nitro.txt", []byte(fmt.Sprintf("version: %s\nos: %s\narch: %s\n", version.Version, runtime.GOOS, runtime.GOARCH))); err != nil {
+		return err
+	}
+
+	output.Pending("collecting config")
+
+	// the config is marshalled with the environment variable references instead of the values
+	switch cfg, err := config.Load(home); err {
+	case nil:
+		content, err := cfg.Marshal()
+		if err != nil {
+			b.problem("config", err)
+			break
+		}
+
+		if err := b.add("config.yaml", content); err != nil {
+			return err
+		}
+	default:
+		b.problem("config", err)
+	}
+
+	output.Done()
+
+	output.Pending("collecting docker version and info")
+
+	if v, err := docker.ServerVersion(ctx); err != nil {
+		b.problem("docker version", err)
+	} else if err := b.addJSON("docker/version.json", v); err != nil {
+		return err
+	}
+
+	if info, err := docker.Info(ctx); err != nil {
+		b.problem("docker info", err)
+	} else if err := b.addJSON("docker/info.json", info); err != nil {
+		return err
+	}
+
+	output.Done()
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: containerlabels.Environment()})
+	if err != nil {
+		b.problem("containers", err)
+	}
+
+	if err := b.addJSON("containers.json", containers); err != nil {
+		return err
+	}
+
+	for _, c := range containers {
+		name := strings.TrimLeft(c.Names[0], "/")
+
+		output.Pending("collecting", name)
+
+		if details, err := docker.ContainerInspect(ctx, c.ID); err != nil {
+			b.problem(name+" inspect", err)
+		} else {
+			if details.Config != nil {
+				details.Config.Env = redact.Env(details.Config.Env)
+			}
+
+			if err := b.addJSON("inspect/"+name+".json", details); err != nil {
+				return err
+			}
+		}
+
+		rdr, err := docker.ContainerLogs(ctx, c.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true, Tail: strconv.Itoa(lines)})
+		if err != nil {
+			b.problem(name+" logs", err)
+			output.Warning()
+			continue
+		}
+
+		buf := new(bytes.Buffer)
+		if _, err := stdcopy.StdCopy(buf, buf, rdr); err != nil {
+			b.problem(name+" logs", err)
+		}
+		rdr.Close()
+
+		if err := b.add("logs/"+name+".log", buf.Bytes()); err != nil {
+			return err
+		}
+
+		output.Done()
+	}
+
+	if len(b.problems) > 0 {
+		if err := b.add("errors.txt", []byte(strings.Join(b.problems, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+
+	if err := b.zw.Close(); err != nil {
+		return fmt.Errorf("unable to write the support bundle, %w", err)
+	}
+
+	output.Info("Saved the support bundle to", file, "(secrets are redacted, check the files before sharing them)")
+
+	return nil
+}
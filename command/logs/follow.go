@@ -0,0 +1,110 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var (
+	// reconnectTimeout is how long to wait for a container to start again
+	// (e.g. when apply recreates it) before no longer following the logs
+	reconnectTimeout = 30 * time.Second
+
+	// reconnectInterval is how often to check if the container started again
+	reconnectInterval = time.Second
+
+	// errContainerGone is returned when the container did not start again before the timeout
+	errContainerGone = errors.New("the container did not start again")
+)
+
+// finder returns the running containers to show the logs for.
+type finder func(ctx context.Context) ([]types.Container, error)
+
+// streamLogs shows the logs for the first container returned by find using write.
+// When the logs are followed and the stream ends because the container stopped
+// or was recreated (e.g. by apply), it waits for the container to be running
+// again and reconnects to the logs of the container. Only the logs since the
+// stream ended are shown after reconnecting.
+func streamLogs(ctx context.Context, docker client.ContainerAPIClient, find finder, opts types.ContainerLogsOptions, write func(io.Reader) error, output terminal.Outputer) error {
+	containers, err := find(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(containers) == 0 {
+		return fmt.Errorf("unable to find a container to show logs for")
+	}
+
+	id := containers[0].ID
+	for {
+		out, err := docker.ContainerLogs(ctx, id, opts)
+		if err != nil {
+			return err
+		}
+
+		err = write(out)
+		out.Close()
+
+		switch {
+		case ctx.Err() != nil:
+			// the command was interrupted
+			return nil
+		case err != nil:
+			return err
+		case !opts.Follow:
+			return nil
+		}
+
+		// don't show the logs from before the stream ended again
+		ended := time.Now()
+		opts.Since = fmt.Sprintf("%d.%09d", ended.Unix(), ended.Nanosecond())
+
+		output.Info("the container stopped, waiting for it to start again…")
+
+		id, err = waitForContainer(ctx, find)
+		switch {
+		case ctx.Err() != nil:
+			// the command was interrupted while waiting
+			return nil
+		case errors.Is(err, errContainerGone):
+			output.Info("the container did not start again, no longer following the logs")
+
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+}
+
+// waitForContainer checks for a running container until one is found, the
+// context is done, or the reconnect timeout is reached.
+func waitForContainer(ctx context.Context, find finder) (string, error) {
+	deadline := time.After(reconnectTimeout)
+
+	for {
+		containers, err := find(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		if len(containers) > 0 {
+			return containers[0].ID, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-deadline:
+			return "", errContainerGone
+		case <-time.After(reconnectInterval):
+		}
+	}
+}
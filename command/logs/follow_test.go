@@ -0,0 +1,123 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+func Test_streamLogs(t *testing.T) {
+	// Arrange
+	defer func(timeout, interval time.Duration) {
+		reconnectTimeout, reconnectInterval = timeout, interval
+	}(reconnectTimeout, reconnectInterval)
+	reconnectTimeout, reconnectInterval = 50*time.Millisecond, time.Millisecond
+
+	old := types.Container{ID: "old", Names: []string{"/craft-dev.nitro"}}
+	recreated := types.Container{ID: "recreated", Names: []string{"/craft-dev.nitro"}}
+
+	tests := []struct {
+		name    string
+		follow  bool
+		found   [][]types.Container
+		wantIDs []string
+	}{
+		{
+			name:    "logs are shown once when not following",
+			found:   [][]types.Container{{old}},
+			wantIDs: []string{"old"},
+		},
+		{
+			name:    "logs reconnect to a recreated container",
+			follow:  true,
+			found:   [][]types.Container{{old}, nil, nil, {recreated}},
+			wantIDs: []string{"old", "recreated"},
+		},
+		{
+			name:    "logs reconnect to a restarted container",
+			follow:  true,
+			found:   [][]types.Container{{old}, {old}},
+			wantIDs: []string{"old", "old"},
+		},
+		{
+			name:    "logs stop following when the container does not start again",
+			follow:  true,
+			found:   [][]types.Container{{old}},
+			wantIDs: []string{"old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docker := dockertest.New(nil, nil)
+			docker.LogsOutput = "GET /index.php 200\n"
+
+			// return the containers in order, then no containers so the logs stop following
+			calls := 0
+			find := func(ctx context.Context) ([]types.Container, error) {
+				defer func() { calls++ }()
+
+				if calls < len(tt.found) {
+					return tt.found[calls], nil
+				}
+
+				return nil, nil
+			}
+
+			buf := &bytes.Buffer{}
+			write := func(out io.Reader) error {
+				_, err := stdcopy.StdCopy(buf, buf, out)
+
+				return err
+			}
+
+			// Act
+			err := streamLogs(context.Background(), docker, find, types.ContainerLogsOptions{Follow: tt.follow}, write, quietOutputer{})
+
+			// Assert
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logs := docker.Calls("ContainerLogs")
+			if len(logs) != len(tt.wantIDs) {
+				t.Fatalf("expected %d log streams, got %d", len(tt.wantIDs), len(logs))
+			}
+
+			for i, c := range logs {
+				if c.Args[0] != tt.wantIDs[i] {
+					t.Errorf("expected log stream %d to be for %s, got %v", i, tt.wantIDs[i], c.Args[0])
+				}
+
+				// only the first stream shows the previous logs
+				opts := c.Args[1].(types.ContainerLogsOptions)
+				if (i > 0) != (opts.Since != "") {
+					t.Errorf("expected log stream %d to only show the logs since reconnecting, got since %q", i, opts.Since)
+				}
+			}
+
+			if want := len(tt.wantIDs) * len(docker.LogsOutput); buf.Len() != want {
+				t.Errorf("expected each stream to be written, got %q", buf.String())
+			}
+		})
+	}
+}
+
+func Test_streamLogs_MissingContainer(t *testing.T) {
+	docker := dockertest.New(nil, nil)
+
+	find := func(ctx context.Context) ([]types.Container, error) {
+		return nil, nil
+	}
+
+	err := streamLogs(context.Background(), docker, find, types.ContainerLogsOptions{Follow: true}, func(io.Reader) error { return nil }, quietOutputer{})
+	if err == nil {
+		t.Error("expected an error when there is no container")
+	}
+}
@@ -1,8 +1,12 @@
 package logs
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
@@ -22,7 +26,28 @@ const exampleText = `  # show logs from a site
   nitro logs --since 5m
 
   # show logs but don't follow
-  nitro logs --follow=false`
+  nitro logs --follow=false
+
+  # show logs from a database engine
+  nitro logs --database mysql-8.0-3306.database.nitro
+
+  # show logs from a service
+  nitro logs --service mailhog
+
+  # show logs from the proxy
+  nitro logs --proxy
+
+  # show the requests to the proxy as METHOD host path -> status (duration)
+  nitro logs --proxy --access
+
+  # show only the requests for a site
+  nitro logs --proxy --host craft-dev.nitro
+
+  # show logs from every container
+  nitro logs --all
+
+  # save the config, docker info, containers, and logs to a zip file for a bug report
+  nitro logs --export nitro-support.zip`
 
 // NewCommand returns the command to show a containers logs. It will check if the current working
 // directory is a known site and default to that container or provide the user with a list of sites
@@ -34,98 +59,181 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 		Short:   "View container logs",
 		Example: exampleText,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// get the current working directory
-			wd, err := os.Getwd()
+			// create a support bundle instead of showing the logs
+			if file := cmd.Flag("export").Value.String(); file != "" {
+				lines, _ := cmd.Flags().GetInt("lines")
+
+				return export(cmd.Context(), docker, home, file, lines, output)
+			}
+
+			// set the options for logging based on the command flags
+			opts := types.ContainerLogsOptions{
+				ShowStdout: true,
+				ShowStderr: true,
+			}
+
+			// parse the flags
+			timestamps, err := strconv.ParseBool(cmd.Flag("timestamps").Value.String())
 			if err != nil {
-				return err
+				timestamps = false
 			}
+			opts.Timestamps = timestamps
 
-			// load the config
-			cfg, err := config.Load(home)
+			follow, err := strconv.ParseBool(cmd.Flag("follow").Value.String())
 			if err != nil {
-				return err
+				follow = true
+			}
+			opts.Follow = follow
+
+			if cmd.Flag("since").Value.String() != "" {
+				opts.Since = cmd.Flag("since").Value.String()
 			}
 
 			// create a filter for the environment
 			filter := filters.NewArgs()
 			filter.Add("label", containerlabels.Nitro)
 
-			// get a context aware list of sites
-			sites := cfg.ListOfSitesByDirectory(home, wd)
+			database := cmd.Flag("database").Value.String()
+			service := cmd.Flag("service").Value.String()
+			proxy, _ := strconv.ParseBool(cmd.Flag("proxy").Value.String())
+			all, _ := strconv.ParseBool(cmd.Flag("all").Value.String())
 
-			// create the options for the sites
-			var options []string
-			for _, s := range sites {
-				options = append(options, s.Hostname)
+			// filtering by host formats the access logs
+			host := cmd.Flag("host").Value.String()
+			access, _ := strconv.ParseBool(cmd.Flag("access").Value.String())
+			if host != "" {
+				access = true
 			}
 
-			switch len(sites) {
-			case 0:
-				selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
+			if access && !proxy {
+				return fmt.Errorf("the --access and --host flags can only be used with --proxy")
+			}
+
+			switch {
+			case all:
+				// find all of the containers for the environment
+				containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter})
 				if err != nil {
 					return err
 				}
 
-				filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
-			case 1:
-				output.Info("show logs for", sites[0].Hostname)
+				if len(containers) == 0 {
+					return fmt.Errorf("unable to find any containers")
+				}
 
-				filter.Add("label", containerlabels.Host+"="+sites[0].Hostname)
+				return multiplex(cmd, docker, containers, opts)
+			case proxy:
+				filter.Add("label", containerlabels.Type+"=proxy")
+			case service != "":
+				filter.Add("label", containerlabels.Type+"="+service)
+			case database != "":
+				filter.Add("label", containerlabels.Type+"=database")
 			default:
-				selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
+				// get the current working directory
+				wd, err := os.Getwd()
 				if err != nil {
 					return err
 				}
 
-				filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
-			}
+				// load the config
+				cfg, err := config.Load(home)
+				if err != nil {
+					return err
+				}
 
-			// find all of the containers, there should only be one if we are in a known directory
-			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter})
-			if err != nil {
-				return err
-			}
+				// get a context aware list of sites
+				sites := cfg.ListOfSitesByDirectory(home, wd)
 
-			// set the options for logging based on the command flags
-			opts := types.ContainerLogsOptions{
-				ShowStdout: true,
-				ShowStderr: true,
-			}
+				// create the options for the sites
+				var options []string
+				for _, s := range sites {
+					options = append(options, s.Hostname)
+				}
 
-			// parse the flags
-			timestamps, err := strconv.ParseBool(cmd.Flag("timestamps").Value.String())
-			if err != nil {
-				timestamps = false
+				switch len(sites) {
+				case 0:
+					selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
+					if err != nil {
+						return err
+					}
+
+					filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
+				case 1:
+					output.Info("show logs for", sites[0].Hostname)
+
+					filter.Add("label", containerlabels.Host+"="+sites[0].Hostname)
+				default:
+					selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
+					if err != nil {
+						return err
+					}
+
+					filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
+				}
 			}
-			opts.Timestamps = timestamps
 
-			follow, err := strconv.ParseBool(cmd.Flag("follow").Value.String())
-			if err != nil {
-				follow = true
-			}
-			opts.Follow = follow
+			// find all of the containers, there should only be one if we are in a known directory. The
+			// containers are found again when following the logs and the container is recreated.
+			find := func(ctx context.Context) ([]types.Container, error) {
+				containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter})
+				if err != nil {
+					return nil, err
+				}
 
-			if cmd.Flag("since").Value.String() != "" {
-				opts.Since = cmd.Flag("since").Value.String()
+				// if a database was requested, find the container by name
+				if database != "" && !proxy && service == "" {
+					var matched []types.Container
+					for _, c := range containers {
+						if strings.HasPrefix(strings.TrimLeft(c.Names[0], "/"), database) {
+							matched = append(matched, c)
+						}
+					}
+
+					containers = matched
+				}
+
+				return containers, nil
 			}
 
-			// get the containers logs
-			out, err := docker.ContainerLogs(cmd.Context(), containers[0].ID, opts)
-			if err != nil {
+			// show the output
+			write := func(out io.Reader) error {
+				_, err := stdcopy.StdCopy(cmd.OutOrStdout(), cmd.ErrOrStderr(), out)
+
 				return err
 			}
 
-			// show the output
-			stdcopy.StdCopy(cmd.OutOrStdout(), cmd.ErrOrStderr(), out)
+			// format the proxy access logs, caddy writes the logs to stderr
+			if access {
+				write = func(out io.Reader) error {
+					o := newAccessWriter(host, cmd.OutOrStdout(), cmd.OutOrStdout())
+					e := newAccessWriter(host, cmd.OutOrStdout(), cmd.ErrOrStderr())
+
+					_, err := stdcopy.StdCopy(o, e, out)
 
-			return nil
+					o.Flush()
+					e.Flush()
+
+					return err
+				}
+			}
+
+			// get the containers logs
+			return streamLogs(cmd.Context(), docker, find, opts, write, output)
 		},
 	}
 
 	// set flags for the command
-	cmd.Flags().Bool("follow", true, "follow log output")
+	cmd.Flags().Bool("follow", true, "follow log output and reconnect when the container restarts")
 	cmd.Flags().Bool("timestamps", false, "show timestamps")
 	cmd.Flags().String("since", "", "Show logs since timestamp (e.g. 2013-01-02T13:23:37Z) or relative (e.g. 42m for 42 minutes)")
+	cmd.Flags().String("database", "", "show logs for a database engine (e.g. mysql-8.0-3306.database.nitro)")
+	cmd.Flags().String("service", "", "show logs for a service (e.g. mailhog, redis)")
+	cmd.Flags().Bool("proxy", false, "show logs for the proxy")
+	cmd.Flags().Bool("all", false, "show logs for every container")
+	cmd.Flags().Bool("access", false, "show the proxy access logs as METHOD host path -> status (duration)")
+	cmd.Flags().String("host", "", "show only the proxy access logs for a hostname (e.g. craft-dev.nitro)")
+	cmd.Flags().String("export", "", "save a support bundle with the config, docker info, containers, and logs to a zip file")
+	cmd.Flags().Int("lines", 500, "number of log lines to export for each container")
 
 	return cmd
 }
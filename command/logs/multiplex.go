@@ -0,0 +1,133 @@
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/spf13/cobra"
+)
+
+// colors are the ANSI color codes used to prefix each containers log lines
+var colors = []int{36, 33, 32, 35, 34, 31}
+
+// syncWriter is used to safely write log lines from multiple
+// containers to a single writer.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.Write(p)
+}
+
+// prefixWriter buffers the output from a container and writes each
+// complete line with the containers prefix to the shared writer.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	buf    []byte
+}
+
+// newPrefixWriter takes the name of the container, the padding to align
+// names, the index used to pick a color, and the shared writer.
+func newPrefixWriter(name string, pad, index int, out io.Writer) *prefixWriter {
+	color := colors[index%len(colors)]
+
+	return &prefixWriter{
+		prefix: fmt.Sprintf("\x1b[%dm%s |\x1b[0m ", color, name+strings.Repeat(" ", pad-len(name))),
+		out:    out,
+	}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		if _, err := p.out.Write([]byte(p.prefix + string(p.buf[:i+1]))); err != nil {
+			return 0, err
+		}
+
+		p.buf = p.buf[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// Flush writes any remaining output that did not end with a new line.
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+
+	_, err := p.out.Write([]byte(p.prefix + string(p.buf) + "\n"))
+	p.buf = nil
+
+	return err
+}
+
+// multiplex shows the logs from multiple containers at once. Each container
+// is read in its own goroutine and every line is prefixed with the container name.
+func multiplex(cmd *cobra.Command, docker client.CommonAPIClient, containers []types.Container, opts types.ContainerLogsOptions) error {
+	// find the longest name to align the prefixes
+	pad := 0
+	for _, c := range containers {
+		if n := len(strings.TrimLeft(c.Names[0], "/")); n > pad {
+			pad = n
+		}
+	}
+
+	stdout := &syncWriter{w: cmd.OutOrStdout()}
+	stderr := &syncWriter{w: cmd.ErrOrStderr()}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(containers))
+
+	for i, c := range containers {
+		name := strings.TrimLeft(c.Names[0], "/")
+
+		wg.Add(1)
+		go func(id, name string, index int) {
+			defer wg.Done()
+
+			out, err := docker.ContainerLogs(cmd.Context(), id, opts)
+			if err != nil {
+				errs <- fmt.Errorf("unable to get logs for %s, %w", name, err)
+				return
+			}
+			defer out.Close()
+
+			o := newPrefixWriter(name, pad, index, stdout)
+			e := newPrefixWriter(name, pad, index, stderr)
+
+			stdcopy.StdCopy(o, e, out)
+
+			o.Flush()
+			e.Flush()
+		}(c.ID, name, i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	// return the first error, if any
+	if err, ok := <-errs; ok {
+		return err
+	}
+
+	return nil
+}
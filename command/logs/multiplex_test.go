@@ -0,0 +1,30 @@
+package logs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_prefixWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := newPrefixWriter("redis", 7, 0, &syncWriter{w: out})
+
+	// write partial lines to make sure they are buffered
+	if _, err := w.Write([]byte("first line\nsecond ")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("line\nthird")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\x1b[36mredis   |\x1b[0m first line\n" +
+		"\x1b[36mredis   |\x1b[0m second line\n" +
+		"\x1b[36mredis   |\x1b[0m third\n"
+
+	if got := out.String(); got != want {
+		t.Errorf("prefixWriter = \ngot:\n%q\nwant:\n%q", got, want)
+	}
+}
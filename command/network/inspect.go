@@ -0,0 +1,217 @@
+package network
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// networkName is the name of the network apply creates for the environment
+const networkName = "nitro-network"
+
+const inspectExampleText = `  # show the containers attached to the environment network
+  nitro network inspect
+
+  # output each container as JSON
+  nitro network inspect --output json`
+
+// endpoint is a container that is, or should be, attached to the network.
+type endpoint struct {
+	Name     string
+	Type     string
+	IP       string
+	Aliases  []string
+	Attached bool
+}
+
+func inspectCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "inspect",
+		Short:   "Show the containers attached to the network",
+		Example: inspectExampleText,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// find the environment network
+			networks, err := docker.NetworkList(ctx, types.NetworkListOptions{Filters: filters.NewArgs(filters.Arg("name", networkName))})
+			if err != nil {
+				return fmt.Errorf("unable to list the networks, %w", err)
+			}
+
+			var id string
+			for _, n := range networks {
+				// the name filter matches partial names
+				if n.Name == networkName {
+					id = n.ID
+				}
+			}
+
+			if id == "" {
+				return fmt.Errorf("unable to find the network %s, run `nitro apply` to create it", networkName)
+			}
+
+			network, err := docker.NetworkInspect(ctx, id, types.NetworkInspectOptions{})
+			if err != nil {
+				return fmt.Errorf("unable to inspect the network %s, %w", networkName, err)
+			}
+
+			// the running nitro containers should all be attached to the network
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: containerlabels.Environment()})
+			if err != nil {
+				return fmt.Errorf("unable to list the containers, %w", err)
+			}
+
+			list := endpoints(network, containers)
+
+			if format, _ := cmd.Flags().GetString("output"); format == "json" {
+				for _, e := range list {
+					level := "info"
+					if !e.Attached {
+						level = "warning"
+					}
+
+					output.Record(terminal.Record{
+						Level:   level,
+						Message: e.Name,
+						Fields: map[string]string{
+							"name":     e.Name,
+							"type":     e.Type,
+							"ip":       e.IP,
+							"aliases":  strings.Join(e.Aliases, ","),
+							"attached": fmt.Sprintf("%t", e.Attached),
+						},
+					})
+				}
+
+				return nil
+			}
+
+			if err := table(cmd.OutOrStdout(), list); err != nil {
+				return err
+			}
+
+			var missing []string
+			for _, e := range list {
+				if !e.Attached {
+					missing = append(missing, e.Name)
+				}
+			}
+
+			if len(missing) > 0 {
+				output.Info("")
+				output.Info("The following containers are not attached to", networkName+":", strings.Join(missing, ", "))
+				output.Info("Other containers are not able to reach them, run `nitro apply` to recreate them")
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// endpoints returns the containers attached to the network and the nitro
+// containers that are missing from the network, sorted by name.
+func endpoints(network types.NetworkResource, containers []types.Container) []endpoint {
+	byID := make(map[string]types.Container)
+	for _, c := range containers {
+		byID[c.ID] = c
+	}
+
+	var list []endpoint
+	for id, e := range network.Containers {
+		ep := endpoint{
+			Name:     strings.TrimLeft(e.Name, "/"),
+			Type:     "-",
+			IP:       strings.Split(e.IPv4Address, "/")[0],
+			Attached: true,
+		}
+
+		if c, ok := byID[id]; ok {
+			ep.Type = kind(c.Labels)
+			ep.Aliases = aliases(c)
+		}
+
+		list = append(list, ep)
+	}
+
+	for _, c := range containers {
+		if _, ok := network.Containers[c.ID]; ok {
+			continue
+		}
+
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimLeft(c.Names[0], "/")
+		}
+
+		list = append(list, endpoint{Name: name, Type: kind(c.Labels)})
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// kind returns the type of nitro container using the labels (e.g. site, database, or proxy).
+func kind(labels map[string]string) string {
+	switch {
+	case labels[containerlabels.Host] != "":
+		return "site"
+	case labels[containerlabels.Type] != "":
+		return labels[containerlabels.Type]
+	case labels[containerlabels.Proxy] != "":
+		return "proxy"
+	}
+
+	return "-"
+}
+
+// aliases returns the aliases of the container on the network.
+func aliases(c types.Container) []string {
+	if c.NetworkSettings == nil {
+		return nil
+	}
+
+	settings, ok := c.NetworkSettings.Networks[networkName]
+	if !ok || settings == nil {
+		return nil
+	}
+
+	return settings.Aliases
+}
+
+// table writes the endpoints as a table.
+func table(w io.Writer, list []endpoint) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(tw, "NAME\tTYPE\tIP\tALIASES\tSTATUS")
+	for _, e := range list {
+		status := "attached"
+		if !e.Attached {
+			status = "missing"
+		}
+
+		ip := e.IP
+		if ip == "" {
+			ip = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.Name, e.Type, ip, strings.Join(e.Aliases, ", "), status)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,94 @@
+package network
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+func TestInspectCommand(t *testing.T) {
+	// Arrange
+	containers := []types.Container{
+		{
+			ID:     "site",
+			Names:  []string{"/craft-dev.nitro"},
+			State:  "running",
+			Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Host: "craft-dev.nitro"},
+			NetworkSettings: &types.SummaryNetworkSettings{
+				Networks: map[string]*network.EndpointSettings{
+					"nitro-network": {Aliases: []string{"craft-dev.nitro", "site"}},
+				},
+			},
+		},
+		{
+			ID:     "database",
+			Names:  []string{"/mysql-8.0-3306.database.nitro"},
+			State:  "running",
+			Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "database"},
+		},
+	}
+	networks := []types.NetworkResource{
+		{
+			ID:   "network",
+			Name: "nitro-network",
+			Containers: map[string]types.EndpointResource{
+				"site":     {Name: "craft-dev.nitro", IPv4Address: "172.18.0.3/16"},
+				"composer": {Name: "composer-1", IPv4Address: "172.18.0.4/16"},
+			},
+		},
+	}
+
+	docker := dockertest.New(containers, networks)
+	buf := new(bytes.Buffer)
+
+	cmd := inspectCommand("", docker, terminal.New())
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{})
+
+	// Act
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	want := map[string]string{
+		"composer-1":                    "composer-1 - 172.18.0.4 attached",
+		"craft-dev.nitro":               "craft-dev.nitro site 172.18.0.3 craft-dev.nitro, site attached",
+		"mysql-8.0-3306.database.nitro": "mysql-8.0-3306.database.nitro database - missing",
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(want)+1 {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(want)+1, len(lines), buf.String())
+	}
+
+	for _, l := range lines[1:] {
+		fields := strings.Fields(l)
+		if got := strings.Join(fields, " "); got != want[fields[0]] {
+			t.Errorf("expected the row %q, got %q", want[fields[0]], got)
+		}
+	}
+
+	if calls := docker.Calls("NetworkInspect"); len(calls) != 1 || calls[0].Args[0] != "network" {
+		t.Errorf("expected the network to be inspected once, got %v", calls)
+	}
+}
+
+func TestInspectCommand_MissingNetwork(t *testing.T) {
+	docker := dockertest.New(nil, nil)
+
+	cmd := inspectCommand("", docker, terminal.New())
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{})
+
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "nitro apply") {
+		t.Errorf("expected an error to run nitro apply, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package network
+
+import (
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # show the containers attached to the environment network
+  nitro network inspect`
+
+// NewCommand returns the command to diagnose the network for the environment.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "network",
+		Short:   "Diagnose the environment network",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		inspectCommand(home, docker, output),
+	)
+
+	return cmd
+}
@@ -2,7 +2,6 @@ package nitro
 
 import (
 	"log"
-	"os"
 
 	nitroclient "github.com/craftcms/nitro/client"
 	"github.com/craftcms/nitro/command/add"
@@ -15,38 +14,51 @@ import (
 	"github.com/craftcms/nitro/command/composer"
 	"github.com/craftcms/nitro/command/container"
 	"github.com/craftcms/nitro/command/context"
+	"github.com/craftcms/nitro/command/cp"
 	"github.com/craftcms/nitro/command/craft"
 	"github.com/craftcms/nitro/command/create"
 	"github.com/craftcms/nitro/command/database"
 	"github.com/craftcms/nitro/command/destroy"
 	"github.com/craftcms/nitro/command/disable"
+	"github.com/craftcms/nitro/command/dnsmasq"
 	"github.com/craftcms/nitro/command/edit"
 	"github.com/craftcms/nitro/command/enable"
+	"github.com/craftcms/nitro/command/exec"
+	"github.com/craftcms/nitro/command/export"
 	"github.com/craftcms/nitro/command/extensions"
 	"github.com/craftcms/nitro/command/hosts"
+	"github.com/craftcms/nitro/command/importenv"
 	"github.com/craftcms/nitro/command/iniset"
 	"github.com/craftcms/nitro/command/initialize"
 	"github.com/craftcms/nitro/command/logs"
+	"github.com/craftcms/nitro/command/network"
 	"github.com/craftcms/nitro/command/npm"
+	"github.com/craftcms/nitro/command/pause"
 	"github.com/craftcms/nitro/command/php"
+	"github.com/craftcms/nitro/command/phpversion"
 	"github.com/craftcms/nitro/command/portcheck"
+	"github.com/craftcms/nitro/command/ps"
+	"github.com/craftcms/nitro/command/pull"
 	"github.com/craftcms/nitro/command/queue"
 	"github.com/craftcms/nitro/command/remove"
 	"github.com/craftcms/nitro/command/restart"
+	"github.com/craftcms/nitro/command/secret"
 	"github.com/craftcms/nitro/command/selfupdate"
 	"github.com/craftcms/nitro/command/share"
 	"github.com/craftcms/nitro/command/ssh"
 	"github.com/craftcms/nitro/command/start"
 	"github.com/craftcms/nitro/command/stop"
 	"github.com/craftcms/nitro/command/trust"
+	"github.com/craftcms/nitro/command/unpause"
 	"github.com/craftcms/nitro/command/update"
 	"github.com/craftcms/nitro/command/validate"
 	"github.com/craftcms/nitro/command/version"
 	"github.com/craftcms/nitro/command/xoff"
 	"github.com/craftcms/nitro/command/xon"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/dockerclient"
 	"github.com/craftcms/nitro/pkg/downloader"
 	"github.com/craftcms/nitro/pkg/terminal"
-	"github.com/docker/docker/client"
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 )
@@ -62,6 +74,35 @@ Version: ` + version.Version,
 	Version:      version.Version,
 }
 
+// withoutDocker are the top level commands that do not need docker to be running
+var withoutDocker = map[string]bool{
+	"__complete":       true,
+	"__completeNoDesc": true,
+	"completion":       true,
+	"dnsmasq":          true,
+	"help":             true,
+	"hosts":            true,
+	"import":           true,
+	"portcheck":        true,
+	"self-update":      true,
+	"version":          true,
+}
+
+// requiresDocker returns true if the command, or its top level
+// command (e.g. db for nitro db add), needs docker to be running.
+func requiresDocker(cmd *cobra.Command) bool {
+	for cmd.HasParent() && cmd.Parent().HasParent() {
+		cmd = cmd.Parent()
+	}
+
+	// the root command only shows the help
+	if !cmd.HasParent() {
+		return false
+	}
+
+	return !withoutDocker[cmd.Name()]
+}
+
 func rootMain(command *cobra.Command, _ []string) error {
 	return command.Help()
 }
@@ -73,34 +114,23 @@ func NewCommand() *cobra.Command {
 		log.Fatal(err)
 	}
 
-	// create the docker client
-	docker, err := client.NewClientWithOpts(client.FromEnv)
+	// create the docker client, using the docker host or context from the environment
+	docker, err := dockerclient.New()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// if _, err := docker.Ping(contextpkg.Background()); err != nil {
-	// 	fmt.Println("Unable to talk to Docker, it appears it is not running…")
-	// 	os.Exit(2)
-	// }
-
-	// get the port for the nitrod API
-	apiPort := "5000"
-	if os.Getenv("NITRO_API_PORT") != "" {
-		apiPort = os.Getenv("NITRO_API_PORT")
-	}
-
-	// create the nitrod gRPC API
-	nitrod, err := nitroclient.NewClient("127.0.0.1", apiPort)
-	if err != nil {
-		log.Fatal(err)
-	}
+	// create the nitrod gRPC API client, which uses the port published by the proxy container
+	nitrod := nitroclient.NewProxyClient(docker)
 
 	// create the "terminal" for capturing output
 	term := terminal.New()
 
 	// create the downloaded for creating projects
-	downloader := downloader.NewDownloader()
+	downloader, err := downloader.NewDownloader()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// register all of the commands
 	commands := []*cobra.Command{
@@ -114,30 +144,42 @@ func NewCommand() *cobra.Command {
 		composer.NewCommand(docker, term),
 		container.NewCommand(home, docker, term),
 		context.NewCommand(home, docker, term),
+		cp.NewCommand(home, docker, term),
 		craft.NewCommand(home, docker, term),
 		create.NewCommand(home, docker, downloader, term),
 		database.NewCommand(home, docker, nitrod, term),
 		destroy.NewCommand(home, docker, term),
 		disable.NewCommand(home, docker, term),
+		dnsmasq.NewCommand(home, term),
 		enable.NewCommand(home, docker, term),
+		exec.NewCommand(home, docker, term),
+		export.NewCommand(home, docker, term),
 		edit.NewCommand(home, docker, term),
 		extensions.NewCommand(home, docker, term),
 		hosts.NewCommand(home, term),
+		importenv.NewCommand(home, docker, term),
 		iniset.NewCommand(home, docker, term),
 		initialize.NewCommand(home, docker, term),
 		logs.NewCommand(home, docker, term),
+		network.NewCommand(home, docker, term),
 		npm.NewCommand(docker, term),
+		pause.NewCommand(docker, term),
 		php.NewCommand(home, docker, term),
+		phpversion.NewCommand(home, docker, term),
 		portcheck.NewCommand(term),
+		ps.NewCommand(home, docker, term),
+		pull.NewCommand(home, docker, term),
 		queue.NewCommand(home, docker, term),
 		remove.NewCommand(home, docker, term),
 		restart.New(docker, term),
+		secret.NewCommand(home, docker, term),
 		selfupdate.NewCommand(term),
 		share.NewCommand(home, docker, term),
 		ssh.NewCommand(home, docker, term),
 		start.NewCommand(docker, term),
 		stop.New(docker, term),
 		trust.NewCommand(home, docker, term),
+		unpause.NewCommand(docker, term),
 		update.NewCommand(home, docker, term),
 		validate.NewCommand(home, docker, term),
 		version.NewCommand(home, docker, nitrod, term),
@@ -148,5 +190,40 @@ func NewCommand() *cobra.Command {
 	// add the commands
 	rootCommand.AddCommand(commands...)
 
+	// add the global flags for the output
+	rootCommand.PersistentFlags().Bool("quiet", false, "hide progress output")
+	rootCommand.PersistentFlags().String("output", "text", "output format (text or json)")
+
+	// add the global flag to choose between the home and project config
+	rootCommand.PersistentFlags().String("config-source", config.SourceAuto, "where to load the config from (auto, home, or project)")
+
+	// add the global flag to fail when the config references environment variables that are not set
+	rootCommand.PersistentFlags().Bool("strict-env", false, "return an error when the config references an environment variable that is not set")
+
+	// configure the terminal and config once the flags are parsed
+	rootCommand.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		source, _ := cmd.Flags().GetString("config-source")
+		if err := config.ValidSource(source); err != nil {
+			return err
+		}
+
+		config.Source = source
+		config.StrictEnv, _ = cmd.Flags().GetBool("strict-env")
+
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		format, _ := cmd.Flags().GetString("output")
+
+		if err := term.Configure(quiet, format); err != nil {
+			return err
+		}
+
+		// make sure docker is running before running the command
+		if requiresDocker(cmd) {
+			return dockerclient.Ping(cmd.Context(), docker)
+		}
+
+		return nil
+	}
+
 	return rootCommand
 }
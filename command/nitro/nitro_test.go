@@ -0,0 +1,60 @@
+package nitro
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func Test_requiresDocker(t *testing.T) {
+	root := &cobra.Command{Use: "nitro"}
+	apply := &cobra.Command{Use: "apply"}
+	version := &cobra.Command{Use: "version"}
+	db := &cobra.Command{Use: "db"}
+	add := &cobra.Command{Use: "add"}
+	hosts := &cobra.Command{Use: "hosts"}
+	remove := &cobra.Command{Use: "remove"}
+
+	db.AddCommand(add)
+	hosts.AddCommand(remove)
+	root.AddCommand(apply, version, db, hosts)
+
+	tests := []struct {
+		name string
+		cmd  *cobra.Command
+		want bool
+	}{
+		{
+			name: "the root command does not require docker",
+			cmd:  root,
+			want: false,
+		},
+		{
+			name: "commands require docker by default",
+			cmd:  apply,
+			want: true,
+		},
+		{
+			name: "skipped commands do not require docker",
+			cmd:  version,
+			want: false,
+		},
+		{
+			name: "sub commands require docker by default",
+			cmd:  add,
+			want: true,
+		},
+		{
+			name: "sub commands of skipped commands do not require docker",
+			cmd:  remove,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requiresDocker(tt.cmd); got != tt.want {
+				t.Errorf("requiresDocker() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
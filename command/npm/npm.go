@@ -103,18 +103,12 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 
 			image := fmt.Sprintf("docker.io/library/%s:%s-alpine", "node", version)
 
-			filter := filters.NewArgs()
-			filter.Add("reference", image)
-
 			// look for the image
-			images, err := docker.ImageList(ctx, types.ImageListOptions{Filters: filter})
+			images, err := docker.ImageList(ctx, types.ImageListOptions{Filters: filters.NewArgs(filters.Arg("reference", image))})
 			if err != nil {
 				return fmt.Errorf("unable to get a list of images, %w", err)
 			}
 
-			// remove the image ref filter
-			filter.Del("reference", image)
-
 			// if we don't have the image, pull it
 			if len(images) == 0 {
 				output.Pending("pulling", image)
@@ -132,12 +126,8 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 				output.Done()
 			}
 
-			// add filters for the volume
-			filter.Add("label", containerlabels.Type+"=npm")
-			filter.Add("label", containerlabels.Path+"="+path)
-
 			// check if there is an existing volume
-			volumes, err := docker.VolumeList(ctx, filter)
+			volumes, err := docker.VolumeList(ctx, containerlabels.ByPath("npm", path))
 			if err != nil {
 				return err
 			}
@@ -229,13 +219,32 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 			}
 			defer stream.Close()
 
+			// detach from the container when the command is interrupted
+			done := make(chan struct{})
+			defer close(done)
+			go func() {
+				select {
+				case <-ctx.Done():
+					stream.Close()
+				case <-done:
+				}
+			}()
+
 			// run the container
 			if err := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
 				return fmt.Errorf("unable to start the container, %w", err)
 			}
 
 			// copy the stream to stdout
-			if _, err := stdcopy.StdCopy(cmd.OutOrStdout(), cmd.ErrOrStderr(), stream.Reader); err != nil {
+			_, err = stdcopy.StdCopy(cmd.OutOrStdout(), cmd.ErrOrStderr(), stream.Reader)
+
+			// if the command was interrupted, the context is done so use a new one to remove the container
+			if ctx.Err() != nil {
+				output.Info("npm", action, "interrupted, removing container…")
+
+				return docker.ContainerRemove(context.Background(), resp.ID, types.ContainerRemoveOptions{Force: true})
+			}
+			if err != nil {
 				return fmt.Errorf("unable to copy the output of the container logs, %w", err)
 			}
 
@@ -0,0 +1,82 @@
+package pause
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var (
+	// ErrNoContainers is returned when no containers are running for an environment
+	ErrNoContainers = fmt.Errorf("there are no running containers")
+)
+
+const exampleText = `  # pause all containers
+  nitro pause
+
+  # resume all containers
+  nitro unpause`
+
+// NewCommand returns the command used to pause all of the containers for an environment.
+// Pausing freezes the processes in each container, which frees the CPU but keeps the
+// memory state so the environment can be resumed faster than using stop and start.
+func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "pause",
+		Short:   "Pause all containers",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// get all the containers using a filter, we only want to pause containers which
+			// have the environment label
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+
+			// get all of the container
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+			if err != nil {
+				return fmt.Errorf("unable to get a list of the containers, %w", err)
+			}
+
+			// if there are no containers, were done
+			if len(containers) == 0 {
+				return ErrNoContainers
+			}
+
+			output.Info("Pausing Nitro…")
+
+			// pause each environment container
+			for _, c := range containers {
+				n := strings.TrimLeft(c.Names[0], "/")
+
+				// stopped containers can't be paused and paused containers are done
+				if c.State != "running" {
+					continue
+				}
+
+				output.Pending("pausing", n)
+
+				// pause the container
+				if err := docker.ContainerPause(ctx, c.ID); err != nil {
+					return fmt.Errorf("unable to pause container %s: %w", n, err)
+				}
+
+				output.Done()
+			}
+
+			output.Info("Nitro paused, run `nitro unpause` to resume ⏸")
+
+			return nil
+		},
+	}
+
+	return cmd
+}
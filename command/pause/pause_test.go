@@ -0,0 +1,75 @@
+package pause
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+func TestNewCommand(t *testing.T) {
+	// Arrange
+	docker := dockertest.New([]types.Container{
+		{ID: "mysql", Names: []string{"/mysql-8.0-3306.database.nitro"}, State: "running", Labels: map[string]string{containerlabels.Nitro: "true"}},
+		{ID: "site", Names: []string{"/craft-dev.nitro"}, State: "running", Labels: map[string]string{containerlabels.Nitro: "true"}},
+		{ID: "done", Names: []string{"/redis.service.nitro"}, State: "paused", Labels: map[string]string{containerlabels.Nitro: "true"}},
+		{ID: "stopped", Names: []string{"/mailhog.service.nitro"}, State: "exited", Labels: map[string]string{containerlabels.Nitro: "true"}},
+		{ID: "unrelated", Names: []string{"/unrelated"}, State: "running"},
+	}, nil)
+
+	// Act
+	cmd := NewCommand(docker, terminal.New())
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	var got []string
+	for _, c := range docker.Calls("ContainerPause") {
+		got = append(got, c.Args[0].(string))
+	}
+
+	if want := []string{"mysql", "site"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the containers %v to be paused, got %v", want, got)
+	}
+
+	for _, c := range docker.Containers {
+		switch c.ID {
+		case "mysql", "site", "done":
+			if c.State != "paused" {
+				t.Errorf("expected %s to be paused, got %s", c.ID, c.State)
+			}
+		case "stopped":
+			if c.State != "exited" {
+				t.Errorf("expected %s to be exited, got %s", c.ID, c.State)
+			}
+		case "unrelated":
+			if c.State != "running" {
+				t.Errorf("expected %s to be running, got %s", c.ID, c.State)
+			}
+		}
+	}
+}
+
+func TestNewCommand_NoContainers(t *testing.T) {
+	// Arrange
+	docker := dockertest.New(nil, nil)
+
+	// Act
+	cmd := NewCommand(docker, terminal.New())
+	cmd.SetArgs([]string{})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	err := cmd.Execute()
+
+	// Assert
+	if !errors.Is(err, ErrNoContainers) {
+		t.Errorf("expected the error %v, got %v", ErrNoContainers, err)
+	}
+}
@@ -0,0 +1,153 @@
+package phpversion
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/imageref"
+	"github.com/craftcms/nitro/pkg/images"
+	"github.com/craftcms/nitro/pkg/phpversions"
+	"github.com/craftcms/nitro/pkg/pullprogress"
+	"github.com/craftcms/nitro/pkg/siteexec"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # change the PHP version for the current site
+  nitro php-version 8.0
+
+  # change the PHP version for a specific site
+  nitro php-version 7.4 --hostname craft-dev.nitro
+
+  # download the images for every supported PHP version so switching is fast
+  nitro php-version pull
+
+  # download the images for specific PHP versions
+  nitro php-version pull 7.4 8.0`
+
+// NewCommand returns the command to change the PHP version of a site. Only the
+// container for the site is recreated, the rest of the environment is not checked.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "php-version VERSION",
+		Short:     "Change a sites PHP version",
+		Example:   exampleText,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: phpversions.Versions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version := args[0]
+			if !phpversions.IsSupported(version) {
+				return fmt.Errorf("the PHP version %q is not supported, use one of %s", version, strings.Join(phpversions.Versions, ", "))
+			}
+
+			// load the config
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			hostname, _ := cmd.Flags().GetString("hostname")
+			site, err := siteexec.FindSite(cmd, home, hostname, cfg, output)
+			if err != nil {
+				return err
+			}
+
+			if site.Version == version {
+				output.Info(site.Hostname, "is already using PHP", version)
+				return nil
+			}
+
+			if err := cfg.SetSitePHPVersion(site.Hostname, version); err != nil {
+				return err
+			}
+
+			// save the config file
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("unable to save config, %w", err)
+			}
+
+			output.Info("Changing", site.Hostname, "from PHP", site.Version, "to", version+"…")
+
+			// only apply the changes to the site
+			for _, c := range cmd.Root().Commands() {
+				if c.Use == "apply" {
+					if err := c.Flags().Set("site", site.Hostname); err != nil {
+						return err
+					}
+
+					if err := c.RunE(c, nil); err != nil {
+						return err
+					}
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("hostname", "", "the hostname of the site (e.g. craft-dev.nitro)")
+
+	cmd.AddCommand(pullCommand(home, docker, output))
+
+	return cmd
+}
+
+// pullCommand returns the command to download the images for PHP versions
+// ahead of time, so changing the PHP version does not need to wait for a pull.
+func pullCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	return &cobra.Command{
+		Use:       "pull [VERSION...]",
+		Short:     "Download PHP images",
+		ValidArgs: phpversions.Versions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			versions := args
+			if len(versions) == 0 {
+				versions = phpversions.Versions
+			}
+
+			for _, v := range versions {
+				if !phpversions.IsSupported(v) {
+					return fmt.Errorf("the PHP version %q is not supported, use one of %s", v, strings.Join(phpversions.Versions, ", "))
+				}
+			}
+
+			// use the registry mirror when there is a config
+			var registry string
+			cfg, err := config.Load(home)
+			switch {
+			case err == nil:
+				registry = cfg.Registry
+			case !errors.Is(err, config.ErrNoConfigFile):
+				return err
+			}
+
+			for _, v := range versions {
+				image := imageref.Resolve(registry, fmt.Sprintf(images.NginxImage, v))
+
+				output.Pending("pulling", image)
+
+				rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{})
+				if err != nil {
+					output.Warning()
+					return fmt.Errorf("unable to pull the image %s, %w", image, err)
+				}
+
+				if err := pullprogress.Wait(rdr, terminal.ProgressWriter(output)); err != nil {
+					output.Warning()
+					return fmt.Errorf("unable to pull the image %s, %w", image, err)
+				}
+
+				output.Done()
+			}
+
+			return nil
+		},
+	}
+}
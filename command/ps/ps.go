@@ -0,0 +1,141 @@
+package ps
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # list the running containers
+  nitro ps
+
+  # include stopped containers
+  nitro ps --all
+
+  # print only the container IDs
+  nitro ps --quiet
+
+  # restart every nitro container
+  docker restart $(nitro ps -q)
+
+  # output each container as JSON
+  nitro ps --output json`
+
+// NewCommand returns the command to list the containers for the environment, the
+// columns match the docker ps command.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ps",
+		Short:   "List containers",
+		Example: exampleText,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+
+			// only show the containers for the environment
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+
+			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{All: all, Filters: filter})
+			if err != nil {
+				return fmt.Errorf("unable to list the containers, %w", err)
+			}
+
+			// sort containers by the name
+			sort.SliceStable(containers, func(i, j int) bool {
+				return name(containers[i]) < name(containers[j])
+			})
+
+			if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+				for _, c := range containers {
+					fmt.Fprintln(cmd.OutOrStdout(), shortID(c.ID))
+				}
+
+				return nil
+			}
+
+			if format, _ := cmd.Flags().GetString("output"); format == "json" {
+				for _, c := range containers {
+					output.Record(terminal.Record{
+						Level:   "info",
+						Message: name(c),
+						Fields: map[string]string{
+							"id":     c.ID,
+							"image":  c.Image,
+							"name":   name(c),
+							"ports":  ports(c.Ports),
+							"state":  c.State,
+							"status": c.Status,
+						},
+					})
+				}
+
+				return nil
+			}
+
+			return table(cmd.OutOrStdout(), containers)
+		},
+	}
+
+	cmd.Flags().BoolP("all", "a", false, "show all containers, including stopped containers")
+	cmd.Flags().BoolP("quiet", "q", false, "only show the container IDs")
+
+	return cmd
+}
+
+// table writes the containers as a table using the docker ps columns.
+func table(w io.Writer, containers []types.Container) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(tw, "CONTAINER ID\tIMAGE\tSTATUS\tPORTS\tNAMES")
+	for _, c := range containers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", shortID(c.ID), c.Image, c.Status, ports(c.Ports), name(c))
+	}
+
+	return tw.Flush()
+}
+
+// name returns the name of the container without the leading slash.
+func name(c types.Container) string {
+	if len(c.Names) == 0 {
+		return ""
+	}
+
+	return strings.TrimLeft(c.Names[0], "/")
+}
+
+// shortID returns the short container ID that docker shows.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+
+	return id
+}
+
+// ports returns the ports in the same format as docker ps (e.g. 0.0.0.0:3306->3306/tcp).
+func ports(ports []types.Port) string {
+	var list []string
+	for _, p := range ports {
+		if p.PublicPort == 0 {
+			list = append(list, fmt.Sprintf("%d/%s", p.PrivatePort, p.Type))
+			continue
+		}
+
+		list = append(list, fmt.Sprintf("%s:%d->%d/%s", p.IP, p.PublicPort, p.PrivatePort, p.Type))
+	}
+
+	sort.Strings(list)
+
+	return strings.Join(list, ", ")
+}
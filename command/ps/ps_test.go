@@ -0,0 +1,118 @@
+package ps
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+func containers() []types.Container {
+	return []types.Container{
+		{
+			ID:     "4f3a2b1c0d9e8f7a6b5c",
+			Names:  []string{"/mysql-8.0-3306.database.nitro"},
+			Image:  "docker.io/library/mysql:8.0",
+			State:  "running",
+			Status: "Up 2 hours",
+			Ports:  []types.Port{{IP: "0.0.0.0", PrivatePort: 3306, PublicPort: 3306, Type: "tcp"}},
+			Labels: map[string]string{containerlabels.Nitro: "true"},
+		},
+		{
+			ID:     "a1b2c3d4e5f6a7b8c9d0",
+			Names:  []string{"/craft-dev.nitro"},
+			Image:  "docker.io/craftcms/nginx:7.4-dev",
+			State:  "exited",
+			Status: "Exited (0) 3 minutes ago",
+			Ports:  []types.Port{{PrivatePort: 8080, Type: "tcp"}},
+			Labels: map[string]string{containerlabels.Nitro: "true"},
+		},
+		{
+			ID:     "ffffffffffffffffffff",
+			Names:  []string{"/unrelated"},
+			Image:  "nginx",
+			State:  "running",
+			Status: "Up 1 hour",
+		},
+	}
+}
+
+func TestNewCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     []string
+		notWant  []string
+		wantRows int
+	}{
+		{
+			name:     "running containers are shown",
+			want:     []string{"CONTAINER ID", "4f3a2b1c0d9e", "mysql-8.0-3306.database.nitro", "0.0.0.0:3306->3306/tcp", "Up 2 hours"},
+			notWant:  []string{"craft-dev.nitro", "unrelated"},
+			wantRows: 2,
+		},
+		{
+			name:     "all includes stopped containers",
+			args:     []string{"--all"},
+			want:     []string{"craft-dev.nitro", "8080/tcp", "Exited (0) 3 minutes ago"},
+			notWant:  []string{"unrelated"},
+			wantRows: 3,
+		},
+		{
+			name:     "quiet shows only the ids",
+			args:     []string{"-a", "-q"},
+			want:     []string{"4f3a2b1c0d9e\n", "a1b2c3d4e5f6\n"},
+			notWant:  []string{"CONTAINER ID", "mysql"},
+			wantRows: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docker := dockertest.New(containers(), nil)
+			buf := new(bytes.Buffer)
+
+			cmd := NewCommand("", docker, terminal.New())
+			cmd.SetOut(buf)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := buf.String()
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("expected the output to contain %q, got:\n%s", w, got)
+				}
+			}
+
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("expected the output to not contain %q, got:\n%s", w, got)
+				}
+			}
+
+			if rows := strings.Count(got, "\n"); rows != tt.wantRows {
+				t.Errorf("expected %d lines, got %d:\n%s", tt.wantRows, rows, got)
+			}
+		})
+	}
+}
+
+func Test_ports(t *testing.T) {
+	got := ports([]types.Port{
+		{IP: "0.0.0.0", PrivatePort: 8080, PublicPort: 80, Type: "tcp"},
+		{PrivatePort: 9000, Type: "tcp"},
+		{IP: "0.0.0.0", PrivatePort: 443, PublicPort: 443, Type: "tcp"},
+	})
+
+	want := "0.0.0.0:443->443/tcp, 0.0.0.0:80->8080/tcp, 9000/tcp"
+	if got != want {
+		t.Errorf("ports() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/images"
+	"github.com/craftcms/nitro/pkg/pullprogress"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// concurrency is the number of images that are pulled at the same time
+var concurrency = 4
+
+const exampleText = `  # download all of the images for the config
+  nitro pull
+
+  # then apply without pulling images again
+  nitro apply --skip-pull`
+
+// NewCommand returns the command to download all of the images required by the config
+// before running apply, which is helpful on slow connections.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "pull",
+		Short:   "Download images",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// load the config
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			// databases can force a platform for the image
+			platforms := make(map[string]string)
+			for _, db := range cfg.Databases {
+				if db.Platform != "" {
+					platforms[images.Database(cfg.Registry, db)] = db.Platform
+				}
+			}
+
+			list := images.ForConfig(cfg)
+
+			output.Info(fmt.Sprintf("Pulling %d images…", len(list)))
+
+			start := time.Now()
+
+			// show a line for each image that is updated as the images are pulled
+			progress := terminal.NewProgress(output)
+
+			var total int64
+			var failed int
+			pullAll(ctx, docker, list, platforms, progress.Update, func(r result) {
+				if r.err != nil {
+					failed++
+					progress.Fail(r.image, r.err)
+					return
+				}
+
+				total += r.size
+				progress.Done(r.image, fmt.Sprintf("(%s)", size(r.size)))
+			})
+
+			output.Info(fmt.Sprintf("Pulled %s in %s", size(total), time.Since(start).Round(time.Second)))
+
+			if failed > 0 {
+				return fmt.Errorf("unable to pull %d images", failed)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+type result struct {
+	image string
+	size  int64
+	err   error
+}
+
+// pullAll pulls the images concurrently and calls done with the result of
+// each image as it completes. Calls to done are never made concurrently, but
+// progress is called from each pull and must be safe to call concurrently.
+func pullAll(ctx context.Context, docker client.ImageAPIClient, list []string, platforms map[string]string, progress func(image string, percent int), done func(result)) {
+	sem := make(chan struct{}, concurrency)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, image := range list {
+		wg.Add(1)
+		go func(image string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			r := pull(ctx, docker, image, platforms[image], progress)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			done(r)
+		}(image)
+	}
+
+	wg.Wait()
+}
+
+// pull pulls a single image, showing the percent downloaded, and returns the size of the image.
+func pull(ctx context.Context, docker client.ImageAPIClient, image, platform string, progress func(image string, percent int)) result {
+	rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{Platform: platform})
+	if err != nil {
+		return result{image: image, err: err}
+	}
+	defer rdr.Close()
+
+	// wait for the pull to complete
+	tracker := pullprogress.NewTracker()
+	if err := pullprogress.Decode(rdr, func(e pullprogress.Event) {
+		progress(image, tracker.Add(e))
+	}); err != nil {
+		return result{image: image, err: err}
+	}
+
+	info, _, err := docker.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		return result{image: image, err: err}
+	}
+
+	return result{image: image, size: info.Size}
+}
+
+// size returns a human readable size for the bytes.
+func size(b int64) string {
+	const unit = 1000
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "kMGTPE"[exp])
+}
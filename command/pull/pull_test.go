@@ -0,0 +1,114 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+func Test_pullAll(t *testing.T) {
+	mock := &mockImageClient{
+		sizes:     map[string]int64{"mysql:8.0": 500, "redis:latest": 100},
+		failPulls: map[string]bool{"missing:latest": true},
+	}
+
+	var mu sync.Mutex
+	percents := make(map[string]int)
+	progress := func(image string, percent int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		percents[image] = percent
+	}
+
+	var got []string
+	var total int64
+	pullAll(context.Background(), mock, []string{"mysql:8.0", "redis:latest", "missing:latest"}, map[string]string{"mysql:8.0": "linux/amd64"}, progress, func(r result) {
+		if r.err != nil {
+			got = append(got, r.image+" failed")
+			return
+		}
+
+		total += r.size
+		got = append(got, r.image)
+	})
+
+	sort.Strings(got)
+	want := []string{"missing:latest failed", "mysql:8.0", "redis:latest"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("pullAll() = %v, want %v", got, want)
+	}
+
+	if total != 600 {
+		t.Errorf("expected the total size to be 600, got %d", total)
+	}
+
+	if percents["mysql:8.0"] != 100 || percents["redis:latest"] != 100 {
+		t.Errorf("expected the progress to be shown for each pull, got %v", percents)
+	}
+
+	if _, ok := percents["missing:latest"]; ok {
+		t.Errorf("expected no progress for failed pulls, got %v", percents)
+	}
+
+	if mock.platforms["mysql:8.0"] != "linux/amd64" {
+		t.Errorf("expected the platform to be used for mysql:8.0, got %q", mock.platforms["mysql:8.0"])
+	}
+}
+
+func Test_size(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{bytes: 512, want: "512 B"},
+		{bytes: 1500, want: "1.5 kB"},
+		{bytes: 235000000, want: "235.0 MB"},
+		{bytes: 1200000000, want: "1.2 GB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := size(tt.bytes); got != tt.want {
+				t.Errorf("size() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type mockImageClient struct {
+	client.ImageAPIClient
+
+	mu        sync.Mutex
+	sizes     map[string]int64
+	failPulls map[string]bool
+	platforms map[string]string
+}
+
+func (m *mockImageClient) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.platforms == nil {
+		m.platforms = make(map[string]string)
+	}
+	m.platforms[ref] = options.Platform
+
+	if m.failPulls[ref] {
+		return nil, fmt.Errorf("image not found")
+	}
+
+	return ioutil.NopCloser(strings.NewReader(`{"status":"Downloading","progressDetail":{"current":512,"total":1024},"id":"abc"}
+{"status":"Pull complete","id":"abc"}`)), nil
+}
+
+func (m *mockImageClient) ImageInspectWithRaw(ctx context.Context, image string) (types.ImageInspect, []byte, error) {
+	return types.ImageInspect{Size: m.sizes[image]}, nil, nil
+}
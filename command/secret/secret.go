@@ -0,0 +1,112 @@
+package secret
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/secrets"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # store the blackfire server token in the keychain
+  nitro secret set blackfire.server_token
+
+  # store a site label in the keychain
+  nitro secret set sites.craft-dev.nitro.labels.api_key
+
+  # store an env variable for a custom container in the keychain
+  nitro secret set containers.adminer.env.ADMINER_PASSWORD`
+
+const setExampleText = `  # store the blackfire server token in the keychain, you will be prompted for the value
+  nitro secret set blackfire.server_token
+
+  # pass the value as an argument
+  nitro secret set blackfire.server_id my-server-id`
+
+// NewCommand returns the command to manage the secrets that are stored in the
+// OS keychain instead of the config file.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "secret",
+		Short:   "Manage secrets",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(setCommand(home, output))
+
+	return cmd
+}
+
+// setCommand returns the command to store a config value in the keychain, the
+// config references the secret by name (e.g. ${secret:blackfire.server_token}).
+func setCommand(home string, output terminal.Outputer) *cobra.Command {
+	return &cobra.Command{
+		Use:     "set NAME [VALUE]",
+		Short:   "Store a secret",
+		Example: setExampleText,
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// load the config
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+
+			var value string
+			switch len(args) {
+			case 2:
+				value = args[1]
+			default:
+				value, err = output.Ask("Enter the value for "+name, "", ":", nil)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := set(cfg, secrets.New, output, name, value); err != nil {
+				return err
+			}
+
+			// save the config file
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("unable to save config, %w", err)
+			}
+
+			return nil
+		},
+	}
+}
+
+// set stores the value in the secret store and sets the config value to reference
+// the secret. When there is no keychain the value is stored in the config as
+// plaintext with a warning.
+func set(cfg *config.Config, newStore func() (secrets.Store, error), output terminal.Outputer, name, value string) error {
+	// reference the secret, this also checks the config value exists
+	if err := cfg.SetField(name, config.SecretRef(name)); err != nil {
+		return err
+	}
+
+	store, err := newStore()
+	if err == nil {
+		err = store.Set(name, value)
+	}
+
+	if err != nil {
+		output.Info("Warning: unable to use the keychain,", err.Error())
+		output.Info("Storing", name, "in the config as plaintext")
+
+		return cfg.SetField(name, value)
+	}
+
+	output.Success("stored", name, "in the keychain")
+
+	return nil
+}
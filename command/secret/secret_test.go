@@ -0,0 +1,98 @@
+package secret
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/secrets"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+type mockOutputer struct {
+	terminal.Outputer
+}
+
+func (m mockOutputer) Info(s ...string) {}
+
+func (m mockOutputer) Success(s ...string) {}
+
+func Test_set(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		store     secrets.Store
+		storeErr  error
+		wantValue string
+		wantErr   bool
+	}{
+		{
+			name:      "values are stored in the keychain",
+			path:      "blackfire.server_token",
+			store:     secrets.Memory{},
+			wantValue: "${secret:blackfire.server_token}",
+		},
+		{
+			name:      "values are stored as plaintext without a keychain",
+			path:      "blackfire.server_token",
+			storeErr:  secrets.ErrUnavailable,
+			wantValue: "my-token",
+		},
+		{
+			name:    "unknown config values return an error",
+			path:    "unknown",
+			store:   secrets.Memory{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			newStore := func() (secrets.Store, error) {
+				return tt.store, tt.storeErr
+			}
+
+			err := set(cfg, newStore, mockOutputer{}, tt.path, "my-token")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if cfg.Blackfire.ServerToken != tt.wantValue {
+				t.Errorf("expected the server token to be %q, got %q", tt.wantValue, cfg.Blackfire.ServerToken)
+			}
+
+			if tt.store != nil {
+				if got, err := tt.store.Get(tt.path); err != nil || got != "my-token" {
+					t.Errorf("expected the secret to be stored, got %q, %v", got, err)
+				}
+			}
+		})
+	}
+}
+
+func Test_set_StoreError(t *testing.T) {
+	cfg := &config.Config{}
+	newStore := func() (secrets.Store, error) {
+		return failingStore{}, nil
+	}
+
+	if err := set(cfg, newStore, mockOutputer{}, "blackfire.server_id", "my-id"); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Blackfire.ServerID != "my-id" {
+		t.Errorf("expected the server id to fall back to plaintext, got %q", cfg.Blackfire.ServerID)
+	}
+}
+
+type failingStore struct {
+	secrets.Memory
+}
+
+func (f failingStore) Set(name, value string) error {
+	return errors.New("no dbus session")
+}
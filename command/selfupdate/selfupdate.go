@@ -29,7 +29,10 @@ var (
 )
 
 const exampleText = `  # update to the latest version of the nitro CLI
-  nitro self-update`
+  nitro self-update
+
+  # update the nitro CLI and the images
+  nitro update --self`
 
 // NewCommand is used to help update a nitro cli using the latest version.
 func NewCommand(output terminal.Outputer) *cobra.Command {
@@ -38,141 +41,164 @@ func NewCommand(output terminal.Outputer) *cobra.Command {
 		Short:   "Update nitro to the latest version",
 		Example: exampleText,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output.Info("Checking for updates")
-
-			u := LatestURL
-			if DevRelease {
-				u = ReleasesURL
-			}
-
-			// find the latest release
-			release, err := releases.NewFinder().Find(u, runtime.GOOS, runtime.GOARCH)
+			v, err := Update(output, DevRelease)
 			if err != nil {
 				return err
 			}
 
-			// make sure the versions do not match
-			if release.Version == version.Version {
-				output.Info("up to date!")
-				return nil
+			// the images are updated separately to keep the update fast
+			if v != "" {
+				output.Info("Run `nitro update` to update the images for Nitro", v)
 			}
 
-			output.Pending("found version", release.Version, "updating")
+			return nil
+		},
+	}
 
-			// create a temp file to save the release into
-			file, err := ioutil.TempFile(os.TempDir(), "nitro-release-download-")
-			if err != nil {
-				return err
-			}
-			defer file.Close()
+	cmd.Flags().BoolVar(&DevRelease, "dev", false, "install the latest development release")
 
-			// download the release
-			if err := releases.NewDownloader().Download(release.URL, file.Name()); err != nil {
-				return err
-			}
+	return cmd
+}
 
-			switch release.ContentType {
-			case "application/gzip":
-				file, err := os.Open(file.Name())
-				if err != nil {
-					return err
-				}
-				defer file.Close()
+// Update finds the latest release and replaces the running binary with it. It
+// returns the version that was installed, or an empty string when nitro is
+// already up to date.
+func Update(output terminal.Outputer, dev bool) (string, error) {
+	output.Info("Checking for updates")
 
-				gz, err := gzip.NewReader(file)
-				if err != nil {
-					return err
-				}
-				defer gz.Close()
+	u := LatestURL
+	if dev {
+		u = ReleasesURL
+	}
 
-				// untar the zip
-				tr := tar.NewReader(gz)
+	// use the proxy and CA bundle from the environment
+	client, err := releases.NewHTTPClient(releases.CAFile())
+	if err != nil {
+		return "", err
+	}
 
-				i := 0
-				for {
-					header, err := tr.Next()
+	// find the latest release
+	release, err := releases.NewFinder(client).Find(u, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
 
-					if err == io.EOF {
-						break
-					}
+	// make sure the versions do not match
+	if release.Version == version.Version {
+		output.Info("up to date!")
+		return "", nil
+	}
 
-					if err != nil {
-						return err
-					}
+	output.Pending("found version", release.Version, "updating")
 
-					switch header.Typeflag {
-					case tar.TypeDir:
-						continue
-					case tar.TypeReg:
-						name := header.Name
+	// create a temp file to save the release into
+	file, err := ioutil.TempFile(os.TempDir(), "nitro-release-download-")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
 
-						switch release.OperatingSystem {
-						case "windows":
-							if name == "nitro.exe" {
-								output.Done()
+	// download the release
+	if err := releases.NewDownloader(client).Download(release.URL, file.Name()); err != nil {
+		return "", err
+	}
 
-								output.Info("Updating to Nitro", release.Version+"!")
+	switch release.ContentType {
+	case "application/gzip":
+		file, err := os.Open(file.Name())
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return "", err
+		}
+		defer gz.Close()
+
+		// untar the zip
+		tr := tar.NewReader(gz)
+
+		i := 0
+		for {
+			header, err := tr.Next()
+
+			if err == io.EOF {
+				break
+			}
 
-								// self update
-								if err := selfupdate.Apply(tr, selfupdate.Options{}); err != nil {
-									return err
-								}
+			if err != nil {
+				return "", err
+			}
 
-								break
-							}
-						default:
-							if name == "nitro" {
-								output.Done()
+			switch header.Typeflag {
+			case tar.TypeDir:
+				continue
+			case tar.TypeReg:
+				name := header.Name
 
-								output.Info("Updating to Nitro", release.Version+"!")
+				switch release.OperatingSystem {
+				case "windows":
+					if name == "nitro.exe" {
+						output.Done()
 
-								// self update
-								if err := selfupdate.Apply(tr, selfupdate.Options{}); err != nil {
-									return err
-								}
+						output.Info("Updating to Nitro", release.Version+"!")
 
-								break
-							}
+						// self update
+						if err := selfupdate.Apply(tr, selfupdate.Options{}); err != nil {
+							return "", err
 						}
+
+						break
 					}
+				default:
+					if name == "nitro" {
+						output.Done()
 
-					i++
-				}
-			case "application/zip":
-				// unzip
-				zr, err := zip.OpenReader(file.Name())
-				if err != nil {
-					return err
-				}
+						output.Info("Updating to Nitro", release.Version+"!")
 
-				for _, file := range zr.Reader.File {
-					switch release.OperatingSystem {
-					case "windows":
-						if file.Name == "nitro.exe" {
-							output.Done()
+						// self update
+						if err := selfupdate.Apply(tr, selfupdate.Options{}); err != nil {
+							return "", err
+						}
 
-							output.Info("Updating to Nitro", release.Version+"!")
+						break
+					}
+				}
+			}
 
-							// read the file
-							f, err := os.Open(file.FileInfo().Name())
-							if err != nil {
-								return err
-							}
+			i++
+		}
+	case "application/zip":
+		// unzip
+		zr, err := zip.OpenReader(file.Name())
+		if err != nil {
+			return "", err
+		}
+
+		for _, file := range zr.Reader.File {
+			switch release.OperatingSystem {
+			case "windows":
+				if file.Name == "nitro.exe" {
+					output.Done()
+
+					output.Info("Updating to Nitro", release.Version+"!")
+
+					// read the file
+					f, err := os.Open(file.FileInfo().Name())
+					if err != nil {
+						return "", err
+					}
 
-							// self update
-							if err := selfupdate.Apply(f, selfupdate.Options{}); err != nil {
-								return err
-							}
-						}
+					// self update
+					if err := selfupdate.Apply(f, selfupdate.Options{}); err != nil {
+						return "", err
 					}
 				}
 			}
-
-			return nil
-		},
+		}
 	}
 
-	cmd.Flags().BoolVar(&DevRelease, "dev", false, "install the latest development release")
-
-	return cmd
+	return release.Version, nil
 }
@@ -136,6 +136,11 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 
 			// append the aliases
 			for _, a := range site.Aliases {
+				// ngrok does not support wildcard host headers
+				if config.IsWildcard(a) {
+					continue
+				}
+
 				ngrokArgs = append(ngrokArgs, "-host-header="+a)
 			}
 
@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -41,14 +42,6 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 		Short:   "SSH into a container",
 		Example: exampleText,
 		Args:    cobra.NoArgs,
-		PreRunE: func(cmd *cobra.Command, args []string) error {
-			// is the docker api alive?
-			if _, err := docker.Ping(cmd.Context()); err != nil {
-				return fmt.Errorf("Couldn’t connect to Docker; please make sure Docker is running.")
-			}
-
-			return nil
-		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// get the current working directory
 			wd, err := os.Getwd()
@@ -174,12 +167,21 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				output.Info("using root… system changes are ephemeral…")
 			}
 
-			c := exec.Command(cli, "exec", "-u", user, "-it", containerID, "sh")
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			// the exec is killed when the command is interrupted
+			c := exec.CommandContext(ctx, cli, "exec", "-u", user, "-it", containerID, "sh")
 
 			c.Stdin = cmd.InOrStdin()
 			c.Stderr = cmd.ErrOrStderr()
 			c.Stdout = cmd.OutOrStdout()
 
+			// restore the terminal in case the exec is killed while in raw mode
+			defer terminal.SaveState(c.Stdin)()
+
 			return c.Run()
 		},
 	}
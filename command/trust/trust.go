@@ -18,6 +18,7 @@ import (
 	"github.com/craftcms/nitro/pkg/certinstall"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
 	"github.com/craftcms/nitro/pkg/terminal"
 )
 
@@ -49,6 +50,11 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				ctx = cmd.Parent().Context()
 			}
 
+			// the proxy is not created by apply --no-proxy
+			if _, err := proxycontainer.FindAndStart(ctx, docker); err != nil {
+				return err
+			}
+
 			// find the nitro proxy for the environment
 			filter := filters.NewArgs()
 			filter.Add("label", containerlabels.Nitro)
@@ -0,0 +1,77 @@
+package unpause
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var (
+	// ErrNoContainers is returned when no containers exist for an environment
+	ErrNoContainers = fmt.Errorf("there are no containers")
+)
+
+const exampleText = `  # resume all paused containers
+  nitro unpause`
+
+// NewCommand returns the command used to resume all of the paused containers for an environment.
+func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "unpause",
+		Short:   "Unpause all containers",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// get all the containers using a filter, we only want to unpause containers which
+			// have the environment label
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+
+			// get all of the container
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+			if err != nil {
+				return fmt.Errorf("unable to get a list of the containers, %w", err)
+			}
+
+			// if there are no containers, were done
+			if len(containers) == 0 {
+				return ErrNoContainers
+			}
+
+			output.Info("Unpausing Nitro…")
+
+			// unpause each environment container
+			for _, c := range containers {
+				n := strings.TrimLeft(c.Names[0], "/")
+
+				// only paused containers can be unpaused
+				if c.State != "paused" {
+					continue
+				}
+
+				output.Pending("unpausing", n)
+
+				// unpause the container
+				if err := docker.ContainerUnpause(ctx, c.ID); err != nil {
+					return fmt.Errorf("unable to unpause container %s: %w", n, err)
+				}
+
+				output.Done()
+			}
+
+			output.Info("Nitro ready 👍")
+
+			return nil
+		},
+	}
+
+	return cmd
+}
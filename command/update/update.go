@@ -2,7 +2,11 @@ package update
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"os"
+	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -12,32 +16,67 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/craftcms/nitro/command/selfupdate"
 	"github.com/craftcms/nitro/command/version"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/imageref"
+	"github.com/craftcms/nitro/pkg/images"
+	"github.com/craftcms/nitro/pkg/phpversions"
 	"github.com/craftcms/nitro/pkg/terminal"
 )
 
 var (
-	DockerImages = map[string]string{
-		"nginx:8.0-dev":                  "docker.io/craftcms/nginx:8.0-dev",
-		"nginx:7.4-dev":                  "docker.io/craftcms/nginx:7.4-dev",
-		"nginx:7.3-dev":                  "docker.io/craftcms/nginx:7.3-dev",
-		"nginx:7.2-dev":                  "docker.io/craftcms/nginx:7.2-dev",
-		"nginx:7.1-dev":                  "docker.io/craftcms/nginx:7.1-dev",
-		"nginx:7.0-dev":                  "docker.io/craftcms/nginx:7.0-dev",
-		"nitro-proxy:" + version.Version: "docker.io/craftcms/nitro-proxy:" + version.Version,
+	DockerImages = dockerImages("")
+	runApply     bool
+
+	// selfUpdate replaces the running binary with the latest release.
+	selfUpdate = selfupdate.Update
+
+	// runUpdated runs the updated binary with the arguments.
+	runUpdated = func(args []string) error {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("unable to find the nitro binary, %w", err)
+		}
+
+		cmd := exec.Command(exe, args...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		return cmd.Run()
 	}
-	runApply bool
 )
 
+// dockerImages returns the images to update, which is a site image for each
+// supported PHP version and the proxy image, using the registry mirror.
+func dockerImages(registry string) map[string]string {
+	list := map[string]string{
+		"nitro-proxy:" + version.Version: imageref.Resolve(registry, "docker.io/craftcms/nitro-proxy:"+version.Version),
+	}
+
+	for _, v := range phpversions.Versions {
+		image := imageref.Resolve(registry, fmt.Sprintf(images.NginxImage, v))
+		list[shortImageName(image)] = image
+	}
+
+	return list
+}
+
 // New returns the update command for updating images on the local machine as well as the nitro-proxy container.
 func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update nitro containers and proxy",
 		Example: `  # update nitro
-  nitro update`,
+  nitro update
+
+  # only update specific PHP versions
+  nitro update --php 7.4 --php 8.0
+
+  # update the nitro CLI and then the images
+  nitro update --self`,
 		PostRunE: func(cmd *cobra.Command, args []string) error {
 			// if there are no updates to apply return
 			if !runApply {
@@ -47,6 +86,11 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			for _, c := range cmd.Parent().Commands() {
 				// set the apply command
 				if c.Use == "apply" {
+					// recreate any sites using an outdated image
+					if err := c.Flags().Set("force-pull", "true"); err != nil {
+						return err
+					}
+
 					if err := c.RunE(c, args); err != nil {
 						return err
 					}
@@ -62,51 +106,48 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				debug = false
 			}
 
-			// load the config
-			cfg, err := config.Load(home)
+			// get the php versions to update
+			flags, err := cmd.Flags().GetStringSlice("php")
 			if err != nil {
 				return err
 			}
 
-			// get all of the php versions from the site
-			versions := make(map[string]bool)
-			for _, s := range cfg.Sites {
-				// check if the php version is already set otherwise set it
-				if _, ok := versions[s.Version]; !ok {
-					versions[s.Version] = true
-				}
-			}
-
-			output.Info("Updating nitro…")
+			// update the binary first so the images match the new version
+			if self, _ := cmd.Flags().GetBool("self"); self {
+				before := version.Version
 
-			// update all of the images
-			for name, image := range DockerImages {
-				// make sure this is version that is installed and not the proxy
-				if _, ok := versions[versionFromName(name)]; !ok && !strings.Contains(name, "proxy") {
-					continue
+				v, err := selfUpdate(output, false)
+				if err != nil {
+					return fmt.Errorf("unable to update nitro, %w", err)
 				}
 
-				output.Pending("downloading", name)
+				// the running binary is the old version, so the new binary updates
+				// the images and recreates the proxy using the new version
+				if v != "" {
+					output.Info("Updated Nitro from", before, "to", v)
 
-				// pull the image
-				rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false})
-				if err != nil {
-					output.Warning()
-					output.Info("  \u2717 unable to pull image", name)
-
-					continue
-				}
+					args := []string{"update"}
+					for _, f := range flags {
+						args = append(args, "--php", f)
+					}
 
-				buf := &bytes.Buffer{}
-				if _, err := buf.ReadFrom(rdr); err != nil {
-					output.Warning()
+					if debug {
+						args = append(args, "--debug")
+					}
 
-					return fmt.Errorf("unable to read the output while pulling image, %w", err)
+					return runUpdated(args)
 				}
+			}
 
-				output.Done()
+			// load the config
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
 			}
 
+			// the images use the registry mirror from the config
+			DockerImages = dockerImages(cfg.Registry)
+
 			// create a filter for nitro containers
 			filter := filters.NewArgs()
 			filter.Add("label", containerlabels.Nitro)
@@ -117,8 +158,35 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				return err
 			}
 
+			versions, err := phpVersions(cfg, containers, flags)
+			if err != nil {
+				return err
+			}
+
+			output.Info("Updating nitro…")
+
+			updated, current, err := pullImages(ctx, docker, versions, output)
+			if err != nil {
+				return err
+			}
+
+			if len(updated) > 0 {
+				output.Info("Updated images:", strings.Join(updated, ", "))
+			}
+
+			if len(current) > 0 {
+				output.Info("Already up to date:", strings.Join(current, ", "))
+			}
+
 			// check all of the containers
 			for _, container := range containers {
+				// only replace containers for the versions being updated
+				if name := shortImageName(container.Image); container.Labels[containerlabels.Host] != "" && strings.HasPrefix(name, "nginx:") {
+					if _, ok := versions[versionFromName(name)]; !ok {
+						continue
+					}
+				}
+
 				// is this a database, service, composer, or node container?
 				if container.Labels[containerlabels.Type] == "dynamodb" || container.Labels[containerlabels.Type] == "mailhog" || container.Labels[containerlabels.Type] == "minio" || container.Labels[containerlabels.Type] == "redis" || container.Labels[containerlabels.Type] == "database" {
 					continue
@@ -166,10 +234,105 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 	}
 
 	cmd.Flags().Bool("debug", false, "Show what will be updated without removing the container")
+	cmd.Flags().Bool("self", false, "Update the nitro CLI to the latest version before updating the images")
+	cmd.Flags().StringSlice("php", nil, "PHP versions to update, defaults to the versions used by sites (e.g. 7.4)")
 
 	return cmd
 }
 
+// pullImages pulls the site images for the PHP versions and the proxy image. It
+// returns the names of the images that changed and the images that were already
+// up to date.
+func pullImages(ctx context.Context, docker client.ImageAPIClient, versions map[string]bool, output terminal.Outputer) (updated, current []string, err error) {
+	for name, image := range DockerImages {
+		// make sure this is version that is installed and not the proxy
+		if _, ok := versions[versionFromName(name)]; !ok && !strings.Contains(name, "proxy") {
+			continue
+		}
+
+		output.Pending("downloading", name)
+
+		// get the image before pulling to see if it changed
+		var before string
+		if inspect, _, err := docker.ImageInspectWithRaw(ctx, image); err == nil {
+			before = inspect.ID
+		}
+
+		// pull the image
+		rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false})
+		if err != nil {
+			output.Warning()
+			output.Info("  \u2717 unable to pull image", name)
+
+			continue
+		}
+
+		buf := &bytes.Buffer{}
+		if _, err := buf.ReadFrom(rdr); err != nil {
+			output.Warning()
+
+			return nil, nil, fmt.Errorf("unable to read the output while pulling image, %w", err)
+		}
+
+		output.Done()
+
+		var after string
+		if inspect, _, err := docker.ImageInspectWithRaw(ctx, image); err == nil {
+			after = inspect.ID
+		}
+
+		if before == after {
+			current = append(current, name)
+		} else {
+			updated = append(updated, name)
+		}
+	}
+
+	sort.Strings(updated)
+	sort.Strings(current)
+
+	return updated, current, nil
+}
+
+// phpVersions returns the PHP versions to update. When versions are provided they
+// must be supported, otherwise the versions are from the sites in the config and
+// the existing site containers.
+func phpVersions(cfg *config.Config, containers []types.Container, flags []string) (map[string]bool, error) {
+	versions := make(map[string]bool)
+
+	if len(flags) > 0 {
+		for _, v := range flags {
+			if _, ok := DockerImages[fmt.Sprintf("nginx:%s-dev", v)]; !ok {
+				return nil, fmt.Errorf("the PHP version %q is not supported", v)
+			}
+
+			versions[v] = true
+		}
+
+		return versions, nil
+	}
+
+	for _, s := range cfg.Sites {
+		versions[s.Version] = true
+	}
+
+	// sites that were removed from the config may still have a container
+	for _, c := range containers {
+		if c.Labels[containerlabels.Host] == "" {
+			continue
+		}
+
+		name := shortImageName(c.Image)
+		if !strings.HasPrefix(name, "nginx:") {
+			continue
+		}
+
+		versions[versionFromName(name)] = true
+	}
+
+	return versions, nil
+}
+
 // docker.io/craftcms/nginx:7.4-dev => nginx:7.4-dev
 func shortImageName(s string) string {
 	parts := strings.Split(s, "/")
@@ -1,6 +1,36 @@
 package update
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/command/version"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+type spyOutputer struct {
+	terminal.Outputer
+	infos []string
+}
+
+func (spy *spyOutputer) Info(s ...string) {
+	spy.infos = append(spy.infos, strings.Join(s, " "))
+}
+
+func (spy *spyOutputer) Pending(s ...string) {}
+
+func (spy *spyOutputer) Done() {}
+
+func (spy *spyOutputer) Warning() {}
 
 func Test_versionFromName(t *testing.T) {
 	type args struct {
@@ -27,3 +57,154 @@ func Test_versionFromName(t *testing.T) {
 		})
 	}
 }
+
+func Test_phpVersions(t *testing.T) {
+	cfg := &config.Config{
+		Sites: []config.Site{
+			{Hostname: "one.nitro", Version: "7.4"},
+			{Hostname: "two.nitro", Version: "8.0"},
+		},
+	}
+
+	containers := []types.Container{
+		{Image: "docker.io/craftcms/nginx:7.3-dev", Labels: map[string]string{containerlabels.Host: "removed.nitro"}},
+		{Image: "mysql:8.0", Labels: map[string]string{containerlabels.Type: "database"}},
+	}
+
+	tests := []struct {
+		name    string
+		flags   []string
+		want    map[string]bool
+		wantErr bool
+	}{
+		{
+			name: "versions default to the config and site containers",
+			want: map[string]bool{"7.3": true, "7.4": true, "8.0": true},
+		},
+		{
+			name:  "flags limit the versions",
+			flags: []string{"7.4"},
+			want:  map[string]bool{"7.4": true},
+		},
+		{
+			name:    "unsupported versions return an error",
+			flags:   []string{"5.6"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := phpVersions(cfg, containers, tt.flags)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("phpVersions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("phpVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pullImages(t *testing.T) {
+	// Arrange
+	docker := dockertest.New(nil, nil)
+	docker.Images = []types.ImageSummary{
+		{ID: "sha256:current", RepoTags: []string{"docker.io/craftcms/nginx:7.4-dev"}},
+	}
+
+	// Act
+	updated, current, err := pullImages(context.Background(), docker, map[string]bool{"7.4": true}, &spyOutputer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if want := []string{"nitro-proxy:" + version.Version}; !reflect.DeepEqual(updated, want) {
+		t.Errorf("expected the updated images %v, got %v", want, updated)
+	}
+
+	if want := []string{"nginx:7.4-dev"}; !reflect.DeepEqual(current, want) {
+		t.Errorf("expected the current images %v, got %v", want, current)
+	}
+
+	if calls := docker.Calls("ImagePull"); len(calls) != 2 {
+		t.Errorf("expected only the proxy and PHP 7.4 images to be pulled, got %d pulls", len(calls))
+	}
+}
+
+func TestNewCommand_Self(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		version  string
+		err      error
+		wantArgs []string
+		wantErr  string
+	}{
+		{
+			name:     "the new binary updates the images",
+			args:     []string{"--self", "--php", "7.4"},
+			version:  "2.0.0",
+			wantArgs: []string{"update", "--php", "7.4"},
+		},
+		{
+			name:    "errors updating the binary are returned",
+			args:    []string{"--self"},
+			err:     errors.New("no release found"),
+			wantErr: "unable to update nitro, no release found",
+		},
+	}
+	defer func(update func(terminal.Outputer, bool) (string, error), run func([]string) error) {
+		selfUpdate, runUpdated = update, run
+	}(selfUpdate, runUpdated)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var gotArgs []string
+			selfUpdate = func(output terminal.Outputer, dev bool) (string, error) {
+				return tt.version, tt.err
+			}
+			runUpdated = func(args []string) error {
+				gotArgs = args
+				return nil
+			}
+
+			docker := dockertest.New(nil, nil)
+			output := &spyOutputer{}
+			cmd := NewCommand(t.TempDir(), docker, output)
+			cmd.SetArgs(tt.args)
+
+			// Act
+			err := cmd.Execute()
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected the error %q, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("expected the updated binary to run with %v, got %v", tt.wantArgs, gotArgs)
+			}
+
+			if want := fmt.Sprintf("Updated Nitro from %s to %s", version.Version, tt.version); len(output.infos) == 0 || output.infos[0] != want {
+				t.Errorf("expected the summary %q, got %v", want, output.infos)
+			}
+
+			// the images are not pulled by the old binary
+			if calls := docker.Calls("ImagePull"); len(calls) != 0 {
+				t.Errorf("expected no images to be pulled, got %d", len(calls))
+			}
+		})
+	}
+}
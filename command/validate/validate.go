@@ -46,6 +46,16 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				output.Done()
 			}
 
+			// check the host ports are only used once
+			output.Pending("validating ports")
+
+			portErr := cfg.ValidatePorts()
+			if portErr != nil {
+				output.Warning()
+			} else {
+				output.Done()
+			}
+
 			// check the site paths
 			if len(sites) > 0 {
 				output.Pending("validating sites")
@@ -61,6 +71,29 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 						siteErrs = append(siteErrs, fmt.Errorf("unable to locate site path %s", p))
 					}
 
+					// validate the aliases
+					aliasvalidator := validate.AliasValidator{}
+					for _, a := range s.Aliases {
+						if err := aliasvalidator.Validate(a); err != nil {
+							siteErrs = append(siteErrs, fmt.Errorf("invalid alias %s for %s, %w", a, s.Hostname, err))
+						}
+					}
+
+					// validate the webroot
+					if _, err := s.GetWebrootPath(); err != nil {
+						siteErrs = append(siteErrs, err)
+					}
+
+					// validate the custom nginx config
+					nginxPath, err := s.GetNginxConfigPath(home)
+					if err != nil {
+						siteErrs = append(siteErrs, err)
+					} else if nginxPath != "" {
+						if _, err := os.Stat(nginxPath); err != nil {
+							siteErrs = append(siteErrs, fmt.Errorf("unable to find the nginx config %s", nginxPath))
+						}
+					}
+
 					// validate the php version
 					phpvalidator := validate.PHPVersionValidator{}
 					if err := phpvalidator.Validate(s.Version); err != nil {
@@ -75,7 +108,20 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				}
 			}
 
+			// show any warnings, such as hostnames not using the tld
+			if warnings := cfg.Validate(); len(warnings) > 0 {
+				output.Info("Warnings:")
+				for _, w := range warnings {
+					output.Info(" \u2610", w.Error())
+				}
+			}
+
 			// show any errors
+			if portErr != nil {
+				output.Info("Port Errors:")
+				output.Info(" \u2610", portErr.Error())
+			}
+
 			if len(siteErrs) > 0 {
 				output.Info("Site Errors:")
 				for _, e := range siteErrs {
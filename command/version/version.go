@@ -1,7 +1,9 @@
 package version
 
 import (
+	"encoding/json"
 	"fmt"
+	"runtime"
 
 	"github.com/docker/docker/client"
 	"github.com/spf13/cobra"
@@ -17,7 +19,38 @@ import (
 // container to use to verify the gRPC API is in sync.
 var Version = "develop"
 
-var exampleText = `nitro version`
+// Commit and BuildDate are set with ldflags when nitro is built.
+var (
+	Commit    = "none"
+	BuildDate = "unknown"
+)
+
+var exampleText = `  # show the version info
+  nitro version
+
+  # show only the version
+  nitro version --format short
+
+  # show the build info as json
+  nitro version --format json`
+
+// Info is the build info that is shown with the json format.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Build returns the build info for the nitro cli.
+func Build() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+}
 
 // NewCommand is used to show the cli and gRPC API client version
 func NewCommand(home string, client client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) *cobra.Command {
@@ -26,9 +59,28 @@ func NewCommand(home string, client client.CommonAPIClient, nitrod protob.NitroC
 		Short:   "Show version info",
 		Example: exampleText,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// the short and json formats do not need the environment
+			if cmd.Flag("format").Value.String() != "" {
+				return nil
+			}
+
 			return prompt.VerifyInit(cmd, args, home, output)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			switch cmd.Flag("format").Value.String() {
+			case "":
+			case "short":
+				fmt.Fprintln(cmd.OutOrStdout(), Version)
+				return nil
+			case "json":
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+
+				return enc.Encode(Build())
+			default:
+				return fmt.Errorf("unknown format %q, the format must be short or json", cmd.Flag("format").Value.String())
+			}
+
 			var vers string
 			nitro, err := nitrod.Version(cmd.Context(), &protob.VersionRequest{})
 			if err != nil {
@@ -71,5 +123,7 @@ func NewCommand(home string, client client.CommonAPIClient, nitrod protob.NitroC
 		},
 	}
 
+	cmd.Flags().String("format", "", "format the output as short or json")
+
 	return cmd
 }
@@ -0,0 +1,72 @@
+package version
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"sort"
+	"testing"
+)
+
+func TestVersionCommand_JSONFormat(t *testing.T) {
+	// Arrange
+	Version, Commit, BuildDate = "2.0.5", "abc1234", "2021-03-01T12:00:00Z"
+	defer func() {
+		Version, Commit, BuildDate = "develop", "none", "unknown"
+	}()
+
+	buf := &bytes.Buffer{}
+	cmd := NewCommand("testdata", nil, nil, nil)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--format", "json"})
+
+	// Act
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	got := make(map[string]string)
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("expected the output to be json, got %q, %v", buf.String(), err)
+	}
+
+	var keys []string
+	for k := range got {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if want := []string{"build_date", "commit", "go_version", "version"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected the keys %v, got %v", want, keys)
+	}
+
+	want := map[string]string{
+		"version":    "2.0.5",
+		"commit":     "abc1234",
+		"build_date": "2021-03-01T12:00:00Z",
+		"go_version": runtime.Version(),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestVersionCommand_ShortFormat(t *testing.T) {
+	Version = "2.0.5"
+	defer func() { Version = "develop" }()
+
+	buf := &bytes.Buffer{}
+	cmd := NewCommand("testdata", nil, nil, nil)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--format", "short"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != "2.0.5\n" {
+		t.Errorf("expected only the version, got %q", got)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"syscall"
 
@@ -76,7 +77,7 @@ func (svc *Service) AddDatabase(ctx context.Context, req *protob.AddDatabaseRequ
 	var addCommand, privilegesCommand []string
 	switch engine {
 	case "mysql":
-		addCommand = []string{"--user=nitro", fmt.Sprintf("--host=%s", hostname), "-pnitro", fmt.Sprintf(`-e CREATE DATABASE IF NOT EXISTS %s;`, db)}
+		addCommand = []string{"--user=nitro", fmt.Sprintf("--host=%s", hostname), "-pnitro", "-e " + database.MySQLCreateStatement(db, req.GetDatabase().GetCharset(), req.GetDatabase().GetCollation())}
 		privilegesCommand = []string{"--user=nitro", fmt.Sprintf("--host=%s", hostname), "-pnitro", fmt.Sprintf(`-e CREATE DATABASE IF NOT EXISTS %s;`, db)}
 	default:
 		addCommand = []string{fmt.Sprintf("--host=%s", hostname), "--port=" + port, "--username=nitro", fmt.Sprintf(`-c CREATE DATABASE %s;`, db)}
@@ -113,34 +114,7 @@ func (svc *Service) Apply(ctx context.Context, request *protob.ApplyRequest) (*p
 	}
 
 	// convert each of the sites into a route
-	routes := []caddy.ServerRoute{}
-	for k, site := range request.GetSites() {
-		// get all of the host names for the site
-		hosts := []string{site.GetHostname()}
-		if site.GetAliases() != "" {
-			hosts = append(hosts, strings.Split(site.GetAliases(), ",")...)
-		}
-
-		// create the route for each of the sites
-		routes = append(routes, caddy.ServerRoute{
-			Handle: []caddy.RouteHandle{
-				{
-					Handler: "reverse_proxy",
-					Upstreams: []caddy.Upstream{
-						{
-							Dial: fmt.Sprintf("%s:%d", k, site.GetPort()),
-						},
-					},
-				},
-			},
-			Match: []caddy.Match{
-				{
-					Host: hosts,
-				},
-			},
-			Terminal: true,
-		})
-	}
+	routes := siteRoutes(request.GetSites())
 
 	update := caddy.UpdateRequest{}
 
@@ -148,11 +122,13 @@ func (svc *Service) Apply(ctx context.Context, request *protob.ApplyRequest) (*p
 	update.HTTPS = caddy.Server{
 		Listen: []string{":443"},
 		Routes: routes,
+		Logs:   &caddy.ServerLogs{},
 	}
 
 	// set the default welcome server
 	update.HTTP = caddy.Server{
 		Listen: []string{":80"},
+		Logs:   &caddy.ServerLogs{},
 		Routes: append(routes, caddy.ServerRoute{
 			Handle: []caddy.RouteHandle{
 				{
@@ -262,6 +238,10 @@ func (svc *Service) ImportDatabase(stream protob.Nitro_ImportDatabaseServer) err
 		opts.CompressionType = req.GetDatabase().GetCompressionType()
 	}
 
+	// set the charset and collation for mysql
+	opts.Charset = req.GetDatabase().GetCharset()
+	opts.Collation = req.GetDatabase().GetCollation()
+
 	// handle the streaming request
 	for {
 		req, err := stream.Recv()
@@ -358,9 +338,10 @@ func (svc *Service) ImportDatabase(stream protob.Nitro_ImportDatabaseServer) err
 	)
 }
 
-// Ping returns a simple response "pong" from the gRPC API to verify connectivity.
+// Ping returns a simple response "pong" from the gRPC API to verify connectivity,
+// and the version of the API so the CLI can check the proxy supports its requests.
 func (svc *Service) Ping(ctx context.Context, request *protob.PingRequest) (*protob.PingResponse, error) {
-	return &protob.PingResponse{Pong: "pong"}, nil
+	return &protob.PingResponse{Pong: "pong", ApiVersion: protob.APIVersion}, nil
 }
 
 // RemoveDatabase handles removing a specific database from a database container
@@ -432,3 +413,67 @@ func (svc *Service) exec(tool string, commands []string) error {
 
 	return nil
 }
+
+// siteRoutes takes the sites from an apply request and returns the routes for
+// the proxy. Wildcard hosts (e.g. *.mysite.nitro) are added as separate routes
+// after all of the other routes so a site with a specific hostname is always
+// matched before a wildcard for the same domain.
+func siteRoutes(sites map[string]*protob.Site) []caddy.ServerRoute {
+	// sort the sites so the routes are always in the same order
+	var keys []string
+	for k := range sites {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	route := func(upstream string, port int32, hosts []string) caddy.ServerRoute {
+		return caddy.ServerRoute{
+			Handle: []caddy.RouteHandle{
+				{
+					Handler: "reverse_proxy",
+					Upstreams: []caddy.Upstream{
+						{
+							Dial: fmt.Sprintf("%s:%d", upstream, port),
+						},
+					},
+				},
+			},
+			Match: []caddy.Match{
+				{
+					Host: hosts,
+				},
+			},
+			Terminal: true,
+		}
+	}
+
+	routes := []caddy.ServerRoute{}
+	wildcards := []caddy.ServerRoute{}
+	for _, k := range keys {
+		site := sites[k]
+
+		// get all of the host names for the site
+		hosts := []string{site.GetHostname()}
+		var wildcardHosts []string
+		if site.GetAliases() != "" {
+			for _, a := range strings.Split(site.GetAliases(), ",") {
+				if strings.HasPrefix(a, "*.") {
+					wildcardHosts = append(wildcardHosts, a)
+					continue
+				}
+
+				hosts = append(hosts, a)
+			}
+		}
+
+		// create the route for each of the sites
+		routes = append(routes, route(k, site.GetPort(), hosts))
+
+		if len(wildcardHosts) > 0 {
+			wildcards = append(wildcards, route(k, site.GetPort(), wildcardHosts))
+		}
+	}
+
+	return append(routes, wildcards...)
+}
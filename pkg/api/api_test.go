@@ -30,7 +30,7 @@ func TestService_Ping(t *testing.T) {
 				ctx:     context.TODO(),
 				request: &protob.PingRequest{},
 			},
-			want:    &protob.PingResponse{Pong: "pong"},
+			want:    &protob.PingResponse{Pong: "pong", ApiVersion: protob.APIVersion},
 			wantErr: false,
 		},
 	}
@@ -92,3 +92,39 @@ func TestService_Version(t *testing.T) {
 		})
 	}
 }
+
+func Test_siteRoutes(t *testing.T) {
+	sites := map[string]*protob.Site{
+		"mysite.nitro": {
+			Hostname: "mysite.nitro",
+			Aliases:  "*.mysite.nitro,othersite.nitro",
+			Port:     8080,
+		},
+		"api.mysite.nitro": {
+			Hostname: "api.mysite.nitro",
+			Port:     8080,
+		},
+	}
+
+	got := siteRoutes(sites)
+
+	var hosts [][]string
+	for _, r := range got {
+		hosts = append(hosts, r.Match[0].Host)
+	}
+
+	// wildcards should be matched after all of the other hosts
+	want := [][]string{
+		{"api.mysite.nitro"},
+		{"mysite.nitro", "othersite.nitro"},
+		{"*.mysite.nitro"},
+	}
+
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("siteRoutes() = \ngot:\n%v\nwant:\n%v", hosts, want)
+	}
+
+	if dial := got[2].Handle[0].Upstreams[0].Dial; dial != "mysite.nitro:8080" {
+		t.Errorf("expected the wildcard to proxy to mysite.nitro:8080, got %s", dial)
+	}
+}
@@ -10,12 +10,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/datetime"
 	"github.com/craftcms/nitro/pkg/helpers"
 	"github.com/craftcms/nitro/pkg/terminal"
 )
@@ -60,20 +62,37 @@ func (o *Options) Validate() error {
 	return nil
 }
 
+// NewOptions returns the options to backup a database in a container, the backup
+// name uses the current time and the backup command is based on the compatibility
+// of the engine (e.g. postgres or mysql).
+func NewOptions(home, containerID, containerName, compatibility, database string) *Options {
+	opts := &Options{
+		BackupName:    fmt.Sprintf("%s-%s.sql", database, datetime.Parse(time.Now())),
+		ContainerID:   containerID,
+		ContainerName: containerName,
+		Database:      database,
+		Home:          home,
+	}
+
+	switch compatibility {
+	case "postgres":
+		opts.Commands = []string{"pg_dump", "--username=nitro", database, "-f", "/tmp/" + opts.BackupName}
+	default:
+		opts.Commands = []string{"/usr/bin/mysqldump", "-h", "127.0.0.1", "-unitro", "--password=nitro", database, "--result-file=" + "/tmp/" + opts.BackupName}
+	}
+
+	return opts
+}
+
 // Prompt is used to ask a user for input and walk them through selecting a database engine (container) and a database. It will return the container ID
 // as the first string, the database name, and the last return is an error.
 func Prompt(ctx context.Context, reader io.Reader, docker client.ContainerAPIClient, output terminal.Outputer, containers []types.Container, containerList []string) (string, string, string, string, error) {
 	// prompt the user for which database to backup
-	selected, err := output.Select(reader, "Which database engine? ", containerList)
+	id, name, compatibility, err := PromptEngine(reader, output, containers, containerList)
 	if err != nil {
 		return "", "", "", "", err
 	}
 
-	// get the selected container details
-	name := containers[selected].Names[0]
-	id := containers[selected].ID
-	compatibility := containers[selected].Labels[containerlabels.DatabaseCompatibility]
-
 	// get all of the databases based on the engine
 	databases, err := Databases(ctx, docker, id, compatibility)
 	if err != nil {
@@ -101,6 +120,22 @@ func Prompt(ctx context.Context, reader io.Reader, docker client.ContainerAPICli
 	return id, name, compatibility, db, nil
 }
 
+// PromptEngine is used to ask a user to select a database engine (container). It returns the container ID, the
+// container name, and the database compatibility of the selected container.
+func PromptEngine(reader io.Reader, output terminal.Outputer, containers []types.Container, containerList []string) (string, string, string, error) {
+	selected, err := output.Select(reader, "Which database engine? ", containerList)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// get the selected container details
+	name := containers[selected].Names[0]
+	id := containers[selected].ID
+	compatibility := containers[selected].Labels[containerlabels.DatabaseCompatibility]
+
+	return id, name, compatibility, nil
+}
+
 // Databases is used to get a list of all the databases for a specific engine. It is returned as a slice of strings using the
 // containers hostname (e.g. mysql-8.0-3306) so it can be presented to the user as a list.
 func Databases(ctx context.Context, docker client.ContainerAPIClient, containerID, compatibility string) ([]string, error) {
@@ -0,0 +1,48 @@
+package backup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		compatibility string
+		want          []string
+	}{
+		{
+			name:          "postgres databases use pg_dump",
+			compatibility: "postgres",
+			want:          []string{"pg_dump", "--username=nitro", "craft", "-f"},
+		},
+		{
+			name:          "mysql databases use mysqldump",
+			compatibility: "mysql",
+			want:          []string{"/usr/bin/mysqldump", "-h", "127.0.0.1", "-unitro", "--password=nitro", "craft"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewOptions("/home/nitro", "abc123", "mysql-8.0-3306.database.nitro", tt.compatibility, "craft")
+
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("expected valid options, got %v", err)
+			}
+
+			if !strings.HasPrefix(opts.BackupName, "craft-") || !strings.HasSuffix(opts.BackupName, ".sql") {
+				t.Errorf("expected the backup name to use the database, got %s", opts.BackupName)
+			}
+
+			if got := opts.Commands[:len(tt.want)]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected the commands to start with %v, got %v", tt.want, opts.Commands)
+			}
+
+			// the backup is written to the temp directory of the container
+			if last := opts.Commands[len(opts.Commands)-1]; !strings.HasSuffix(last, "/tmp/"+opts.BackupName) {
+				t.Errorf("expected the backup to be written to /tmp, got %s", last)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// Schema returns the schema of the database without any of the data, which
+// is used to share the structure of a database without the records.
+func Schema(ctx context.Context, docker client.ContainerAPIClient, containerID, compatibility, database string) (string, error) {
+	var commands []string
+	switch compatibility {
+	case "mysql":
+		commands = []string{"mysqldump", "-unitro", "-pnitro", "--no-data", "--skip-comments", "--skip-add-drop-table", database}
+	case "postgres":
+		commands = []string{"pg_dump", "--username=nitro", "--schema-only", "--no-owner", "--no-privileges", database}
+	default:
+		return "", fmt.Errorf("unknown database compatibility %q", compatibility)
+	}
+
+	exec, err := docker.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          false,
+		Cmd:          commands,
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to create the schema dump for %s, %w", database, err)
+	}
+
+	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{Tty: false})
+	if err != nil {
+		return "", fmt.Errorf("unable to attach to the schema dump for %s, %w", database, err)
+	}
+	defer resp.Close()
+
+	if err := docker.ContainerExecStart(ctx, exec.ID, types.ExecStartCheck{}); err != nil {
+		return "", fmt.Errorf("unable to start the schema dump for %s, %w", database, err)
+	}
+
+	// the password warning is written to stderr, so only stdout is the schema
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if _, err := stdcopy.StdCopy(stdout, stderr, resp.Reader); err != nil {
+		return "", fmt.Errorf("unable to read the schema dump for %s, %w", database, err)
+	}
+
+	for {
+		info, err := docker.ContainerExecInspect(ctx, exec.ID)
+		if err != nil {
+			return "", err
+		}
+
+		if info.Running {
+			continue
+		}
+
+		if info.ExitCode != 0 {
+			return "", fmt.Errorf("unable to dump the schema for %s, %s", database, strings.TrimSpace(stderr.String()))
+		}
+
+		return stdout.String(), nil
+	}
+}
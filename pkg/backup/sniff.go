@@ -0,0 +1,69 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// SniffSize is the number of bytes read from the start of a backup to detect the engine.
+const SniffSize = 8 * 1024
+
+// ErrUnknownEngine is returned when the engine can not be detected from the backup.
+var ErrUnknownEngine = fmt.Errorf("unable to detect the database engine from the backup")
+
+// markers are the strings found in the header of mysql and postgres dumps, the
+// postgres markers are checked first because both engines use "SET" statements.
+var markers = []struct {
+	engine  string
+	markers []string
+}{
+	{
+		engine:  "postgres",
+		markers: []string{"PostgreSQL database dump", "pg_dump", "pg_catalog", "SET client_encoding"},
+	},
+	{
+		engine:  "mysql",
+		markers: []string{"MySQL dump", "mysqldump", "MariaDB", "mariadb", "phpMyAdmin", "ENGINE=InnoDB", "/*!40101 SET"},
+	},
+}
+
+// Sniff reads the start of a backup and returns the engine (mysql or postgres)
+// that created the backup. If the engine is not detected ErrUnknownEngine is
+// returned.
+func Sniff(r io.Reader) (string, error) {
+	header := make([]byte, SniffSize)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("unable to read the backup, %w", err)
+	}
+
+	header = header[:n]
+
+	// custom format dumps from pg_dump start with PGDMP
+	if bytes.HasPrefix(header, []byte("PGDMP")) {
+		return "postgres", nil
+	}
+
+	for _, m := range markers {
+		for _, s := range m.markers {
+			if bytes.Contains(header, []byte(s)) {
+				return m.engine, nil
+			}
+		}
+	}
+
+	return "", ErrUnknownEngine
+}
+
+// SniffFile opens the backup file and returns the engine using Sniff.
+func SniffFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to open the backup, %w", err)
+	}
+	defer f.Close()
+
+	return Sniff(f)
+}
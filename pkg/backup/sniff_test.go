@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backup  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "mysqldump backups are mysql",
+			backup: "-- MySQL dump 10.13  Distrib 8.0.22, for Linux (x86_64)\n--\n-- Host: localhost    Database: nitro\n",
+			want:   "mysql",
+		},
+		{
+			name:   "mariadb backups are mysql",
+			backup: "-- MariaDB dump 10.19  Distrib 10.5.9-MariaDB, for Linux (x86_64)\n",
+			want:   "mysql",
+		},
+		{
+			name:   "phpmyadmin backups are mysql",
+			backup: "-- phpMyAdmin SQL Dump\n-- version 4.9.5\n",
+			want:   "mysql",
+		},
+		{
+			name:   "pg_dump backups are postgres",
+			backup: "--\n-- PostgreSQL database dump\n--\n\n-- Dumped by pg_dump version 11.5\n\nSET statement_timeout = 0;\n",
+			want:   "postgres",
+		},
+		{
+			name:   "pg_dump custom format backups are postgres",
+			backup: "PGDMP\x01\x0e\x00\x04\x08\x01\x01",
+			want:   "postgres",
+		},
+		{
+			name:    "markers after the header are not used",
+			backup:  strings.Repeat("INSERT INTO users VALUES (1);\n", SniffSize/10) + "-- MySQL dump completed",
+			wantErr: ErrUnknownEngine,
+		},
+		{
+			name:    "unknown backups return an error",
+			backup:  "this is not a backup",
+			wantErr: ErrUnknownEngine,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Sniff(strings.NewReader(tt.backup))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Sniff() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Sniff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
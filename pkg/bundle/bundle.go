@@ -0,0 +1,121 @@
+// Package bundle is used to export the config for an environment, and
+// optionally the database schemas, into a single file that can be shared and
+// imported to recreate the environment.
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+// Version is the version of the bundle format.
+const Version = 1
+
+// Bundle is the portable version of an environment.
+type Bundle struct {
+	Version int            `yaml:"version"`
+	Config  *config.Config `yaml:"config"`
+	Schemas []Schema       `yaml:"schemas,omitempty"`
+
+	// Secrets are the names of the secrets that were replaced with a
+	// reference and need to be set when the bundle is imported
+	Secrets []string `yaml:"secrets,omitempty"`
+}
+
+// Schema is the structure of a database without any data.
+type Schema struct {
+	// Engine is the hostname of the database engine (e.g. mysql-8.0-3306)
+	Engine   string `yaml:"engine"`
+	Database string `yaml:"database"`
+	SQL      string `yaml:"sql"`
+}
+
+// autoIncrementRegex matches the AUTO_INCREMENT table option, which shows how many rows a table had
+var autoIncrementRegex = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+// New returns a bundle for the config. Unless includeSecrets is true, the
+// blackfire credentials are replaced with a reference to a secret so they are
+// not shared with the bundle.
+func New(cfg *config.Config, includeSecrets bool) (*Bundle, error) {
+	// copy the config so the credentials are not removed from the original
+	data, err := cfg.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal the config, %w", err)
+	}
+
+	c := &config.Config{}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("unable to copy the config, %w", err)
+	}
+
+	b := &Bundle{Version: Version, Config: c}
+
+	if includeSecrets {
+		return b, nil
+	}
+
+	for name, value := range map[string]*string{
+		"blackfire.server_id":    &c.Blackfire.ServerID,
+		"blackfire.server_token": &c.Blackfire.ServerToken,
+	} {
+		// values that already reference an environment variable or secret are safe to share
+		if *value == "" || strings.Contains(*value, "${") {
+			continue
+		}
+
+		*value = config.SecretRef(name)
+		b.Secrets = append(b.Secrets, name)
+	}
+
+	// sort the secrets so the bundle is the same each time
+	sort.Strings(b.Secrets)
+
+	return b, nil
+}
+
+// AddSchema adds the schema for the database to the bundle. The AUTO_INCREMENT
+// values are removed so the schema does not show how many rows the tables had.
+func (b *Bundle) AddSchema(engine, database, sql string) {
+	b.Schemas = append(b.Schemas, Schema{
+		Engine:   engine,
+		Database: database,
+		SQL:      autoIncrementRegex.ReplaceAllString(sql, ""),
+	})
+}
+
+// Write writes the bundle as yaml.
+func (b *Bundle) Write(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(b); err != nil {
+		return fmt.Errorf("unable to write the bundle, %w", err)
+	}
+
+	return enc.Close()
+}
+
+// Read returns the bundle from the reader, it returns an error if the bundle
+// does not have a config or was created by a newer version of nitro.
+func Read(r io.Reader) (*Bundle, error) {
+	b := &Bundle{}
+	if err := yaml.NewDecoder(r).Decode(b); err != nil {
+		return nil, fmt.Errorf("unable to read the bundle, %w", err)
+	}
+
+	switch {
+	case b.Version == 0 || b.Config == nil:
+		return nil, fmt.Errorf("the file is not a nitro export")
+	case b.Version > Version:
+		return nil, fmt.Errorf("the export uses version %d of the format, update nitro to import it", b.Version)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,157 @@
+package bundle
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Blackfire: config.Blackfire{ServerID: "my-server-id", ServerToken: "${BLACKFIRE_SERVER_TOKEN}"},
+		Databases: []config.Database{{Engine: "mysql", Version: "8.0", Port: "3306"}},
+		Sites: []config.Site{{
+			Hostname: "craft-dev.nitro",
+			Aliases:  []string{"api.craft-dev.nitro"},
+			Path:     "~/dev/craft-dev",
+			Version:  "7.4",
+			Webroot:  "web",
+		}},
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name           string
+		includeSecrets bool
+		wantID         string
+		wantToken      string
+		wantSecrets    []string
+	}{
+		{
+			name:        "plain text credentials are replaced with a secret",
+			wantID:      "${secret:blackfire.server_id}",
+			wantToken:   "${BLACKFIRE_SERVER_TOKEN}",
+			wantSecrets: []string{"blackfire.server_id"},
+		},
+		{
+			name:           "credentials are kept when including secrets",
+			includeSecrets: true,
+			wantID:         "my-server-id",
+			wantToken:      "${BLACKFIRE_SERVER_TOKEN}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+
+			got, err := New(cfg, tt.includeSecrets)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Config.Blackfire.ServerID != tt.wantID {
+				t.Errorf("expected the server id %q, got %q", tt.wantID, got.Config.Blackfire.ServerID)
+			}
+
+			if got.Config.Blackfire.ServerToken != tt.wantToken {
+				t.Errorf("expected the server token %q, got %q", tt.wantToken, got.Config.Blackfire.ServerToken)
+			}
+
+			if !reflect.DeepEqual(got.Secrets, tt.wantSecrets) {
+				t.Errorf("expected the secrets %v, got %v", tt.wantSecrets, got.Secrets)
+			}
+
+			if cfg.Blackfire.ServerID != "my-server-id" {
+				t.Errorf("expected the original config to keep the server id, got %q", cfg.Blackfire.ServerID)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	// Arrange
+	b, err := New(testConfig(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.AddSchema("mysql-8.0-3306", "craft", "CREATE TABLE `users` (\n  `id` int NOT NULL AUTO_INCREMENT\n) ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4;\n")
+
+	buf := new(bytes.Buffer)
+
+	// Act
+	if err := b.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	want, err := b.Config.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := got.Config.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(imported) != string(want) {
+		t.Errorf("expected the imported config to match:\n%s\ngot:\n%s", want, imported)
+	}
+
+	if !reflect.DeepEqual(got.Schemas, b.Schemas) || !reflect.DeepEqual(got.Secrets, b.Secrets) {
+		t.Errorf("expected the schemas and secrets to match, got %+v", got)
+	}
+
+	if sql := got.Schemas[0].SQL; strings.Contains(sql, "AUTO_INCREMENT=42") || !strings.Contains(sql, "NOT NULL AUTO_INCREMENT") {
+		t.Errorf("expected only the auto increment table option to be removed, got %q", sql)
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string
+	}{
+		{
+			name:    "files without a config return an error",
+			file:    "sites:\n  - hostname: craft-dev.nitro\n",
+			wantErr: "not a nitro export",
+		},
+		{
+			name:    "newer versions return an error",
+			file:    "version: 2\nconfig:\n  sites: []\n",
+			wantErr: "update nitro",
+		},
+		{
+			name: "the current version is read",
+			file: "version: 1\nconfig:\n  sites:\n    - hostname: craft-dev.nitro\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.file))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected the error to contain %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -8,8 +8,13 @@ type UpdateRequest struct {
 type Server struct {
 	Listen []string      `json:"listen"`
 	Routes []ServerRoute `json:"routes"`
+	Logs   *ServerLogs   `json:"logs,omitempty"`
 }
 
+// ServerLogs enables the access logs for a server, the logs are
+// written to the default logger (stderr).
+type ServerLogs struct{}
+
 type ServerRoute struct {
 	Handle   []RouteHandle `json:"handle"`
 	Match    []Match       `json:"match,omitempty"`
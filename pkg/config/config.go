@@ -3,13 +3,22 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+
+	// embed the timezone database so timezones are validated on every platform
+	_ "time/tzdata"
 
 	"github.com/craftcms/nitro/pkg/helpers"
+	"github.com/craftcms/nitro/pkg/imageref"
+	"github.com/craftcms/nitro/pkg/phpversions"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,6 +27,12 @@ var (
 	// DirectoryName is the name of the directory to store nitro configs
 	DirectoryName = ".nitro"
 
+	// DefaultTLD is the top level domain used for sites when the config does not set one
+	DefaultTLD = "nitro"
+
+	// DefaultBindAddress is the host address ports are bound to when the config does not set one
+	DefaultBindAddress = "127.0.0.1"
+
 	// ErrNoConfigFile is returned when a configuration file cannot be found
 	ErrNoConfigFile = fmt.Errorf("there is no config file for the environment")
 
@@ -48,16 +63,191 @@ var (
 
 // Config represents the nitro-dev.yaml users add for local development.
 type Config struct {
-	Containers []Container `json:"containers,omitempty" yaml:"containers,omitempty"`
-	Blackfire  Blackfire   `json:"blackfire,omitempty" yaml:"blackfire,omitempty"`
-	Databases  []Database  `json:"databases,omitempty" yaml:"databases,omitempty"`
-	Services   Services    `json:"services" yaml:"services"`
-	Sites      []Site      `json:"sites,omitempty" yaml:"sites,omitempty"`
-	File       string      `json:"-" yaml:"-"`
+	BindAddress string      `json:"bind_address,omitempty" yaml:"bind_address,omitempty"`
+	Containers  []Container `json:"containers,omitempty" yaml:"containers,omitempty"`
+	Blackfire   Blackfire   `json:"blackfire,omitempty" yaml:"blackfire,omitempty"`
+	Databases   []Database  `json:"databases,omitempty" yaml:"databases,omitempty"`
+	DefaultPHP  string      `json:"default_php,omitempty" yaml:"default_php,omitempty"`
+	Dnsmasq     bool        `json:"dnsmasq,omitempty" yaml:"dnsmasq,omitempty"`
+	EditHosts   *bool       `json:"edit_hosts,omitempty" yaml:"edit_hosts,omitempty"`
+	Hooks       Hooks       `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	Proxy       Proxy       `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	Registry    string      `json:"registry,omitempty" yaml:"registry,omitempty"`
+	Replication bool        `json:"replication,omitempty" yaml:"replication,omitempty"`
+	Restart     Restart     `json:"restart,omitempty" yaml:"restart,omitempty"`
+	Services    Services    `json:"services" yaml:"services"`
+	Sites       []Site      `json:"sites,omitempty" yaml:"sites,omitempty"`
+	TLD         string      `json:"tld,omitempty" yaml:"tld,omitempty"`
+	Timezone    string      `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	File        string      `json:"-" yaml:"-"`
+
+	// tokens are the values that reference environment variables
+	tokens map[string]token
 
 	rw sync.RWMutex
 }
 
+// GetDefaultPHP returns the PHP version for sites that do not set a
+// version, which defaults to phpversions.Default.
+func (c *Config) GetDefaultPHP() string {
+	if c.DefaultPHP == "" {
+		return phpversions.Default
+	}
+
+	return c.DefaultPHP
+}
+
+// GetBindAddress returns the host address the proxy, databases, services,
+// and custom containers bind their ports to, which defaults to
+// DefaultBindAddress.
+func (c *Config) GetBindAddress() string {
+	if c.BindAddress == "" {
+		return DefaultBindAddress
+	}
+
+	return c.BindAddress
+}
+
+// GetDatabaseBindAddress returns the host address for the database port, the
+// bind address on the database takes priority over the global bind address.
+func (c *Config) GetDatabaseBindAddress(db Database) string {
+	if db.BindAddress != "" {
+		return db.BindAddress
+	}
+
+	return c.GetBindAddress()
+}
+
+// GetServiceBindAddress takes the name of a service (e.g. redis) and returns
+// the host address for the service ports, the bind address for the service
+// takes priority over the global bind address.
+func (c *Config) GetServiceBindAddress(name string) string {
+	if addr := c.Services.BindAddresses[name]; addr != "" {
+		return addr
+	}
+
+	return c.GetBindAddress()
+}
+
+// ValidateBindAddresses returns an error if the global, database, or service
+// bind addresses are not a valid IPv4 or IPv6 address (e.g. 0.0.0.0 or ::1).
+func (c *Config) ValidateBindAddresses() error {
+	if c.BindAddress != "" && net.ParseIP(c.BindAddress) == nil {
+		return fmt.Errorf("the bind_address %q is not a valid IP address", c.BindAddress)
+	}
+
+	for _, db := range c.Databases {
+		if db.BindAddress != "" && net.ParseIP(db.BindAddress) == nil {
+			return fmt.Errorf("the bind_address %q for the %s %s database is not a valid IP address", db.BindAddress, db.Engine, db.Version)
+		}
+	}
+
+	names := make([]string, 0, len(c.Services.BindAddresses))
+	for name := range c.Services.BindAddresses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if addr := c.Services.BindAddresses[name]; addr != "" && net.ParseIP(addr) == nil {
+			return fmt.Errorf("the bind address %q for the %s service is not a valid IP address", addr, name)
+		}
+	}
+
+	return nil
+}
+
+// GetSiteTimezone returns the timezone for the site, which defaults to the
+// global timezone. An empty timezone uses the default of the image (UTC).
+func (c *Config) GetSiteTimezone(site Site) string {
+	if site.Timezone != "" {
+		return site.Timezone
+	}
+
+	return c.Timezone
+}
+
+// ValidateRegistry returns an error if the registry mirror is not a hostname
+// with an optional port and path (e.g. mirror.example.com:5000/hub).
+func (c *Config) ValidateRegistry() error {
+	return imageref.Validate(c.Registry)
+}
+
+// ValidateTimezones returns an error if the global or a sites timezone is
+// not in the IANA timezone database (e.g. America/Chicago).
+func (c *Config) ValidateTimezones() error {
+	if err := validTimezone(c.Timezone); err != nil {
+		return fmt.Errorf("the timezone %q is not a valid IANA timezone (e.g. Europe/Berlin)", c.Timezone)
+	}
+
+	for _, s := range c.Sites {
+		if err := validTimezone(s.Timezone); err != nil {
+			return fmt.Errorf("the timezone %q for %s is not a valid IANA timezone (e.g. Europe/Berlin)", s.Timezone, s.Hostname)
+		}
+	}
+
+	return nil
+}
+
+// validTimezone returns an error if the timezone can not be loaded, the
+// Local timezone depends on the host so it is not allowed.
+func validTimezone(tz string) error {
+	switch tz {
+	case "":
+		return nil
+	case "Local":
+		return fmt.Errorf("unknown time zone %s", tz)
+	}
+
+	_, err := time.LoadLocation(tz)
+
+	return err
+}
+
+// TimezoneEnvs returns the environment variables that set the timezone for
+// the container (TZ) and the PHP date.timezone setting.
+func TimezoneEnvs(tz string) []string {
+	if tz == "" {
+		return nil
+	}
+
+	return []string{"TZ=" + tz, "PHP_DATE_TIMEZONE=" + tz}
+}
+
+// GetTLD returns the top level domain for site hostnames without
+// the leading period (e.g. nitro), which defaults to DefaultTLD.
+func (c *Config) GetTLD() string {
+	tld := strings.Trim(c.TLD, ".")
+	if tld == "" {
+		return DefaultTLD
+	}
+
+	return strings.ToLower(tld)
+}
+
+// Validate checks the config for problems that do not prevent the config
+// from being used and returns a warning for each one, such as a site
+// hostname or alias that does not use the top level domain.
+func (c *Config) Validate() []error {
+	tld := c.GetTLD()
+
+	var warnings []error
+	for _, s := range c.Sites {
+		for _, h := range append([]string{s.Hostname}, s.Aliases...) {
+			if !strings.HasSuffix(strings.ToLower(h), "."+tld) {
+				warnings = append(warnings, fmt.Errorf("the hostname %s for %s does not use the .%s top level domain", h, s.Hostname, tld))
+			}
+		}
+
+		// the xdebug images can turn off opcache, so the setting might not apply
+		if s.Xdebug && s.PHP.OpcacheEnable {
+			warnings = append(warnings, fmt.Errorf("xdebug is enabled for %s, opcache may be disabled by the image", s.Hostname))
+		}
+	}
+
+	return warnings
+}
+
 // AllSitesWithHostnames takes the address, which is the nitro-proxy
 // ip address, and the current site and returns a list of all the
 func (c *Config) AllSitesWithHostnames(site Site, addr string) map[string][]string {
@@ -75,6 +265,18 @@ func (c *Config) AllSitesWithHostnames(site Site, addr string) map[string][]stri
 	return hostnames
 }
 
+// EnabledSites returns the sites that are not disabled.
+func (c *Config) EnabledSites() []Site {
+	var sites []Site
+	for _, s := range c.Sites {
+		if !s.Disabled {
+			sites = append(sites, s)
+		}
+	}
+
+	return sites
+}
+
 // FindSiteByHostName takes a hostname and returns the site if the hostnames match.
 func (c *Config) FindSiteByHostName(hostname string) (*Site, error) {
 	// find the site by the hostname
@@ -130,13 +332,110 @@ type Container struct {
 	// containers port in the <host>:<container> syntax
 	Ports []string `json:"ports,omitempty" yaml:"ports,omitempty"`
 
-	// Volume stores the volumes we should create and maintain for the container (e.g. <name>_container_<vol>_nitro_volume)
+	// Volume stores the volumes we should create and maintain for the container (e.g. <name>_container_<vol>_nitro_volume),
+	// a host path can be mounted using the <host>:<container> syntax (e.g. ~/dev/mysite:/app)
 	Volumes []string `json:"volumes,omitempty" yaml:"volumes,omitempty"`
 
+	// Command overrides the default command of the image (e.g. ["php", "craft", "queue/listen"])
+	Command []string `json:"command,omitempty" yaml:"command,omitempty"`
+
+	// Entrypoint overrides the default entrypoint of the image
+	Entrypoint []string `json:"entrypoint,omitempty" yaml:"entrypoint,omitempty"`
+
+	// Env are the environment variables set in the container, they override
+	// the variables from the env file
+	Env map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+
 	WebGui  int    `json:"web_gui,omitempty" yaml:"web_gui,omitempty"`
 	EnvFile string `json:"env_file,omitempty" yaml:"env_file,omitempty"`
 }
 
+// Validate returns an error if the container does not have a name or image, or
+// if a port does not use the <host>:<container> syntax.
+func (c *Container) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("the container for %q does not have a name", c.Image)
+	}
+
+	if strings.ContainsAny(c.Name, " /:") {
+		return fmt.Errorf("the container name %q can not contain spaces, slashes, or colons", c.Name)
+	}
+
+	if strings.TrimSpace(c.Image) == "" {
+		return fmt.Errorf("the container %s does not have an image", c.Name)
+	}
+
+	for _, p := range c.Ports {
+		parts := strings.Split(p, ":")
+		if len(parts) != 2 {
+			return fmt.Errorf("the port %q for the container %s must use the <host>:<container> syntax", p, c.Name)
+		}
+
+		for _, n := range parts {
+			if port, err := strconv.Atoi(n); err != nil || port < 1 || port > 65535 {
+				return fmt.Errorf("the port %q for the container %s is not a valid port", p, c.Name)
+			}
+		}
+	}
+
+	for _, v := range c.Volumes {
+		if strings.TrimSpace(v) == "" || strings.HasSuffix(v, ":") {
+			return fmt.Errorf("the volume %q for the container %s does not have a path in the container", v, c.Name)
+		}
+	}
+
+	return nil
+}
+
+// ContainerVolume is a volume mounted in a custom container, when the source
+// is empty a named volume is created for the target.
+type ContainerVolume struct {
+	Source string
+	Target string
+}
+
+// GetVolumes returns the volumes for the container with the absolute path
+// to the host paths. The target is after the last colon so Windows paths
+// (e.g. C:\dev\mysite:/app) can be used.
+func (c *Container) GetVolumes(home string) ([]ContainerVolume, error) {
+	var volumes []ContainerVolume
+	for _, v := range c.Volumes {
+		i := strings.LastIndex(v, ":")
+		if i <= 0 || !strings.HasPrefix(v[i+1:], "/") {
+			volumes = append(volumes, ContainerVolume{Target: v})
+			continue
+		}
+
+		source, err := absPath(home, "", v[:i])
+		if err != nil {
+			return nil, fmt.Errorf("unable to find the volume %q for the container %s, %w", v, c.Name, err)
+		}
+
+		volumes = append(volumes, ContainerVolume{Source: source, Target: v[i+1:]})
+	}
+
+	return volumes, nil
+}
+
+// ValidateContainers returns an error if a custom container is not valid or
+// more than one container uses the same name.
+func (c *Config) ValidateContainers() error {
+	names := map[string]bool{}
+	for i := range c.Containers {
+		if err := c.Containers[i].Validate(); err != nil {
+			return err
+		}
+
+		if names[c.Containers[i].Name] {
+			return fmt.Errorf("more than one container is named %s", c.Containers[i].Name)
+		}
+
+		names[c.Containers[i].Name] = true
+	}
+
+	return nil
+}
+
 // AddContainer adds a new container config to an config. It will validate there are no other
 // container names to avoid colision or duplicate ports.
 func (c *Config) AddContainer(container Container) error {
@@ -163,28 +462,298 @@ type Database struct {
 	Engine  string `json:"engine" yaml:"engine"`
 	Version string `json:"version" yaml:"version"`
 	Port    string `json:"port" yaml:"port"`
+
+	// Platform forces the platform of the image (e.g. linux/amd64), this
+	// allows images without native support to run using emulation
+	Platform string `json:"platform,omitempty" yaml:"platform,omitempty"`
+
+	// Replica creates a second container that replicates from the database,
+	// it requires replication to be enabled in the config
+	Replica *Replica `json:"replica,omitempty" yaml:"replica,omitempty"`
+
+	// InitSQL are files (.sql, .sql.gz, or .sql.xz) the image runs against the
+	// nitro database when the database is first created, they are run in the
+	// order they are listed. The files only run when the volume is empty, to
+	// run them again remove the database and its volume with nitro db destroy
+	// and run apply.
+	InitSQL []string `json:"init_sql,omitempty" yaml:"init_sql,omitempty"`
+
+	// BindAddress is the host address for the database port, it overrides
+	// the global bind address (e.g. 0.0.0.0 to allow connections from the
+	// local network)
+	BindAddress string `json:"bind_address,omitempty" yaml:"bind_address,omitempty"`
+
+	// base is the directory of a project config, relative paths are relative to it
+	base string
+}
+
+// GetInitSQLPaths returns the absolute paths to the init SQL files for the
+// database. Relative paths are relative to the current directory, or to the
+// config file for project configs.
+func (d *Database) GetInitSQLPaths(home string) ([]string, error) {
+	var paths []string
+	for _, f := range d.InitSQL {
+		if !strings.HasSuffix(f, ".sql") && !strings.HasSuffix(f, ".sql.gz") && !strings.HasSuffix(f, ".sql.xz") {
+			return nil, fmt.Errorf("the init_sql file %s must be a .sql, .sql.gz, or .sql.xz file", f)
+		}
+
+		p, err := absPath(home, d.base, f)
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, p)
+	}
+
+	return paths, nil
+}
+
+// Replica is a read only copy of a database that uses MySQL replication or
+// Postgres streaming replication, it is used to test read/write splitting.
+// There are a few limitations:
+//
+//   - adding a replica recreates the database container to enable the binary
+//     log, the volume is kept so the data is not lost
+//   - MySQL and MariaDB replicas replay the binary log from when it was enabled,
+//     so data imported before the replica was added is not copied
+//   - Postgres replicas copy the database when the replica is created
+//   - replicas are listed with the databases (e.g. nitro db backup)
+type Replica struct {
+	Port string `json:"port" yaml:"port"`
 }
 
 // GetHostname returns a friendly and predictable name for a database
 // container. It is used for accessing a database by hostname. For
 // example, mysql-8.0-3306 would be the hostname to use in the .env
-// for DB_HOST.
+// for DB_HOST. The hostname is also used for the container and volume
+// names, so the same database always returns the same hostname.
 func (d *Database) GetHostname() (string, error) {
 	if d.Engine == "" || d.Version == "" || d.Port == "" {
 		return "", fmt.Errorf("the engine, version, and port must be defined for the database")
 	}
 
-	return fmt.Sprintf("%s-%s-%s.database.nitro", d.Engine, d.Version, d.Port), nil
+	if _, err := strconv.Atoi(d.Port); err != nil {
+		return "", fmt.Errorf("the port %q for the database must be a number", d.Port)
+	}
+
+	engine := dnsSafe(d.Engine)
+	version := dnsSafe(d.Version)
+	if engine == "" || version == "" {
+		return "", fmt.Errorf("the engine %q and version %q must contain letters or numbers", d.Engine, d.Version)
+	}
+
+	hostname := fmt.Sprintf("%s-%s-%s", engine, version, d.Port)
+
+	// each part of a hostname is limited to 63 characters
+	for _, part := range strings.Split(hostname, ".") {
+		if len(part) > 63 {
+			return "", fmt.Errorf("the database hostname %q is too long", hostname)
+		}
+	}
+
+	return hostname + ".database.nitro", nil
+}
+
+// GetReplicaHostname returns the hostname for the databases replica, which is
+// the database hostname with a replica suffix (e.g. mysql-8.0-3306-replica.database.nitro).
+func (d *Database) GetReplicaHostname() (string, error) {
+	if d.Replica == nil {
+		return "", fmt.Errorf("the database does not have a replica")
+	}
+
+	if _, err := strconv.Atoi(d.Replica.Port); err != nil {
+		return "", fmt.Errorf("the port %q for the replica must be a number", d.Replica.Port)
+	}
+
+	if d.Replica.Port == d.Port {
+		return "", fmt.Errorf("the port %s for the replica must be different than the database", d.Port)
+	}
+
+	hostname, err := d.GetHostname()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(hostname, ".database.nitro") + "-replica.database.nitro", nil
+}
+
+// dnsSafe lowercases the value and replaces any characters that are not
+// allowed in a hostname with a hyphen. Dots are kept so versions such as
+// 5.7 result in the same hostname as previous versions of nitro.
+func dnsSafe(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+
+	// remove empty parts and hyphens at the start or end of each part
+	var parts []string
+	for _, p := range strings.Split(b.String(), ".") {
+		p = strings.Trim(p, "-")
+		for strings.Contains(p, "--") {
+			p = strings.Replace(p, "--", "-", -1)
+		}
+
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// AddDatabase takes a database and adds it to the config. It will
+// return an error if there is an existing database with the same
+// engine, version, and port.
+func (c *Config) AddDatabase(db Database) error {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	for _, e := range c.Databases {
+		if e.Engine == db.Engine && e.Version == db.Version && e.Port == db.Port {
+			return fmt.Errorf("database %s %s on port %s already exists", db.Engine, db.Version, db.Port)
+		}
+	}
+
+	c.Databases = append(c.Databases, db)
+
+	return nil
+}
+
+// RemoveDatabase takes a database and removes it from the config by
+// matching the engine, version, and port. If the database cannot be
+// found it will return an error.
+func (c *Config) RemoveDatabase(db Database) error {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	for i, e := range c.Databases {
+		if e.Engine == db.Engine && e.Version == db.Version && e.Port == db.Port {
+			c.Databases = append(c.Databases[:i], c.Databases[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown database %s %s on port %s", db.Engine, db.Version, db.Port)
+}
+
+// GetDatabaseAliases returns the network aliases for a database so sites can
+// connect using a stable name instead of the versioned hostname. The first
+// database is aliased as database and the first database for each engine is
+// aliased as the engine (e.g. mysql, mariadb, or postgres), so each alias only
+// resolves to one container.
+func (c *Config) GetDatabaseAliases(db Database) []string {
+	engines := map[string]bool{}
+	for i, d := range c.Databases {
+		engine := dnsSafe(d.Engine)
+
+		if d.Engine != db.Engine || d.Version != db.Version || d.Port != db.Port {
+			engines[engine] = true
+			continue
+		}
+
+		var aliases []string
+		if i == 0 {
+			aliases = append(aliases, "database")
+		}
+
+		if engine != "" && !engines[engine] {
+			aliases = append(aliases, engine)
+		}
+
+		return aliases
+	}
+
+	return nil
+}
+
+// Hooks are commands that run inside the site containers, AfterApply
+// commands run in order once apply has updated the proxy (e.g. to run
+// migrations or clear caches).
+type Hooks struct {
+	AfterApply []Hook `json:"after_apply,omitempty" yaml:"after_apply,omitempty"`
+}
+
+// Hook is a command that runs with sh in the /app directory of the site
+// container. When ContinueOnError is true a command that exits with a
+// non-zero code does not stop the apply.
+type Hook struct {
+	Site            string `json:"site" yaml:"site"`
+	Command         string `json:"command" yaml:"command"`
+	ContinueOnError bool   `json:"continue_on_error,omitempty" yaml:"continue_on_error,omitempty"`
+}
+
+// ValidateHooks returns an error if a hook does not have a command or the
+// site for the hook is not in the config.
+func (c *Config) ValidateHooks() error {
+	for i, h := range c.Hooks.AfterApply {
+		if strings.TrimSpace(h.Command) == "" {
+			return fmt.Errorf("the after_apply hook %d for %s does not have a command", i+1, h.Site)
+		}
+
+		if _, err := c.FindSiteByHostName(h.Site); err != nil {
+			return fmt.Errorf("unable to find the site %q for the after_apply hook %q", h.Site, h.Command)
+		}
+	}
+
+	return nil
+}
+
+// Proxy is used to change the ports the proxy container binds to on the
+// host machine. Setting different ports allows multiple environments to
+// run at the same time (e.g. https://mysite.nitro:8443). The API port is
+// always bound to localhost.
+type Proxy struct {
+	HTTPPort  string `json:"http_port,omitempty" yaml:"http_port,omitempty"`
+	HTTPSPort string `json:"https_port,omitempty" yaml:"https_port,omitempty"`
+	APIPort   string `json:"api_port,omitempty" yaml:"api_port,omitempty"`
+}
+
+// Restart controls how docker restarts the containers when the docker daemon
+// or machine restarts. By default the proxy, databases, and services are
+// restarted unless they have been stopped (e.g. nitro stop). Sites are only
+// restarted when Sites is true.
+type Restart struct {
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	Sites    bool `json:"sites,omitempty" yaml:"sites,omitempty"`
+}
+
+// Policy takes a bool to indicate the container is a site and returns the
+// docker restart policy name for the container.
+func (r Restart) Policy(site bool) string {
+	if r.Disabled || (site && !r.Sites) {
+		return "no"
+	}
+
+	return "unless-stopped"
 }
 
 // Services define common tools for development that should run as containers. We don't expose the volumes, ports, and
 // networking options for these types of services. We plan to support "custom" container options to make local users
 // development even better.
 type Services struct {
-	DynamoDB bool `json:"dynamodb"`
-	Mailhog  bool `json:"mailhog"`
-	Minio    bool `json:"minio"`
-	Redis    bool `json:"redis"`
+	DynamoDB      bool `json:"dynamodb"`
+	Elasticsearch bool `json:"elasticsearch"`
+	Mailhog       bool `json:"mailhog"`
+	Meilisearch   bool `json:"meilisearch"`
+	Minio         bool `json:"minio"`
+	Redis         bool `json:"redis"`
+
+	// RedisConfig are the options for the redis service (e.g. appendonly or maxmemory)
+	RedisConfig RedisConfig `json:"redis_config,omitempty" yaml:"redis_config,omitempty"`
+
+	// BindAddresses overrides the global bind address for a service using the
+	// name of the service (e.g. redis: 0.0.0.0)
+	BindAddresses map[string]string `json:"bind_addresses,omitempty" yaml:"bind_addresses,omitempty"`
+
+	// SharedVolumes are the services (e.g. minio) with a data volume that is
+	// shared by every nitro environment and kept when nitro is destroyed
+	SharedVolumes []string `json:"shared_volumes,omitempty" yaml:"shared_volumes,omitempty"`
 }
 
 // Site represents a web application. It has a hostname, aliases (which
@@ -200,13 +769,172 @@ type Site struct {
 	Webroot    string   `json:"webroot" yaml:"webroot"`
 	Xdebug     bool     `json:"xdebug" yaml:"xdebug"`
 	Blackfire  bool     `json:"blackfire" yaml:"blackfire"`
+	Dotenv     string   `json:"dotenv,omitempty" yaml:"dotenv,omitempty"`
+	PHPIni     string   `json:"php_ini,omitempty" yaml:"php_ini,omitempty"`
+
+	// NginxConfig is a custom nginx config file that is mounted into the sites container
+	NginxConfig string `json:"nginx_config,omitempty" yaml:"nginx_config,omitempty"`
+
+	// Dockerfile is built during apply and used instead of the nginx image, it is relative to the sites path
+	Dockerfile string `json:"dockerfile,omitempty" yaml:"dockerfile,omitempty"`
+
+	// Image is used instead of the nginx image for the PHP version, the image must use the same mounts (e.g. /app)
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+
+	// Labels are custom labels added to the sites container
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Disabled removes the sites container without removing the site from the config
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+
+	// Timezone is the IANA timezone for the container and PHP (e.g. Europe/Berlin), it overrides the global timezone
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+
+	// base is the directory relative paths start from, it is set for project configs
+	base string
+
+	// defaultVersion is true when the version is the default PHP version from the
+	// config, the version is not written to the file when the config is saved
+	defaultVersion bool
+}
+
+// GetDotenvPath returns the absolute path to the sites .env file. The
+// dotenv option is relative to the sites path (e.g. .env). If the site
+// does not use a .env file it returns an empty string.
+func (s *Site) GetDotenvPath(home string) (string, error) {
+	if s.Dotenv == "" {
+		return "", nil
+	}
+
+	path, err := s.GetAbsPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(path, s.Dotenv), nil
+}
+
+// GetDockerfilePath returns the absolute path to the sites Dockerfile. The
+// dockerfile option is relative to the sites path (e.g. docker/Dockerfile)
+// because the sites path is the build context. If the site does not use a
+// Dockerfile it returns an empty string.
+func (s *Site) GetDockerfilePath(home string) (string, error) {
+	if s.Dockerfile == "" {
+		return "", nil
+	}
+
+	path, err := s.GetAbsPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(path, s.Dockerfile), nil
+}
+
+// ValidateImage returns an error unless exactly one of the image, the
+// Dockerfile, or the PHP version is used to choose the sites image. The PHP
+// version can be set with a Dockerfile since it is used as a build argument.
+func (s *Site) ValidateImage() error {
+	switch {
+	case s.Image != "" && s.Dockerfile != "":
+		return fmt.Errorf("the site %s can not use both an image and a Dockerfile", s.Hostname)
+	case s.Image == "" && s.Dockerfile == "" && s.Version == "":
+		return fmt.Errorf("the site %s must have a PHP version, an image, or a Dockerfile", s.Hostname)
+	}
+
+	return nil
+}
+
+// GetHostsEntries returns the hostname and aliases for the site that can be
+// added to a hosts file. Wildcard aliases (e.g. *.mysite.nitro) are routed by
+// the proxy but are not supported by hosts files, so they are not returned.
+func (s *Site) GetHostsEntries() []string {
+	hosts := []string{s.Hostname}
+	for _, a := range s.Aliases {
+		if IsWildcard(a) {
+			continue
+		}
+
+		hosts = append(hosts, a)
+	}
+
+	return hosts
+}
+
+// IsWildcard returns true if the hostname is a wildcard (e.g. *.mysite.nitro).
+func IsWildcard(hostname string) bool {
+	return strings.HasPrefix(hostname, "*.")
+}
+
+// GetPHPIniPath returns the absolute path to the sites custom php.ini
+// file. Relative paths are relative to the sites path. If the site does
+// not use a custom php.ini it returns an empty string.
+func (s *Site) GetPHPIniPath(home string) (string, error) {
+	if s.PHPIni == "" {
+		return "", nil
+	}
+
+	// absolute paths and paths from the home directory are used as is
+	if filepath.IsAbs(s.PHPIni) || strings.HasPrefix(s.PHPIni, "~") {
+		return cleanPath(home, s.PHPIni)
+	}
+
+	path, err := s.GetAbsPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(path, s.PHPIni), nil
+}
+
+// GetNginxConfigPath returns the absolute path to the sites custom nginx
+// config file. Relative paths are relative to the sites path. If the site
+// does not use a custom nginx config it returns an empty string.
+func (s *Site) GetNginxConfigPath(home string) (string, error) {
+	if s.NginxConfig == "" {
+		return "", nil
+	}
+
+	// absolute paths and paths from the home directory are used as is
+	if filepath.IsAbs(s.NginxConfig) || strings.HasPrefix(s.NginxConfig, "~") {
+		return cleanPath(home, s.NginxConfig)
+	}
+
+	path, err := s.GetAbsPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(path, s.NginxConfig), nil
 }
 
 // GetAbsPath gets the directory for a site.Path,
 // It is used to create the mount for a sites
-// container.
+// container. A leading ~ and environment variables
+// (e.g. $HOME/dev/mysite or ${SITES}/mysite) are expanded.
 func (s *Site) GetAbsPath(home string) (string, error) {
-	return cleanPath(home, s.Path)
+	return absPath(home, s.base, s.Path)
+}
+
+// GetWebrootPath returns the absolute path to the sites webroot in the
+// container (e.g. /app/web). The webroot must be relative to the sites
+// path, an error is returned if it is absolute or outside of the site.
+func (s *Site) GetWebrootPath() (string, error) {
+	webroot := s.Webroot
+	if webroot == "" {
+		webroot = "web"
+	}
+
+	if path.IsAbs(webroot) || filepath.IsAbs(webroot) {
+		return "", fmt.Errorf("the webroot %q for %s must be relative to the site path", s.Webroot, s.Hostname)
+	}
+
+	p := path.Join("/app", filepath.ToSlash(webroot))
+	if p != "/app" && !strings.HasPrefix(p, "/app/") {
+		return "", fmt.Errorf("the webroot %q for %s must be inside the site path", s.Webroot, s.Hostname)
+	}
+
+	return p, nil
 }
 
 // GetContainerPath is responsible for looking at the
@@ -368,6 +1096,28 @@ func (c *Config) SetPHPIntSetting(hostname, setting string, value int) error {
 	return fmt.Errorf("unable to find the site: %s", hostname)
 }
 
+// SetSitePHPVersion changes the PHP version for the site by its hostname. It returns
+// an error if the version is not supported or the site cannot be found.
+func (c *Config) SetSitePHPVersion(hostname, version string) error {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	if !phpversions.IsSupported(version) {
+		return fmt.Errorf("the PHP version %q is not supported, use one of %s", version, strings.Join(phpversions.Versions, ", "))
+	}
+
+	for i, s := range c.Sites {
+		if s.Hostname == hostname {
+			c.Sites[i].Version = version
+			c.Sites[i].defaultVersion = false
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unable to find the site: %s", hostname)
+}
+
 // SetPHPStrSetting is used to set php settings that are strings. It will look
 // for the site by its hostname and change the setting. If it cannot find the
 // site or setting it will return an error.
@@ -417,13 +1167,26 @@ type PHP struct {
 
 // Load is used to return the unmarshalled config, and
 // returns an error when trying to get the users home directory or
-// while marshalling the config.
+// while marshalling the config. When a nitro.yaml is found in the
+// current directory (or a parent up to the repo root) it is used
+// instead of the home config, unless Source is set to home.
 func Load(home string) (*Config, error) {
-	file, err := IsEmpty(home)
+	file, project, err := configFile(home)
 	if err != nil {
 		return nil, err
 	}
 
+	return load(file, project)
+}
+
+// LoadFile returns the unmarshalled config from a config file in the nitro
+// directory (e.g. ~/.nitro/work.yaml), it ignores the Source and project
+// configs and is used when applying every environment.
+func LoadFile(file string) (*Config, error) {
+	return load(file, false)
+}
+
+func load(file string, project bool) (*Config, error) {
 	// create the config
 	c := &Config{
 		File: file,
@@ -440,10 +1203,77 @@ func Load(home string) (*Config, error) {
 		return nil, err
 	}
 
+	// relative site paths in a project config are relative to the config file
+	if project {
+		for i := range c.Sites {
+			c.Sites[i].base = filepath.Dir(file)
+		}
+
+		for i := range c.Databases {
+			c.Databases[i].base = filepath.Dir(file)
+		}
+	}
+
+	// use the default PHP version for sites without a version
+	if err := c.setDefaultPHP(); err != nil {
+		return nil, err
+	}
+
+	// replace the environment variables (e.g. ${BLACKFIRE_SERVER_TOKEN})
+	if err := c.interpolate(os.LookupEnv, StrictEnv); err != nil {
+		return nil, err
+	}
+
+	// remove duplicate aliases so the proxy and hosts do not get redundant entries
+	c.normalizeAliases()
+
 	// return the config
 	return c, nil
 }
 
+// setDefaultPHP sets the default PHP version on the sites that do not set a
+// version or an image, so a container is never created without a version.
+// It returns an error if the default version is not supported.
+func (c *Config) setDefaultPHP() error {
+	if c.DefaultPHP != "" && !phpversions.IsSupported(c.DefaultPHP) {
+		return fmt.Errorf("the default_php version %q is not supported, use one of %s", c.DefaultPHP, strings.Join(phpversions.Versions, ", "))
+	}
+
+	for i, s := range c.Sites {
+		if s.Image == "" && s.Version == "" {
+			c.Sites[i].Version = c.GetDefaultPHP()
+			c.Sites[i].defaultVersion = true
+		}
+	}
+
+	return nil
+}
+
+// normalizeAliases lowercases and trims the aliases of each site, and removes
+// empty aliases, duplicates, and aliases that are the sites hostname.
+func (c *Config) normalizeAliases() {
+	for i, s := range c.Sites {
+		if len(s.Aliases) == 0 {
+			continue
+		}
+
+		seen := map[string]bool{strings.ToLower(strings.TrimSpace(s.Hostname)): true}
+
+		var aliases []string
+		for _, a := range s.Aliases {
+			a = strings.ToLower(strings.TrimSpace(a))
+			if a == "" || seen[a] {
+				continue
+			}
+
+			seen[a] = true
+			aliases = append(aliases, a)
+		}
+
+		c.Sites[i].Aliases = aliases
+	}
+}
+
 // IsEmpty is used to check if the config file is empty
 func IsEmpty(home string) (string, error) {
 	// verify the file exists
@@ -575,62 +1405,99 @@ func (c *Config) EnableXdebug(site string) error {
 	return fmt.Errorf("unknown site, %s", site)
 }
 
-// Save takes a file path and marshals the config into a file.
+// Save marshals the config and writes it to the config file. The config is
+// written to a temp file and renamed so the file is never partially written.
 func (c *Config) Save() error {
 	c.rw.Lock()
 	defer c.rw.Unlock()
 
-	// make sure the file exists
-	if _, err := os.Stat(c.File); os.IsNotExist(err) {
-		dir, _ := filepath.Split(c.File)
+	dir, _ := filepath.Split(c.File)
 
-		if err := c.createFile(dir); err != nil {
-			return err
-		}
+	// create the .nitro directory if it does not exist
+	if err := helpers.MkdirIfNotExists(dir); err != nil {
+		return err
 	}
 
-	// open the file
-	f, err := os.OpenFile(c.File, os.O_TRUNC|os.O_WRONLY, os.ModeAppend)
+	// lock the file so other nitro processes do not write at the same time
+	unlock, err := lock(c.File)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	defer unlock()
 
-	// unmarshal
-	data, err := yaml.Marshal(&c)
+	data, err := c.marshal()
 	if err != nil {
 		return err
 	}
 
-	// write the content
+	// keep the permissions of an existing file
+	mode := os.FileMode(0644)
+	if stat, err := os.Stat(c.File); err == nil {
+		mode = stat.Mode().Perm()
+	}
+
+	// write to a temp file in the same directory so the rename is atomic
+	f, err := ioutil.TempFile(dir, "."+FileName+".tmp-")
+	if err != nil {
+		return fmt.Errorf("unable to create a temp file for the config, %w", err)
+	}
+	defer os.Remove(f.Name())
+
 	if _, err := f.Write(data); err != nil {
-		return err
+		f.Close()
+		return fmt.Errorf("unable to write the config, %w", err)
 	}
 
-	return nil
-}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write the config, %w", err)
+	}
 
-func (c *Config) createFile(dir string) error {
-	// create the .nitro directory if it does not exist
-	if err := helpers.MkdirIfNotExists(dir); err != nil {
-		return err
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write the config, %w", err)
 	}
 
-	// otherwise create it
-	f, err := os.Create(c.File)
-	if err != nil {
+	if err := os.Chmod(f.Name(), mode); err != nil {
 		return err
 	}
-	defer f.Close()
 
 	// try to chown otherwise be quiet
-	if err := f.Chown(os.Geteuid(), os.Getuid()); err != nil {
-		return nil
+	_ = os.Chown(f.Name(), os.Geteuid(), os.Getuid())
+
+	// replace the config with the temp file
+	if err := os.Rename(f.Name(), c.File); err != nil {
+		return fmt.Errorf("unable to save the config, %w", err)
 	}
 
 	return nil
 }
 
+// Marshal returns the config as yaml, the same as it is written to the
+// config file. Values that reference environment variables or secrets are
+// returned as the reference, not the value.
+func (c *Config) Marshal() ([]byte, error) {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	return c.marshal()
+}
+
+func (c *Config) marshal() ([]byte, error) {
+	// write the environment variable references instead of the values
+	undo := c.restoreTokens()
+	defer undo()
+
+	// sites using the default PHP version do not write the version
+	for i, s := range c.Sites {
+		if s.defaultVersion && s.Version == c.GetDefaultPHP() {
+			c.Sites[i].Version = ""
+			defer func(i int, version string) { c.Sites[i].Version = version }(i, s.Version)
+		}
+	}
+
+	return yaml.Marshal(c)
+}
+
 // GetFile returns the file location for the config
 func (c *Config) GetFile() string {
 	return c.File
@@ -723,9 +1590,20 @@ func xdebugVars(php PHP, xdebug bool, version, hostname, addr string) []string {
 }
 
 func cleanPath(home, path string) (string, error) {
-	p := path
-	if strings.Contains(p, "~") {
-		p = strings.Replace(p, "~", home, -1)
+	return absPath(home, "", path)
+}
+
+// absPath expands the path and returns the absolute path. Relative paths are
+// relative to the base directory when it is set (e.g. the directory of a
+// project config), otherwise they are relative to the current directory.
+func absPath(home, base, path string) (string, error) {
+	p, err := expandPath(home, path)
+	if err != nil {
+		return "", err
+	}
+
+	if base != "" && !filepath.IsAbs(p) {
+		return filepath.Clean(filepath.Join(base, p)), nil
 	}
 
 	abs, err := filepath.Abs(p)
@@ -735,3 +1613,35 @@ func cleanPath(home, path string) (string, error) {
 
 	return filepath.Clean(abs), nil
 }
+
+// expandPath replaces a leading ~ with the home directory and the $VAR or
+// ${VAR} environment variables in the path. $HOME is always the home
+// directory, an error is returned if any other variable is not set.
+func expandPath(home, path string) (string, error) {
+	var missing string
+	p := os.Expand(path, func(name string) string {
+		if name == "HOME" {
+			return home
+		}
+
+		v, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+
+		return v
+	})
+
+	if missing != "" {
+		return "", fmt.Errorf("the environment variable %s in the path %s is not set", missing, path)
+	}
+
+	switch {
+	case p == "~":
+		return home, nil
+	case strings.HasPrefix(p, "~/"), strings.HasPrefix(p, `~\`):
+		return filepath.Join(home, p[2:]), nil
+	}
+
+	return p, nil
+}
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -227,12 +229,42 @@ func TestDatabase_GetHostname(t *testing.T) {
 			want:    "mysql-5.7-3306.database.nitro",
 			wantErr: false,
 		},
+		{
+			name:   "postgres hostnames include the version and port",
+			fields: fields{Engine: "postgres", Version: "13", Port: "5433"},
+			want:   "postgres-13-5433.database.nitro",
+		},
+		{
+			name:   "patch versions keep the dots",
+			fields: fields{Engine: "mariadb", Version: "10.5.8", Port: "3307"},
+			want:   "mariadb-10.5.8-3307.database.nitro",
+		},
+		{
+			name:   "uppercase and invalid characters are replaced",
+			fields: fields{Engine: "MySQL", Version: " 8.0_debian ", Port: "3306"},
+			want:   "mysql-8.0-debian-3306.database.nitro",
+		},
+		{
+			name:   "the same database on different ports returns different hostnames",
+			fields: fields{Engine: "mysql", Version: "5.7", Port: "33060"},
+			want:   "mysql-5.7-33060.database.nitro",
+		},
 		{
 			name:    "empty values return an error",
 			fields:  fields{Engine: "mysql", Port: "3306"},
 			want:    "",
 			wantErr: true,
 		},
+		{
+			name:    "ports that are not numbers return an error",
+			fields:  fields{Engine: "mysql", Version: "5.7", Port: "mysql"},
+			wantErr: true,
+		},
+		{
+			name:    "versions without letters or numbers return an error",
+			fields:  fields{Engine: "mysql", Version: "..", Port: "3306"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -253,6 +285,95 @@ func TestDatabase_GetHostname(t *testing.T) {
 	}
 }
 
+func TestDatabase_GetReplicaHostname(t *testing.T) {
+	tests := []struct {
+		name    string
+		replica *Replica
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "replicas use the database hostname with a suffix",
+			replica: &Replica{Port: "3307"},
+			want:    "mysql-8.0-3306-replica.database.nitro",
+		},
+		{
+			name:    "databases without a replica return an error",
+			wantErr: true,
+		},
+		{
+			name:    "replica ports that are not numbers return an error",
+			replica: &Replica{Port: "replica"},
+			wantErr: true,
+		},
+		{
+			name:    "replicas cannot use the database port",
+			replica: &Replica{Port: "3306"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{Engine: "mysql", Version: "8.0", Port: "3306", Replica: tt.replica}
+
+			got, err := d.GetReplicaHostname()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Database.GetReplicaHostname() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Database.GetReplicaHostname() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetInitSQLPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		db      Database
+		home    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "paths are expanded using the home directory",
+			db:   Database{InitSQL: []string{"~/dev/seed.sql", "/tmp/users.sql.gz"}},
+			home: "/Users/oli",
+			want: []string{"/Users/oli/dev/seed.sql", "/tmp/users.sql.gz"},
+		},
+		{
+			name: "relative paths in project configs are relative to the config",
+			db:   Database{InitSQL: []string{"db/seed.sql"}, base: "/Users/oli/dev/craft-dev"},
+			home: "/Users/oli",
+			want: []string{"/Users/oli/dev/craft-dev/db/seed.sql"},
+		},
+		{
+			name:    "files that are not sql return an error",
+			db:      Database{InitSQL: []string{"~/dev/seed.sh"}},
+			home:    "/Users/oli",
+			wantErr: true,
+		},
+		{
+			name: "databases without init files return nothing",
+			home: "/Users/oli",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.db.GetInitSQLPaths(tt.home)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Database.GetInitSQLPaths() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Database.GetInitSQLPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestLoad(t *testing.T) {
 	// get the working dir for the test path
 	wd, err := os.Getwd()
@@ -340,6 +461,211 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestConfig_Save(t *testing.T) {
+	home := t.TempDir()
+	file := filepath.Join(home, DirectoryName, FileName)
+
+	// save a larger config first
+	large := &Config{
+		File: file,
+		Databases: []Database{
+			{Engine: "mysql", Version: "8.0", Port: "3306"},
+			{Engine: "postgres", Version: "13", Port: "5432"},
+		},
+		Sites: []Site{
+			{Hostname: "site-one.nitro", Path: "~/dev/site-one", Version: "7.4", Webroot: "web", Aliases: []string{"one.nitro", "uno.nitro"}},
+			{Hostname: "site-two.nitro", Path: "~/dev/site-two", Version: "7.4", Webroot: "web"},
+		},
+	}
+	if err := large.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// save a smaller config over the larger one
+	small := &Config{
+		File: file,
+		Sites: []Site{
+			{Hostname: "site-one.nitro", Path: "~/dev/site-one", Version: "7.4", Webroot: "web"},
+		},
+	}
+	if err := small.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load(home)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got.Sites, small.Sites) {
+		t.Errorf("Load() = \ngot:\n%v,\nwant\n%v", got.Sites, small.Sites)
+	}
+
+	if len(got.Databases) != 0 {
+		t.Errorf("expected no databases, got %v", got.Databases)
+	}
+
+	// make sure the lock file is removed
+	if _, err := os.Stat(file + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("expected the lock file to be removed, got %v", err)
+	}
+}
+
+func TestLoad_DefaultPHP(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "sites without a version use the default version",
+			config: "sites:\n  - hostname: one.nitro\n    path: ~/dev/one\n  - hostname: two.nitro\n    path: ~/dev/two\n    version: \"8.0\"\n",
+			want:   []string{"7.4", "8.0"},
+		},
+		{
+			name:   "the default version can be changed",
+			config: "default_php: \"8.0\"\nsites:\n  - hostname: one.nitro\n    path: ~/dev/one\n",
+			want:   []string{"8.0"},
+		},
+		{
+			name:   "sites using an image do not use the default version",
+			config: "sites:\n  - hostname: one.nitro\n    path: ~/dev/one\n    image: php:8.0-fpm\n",
+			want:   []string{""},
+		},
+		{
+			name:   "sites using a Dockerfile use the default version as the build argument",
+			config: "sites:\n  - hostname: one.nitro\n    path: ~/dev/one\n    dockerfile: Dockerfile\n",
+			want:   []string{"7.4"},
+		},
+		{
+			name:    "unsupported default versions return an error",
+			config:  "default_php: \"5.6\"\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			if err := os.Mkdir(filepath.Join(home, DirectoryName), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ioutil.WriteFile(filepath.Join(home, DirectoryName, FileName), []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := Load(home)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			var got []string
+			for _, s := range cfg.Sites {
+				got = append(got, s.Version)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected the versions %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoad_Aliases(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name:   "duplicate aliases are removed",
+			config: "sites:\n  - hostname: craft-dev.nitro\n    aliases:\n      - one.nitro\n      - two.nitro\n      - one.nitro\n",
+			want:   []string{"one.nitro", "two.nitro"},
+		},
+		{
+			name:   "mixed case aliases are lowercased and trimmed",
+			config: "sites:\n  - hostname: craft-dev.nitro\n    aliases:\n      - One.Nitro\n      - \" one.nitro \"\n      - \"*.Craft-Dev.nitro\"\n",
+			want:   []string{"one.nitro", "*.craft-dev.nitro"},
+		},
+		{
+			name:   "the hostname is removed from the aliases",
+			config: "sites:\n  - hostname: craft-dev.nitro\n    aliases:\n      - Craft-Dev.nitro\n      - one.nitro\n      - \"\"\n",
+			want:   []string{"one.nitro"},
+		},
+		{
+			name:   "only the hostname in the aliases leaves no aliases",
+			config: "sites:\n  - hostname: craft-dev.nitro\n    aliases:\n      - craft-dev.nitro\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			if err := os.Mkdir(filepath.Join(home, DirectoryName), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ioutil.WriteFile(filepath.Join(home, DirectoryName, FileName), []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := Load(home)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if got := cfg.Sites[0].Aliases; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected the aliases %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConfig_Save_DefaultPHP(t *testing.T) {
+	// Arrange
+	home := t.TempDir()
+	if err := os.Mkdir(filepath.Join(home, DirectoryName), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(home, DirectoryName, FileName)
+	if err := ioutil.WriteFile(file, []byte("sites:\n  - hostname: one.nitro\n    path: ~/dev/one\n  - hostname: two.nitro\n    path: ~/dev/two\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	if err := cfg.SetSitePHPVersion("two.nitro", "7.4"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Count(string(data), `version: "7.4"`); got != 1 {
+		t.Errorf("expected only the version that was set to be saved, got\n%s", data)
+	}
+
+	if cfg.Sites[0].Version != "7.4" {
+		t.Errorf("expected the default version to be kept after saving, got %q", cfg.Sites[0].Version)
+	}
+}
+
 func TestConfig_EnableXdebug(t *testing.T) {
 	type fields struct {
 		Blackfire Blackfire
@@ -375,162 +701,1007 @@ func TestConfig_EnableXdebug(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "can enable xdebug for a site",
-			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "somesite",
-						Xdebug:   false,
-					},
-					{
-						Hostname: "anothersite",
-						Xdebug:   true,
-					},
-				},
-			},
-			args:    args{site: "somesite"},
-			wantErr: false,
+			name: "can enable xdebug for a site",
+			fields: fields{
+				Sites: []Site{
+					{
+						Hostname: "somesite",
+						Xdebug:   false,
+					},
+					{
+						Hostname: "anothersite",
+						Xdebug:   true,
+					},
+				},
+			},
+			args:    args{site: "somesite"},
+			wantErr: false,
+		},
+		{
+			name:    "sites that don't exist return an error",
+			args:    args{site: "idontexist"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				Blackfire: tt.fields.Blackfire,
+				Databases: tt.fields.Databases,
+				Services:  tt.fields.Services,
+				Sites:     tt.fields.Sites,
+				File:      tt.fields.File,
+			}
+			if err := c.EnableXdebug(tt.args.site); (err != nil) != tt.wantErr {
+				t.Errorf("Config.EnableXdebug() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_DisableXdebug(t *testing.T) {
+	type fields struct {
+		Blackfire Blackfire
+		Databases []Database
+		Services  Services
+		Sites     []Site
+		File      string
+	}
+	type args struct {
+		site string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "can enable xdebug for a site",
+			fields: fields{
+				Sites: []Site{
+					{
+						Hostname: "somesite",
+						Xdebug:   false,
+					},
+					{
+						Hostname: "anothersite",
+						Xdebug:   true,
+					},
+				},
+			},
+			args:    args{site: "somesite"},
+			wantErr: false,
+		},
+		{
+			name:    "sites that don't exist return an error",
+			args:    args{site: "idontexist"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				Blackfire: tt.fields.Blackfire,
+				Databases: tt.fields.Databases,
+				Services:  tt.fields.Services,
+				Sites:     tt.fields.Sites,
+				File:      tt.fields.File,
+			}
+			if err := c.DisableXdebug(tt.args.site); (err != nil) != tt.wantErr {
+				t.Errorf("Config.DisableXdebug() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_AddSite(t *testing.T) {
+	type fields struct {
+		Blackfire Blackfire
+		Databases []Database
+		Services  Services
+		Sites     []Site
+		File      string
+	}
+	type args struct {
+		s Site
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "existing hostnames returns an error",
+			fields: fields{
+				Sites: []Site{
+					{
+						Hostname: "example",
+					},
+				},
+			},
+			args: args{
+				s: Site{Hostname: "example"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				Blackfire: tt.fields.Blackfire,
+				Databases: tt.fields.Databases,
+				Services:  tt.fields.Services,
+				Sites:     tt.fields.Sites,
+				File:      tt.fields.File,
+			}
+			if err := c.AddSite(tt.args.s); (err != nil) != tt.wantErr {
+				t.Errorf("Config.AddSite() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_AddDatabase(t *testing.T) {
+	type args struct {
+		db Database
+	}
+	tests := []struct {
+		name    string
+		fields  []Database
+		args    args
+		want    []Database
+		wantErr bool
+	}{
+		{
+			name: "can add a database",
+			args: args{
+				db: Database{Engine: "mysql", Version: "8.0", Port: "3306"},
+			},
+			want: []Database{
+				{Engine: "mysql", Version: "8.0", Port: "3306"},
+			},
+		},
+		{
+			name: "can add the same engine and version on a different port",
+			fields: []Database{
+				{Engine: "mysql", Version: "8.0", Port: "3306"},
+			},
+			args: args{
+				db: Database{Engine: "mysql", Version: "8.0", Port: "3307"},
+			},
+			want: []Database{
+				{Engine: "mysql", Version: "8.0", Port: "3306"},
+				{Engine: "mysql", Version: "8.0", Port: "3307"},
+			},
+		},
+		{
+			name: "duplicate engine, version, and port returns an error",
+			fields: []Database{
+				{Engine: "postgres", Version: "13", Port: "5432"},
+			},
+			args: args{
+				db: Database{Engine: "postgres", Version: "13", Port: "5432"},
+			},
+			want: []Database{
+				{Engine: "postgres", Version: "13", Port: "5432"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				Databases: tt.fields,
+			}
+			if err := c.AddDatabase(tt.args.db); (err != nil) != tt.wantErr {
+				t.Errorf("Config.AddDatabase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(c.Databases, tt.want) {
+				t.Errorf("Config.AddDatabase() got = \n%v\nwant\n%v", c.Databases, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_RemoveDatabase(t *testing.T) {
+	type args struct {
+		db Database
+	}
+	tests := []struct {
+		name    string
+		fields  []Database
+		args    args
+		want    []Database
+		wantErr bool
+	}{
+		{
+			name: "can remove a database",
+			fields: []Database{
+				{Engine: "mysql", Version: "8.0", Port: "3306"},
+				{Engine: "postgres", Version: "13", Port: "5432"},
+			},
+			args: args{
+				db: Database{Engine: "mysql", Version: "8.0", Port: "3306"},
+			},
+			want: []Database{
+				{Engine: "postgres", Version: "13", Port: "5432"},
+			},
+		},
+		{
+			name: "unknown databases return an error",
+			fields: []Database{
+				{Engine: "mysql", Version: "8.0", Port: "3306"},
+			},
+			args: args{
+				db: Database{Engine: "mysql", Version: "8.0", Port: "3307"},
+			},
+			want: []Database{
+				{Engine: "mysql", Version: "8.0", Port: "3306"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				Databases: tt.fields,
+			}
+			if err := c.RemoveDatabase(tt.args.db); (err != nil) != tt.wantErr {
+				t.Errorf("Config.RemoveDatabase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(c.Databases, tt.want) {
+				t.Errorf("Config.RemoveDatabase() got = \n%v\nwant\n%v", c.Databases, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_GetDatabaseAliases(t *testing.T) {
+	databases := []Database{
+		{Engine: "mysql", Version: "8.0", Port: "3306"},
+		{Engine: "mysql", Version: "5.7", Port: "33061"},
+		{Engine: "postgres", Version: "13", Port: "5432"},
+		{Engine: "postgres", Version: "12", Port: "54321"},
+		{Engine: "mariadb", Version: "10.5", Port: "3307"},
+	}
+
+	tests := []struct {
+		name string
+		db   Database
+		want []string
+	}{
+		{
+			name: "the first database is aliased as database and its engine",
+			db:   databases[0],
+			want: []string{"database", "mysql"},
+		},
+		{
+			name: "other databases with the same engine are not aliased",
+			db:   databases[1],
+		},
+		{
+			name: "the first database for an engine is aliased as the engine",
+			db:   databases[2],
+			want: []string{"postgres"},
+		},
+		{
+			name: "the second database for an engine is not aliased",
+			db:   databases[3],
+		},
+		{
+			name: "mariadb is aliased separately from mysql",
+			db:   databases[4],
+			want: []string{"mariadb"},
+		},
+		{
+			name: "unknown databases are not aliased",
+			db:   Database{Engine: "mysql", Version: "8.0", Port: "3308"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Databases: databases}
+
+			if got := c.GetDatabaseAliases(tt.db); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.GetDatabaseAliases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_GetBindAddresses(t *testing.T) {
+	db := Database{Engine: "mysql", Version: "8.0", Port: "3306"}
+
+	tests := []struct {
+		name         string
+		cfg          *Config
+		wantGlobal   string
+		wantDatabase string
+		wantService  string
+	}{
+		{
+			name:         "addresses default to localhost",
+			cfg:          &Config{},
+			wantGlobal:   "127.0.0.1",
+			wantDatabase: "127.0.0.1",
+			wantService:  "127.0.0.1",
+		},
+		{
+			name:         "the global address is used for databases and services",
+			cfg:          &Config{BindAddress: "0.0.0.0"},
+			wantGlobal:   "0.0.0.0",
+			wantDatabase: "0.0.0.0",
+			wantService:  "0.0.0.0",
+		},
+		{
+			name: "databases and services override the global address",
+			cfg: &Config{
+				BindAddress: "::1",
+				Databases:   []Database{{Engine: "mysql", Version: "8.0", Port: "3306", BindAddress: "0.0.0.0"}},
+				Services:    Services{Redis: true, BindAddresses: map[string]string{"redis": "192.168.1.10"}},
+			},
+			wantGlobal:   "::1",
+			wantDatabase: "0.0.0.0",
+			wantService:  "192.168.1.10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := db
+			if len(tt.cfg.Databases) > 0 {
+				d = tt.cfg.Databases[0]
+			}
+
+			if got := tt.cfg.GetBindAddress(); got != tt.wantGlobal {
+				t.Errorf("Config.GetBindAddress() = %v, want %v", got, tt.wantGlobal)
+			}
+
+			if got := tt.cfg.GetDatabaseBindAddress(d); got != tt.wantDatabase {
+				t.Errorf("Config.GetDatabaseBindAddress() = %v, want %v", got, tt.wantDatabase)
+			}
+
+			if got := tt.cfg.GetServiceBindAddress("redis"); got != tt.wantService {
+				t.Errorf("Config.GetServiceBindAddress() = %v, want %v", got, tt.wantService)
+			}
+
+			// other services use the global address
+			if got := tt.cfg.GetServiceBindAddress("mailhog"); got != tt.wantGlobal {
+				t.Errorf("Config.GetServiceBindAddress() = %v, want %v", got, tt.wantGlobal)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateBindAddresses(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr string
+	}{
+		{
+			name: "empty addresses are valid",
+			cfg:  &Config{},
+		},
+		{
+			name: "IPv4 and IPv6 addresses are valid",
+			cfg: &Config{
+				BindAddress: "0.0.0.0",
+				Databases:   []Database{{Engine: "postgres", Version: "13", BindAddress: "::1"}},
+				Services:    Services{BindAddresses: map[string]string{"redis": "::"}},
+			},
+		},
+		{
+			name:    "hostnames are not valid",
+			cfg:     &Config{BindAddress: "localhost"},
+			wantErr: `the bind_address "localhost" is not a valid IP address`,
+		},
+		{
+			name:    "database addresses are validated",
+			cfg:     &Config{Databases: []Database{{Engine: "postgres", Version: "13", BindAddress: "127.0.0.1:5432"}}},
+			wantErr: `the bind_address "127.0.0.1:5432" for the postgres 13 database is not a valid IP address`,
+		},
+		{
+			name:    "service addresses are validated",
+			cfg:     &Config{Services: Services{BindAddresses: map[string]string{"mailhog": "0.0.0.0", "redis": "lan"}}},
+			wantErr: `the bind address "lan" for the redis service is not a valid IP address`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.ValidateBindAddresses()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Config.ValidateBindAddresses() unexpected error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Config.ValidateBindAddresses() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSite_GetAbsPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type fields struct {
+		Hostname string
+		Aliases  []string
+		Path     string
+		Version  string
+		PHP      PHP
+		Webroot  string
+		Xdebug   bool
+	}
+	type args struct {
+		home string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "existing paths return the complete path",
+			fields: fields{
+				Path: filepath.Join(wd, "testdata"),
+			},
+			args: args{
+				home: wd,
+			},
+			want:    filepath.Join(wd, "testdata"),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Site{
+				Hostname: tt.fields.Hostname,
+				Aliases:  tt.fields.Aliases,
+				Path:     tt.fields.Path,
+				Version:  tt.fields.Version,
+				PHP:      tt.fields.PHP,
+				Webroot:  tt.fields.Webroot,
+				Xdebug:   tt.fields.Xdebug,
+			}
+			got, err := s.GetAbsPath(tt.args.home)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Site.GetAbsPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Site.GetAbsPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSite_GetAbsPath_Expansion(t *testing.T) {
+	os.Setenv("NITRO_TEST_SITES", "/srv/sites")
+	defer os.Unsetenv("NITRO_TEST_SITES")
+
+	tests := []struct {
+		name    string
+		site    Site
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "a leading tilde is the home directory",
+			site: Site{Path: "~/Sites/demo"},
+			want: "/Users/oli/Sites/demo",
+		},
+		{
+			name: "$HOME is the home directory",
+			site: Site{Path: "$HOME/Sites/demo"},
+			want: "/Users/oli/Sites/demo",
+		},
+		{
+			name: "environment variables are expanded",
+			site: Site{Path: "${NITRO_TEST_SITES}/demo"},
+			want: "/srv/sites/demo",
+		},
+		{
+			name: "tildes that are not leading are kept",
+			site: Site{Path: "/srv/~demo"},
+			want: "/srv/~demo",
+		},
+		{
+			name: "relative paths in project configs are relative to the config",
+			site: Site{Path: "./demo", base: "/Users/oli/dev"},
+			want: "/Users/oli/dev/demo",
+		},
+		{
+			name: "expanded paths in project configs are not relative to the config",
+			site: Site{Path: "$HOME/demo", base: "/Users/oli/dev"},
+			want: "/Users/oli/demo",
+		},
+		{
+			name:    "variables that are not set return an error",
+			site:    Site{Path: "${NITRO_TEST_MISSING}/demo"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.site.GetAbsPath("/Users/oli")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Site.GetAbsPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Site.GetAbsPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_EnabledSites(t *testing.T) {
+	c := &Config{
+		Sites: []Site{
+			{Hostname: "one.nitro"},
+			{Hostname: "two.nitro", Disabled: true},
+			{Hostname: "three.nitro"},
+		},
+	}
+
+	var got []string
+	for _, s := range c.EnabledSites() {
+		got = append(got, s.Hostname)
+	}
+
+	want := []string{"one.nitro", "three.nitro"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Config.EnabledSites() = %v, want %v", got, want)
+	}
+
+	// enabling the site includes it again
+	c.Sites[1].Disabled = false
+	if got := len(c.EnabledSites()); got != 3 {
+		t.Errorf("expected 3 enabled sites, got %d", got)
+	}
+}
+
+func TestSite_GetHostsEntries(t *testing.T) {
+	s := &Site{
+		Hostname: "mysite.nitro",
+		Aliases:  []string{"*.mysite.nitro", "othersite.nitro"},
+	}
+
+	want := []string{"mysite.nitro", "othersite.nitro"}
+	if got := s.GetHostsEntries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Site.GetHostsEntries() = %v, want %v", got, want)
+	}
+}
+
+func TestConfig_GetTLD(t *testing.T) {
+	tests := []struct {
+		name string
+		tld  string
+		want string
+	}{
+		{
+			name: "empty tlds use the default",
+			want: "nitro",
+		},
+		{
+			name: "custom tlds are returned",
+			tld:  "test",
+			want: "test",
+		},
+		{
+			name: "periods are removed and tlds are lowercase",
+			tld:  ".Localhost",
+			want: "localhost",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{TLD: tt.tld}
+			if got := c.GetTLD(); got != tt.want {
+				t.Errorf("Config.GetTLD() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		tld          string
+		sites        []Site
+		wantWarnings int
+	}{
+		{
+			name: "sites using the default tld do not warn",
+			sites: []Site{
+				{Hostname: "mysite.nitro", Aliases: []string{"*.mysite.nitro"}},
+			},
+		},
+		{
+			name: "sites using a custom tld do not warn",
+			tld:  "test",
+			sites: []Site{
+				{Hostname: "mysite.test"},
+			},
+		},
+		{
+			name: "hostnames and aliases with another tld warn",
+			tld:  "test",
+			sites: []Site{
+				{Hostname: "mysite.nitro", Aliases: []string{"alias.test", "other.localhost"}},
+			},
+			wantWarnings: 2,
+		},
+		{
+			name: "sites with opcache and xdebug enabled warn",
+			sites: []Site{
+				{Hostname: "mysite.nitro", Xdebug: true, PHP: PHP{OpcacheEnable: true}},
+				{Hostname: "other.nitro", Xdebug: true},
+				{Hostname: "another.nitro", PHP: PHP{OpcacheEnable: true}},
+			},
+			wantWarnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{TLD: tt.tld, Sites: tt.sites}
+			if got := c.Validate(); len(got) != tt.wantWarnings {
+				t.Errorf("Config.Validate() = %v, want %d warnings", got, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateHooks(t *testing.T) {
+	sites := []Site{{Hostname: "mysite.nitro"}}
+
+	tests := []struct {
+		name    string
+		hooks   []Hook
+		wantErr bool
+	}{
+		{
+			name: "hooks with a command and site are valid",
+			hooks: []Hook{
+				{Site: "mysite.nitro", Command: "php craft migrate/all"},
+				{Site: "mysite.nitro", Command: "php craft clear-caches/all", ContinueOnError: true},
+			},
+		},
+		{
+			name: "hooks without a command return an error",
+			hooks: []Hook{
+				{Site: "mysite.nitro", Command: "  "},
+			},
+			wantErr: true,
+		},
+		{
+			name: "hooks for an unknown site return an error",
+			hooks: []Hook{
+				{Site: "unknown.nitro", Command: "php craft migrate/all"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Sites: sites, Hooks: Hooks{AfterApply: tt.hooks}}
+			if err := c.ValidateHooks(); (err != nil) != tt.wantErr {
+				t.Errorf("Config.ValidateHooks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSite_ValidateImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		site    Site
+		wantErr bool
+	}{
+		{
+			name: "sites with a PHP version are valid",
+			site: Site{Hostname: "mysite.nitro", Version: "7.4"},
+		},
+		{
+			name: "sites with an image are valid",
+			site: Site{Hostname: "mysite.nitro", Image: "registry.example.com/team/php:7.4"},
+		},
+		{
+			name: "sites with a Dockerfile and PHP version are valid",
+			site: Site{Hostname: "mysite.nitro", Version: "7.4", Dockerfile: "Dockerfile"},
+		},
+		{
+			name:    "sites with an image and a Dockerfile return an error",
+			site:    Site{Hostname: "mysite.nitro", Image: "registry.example.com/team/php:7.4", Dockerfile: "Dockerfile"},
+			wantErr: true,
+		},
+		{
+			name:    "sites without an image, Dockerfile, or PHP version return an error",
+			site:    Site{Hostname: "mysite.nitro"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.site.ValidateImage(); (err != nil) != tt.wantErr {
+				t.Errorf("Site.ValidateImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateContainers(t *testing.T) {
+	tests := []struct {
+		name       string
+		containers []Container
+		wantErr    bool
+	}{
+		{
+			name: "containers with a name, image, and ports are valid",
+			containers: []Container{
+				{Name: "queue", Image: "craftcms/php-fpm", Command: []string{"php", "craft", "queue/listen"}},
+				{Name: "adminer", Image: "adminer", Ports: []string{"8080:8080"}, Volumes: []string{"/data", "~/dev:/app"}},
+			},
+		},
+		{
+			name:       "containers without a name return an error",
+			containers: []Container{{Image: "adminer"}},
+			wantErr:    true,
+		},
+		{
+			name:       "containers with a space in the name return an error",
+			containers: []Container{{Name: "my queue", Image: "adminer"}},
+			wantErr:    true,
+		},
+		{
+			name:       "containers without an image return an error",
+			containers: []Container{{Name: "adminer"}},
+			wantErr:    true,
+		},
+		{
+			name:       "ports without a host port return an error",
+			containers: []Container{{Name: "adminer", Image: "adminer", Ports: []string{"8080"}}},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid ports return an error",
+			containers: []Container{{Name: "adminer", Image: "adminer", Ports: []string{"8080:http"}}},
+			wantErr:    true,
+		},
+		{
+			name:       "volumes without a container path return an error",
+			containers: []Container{{Name: "adminer", Image: "adminer", Volumes: []string{"/data:"}}},
+			wantErr:    true,
+		},
+		{
+			name:       "duplicate names return an error",
+			containers: []Container{{Name: "adminer", Image: "adminer"}, {Name: "adminer", Image: "adminer"}},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Containers: tt.containers}
+			if err := c.ValidateContainers(); (err != nil) != tt.wantErr {
+				t.Errorf("Config.ValidateContainers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContainer_GetVolumes(t *testing.T) {
+	home := filepath.Join(string(filepath.Separator), "home", "nitro")
+
+	c := &Container{Name: "tools", Volumes: []string{"/data", "~/dev/mysite:/app", "/var/cache:/cache"}}
+
+	got, err := c.GetVolumes(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ContainerVolume{
+		{Target: "/data"},
+		{Source: filepath.Join(home, "dev", "mysite"), Target: "/app"},
+		{Source: filepath.Clean("/var/cache"), Target: "/cache"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Container.GetVolumes() = %v, want %v", got, want)
+	}
+}
+
+func TestConfig_ValidateTimezones(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		site     string
+		wantErr  bool
+	}{
+		{
+			name: "empty timezones are valid",
+		},
+		{
+			name:     "IANA timezones are valid",
+			timezone: "America/Chicago",
+			site:     "Europe/Berlin",
 		},
 		{
-			name:    "sites that don't exist return an error",
-			args:    args{site: "idontexist"},
+			name:     "bogus global timezones return an error",
+			timezone: "Mars/Olympus_Mons",
+			wantErr:  true,
+		},
+		{
+			name:    "bogus site timezones return an error",
+			site:    "Europe/Nowhere",
 			wantErr: true,
 		},
+		{
+			name:     "the local timezone returns an error",
+			timezone: "Local",
+			wantErr:  true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := &Config{
-				Blackfire: tt.fields.Blackfire,
-				Databases: tt.fields.Databases,
-				Services:  tt.fields.Services,
-				Sites:     tt.fields.Sites,
-				File:      tt.fields.File,
-			}
-			if err := c.EnableXdebug(tt.args.site); (err != nil) != tt.wantErr {
-				t.Errorf("Config.EnableXdebug() error = %v, wantErr %v", err, tt.wantErr)
+			c := &Config{Timezone: tt.timezone, Sites: []Site{{Hostname: "craft-dev.nitro", Timezone: tt.site}}}
+			if err := c.ValidateTimezones(); (err != nil) != tt.wantErr {
+				t.Errorf("Config.ValidateTimezones() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestConfig_DisableXdebug(t *testing.T) {
-	type fields struct {
-		Blackfire Blackfire
-		Databases []Database
-		Services  Services
-		Sites     []Site
-		File      string
+func TestConfig_GetSiteTimezone(t *testing.T) {
+	c := &Config{Timezone: "America/Chicago"}
+
+	if got := c.GetSiteTimezone(Site{Timezone: "Europe/Berlin"}); got != "Europe/Berlin" {
+		t.Errorf("expected the site timezone to override the global timezone, got %q", got)
 	}
-	type args struct {
-		site string
+
+	if got := c.GetSiteTimezone(Site{}); got != "America/Chicago" {
+		t.Errorf("expected the global timezone, got %q", got)
+	}
+
+	if got := TimezoneEnvs("Europe/Berlin"); !reflect.DeepEqual(got, []string{"TZ=Europe/Berlin", "PHP_DATE_TIMEZONE=Europe/Berlin"}) {
+		t.Errorf("TimezoneEnvs() = %v", got)
 	}
+
+	if got := TimezoneEnvs(""); got != nil {
+		t.Errorf("expected no envs without a timezone, got %v", got)
+	}
+}
+
+func TestRestart_Policy(t *testing.T) {
 	tests := []struct {
 		name    string
-		fields  fields
-		args    args
-		wantErr bool
+		restart Restart
+		site    bool
+		want    string
 	}{
 		{
-			name: "can enable xdebug for a site",
-			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "somesite",
-						Xdebug:   false,
-					},
-					{
-						Hostname: "anothersite",
-						Xdebug:   true,
-					},
-				},
-			},
-			args:    args{site: "somesite"},
-			wantErr: false,
+			name: "services are restarted by default",
+			want: "unless-stopped",
 		},
 		{
-			name:    "sites that don't exist return an error",
-			args:    args{site: "idontexist"},
-			wantErr: true,
+			name: "sites are not restarted by default",
+			site: true,
+			want: "no",
+		},
+		{
+			name:    "sites are restarted when enabled",
+			restart: Restart{Sites: true},
+			site:    true,
+			want:    "unless-stopped",
+		},
+		{
+			name:    "disabled does not restart anything",
+			restart: Restart{Disabled: true, Sites: true},
+			site:    true,
+			want:    "no",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := &Config{
-				Blackfire: tt.fields.Blackfire,
-				Databases: tt.fields.Databases,
-				Services:  tt.fields.Services,
-				Sites:     tt.fields.Sites,
-				File:      tt.fields.File,
-			}
-			if err := c.DisableXdebug(tt.args.site); (err != nil) != tt.wantErr {
-				t.Errorf("Config.DisableXdebug() error = %v, wantErr %v", err, tt.wantErr)
+			if got := tt.restart.Policy(tt.site); got != tt.want {
+				t.Errorf("Restart.Policy() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestConfig_AddSite(t *testing.T) {
+func TestSite_GetPHPIniPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	type fields struct {
-		Blackfire Blackfire
-		Databases []Database
-		Services  Services
-		Sites     []Site
-		File      string
+		Path   string
+		PHPIni string
 	}
 	type args struct {
-		s Site
+		home string
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
+		want    string
 		wantErr bool
 	}{
 		{
-			name: "existing hostnames returns an error",
+			name: "sites without a php.ini return an empty string",
 			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "example",
-					},
-				},
+				Path: filepath.Join(wd, "testdata"),
 			},
 			args: args{
-				s: Site{Hostname: "example"},
+				home: wd,
 			},
-			wantErr: true,
+			want: "",
+		},
+		{
+			name: "relative paths are relative to the site",
+			fields: fields{
+				Path:   filepath.Join(wd, "testdata"),
+				PHPIni: "config/php.ini",
+			},
+			args: args{
+				home: wd,
+			},
+			want: filepath.Join(wd, "testdata", "config", "php.ini"),
+		},
+		{
+			name: "paths using the home directory are expanded",
+			fields: fields{
+				Path:   filepath.Join(wd, "testdata"),
+				PHPIni: "~/php.ini",
+			},
+			args: args{
+				home: wd,
+			},
+			want: filepath.Join(wd, "php.ini"),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := &Config{
-				Blackfire: tt.fields.Blackfire,
-				Databases: tt.fields.Databases,
-				Services:  tt.fields.Services,
-				Sites:     tt.fields.Sites,
-				File:      tt.fields.File,
+			s := &Site{
+				Path:   tt.fields.Path,
+				PHPIni: tt.fields.PHPIni,
 			}
-			if err := c.AddSite(tt.args.s); (err != nil) != tt.wantErr {
-				t.Errorf("Config.AddSite() error = %v, wantErr %v", err, tt.wantErr)
+			got, err := s.GetPHPIniPath(tt.args.home)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Site.GetPHPIniPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Site.GetPHPIniPath() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestSite_GetAbsPath(t *testing.T) {
+func TestSite_GetNginxConfigPath(t *testing.T) {
 	wd, err := os.Getwd()
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	type fields struct {
-		Hostname string
-		Aliases  []string
-		Path     string
-		Version  string
-		PHP      PHP
-		Webroot  string
-		Xdebug   bool
+		Path        string
+		NginxConfig string
 	}
 	type args struct {
 		home string
@@ -543,35 +1714,142 @@ func TestSite_GetAbsPath(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name: "existing paths return the complete path",
+			name: "sites without a nginx config return an empty string",
 			fields: fields{
 				Path: filepath.Join(wd, "testdata"),
 			},
 			args: args{
 				home: wd,
 			},
-			want:    filepath.Join(wd, "testdata"),
-			wantErr: false,
+			want: "",
+		},
+		{
+			name: "relative paths are relative to the site",
+			fields: fields{
+				Path:        filepath.Join(wd, "testdata"),
+				NginxConfig: "config/nginx.conf",
+			},
+			args: args{
+				home: wd,
+			},
+			want: filepath.Join(wd, "testdata", "config", "nginx.conf"),
+		},
+		{
+			name: "paths using the home directory are expanded",
+			fields: fields{
+				Path:        filepath.Join(wd, "testdata"),
+				NginxConfig: "~/nginx.conf",
+			},
+			args: args{
+				home: wd,
+			},
+			want: filepath.Join(wd, "nginx.conf"),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Site{
-				Hostname: tt.fields.Hostname,
-				Aliases:  tt.fields.Aliases,
-				Path:     tt.fields.Path,
-				Version:  tt.fields.Version,
-				PHP:      tt.fields.PHP,
-				Webroot:  tt.fields.Webroot,
-				Xdebug:   tt.fields.Xdebug,
+				Path:        tt.fields.Path,
+				NginxConfig: tt.fields.NginxConfig,
 			}
-			got, err := s.GetAbsPath(tt.args.home)
+			got, err := s.GetNginxConfigPath(tt.args.home)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Site.GetAbsPath() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("Site.GetNginxConfigPath() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if got != tt.want {
-				t.Errorf("Site.GetAbsPath() = %v, want %v", got, tt.want)
+				t.Errorf("Site.GetNginxConfigPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSite_GetDockerfilePath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		dockerfile string
+		want       string
+	}{
+		{
+			name: "sites without a Dockerfile return an empty string",
+			want: "",
+		},
+		{
+			name:       "paths are relative to the site",
+			dockerfile: "docker/Dockerfile",
+			want:       filepath.Join(wd, "testdata", "docker", "Dockerfile"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Site{
+				Path:       filepath.Join(wd, "testdata"),
+				Dockerfile: tt.dockerfile,
+			}
+			got, err := s.GetDockerfilePath(wd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Site.GetDockerfilePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_SetSitePHPVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostname string
+		version  string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "can change a sites php version",
+			hostname: "siteone.nitro",
+			version:  "8.0",
+			want:     "8.0",
+		},
+		{
+			name:     "unsupported versions return an error",
+			hostname: "siteone.nitro",
+			version:  "5.6",
+			want:     "7.4",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown sites return an error",
+			hostname: "unknown.nitro",
+			version:  "8.0",
+			want:     "7.4",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				Sites: []Site{
+					{Hostname: "siteone.nitro", Version: "7.4"},
+					{Hostname: "sitetwo.nitro", Version: "7.4"},
+				},
+			}
+
+			if err := c.SetSitePHPVersion(tt.hostname, tt.version); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetSitePHPVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if c.Sites[0].Version != tt.want {
+				t.Errorf("expected the version to be %q, got %q", tt.want, c.Sites[0].Version)
+			}
+
+			if c.Sites[1].Version != "7.4" {
+				t.Errorf("expected the other site to not change, got %q", c.Sites[1].Version)
 			}
 		})
 	}
@@ -975,6 +2253,53 @@ func TestConfig_SetPHPIntSetting(t *testing.T) {
 	}
 }
 
+func TestSite_GetWebrootPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		webroot string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty webroots use web",
+			want: "/app/web",
+		},
+		{
+			name:    "relative webroots are in the app directory",
+			webroot: "public",
+			want:    "/app/public",
+		},
+		{
+			name:    "nested webroots are in the app directory",
+			webroot: "site/public/",
+			want:    "/app/site/public",
+		},
+		{
+			name:    "absolute webroots return an error",
+			webroot: "/var/www/html",
+			wantErr: true,
+		},
+		{
+			name:    "webroots outside of the site return an error",
+			webroot: "../public",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Site{Hostname: "mysite.nitro", Webroot: tt.webroot}
+			got, err := s.GetWebrootPath()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Site.GetWebrootPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Site.GetWebrootPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSite_GetContainerPath(t *testing.T) {
 	type fields struct {
 		Webroot string
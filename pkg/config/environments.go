@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Environments returns the config files for every environment in the nitro
+// directory (e.g. ~/.nitro/*.yaml) sorted by name. Empty files are skipped.
+func Environments(home string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(home, DirectoryName, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var environments []string
+	for _, f := range files {
+		stat, err := os.Stat(f)
+		if err != nil || stat.IsDir() || stat.Size() == 0 {
+			continue
+		}
+
+		environments = append(environments, f)
+	}
+
+	if len(environments) == 0 {
+		return nil, ErrNoConfigFile
+	}
+
+	sort.Strings(environments)
+
+	return environments, nil
+}
+
+// ValidateEnvironmentPorts returns an error if the environments bind the same
+// host port. Each environment has its own network, proxy, and containers, so
+// every port in one environment must be different from the ports in the other
+// environments, including the proxy ports (see Proxy).
+func ValidateEnvironmentPorts(environments []*Config) error {
+	var conflicts []string
+	for i, a := range environments {
+		for _, b := range environments[i+1:] {
+			for _, x := range a.hostPorts() {
+				for _, y := range b.hostPorts() {
+					if x.port != "" && x.port == y.port && addressesOverlap(x.address, y.address) {
+						conflicts = append(conflicts, fmt.Sprintf("the port %s is used by %s in %s and %s in %s", x.port, x.owner, filepath.Base(a.File), y.owner, filepath.Base(b.File)))
+					}
+				}
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("unable to use the same host port in more than one environment, %s", strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// ValidateEnvironmentHostnames returns an error if the environments use the
+// same hostname for a site, database, service, or custom container. The
+// containers are named after the hostname, so an environment would replace
+// the container of another environment.
+func ValidateEnvironmentHostnames(environments []*Config) error {
+	files := map[string]string{}
+
+	var conflicts []string
+	for _, cfg := range environments {
+		var names []string
+		for _, s := range cfg.Sites {
+			names = append(names, s.Hostname)
+		}
+
+		for _, db := range cfg.Databases {
+			if hostname, err := db.GetHostname(); err == nil {
+				names = append(names, hostname)
+			}
+		}
+
+		for _, name := range []string{"dynamodb", "elasticsearch", "mailhog", "meilisearch", "minio", "redis"} {
+			if cfg.Services.enabled(name) {
+				names = append(names, fmt.Sprintf("%s.service.nitro", name))
+			}
+		}
+
+		for _, c := range cfg.Containers {
+			names = append(names, fmt.Sprintf("%s.containers.nitro", c.Name))
+		}
+
+		for _, name := range names {
+			if other, ok := files[name]; ok && other != cfg.File {
+				conflicts = append(conflicts, fmt.Sprintf("%s is used in %s and %s", name, filepath.Base(other), filepath.Base(cfg.File)))
+				continue
+			}
+
+			files[name] = cfg.File
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("unable to use the same hostname in more than one environment, %s", strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
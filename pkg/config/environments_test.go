@@ -0,0 +1,150 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvironments(t *testing.T) {
+	// Arrange
+	home := t.TempDir()
+	dir := filepath.Join(home, DirectoryName)
+	if err := os.MkdirAll(filepath.Join(dir, "backups.yaml"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"work.yaml":   "sites: []",
+		"nitro.yaml":  "sites: []",
+		"empty.yaml":  "",
+		"schema.json": "{}",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Act
+	got, err := Environments(home)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{filepath.Join(dir, "nitro.yaml"), filepath.Join(dir, "work.yaml")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, err := Environments(t.TempDir()); err != ErrNoConfigFile {
+		t.Errorf("expected ErrNoConfigFile without any environments, got %v", err)
+	}
+}
+
+func TestValidateEnvironmentPorts(t *testing.T) {
+	// the other environments use different proxy ports
+	work := Proxy{HTTPPort: "8080", HTTPSPort: "8443", APIPort: "5001"}
+
+	tests := []struct {
+		name         string
+		environments []*Config
+		wantErr      bool
+		wantOwners   []string
+	}{
+		{
+			name: "different ports are valid",
+			environments: []*Config{
+				{File: "nitro.yaml", Databases: []Database{{Engine: "mysql", Version: "8.0", Port: "3306"}}},
+				{File: "work.yaml", Proxy: work, Databases: []Database{{Engine: "mysql", Version: "8.0", Port: "3307"}}},
+			},
+		},
+		{
+			name: "the same database in both environments is not valid",
+			environments: []*Config{
+				{File: "nitro.yaml", Databases: []Database{{Engine: "mysql", Version: "8.0", Port: "3306"}}},
+				{File: "work.yaml", Proxy: work, Databases: []Database{{Engine: "mysql", Version: "8.0", Port: "3306"}}},
+			},
+			wantErr:    true,
+			wantOwners: []string{"the mysql 8.0 database in nitro.yaml", "the mysql 8.0 database in work.yaml"},
+		},
+		{
+			name: "a container on the port of a database in another environment is not valid",
+			environments: []*Config{
+				{File: "nitro.yaml", Databases: []Database{{Engine: "postgres", Version: "13", Port: "5432"}}},
+				{File: "other.yaml", Proxy: Proxy{HTTPPort: "8081", HTTPSPort: "8444", APIPort: "5002"}},
+				{File: "work.yaml", Proxy: work, Containers: []Container{{Name: "pgbouncer", Image: "pgbouncer", Ports: []string{"5432:5432"}}}},
+			},
+			wantErr:    true,
+			wantOwners: []string{"the postgres 13 database in nitro.yaml", "the pgbouncer container in work.yaml"},
+		},
+		{
+			name: "the same proxy ports are not valid",
+			environments: []*Config{
+				{File: "nitro.yaml"},
+				{File: "work.yaml"},
+			},
+			wantErr:    true,
+			wantOwners: []string{"the proxy in nitro.yaml", "the proxy api in work.yaml"},
+		},
+		{
+			name: "ports on different bind addresses are valid",
+			environments: []*Config{
+				{File: "nitro.yaml", Containers: []Container{{Name: "pgbouncer", Image: "pgbouncer", Ports: []string{"6432:5432"}}}},
+				{File: "work.yaml", Proxy: work, BindAddress: "127.0.0.2", Containers: []Container{{Name: "bouncer", Image: "pgbouncer", Ports: []string{"6432:5432"}}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEnvironmentPorts(tt.environments)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateEnvironmentPorts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, owner := range tt.wantOwners {
+				if !strings.Contains(err.Error(), owner) {
+					t.Errorf("expected the error to name %s, got %v", owner, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateEnvironmentHostnames(t *testing.T) {
+	// Arrange
+	environments := []*Config{
+		{File: "nitro.yaml", Sites: []Site{{Hostname: "craft-dev.nitro"}}, Services: Services{Redis: true}, Containers: []Container{{Name: "pgbouncer"}}},
+		{File: "work.yaml", Sites: []Site{{Hostname: "work.nitro"}}, Databases: []Database{{Engine: "mysql", Version: "8.0", Port: "3307"}}},
+	}
+
+	// Act
+	if err := ValidateEnvironmentHostnames(environments); err != nil {
+		t.Fatalf("expected different hostnames to be valid, got %v", err)
+	}
+
+	environments = append(environments, &Config{
+		File:       "other.yaml",
+		Sites:      []Site{{Hostname: "craft-dev.nitro"}},
+		Services:   Services{Redis: true},
+		Containers: []Container{{Name: "pgbouncer"}},
+	})
+
+	err := ValidateEnvironmentHostnames(environments)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected the same hostname in two environments to return an error")
+	}
+
+	for _, want := range []string{"craft-dev.nitro is used in nitro.yaml and other.yaml", "redis.service.nitro is used in nitro.yaml and other.yaml", "pgbouncer.containers.nitro is used in nitro.yaml and other.yaml"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to contain %q, got %v", want, err)
+		}
+	}
+}
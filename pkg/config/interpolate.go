@@ -0,0 +1,217 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/craftcms/nitro/pkg/secrets"
+)
+
+// StrictEnv returns an error when a config value references an environment
+// variable that is not set and does not have a default, otherwise the value
+// is empty. It is set using the --strict-env flag.
+var StrictEnv = false
+
+// SecretStore returns the store used to resolve ${secret:NAME} references, it
+// is only called when the config references a secret.
+var SecretStore = secrets.New
+
+// envRegex matches ${VAR} and ${VAR:-default} in config values
+var envRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// secretRegex matches ${secret:NAME} in config values
+var secretRegex = regexp.MustCompile(`\$\{secret:([A-Za-z0-9_.-]+)\}`)
+
+// token is a config value that references environment variables, the raw
+// value is written back to the file when the config is saved so secrets
+// are never written to the config.
+type token struct {
+	raw      string
+	resolved string
+}
+
+// field is a config value that supports environment variables
+type field struct {
+	get func() string
+	set func(string)
+}
+
+// Interpolate replaces the ${VAR} and ${VAR:-default} references in the value using
+// the lookup func (e.g. os.LookupEnv). The default is used when the variable is
+// not set or is empty. When strict is true, variables that are not set and do
+// not have a default return an error.
+func Interpolate(value string, lookup func(string) (string, bool), strict bool) (string, error) {
+	var err error
+	resolved := envRegex.ReplaceAllStringFunc(value, func(match string) string {
+		parts := envRegex.FindStringSubmatch(match)
+
+		v, ok := lookup(parts[1])
+		switch {
+		case ok && v != "":
+			return v
+		case strings.Contains(match, ":-"):
+			return parts[2]
+		case !ok && strict && err == nil:
+			err = fmt.Errorf("the environment variable %s is not set", parts[1])
+		}
+
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return resolved, nil
+}
+
+// SecretRef returns the reference to a secret in the secret store, which is
+// used as the config value (e.g. ${secret:blackfire.server_token}).
+func SecretRef(name string) string {
+	return "${secret:" + name + "}"
+}
+
+// resolveSecrets replaces the ${secret:NAME} references in the value using the
+// secret store.
+func resolveSecrets(value string) (string, error) {
+	if !secretRegex.MatchString(value) {
+		return value, nil
+	}
+
+	store, err := SecretStore()
+	if err != nil {
+		return "", fmt.Errorf("unable to read the secrets, %w", err)
+	}
+
+	resolved := secretRegex.ReplaceAllStringFunc(value, func(match string) string {
+		name := secretRegex.FindStringSubmatch(match)[1]
+
+		v, e := store.Get(name)
+		if e != nil && err == nil {
+			err = fmt.Errorf("unable to get the secret %s, %w", name, e)
+		}
+
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return resolved, nil
+}
+
+// interpolate replaces the environment variables and secrets in the config
+// values that support them (blackfire credentials and site labels) and keeps
+// the raw values so they can be restored when the config is saved.
+func (c *Config) interpolate(lookup func(string) (string, bool), strict bool) error {
+	c.tokens = nil
+
+	for path, f := range c.envFields() {
+		raw := f.get()
+		if !envRegex.MatchString(raw) && !secretRegex.MatchString(raw) {
+			continue
+		}
+
+		resolved, err := Interpolate(raw, lookup, strict)
+		if err != nil {
+			return fmt.Errorf("unable to resolve %s, %w", path, err)
+		}
+
+		resolved, err = resolveSecrets(resolved)
+		if err != nil {
+			return fmt.Errorf("unable to resolve %s, %w", path, err)
+		}
+
+		if c.tokens == nil {
+			c.tokens = make(map[string]token)
+		}
+
+		c.tokens[path] = token{raw: raw, resolved: resolved}
+		f.set(resolved)
+	}
+
+	return nil
+}
+
+// restoreTokens sets the values that were interpolated back to the raw values
+// and returns a func to undo the change. Values that changed since the config
+// was loaded are not restored.
+func (c *Config) restoreTokens() func() {
+	fields := c.envFields()
+
+	var undo []func()
+	for path, t := range c.tokens {
+		f, ok := fields[path]
+		if !ok || f.get() != t.resolved {
+			continue
+		}
+
+		f.set(t.raw)
+
+		resolved := t.resolved
+		undo = append(undo, func() { f.set(resolved) })
+	}
+
+	return func() {
+		for _, u := range undo {
+			u()
+		}
+	}
+}
+
+// SetField sets a config value that supports environment variables and secrets
+// using its path (e.g. blackfire.server_token).
+func (c *Config) SetField(path, value string) error {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	f, ok := c.envFields()[path]
+	if !ok {
+		return fmt.Errorf("unable to find the config value %s", path)
+	}
+
+	f.set(value)
+	delete(c.tokens, path)
+
+	return nil
+}
+
+// envFields returns the config values that support environment variables by their path,
+// which are the blackfire credentials, site labels, and the env of custom containers. The
+// database passwords are not part of the config, so they are not included.
+func (c *Config) envFields() map[string]field {
+	fields := map[string]field{
+		"blackfire.server_id": {
+			get: func() string { return c.Blackfire.ServerID },
+			set: func(v string) { c.Blackfire.ServerID = v },
+		},
+		"blackfire.server_token": {
+			get: func() string { return c.Blackfire.ServerToken },
+			set: func(v string) { c.Blackfire.ServerToken = v },
+		},
+	}
+
+	for i := range c.Sites {
+		labels := c.Sites[i].Labels
+		for k := range labels {
+			k := k
+			fields[fmt.Sprintf("sites.%s.labels.%s", c.Sites[i].Hostname, k)] = field{
+				get: func() string { return labels[k] },
+				set: func(v string) { labels[k] = v },
+			}
+		}
+	}
+
+	for i := range c.Containers {
+		env := c.Containers[i].Env
+		for k := range env {
+			k := k
+			fields[fmt.Sprintf("containers.%s.env.%s", c.Containers[i].Name, k)] = field{
+				get: func() string { return env[k] },
+				set: func(v string) { env[k] = v },
+			}
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,291 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/secrets"
+)
+
+func lookup(envs map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := envs[name]
+		return v, ok
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	envs := map[string]string{
+		"BLACKFIRE_TOKEN": "my-token",
+		"EMPTY":           "",
+		"HOST":            "example",
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		strict  bool
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "values without variables are not changed",
+			value: "my-token",
+			want:  "my-token",
+		},
+		{
+			name:  "variables are resolved",
+			value: "${BLACKFIRE_TOKEN}",
+			want:  "my-token",
+		},
+		{
+			name:  "multiple variables are resolved",
+			value: "${HOST}.${MISSING:-nitro}",
+			want:  "example.nitro",
+		},
+		{
+			name:  "missing variables use the default",
+			value: "${MISSING:-fallback}",
+			want:  "fallback",
+		},
+		{
+			name:  "empty variables use the default",
+			value: "${EMPTY:-fallback}",
+			want:  "fallback",
+		},
+		{
+			name:  "missing variables are empty",
+			value: "${MISSING}",
+			want:  "",
+		},
+		{
+			name:    "missing variables return an error when strict",
+			value:   "${MISSING}",
+			strict:  true,
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name:   "missing variables with a default do not return an error when strict",
+			value:  "${MISSING:-}",
+			strict: true,
+			want:   "",
+		},
+		{
+			name:   "empty variables do not return an error when strict",
+			value:  "${EMPTY}",
+			strict: true,
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Interpolate(tt.value, lookup(envs), tt.strict)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Interpolate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_interpolate(t *testing.T) {
+	cfg := &Config{
+		Blackfire: Blackfire{
+			ServerID:    "my-id",
+			ServerToken: "${BLACKFIRE_TOKEN}",
+		},
+		Sites: []Site{
+			{
+				Hostname: "example.nitro",
+				Labels:   map[string]string{"traefik.host": "${HOST:-example}.nitro"},
+			},
+		},
+		Containers: []Container{
+			{
+				Name: "adminer",
+				Env:  map[string]string{"ADMINER_PASSWORD": "${ADMINER_PASSWORD}"},
+			},
+		},
+	}
+
+	if err := cfg.interpolate(lookup(map[string]string{"BLACKFIRE_TOKEN": "my-token", "ADMINER_PASSWORD": "secret"}), false); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Blackfire.ServerID != "my-id" {
+		t.Errorf("expected the server id to not change, got %q", cfg.Blackfire.ServerID)
+	}
+
+	if cfg.Blackfire.ServerToken != "my-token" {
+		t.Errorf("expected the server token to be resolved, got %q", cfg.Blackfire.ServerToken)
+	}
+
+	if got := cfg.Sites[0].Labels["traefik.host"]; got != "example.nitro" {
+		t.Errorf("expected the label to use the default, got %q", got)
+	}
+
+	if got := cfg.Containers[0].Env["ADMINER_PASSWORD"]; got != "secret" {
+		t.Errorf("expected the container env to be resolved, got %q", got)
+	}
+
+	// the raw values are restored for saving
+	undo := cfg.restoreTokens()
+	if cfg.Blackfire.ServerToken != "${BLACKFIRE_TOKEN}" {
+		t.Errorf("expected the server token to be restored, got %q", cfg.Blackfire.ServerToken)
+	}
+
+	if got := cfg.Sites[0].Labels["traefik.host"]; got != "${HOST:-example}.nitro" {
+		t.Errorf("expected the label to be restored, got %q", got)
+	}
+
+	if got := cfg.Containers[0].Env["ADMINER_PASSWORD"]; got != "${ADMINER_PASSWORD}" {
+		t.Errorf("expected the container env to be restored, got %q", got)
+	}
+
+	undo()
+	if cfg.Blackfire.ServerToken != "my-token" {
+		t.Errorf("expected the server token to be resolved after undo, got %q", cfg.Blackfire.ServerToken)
+	}
+
+	// the strict option returns an error
+	cfg.Blackfire.ServerID = "${MISSING}"
+	if err := cfg.interpolate(lookup(nil), true); err == nil {
+		t.Errorf("expected an error for the missing variable")
+	}
+}
+
+func TestConfig_Save_KeepsEnvReferences(t *testing.T) {
+	home := t.TempDir()
+	file := filepath.Join(home, DirectoryName, FileName)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "blackfire:\n  server_id: my-id\n  server_token: ${NITRO_TEST_BLACKFIRE_TOKEN}\n"
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("NITRO_TEST_BLACKFIRE_TOKEN", "secret-token")
+	defer os.Unsetenv("NITRO_TEST_BLACKFIRE_TOKEN")
+
+	cfg, err := Load(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Blackfire.ServerToken != "secret-token" {
+		t.Errorf("expected the server token to be resolved, got %q", cfg.Blackfire.ServerToken)
+	}
+
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(saved), "secret-token") {
+		t.Errorf("expected the saved config to not contain the secret, got:\n%s", saved)
+	}
+
+	if !strings.Contains(string(saved), "${NITRO_TEST_BLACKFIRE_TOKEN}") {
+		t.Errorf("expected the saved config to keep the variable, got:\n%s", saved)
+	}
+
+	if cfg.Blackfire.ServerToken != "secret-token" {
+		t.Errorf("expected the server token to stay resolved after saving, got %q", cfg.Blackfire.ServerToken)
+	}
+}
+
+func TestConfig_interpolate_Secrets(t *testing.T) {
+	original := SecretStore
+	defer func() { SecretStore = original }()
+
+	store := secrets.Memory{"blackfire.server_token": "my-token"}
+	SecretStore = func() (secrets.Store, error) { return store, nil }
+
+	cfg := &Config{
+		Blackfire: Blackfire{
+			ServerID:    "${BLACKFIRE_ID}",
+			ServerToken: SecretRef("blackfire.server_token"),
+		},
+	}
+
+	if err := cfg.interpolate(lookup(map[string]string{"BLACKFIRE_ID": "my-id"}), false); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Blackfire.ServerID != "my-id" {
+		t.Errorf("expected the server id to be resolved, got %q", cfg.Blackfire.ServerID)
+	}
+
+	if cfg.Blackfire.ServerToken != "my-token" {
+		t.Errorf("expected the server token to be resolved from the store, got %q", cfg.Blackfire.ServerToken)
+	}
+
+	// the secret reference is restored for saving
+	undo := cfg.restoreTokens()
+	if cfg.Blackfire.ServerToken != "${secret:blackfire.server_token}" {
+		t.Errorf("expected the server token to be restored, got %q", cfg.Blackfire.ServerToken)
+	}
+	undo()
+
+	// missing secrets return an error
+	cfg.Blackfire.ServerToken = SecretRef("missing")
+	if err := cfg.interpolate(lookup(nil), false); !errors.Is(err, secrets.ErrNotFound) {
+		t.Errorf("expected the secret to not be found, got %v", err)
+	}
+
+	// an unavailable store returns an error
+	SecretStore = func() (secrets.Store, error) { return nil, secrets.ErrUnavailable }
+	cfg.Blackfire.ServerToken = SecretRef("blackfire.server_token")
+	if err := cfg.interpolate(lookup(nil), false); !errors.Is(err, secrets.ErrUnavailable) {
+		t.Errorf("expected the store to be unavailable, got %v", err)
+	}
+}
+
+func TestConfig_SetField(t *testing.T) {
+	cfg := &Config{
+		Blackfire: Blackfire{ServerToken: "${BLACKFIRE_TOKEN}"},
+		Sites: []Site{
+			{Hostname: "example.nitro", Labels: map[string]string{"traefik.host": "example.nitro"}},
+		},
+	}
+
+	if err := cfg.interpolate(lookup(map[string]string{"BLACKFIRE_TOKEN": "my-token"}), false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetField("blackfire.server_token", SecretRef("blackfire.server_token")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetField("sites.example.nitro.labels.traefik.host", "other.nitro"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetField("unknown", "value"); err == nil {
+		t.Errorf("expected an error for an unknown value")
+	}
+
+	// the new value is saved instead of the old reference
+	cfg.restoreTokens()
+	if cfg.Blackfire.ServerToken != "${secret:blackfire.server_token}" {
+		t.Errorf("expected the server token to be the secret reference, got %q", cfg.Blackfire.ServerToken)
+	}
+
+	if got := cfg.Sites[0].Labels["traefik.host"]; got != "other.nitro" {
+		t.Errorf("expected the label to be set, got %q", got)
+	}
+}
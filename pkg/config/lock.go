@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// lockTimeout is how long to wait for another process to release the lock
+	lockTimeout = 10 * time.Second
+
+	// lockStale is the age a lock file is considered abandoned (e.g. a process was killed)
+	lockStale = 30 * time.Second
+)
+
+// applyHolder is written to the lock file while apply holds the lock, other
+// processes wait for the apply to finish instead of timing out
+const applyHolder = "apply"
+
+// lock takes a file and creates a lock file next to it to prevent multiple nitro
+// processes from writing the file at the same time. The lock is advisory and
+// works on every platform because it only relies on creating a file exclusively.
+// It returns a func to release the lock.
+func lock(file string) (func(), error) {
+	return acquire(file, "")
+}
+
+// LockForApply locks the config file for the duration of an apply so the config
+// is not saved (e.g. by nitro add or nitro edit) while the environment is being
+// reconciled. It shares the lock file used by Save, other processes wait until
+// the apply releases the lock. The lock is refreshed while it is held so long
+// applies are not considered abandoned. It returns a func to release the lock.
+func LockForApply(file string) (func(), error) {
+	release, err := acquire(file, applyHolder)
+	if err != nil {
+		return nil, err
+	}
+
+	name := file + ".lock"
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(lockStale / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				now := time.Now()
+				os.Chtimes(name, now, now)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		release()
+	}, nil
+}
+
+// acquire creates the lock file for the file and writes the process id and
+// holder to it. When another apply holds the lock, it waits until the lock is
+// released or abandoned.
+func acquire(file, holder string) (func(), error) {
+	name := file + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			// write the process id to help debugging
+			fmt.Fprintf(f, "%d", os.Getpid())
+			if holder != "" {
+				fmt.Fprintf(f, " %s", holder)
+			}
+			f.Close()
+
+			return func() { os.Remove(name) }, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("unable to lock the config, %w", err)
+		}
+
+		// remove abandoned lock files
+		if stat, err := os.Stat(name); err == nil && time.Since(stat.ModTime()) > lockStale {
+			os.Remove(name)
+			continue
+		}
+
+		// applies can take longer than the timeout, so wait for them to finish
+		if time.Now().After(deadline) && !heldByApply(name) {
+			return nil, fmt.Errorf("unable to lock the config, another nitro process is using %s", file)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// heldByApply returns true if the lock file was created by apply.
+func heldByApply(name string) bool {
+	content, err := ioutil.ReadFile(name)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasSuffix(strings.TrimSpace(string(content)), " "+applyHolder)
+}
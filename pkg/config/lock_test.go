@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockForApply_SaveWaitsForApply(t *testing.T) {
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 100 * time.Millisecond
+
+	// Arrange
+	file := filepath.Join(t.TempDir(), FileName)
+	cfg := &Config{File: file, Sites: []Site{{Hostname: "craft-dev.nitro", Path: "~/dev/craft-dev", Version: "7.4"}}}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := LockForApply(file)
+	if err != nil {
+		t.Fatalf("LockForApply() error = %v", err)
+	}
+
+	// Act
+	saved := make(chan error, 1)
+	go func() {
+		saved <- cfg.Save()
+	}()
+
+	// Assert
+	// the save waits longer than the timeout while the apply holds the lock
+	select {
+	case err := <-saved:
+		t.Fatalf("expected the save to wait for the apply, got %v", err)
+	case <-time.After(3 * lockTimeout):
+	}
+
+	unlock()
+
+	select {
+	case err := <-saved:
+		if err != nil {
+			t.Errorf("expected the save to complete after the apply, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the save to complete after the apply released the lock")
+	}
+
+	if _, err := os.Stat(file + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("expected the lock file to be removed, got %v", err)
+	}
+}
+
+func TestLock_TimesOutForOtherProcesses(t *testing.T) {
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 100 * time.Millisecond
+
+	// Arrange
+	file := filepath.Join(t.TempDir(), FileName)
+	unlock, err := lock(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	// Act
+	_, err = lock(file)
+
+	// Assert
+	if err == nil {
+		t.Error("expected an error when the lock is held by another save")
+	}
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// servicePorts are the host ports for each service, the ports can be changed
+// with an environment variable (e.g. NITRO_REDIS_PORT) and must match the
+// ports the containers use in pkg/svc.
+var servicePorts = []struct {
+	service string
+	env     string
+	port    string
+}{
+	{service: "dynamodb", env: "NITRO_DYNAMODB_PORT", port: "8000"},
+	{service: "elasticsearch", env: "NITRO_ELASTICSEARCH_PORT", port: "9200"},
+	{service: "mailhog", env: "NITRO_MAILHOG_SMTP_PORT", port: "1025"},
+	{service: "mailhog", env: "NITRO_MAILHOG_HTTP_PORT", port: "8025"},
+	{service: "meilisearch", env: "NITRO_MEILISEARCH_PORT", port: "7700"},
+	{service: "minio", env: "NITRO_MINIO_PORT", port: "9000"},
+	{service: "redis", env: "NITRO_REDIS_PORT", port: "6379"},
+}
+
+// ServicePort returns the host port for the service port environment variable
+// (e.g. NITRO_MAILHOG_HTTP_PORT), the default port is used when it is not set.
+func ServicePort(env string) string {
+	for _, s := range servicePorts {
+		if s.env == env {
+			return envOrDefault(s.env, s.port)
+		}
+	}
+
+	return ""
+}
+
+// hostPort is a port bound on the host and the entry in the config that uses it.
+type hostPort struct {
+	port    string
+	address string
+	owner   string
+}
+
+// ValidatePorts returns an error if more than one database, service, custom
+// container, or the proxy use the same port on the host. Ports on different
+// bind addresses do not conflict unless one binds to every address (e.g. 0.0.0.0).
+func (c *Config) ValidatePorts() error {
+	ports := c.hostPorts()
+
+	var conflicts []string
+	for i, a := range ports {
+		for _, b := range ports[i+1:] {
+			if a.port != "" && a.port == b.port && addressesOverlap(a.address, b.address) {
+				conflicts = append(conflicts, fmt.Sprintf("the port %s is used by %s and %s", a.port, a.owner, b.owner))
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("unable to use the same host port more than once, %s", strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// hostPorts returns the ports bound on the host for the proxy, databases,
+// enabled services, and custom containers.
+func (c *Config) hostPorts() []hostPort {
+	var ports []hostPort
+
+	// the proxy ports use the same order as proxycontainer.HostPorts
+	httpPort, httpsPort := envOrDefault("NITRO_HTTP_PORT", "80"), envOrDefault("NITRO_HTTPS_PORT", "443")
+	if c.Proxy.HTTPPort != "" {
+		httpPort = c.Proxy.HTTPPort
+	}
+	if c.Proxy.HTTPSPort != "" {
+		httpsPort = c.Proxy.HTTPSPort
+	}
+	apiPort := envOrDefault("NITRO_API_PORT", "5000")
+	if c.Proxy.APIPort != "" {
+		apiPort = c.Proxy.APIPort
+	}
+
+	ports = append(ports,
+		hostPort{port: httpPort, address: c.GetBindAddress(), owner: "the proxy"},
+		hostPort{port: httpsPort, address: c.GetBindAddress(), owner: "the proxy"},
+		hostPort{port: apiPort, address: DefaultBindAddress, owner: "the proxy api"},
+	)
+
+	for _, db := range c.Databases {
+		ports = append(ports, hostPort{port: db.Port, address: c.GetDatabaseBindAddress(db), owner: fmt.Sprintf("the %s %s database", db.Engine, db.Version)})
+
+		if db.Replica != nil {
+			ports = append(ports, hostPort{port: db.Replica.Port, address: c.GetDatabaseBindAddress(db), owner: fmt.Sprintf("the %s %s replica", db.Engine, db.Version)})
+		}
+	}
+
+	for _, s := range servicePorts {
+		if !c.Services.enabled(s.service) {
+			continue
+		}
+
+		ports = append(ports, hostPort{port: envOrDefault(s.env, s.port), address: c.GetServiceBindAddress(s.service), owner: fmt.Sprintf("the %s service", s.service)})
+	}
+
+	for _, ctr := range c.Containers {
+		for _, p := range ctr.Ports {
+			ports = append(ports, hostPort{port: strings.SplitN(p, ":", 2)[0], address: c.GetBindAddress(), owner: fmt.Sprintf("the %s container", ctr.Name)})
+		}
+	}
+
+	return ports
+}
+
+// enabled returns true if the service (e.g. redis) is enabled.
+func (s Services) enabled(name string) bool {
+	switch name {
+	case "dynamodb":
+		return s.DynamoDB
+	case "elasticsearch":
+		return s.Elasticsearch
+	case "mailhog":
+		return s.Mailhog
+	case "meilisearch":
+		return s.Meilisearch
+	case "minio":
+		return s.Minio
+	case "redis":
+		return s.Redis
+	}
+
+	return false
+}
+
+// addressesOverlap returns true if ports on the addresses conflict, which is
+// when the addresses are the same or either address is unspecified (e.g. 0.0.0.0 or ::).
+func addressesOverlap(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a == b
+	}
+
+	return ipA.IsUnspecified() || ipB.IsUnspecified() || ipA.Equal(ipB)
+}
+
+// envOrDefault returns the value of the environment variable when it is set,
+// otherwise it returns the default value.
+func envOrDefault(key, def string) string {
+	if v, defined := os.LookupEnv(key); defined {
+		return v
+	}
+
+	return def
+}
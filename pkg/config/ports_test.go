@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidatePorts(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        *Config
+		envs       map[string]string
+		wantErr    bool
+		wantOwners []string
+	}{
+		{
+			name: "databases, services, and containers on different ports are valid",
+			cfg: &Config{
+				Databases: []Database{
+					{Engine: "mysql", Version: "8.0", Port: "3306", Replica: &Replica{Port: "3307"}},
+					{Engine: "postgres", Version: "13", Port: "5432"},
+				},
+				Services:   Services{Mailhog: true, Redis: true},
+				Containers: []Container{{Name: "adminer", Image: "adminer", Ports: []string{"8080:8080"}}},
+			},
+		},
+		{
+			name: "a database on the mailhog port is not valid",
+			cfg: &Config{
+				Databases: []Database{{Engine: "mysql", Version: "8.0", Port: "8025"}},
+				Services:  Services{Mailhog: true},
+			},
+			wantErr:    true,
+			wantOwners: []string{"the mysql 8.0 database", "the mailhog service"},
+		},
+		{
+			name: "disabled services do not use a port",
+			cfg: &Config{
+				Databases: []Database{{Engine: "mysql", Version: "8.0", Port: "8025"}},
+			},
+		},
+		{
+			name: "two databases on the same port are not valid",
+			cfg: &Config{
+				Databases: []Database{
+					{Engine: "mysql", Version: "8.0", Port: "3306"},
+					{Engine: "mariadb", Version: "10.5", Port: "3306"},
+				},
+			},
+			wantErr:    true,
+			wantOwners: []string{"the mysql 8.0 database", "the mariadb 10.5 database"},
+		},
+		{
+			name: "a replica on the port of another database is not valid",
+			cfg: &Config{
+				Databases: []Database{
+					{Engine: "mysql", Version: "8.0", Port: "3306", Replica: &Replica{Port: "5432"}},
+					{Engine: "postgres", Version: "13", Port: "5432"},
+				},
+			},
+			wantErr:    true,
+			wantOwners: []string{"the mysql 8.0 replica", "the postgres 13 database"},
+		},
+		{
+			name: "a container on the proxy port is not valid",
+			cfg: &Config{
+				Proxy:      Proxy{HTTPPort: "8080"},
+				Containers: []Container{{Name: "adminer", Image: "adminer", Ports: []string{"8080:8080"}}},
+			},
+			wantErr:    true,
+			wantOwners: []string{"the proxy", "the adminer container"},
+		},
+		{
+			name: "service ports from the environment are used",
+			cfg: &Config{
+				Databases: []Database{{Engine: "postgres", Version: "13", Port: "6380"}},
+				Services:  Services{Redis: true},
+			},
+			envs:       map[string]string{"NITRO_REDIS_PORT": "6380"},
+			wantErr:    true,
+			wantOwners: []string{"the postgres 13 database", "the redis service"},
+		},
+		{
+			name: "the same port on different bind addresses is valid",
+			cfg: &Config{
+				Databases: []Database{
+					{Engine: "mysql", Version: "8.0", Port: "3306"},
+					{Engine: "mariadb", Version: "10.5", Port: "3306", BindAddress: "192.168.1.10"},
+				},
+			},
+		},
+		{
+			name: "the same port on every address and a single address is not valid",
+			cfg: &Config{
+				Databases: []Database{
+					{Engine: "mysql", Version: "8.0", Port: "3306"},
+					{Engine: "mariadb", Version: "10.5", Port: "3306", BindAddress: "0.0.0.0"},
+				},
+			},
+			wantErr:    true,
+			wantOwners: []string{"the mysql 8.0 database", "the mariadb 10.5 database"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envs {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
+
+			err := tt.cfg.ValidatePorts()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidatePorts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for _, owner := range tt.wantOwners {
+				if !strings.Contains(err.Error(), owner) {
+					t.Errorf("expected the error to name %s, got %v", owner, err)
+				}
+			}
+		})
+	}
+}
+
+func TestServicePort(t *testing.T) {
+	os.Setenv("NITRO_MINIO_PORT", "9001")
+	defer os.Unsetenv("NITRO_MINIO_PORT")
+
+	if got := ServicePort("NITRO_MINIO_PORT"); got != "9001" {
+		t.Errorf("expected the port from the environment, got %q", got)
+	}
+
+	if got := ServicePort("NITRO_REDIS_PORT"); got != "6379" {
+		t.Errorf("expected the default port, got %q", got)
+	}
+
+	if got := ServicePort("NITRO_UNKNOWN_PORT"); got != "" {
+		t.Errorf("expected no port for an unknown service, got %q", got)
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// SourceAuto uses a project config when one is found, otherwise the home config is used
+	SourceAuto = "auto"
+
+	// SourceHome always uses the config in the home directory (e.g. ~/.nitro/nitro.yaml)
+	SourceHome = "home"
+
+	// SourceProject always uses a project config (e.g. ~/dev/mysite/nitro.yaml)
+	SourceProject = "project"
+)
+
+var (
+	// Source determines where the config is loaded from and is set
+	// using the --config-source flag
+	Source = SourceAuto
+
+	// ErrNoProjectConfig is returned when the project config is required but cannot be found
+	ErrNoProjectConfig = fmt.Errorf("unable to find a %s in the current directory or any parent directory", FileName)
+)
+
+// ValidSource returns an error if the source is not auto, home, or project.
+func ValidSource(source string) error {
+	switch source {
+	case SourceAuto, SourceHome, SourceProject:
+		return nil
+	}
+
+	return fmt.Errorf("invalid config source %q, must be %s, %s, or %s", source, SourceAuto, SourceHome, SourceProject)
+}
+
+// FindProjectFile looks for a nitro.yaml in the directory and each parent
+// directory. It stops at the root of a repository (a directory with a .git
+// directory), the home directory, or the root of the file system.
+func FindProjectFile(home, dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		file := filepath.Join(dir, FileName)
+		if stat, err := os.Stat(file); err == nil && !stat.IsDir() {
+			if stat.Size() == 0 {
+				return "", ErrEmptyfile
+			}
+
+			return file, nil
+		}
+
+		// stop at the root of the repo
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+
+		// stop at the home directory since the home config lives there
+		if home != "" && dir == filepath.Clean(home) {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+
+		dir = parent
+	}
+
+	return "", ErrNoProjectConfig
+}
+
+// configFile returns the config file to load based on the source and if the
+// file is a project config.
+func configFile(home string) (string, bool, error) {
+	if Source == SourceHome {
+		file, err := IsEmpty(home)
+		return file, false, err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", false, fmt.Errorf("unable to get the current directory, %w", err)
+	}
+
+	file, err := FindProjectFile(home, wd)
+	switch {
+	case err == nil:
+		return file, true, nil
+	case err == ErrNoProjectConfig && Source == SourceAuto:
+		file, err := IsEmpty(home)
+		return file, false, err
+	}
+
+	return "", false, err
+}
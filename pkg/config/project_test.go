@@ -0,0 +1,99 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectFile(t *testing.T) {
+	// create a repo with a nested directory
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	nested := filepath.Join(repo, "web", "assets")
+
+	for _, dir := range []string{filepath.Join(repo, ".git"), nested, filepath.Join(root, "other")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a config outside of the repo should not be used
+	for _, file := range []string{filepath.Join(repo, FileName), filepath.Join(root, FileName)} {
+		if err := ioutil.WriteFile(file, []byte("sites: []\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		home    string
+		dir     string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "configs in the directory are found",
+			dir:  repo,
+			want: filepath.Join(repo, FileName),
+		},
+		{
+			name: "configs in a parent directory are found",
+			dir:  nested,
+			want: filepath.Join(repo, FileName),
+		},
+		{
+			name:    "directories stop at the home directory",
+			home:    filepath.Join(root, "other"),
+			dir:     filepath.Join(root, "other"),
+			wantErr: ErrNoProjectConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindProjectFile(tt.home, tt.dir)
+			if err != tt.wantErr {
+				t.Errorf("FindProjectFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("FindProjectFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the repo root stops the search
+	if err := os.Remove(filepath.Join(repo, FileName)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := FindProjectFile("", nested); err != ErrNoProjectConfig {
+		t.Errorf("expected the search to stop at the repo root, got %v", err)
+	}
+}
+
+func TestSite_GetAbsPath_Project(t *testing.T) {
+	s := &Site{Path: "./web", base: "/home/nitro/dev/mysite"}
+
+	got, err := s.GetAbsPath("/home/nitro")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.Join("/home/nitro/dev/mysite", "web"); got != want {
+		t.Errorf("GetAbsPath() = %v, want %v", got, want)
+	}
+}
+
+func TestValidSource(t *testing.T) {
+	for _, source := range []string{SourceAuto, SourceHome, SourceProject} {
+		if err := ValidSource(source); err != nil {
+			t.Errorf("expected %s to be valid, got %v", source, err)
+		}
+	}
+
+	if err := ValidSource("remote"); err == nil {
+		t.Error("expected an error for an invalid source")
+	}
+}
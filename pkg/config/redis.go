@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RedisEvictionPolicies are the maxmemory-policy values supported by redis.
+var RedisEvictionPolicies = []string{
+	"noeviction",
+	"allkeys-lru",
+	"allkeys-lfu",
+	"allkeys-random",
+	"volatile-lru",
+	"volatile-lfu",
+	"volatile-random",
+	"volatile-ttl",
+}
+
+// redisMemoryRegex matches the memory units redis supports (e.g. 100mb or 1gb)
+var redisMemoryRegex = regexp.MustCompile(`(?i)^[0-9]+(b|k|kb|m|mb|g|gb)?$`)
+
+// RedisConfig are the options for the redis service, they are passed to the
+// redis server as command args when the container is created.
+type RedisConfig struct {
+	// AppendOnly enables append only file persistence, the data is stored in a volume
+	AppendOnly bool `json:"appendonly,omitempty" yaml:"appendonly,omitempty"`
+
+	// Databases is the number of databases, redis defaults to 16
+	Databases int `json:"databases,omitempty" yaml:"databases,omitempty"`
+
+	// MaxMemory is the memory limit for the data (e.g. 100mb or 1gb)
+	MaxMemory string `json:"maxmemory,omitempty" yaml:"maxmemory,omitempty"`
+
+	// MaxMemoryPolicy is how keys are evicted when the memory limit is reached (e.g. allkeys-lru)
+	MaxMemoryPolicy string `json:"maxmemory_policy,omitempty" yaml:"maxmemory_policy,omitempty"`
+}
+
+// Args returns the command for the redis container, when there are no
+// options it returns nil so the image default is used.
+func (r RedisConfig) Args() []string {
+	var args []string
+
+	if r.AppendOnly {
+		args = append(args, "--appendonly", "yes")
+	}
+
+	if r.Databases > 0 {
+		args = append(args, "--databases", strconv.Itoa(r.Databases))
+	}
+
+	if r.MaxMemory != "" {
+		args = append(args, "--maxmemory", strings.ToLower(r.MaxMemory))
+	}
+
+	if r.MaxMemoryPolicy != "" {
+		args = append(args, "--maxmemory-policy", r.MaxMemoryPolicy)
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	return append([]string{"redis-server"}, args...)
+}
+
+// Validate returns an error if the max memory is not a size redis supports
+// or the eviction policy is not known.
+func (r RedisConfig) Validate() error {
+	if r.Databases < 0 {
+		return fmt.Errorf("the redis databases %d must be a positive number", r.Databases)
+	}
+
+	if r.MaxMemory != "" && !redisMemoryRegex.MatchString(r.MaxMemory) {
+		return fmt.Errorf("the redis maxmemory %q is not valid, use a number of bytes or a unit (e.g. 100mb or 1gb)", r.MaxMemory)
+	}
+
+	if r.MaxMemoryPolicy == "" {
+		return nil
+	}
+
+	for _, p := range RedisEvictionPolicies {
+		if r.MaxMemoryPolicy == p {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("the redis maxmemory_policy %q is not valid, use one of %s", r.MaxMemoryPolicy, strings.Join(RedisEvictionPolicies, ", "))
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedisConfig_Args(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RedisConfig
+		want   []string
+	}{
+		{
+			name:   "no options use the image default",
+			config: RedisConfig{},
+			want:   nil,
+		},
+		{
+			name:   "append only persistence",
+			config: RedisConfig{AppendOnly: true},
+			want:   []string{"redis-server", "--appendonly", "yes"},
+		},
+		{
+			name:   "all of the options are passed as args",
+			config: RedisConfig{AppendOnly: true, Databases: 32, MaxMemory: "1GB", MaxMemoryPolicy: "volatile-lfu"},
+			want:   []string{"redis-server", "--appendonly", "yes", "--databases", "32", "--maxmemory", "1gb", "--maxmemory-policy", "volatile-lfu"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Args(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Args() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RedisConfig
+		wantErr bool
+	}{
+		{
+			name:   "no options are valid",
+			config: RedisConfig{},
+		},
+		{
+			name:   "memory units and known policies are valid",
+			config: RedisConfig{MaxMemory: "256mb", MaxMemoryPolicy: "allkeys-lru"},
+		},
+		{
+			name:   "memory in bytes is valid",
+			config: RedisConfig{MaxMemory: "1048576"},
+		},
+		{
+			name:    "unknown memory units return an error",
+			config:  RedisConfig{MaxMemory: "256 megabytes"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown policies return an error",
+			config:  RedisConfig{MaxMemoryPolicy: "lru"},
+			wantErr: true,
+		},
+		{
+			name:    "negative databases return an error",
+			config:  RedisConfig{Databases: -1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/craftcms/nitro/pkg/helpers"
+)
+
+// SchemaFile is the name of the JSON schema file stored in the nitro directory
+const SchemaFile = "schema.json"
+
+// Schema returns a JSON schema for the config file. The schema is generated
+// from the yaml struct tags so it always matches the config.
+func Schema() ([]byte, error) {
+	schema := schemaFor(reflect.TypeOf(Config{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "Nitro config"
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// WriteSchema writes the JSON schema to the nitro directory in the home
+// directory (e.g. ~/.nitro/schema.json) and returns the path to the file.
+func WriteSchema(home string) (string, error) {
+	content, err := Schema()
+	if err != nil {
+		return "", fmt.Errorf("unable to generate the schema, %w", err)
+	}
+
+	dir := filepath.Join(home, DirectoryName)
+	if err := helpers.MkdirIfNotExists(dir); err != nil {
+		return "", err
+	}
+
+	file := filepath.Join(dir, SchemaFile)
+	if err := ioutil.WriteFile(file, content, 0644); err != nil {
+		return "", fmt.Errorf("unable to write the schema, %w", err)
+	}
+
+	return file, nil
+}
+
+// AddSchemaComment adds the yaml-language-server comment that references the
+// schema to the top of the config file, editors that use the yaml language
+// server (e.g. VS Code) will then provide completion and validation. It waits
+// for a running apply to finish before changing the file.
+func AddSchemaComment(file, schema string) error {
+	// wait for other nitro processes (e.g. apply) to release the config
+	unlock, err := lock(file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read the config, %w", err)
+	}
+
+	// the file already references a schema
+	if bytes.HasPrefix(content, []byte(schemaCommentPrefix)) {
+		return nil
+	}
+
+	comment := []byte(schemaCommentPrefix + filepath.ToSlash(schema) + "\n")
+
+	return ioutil.WriteFile(file, append(comment, content...), 0644)
+}
+
+// schemaCommentPrefix is the comment used by the yaml language server to find the schema
+const schemaCommentPrefix = "# yaml-language-server: $schema="
+
+// schemaFor returns the schema for a type, structs use the exported fields
+// with the yaml tag as the property name.
+func schemaFor(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaFor(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+
+			// skip unexported fields
+			if f.PkgPath != "" {
+				continue
+			}
+
+			name := schemaName(f)
+			if name == "-" {
+				continue
+			}
+
+			properties[name] = schemaFor(f.Type)
+		}
+
+		return map[string]interface{}{"type": "object", "properties": properties, "additionalProperties": false}
+	}
+
+	return map[string]interface{}{}
+}
+
+// schemaName returns the name of the field in the config file, which
+// is the yaml tag or the lowercase field name when there is no tag.
+func schemaName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+
+	return name
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	content, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Schema     string `json:"$schema"`
+		Type       string `json:"type"`
+		Properties map[string]struct {
+			Type  string `json:"type"`
+			Items struct {
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"items"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatalf("unable to parse the schema, %v", err)
+	}
+
+	if schema.Schema == "" || schema.Type != "object" {
+		t.Errorf("expected a json schema object, got %q %q", schema.Schema, schema.Type)
+	}
+
+	// every top level field in the config should be in the schema
+	cfg := reflect.TypeOf(Config{})
+	for i := 0; i < cfg.NumField(); i++ {
+		f := cfg.Field(i)
+		if f.PkgPath != "" || schemaName(f) == "-" {
+			continue
+		}
+
+		if _, ok := schema.Properties[schemaName(f)]; !ok {
+			t.Errorf("expected the schema to include %q", schemaName(f))
+		}
+	}
+
+	if _, ok := schema.Properties["file"]; ok {
+		t.Errorf("expected the schema to not include fields that are not in the config file")
+	}
+
+	// check the nested types
+	if _, ok := schema.Properties["sites"].Items.Properties["hostname"]; !ok {
+		t.Errorf("expected the site properties to include hostname")
+	}
+
+	if _, ok := schema.Properties["sites"].Items.Properties["base"]; ok {
+		t.Errorf("expected the site properties to not include unexported fields")
+	}
+
+	if _, ok := schema.Properties["services"].Properties["mailhog"]; !ok {
+		t.Errorf("expected the services to include mailhog")
+	}
+
+	if got := schema.Properties["databases"].Type; got != "array" {
+		t.Errorf("expected the databases to be an array, got %q", got)
+	}
+}
+
+func TestAddSchemaComment(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, FileName)
+	if err := ioutil.WriteFile(file, []byte("sites:\n  - hostname: example.nitro\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// adding the comment twice should only add it once
+	for i := 0; i < 2; i++ {
+		if err := AddSchemaComment(file, "/home/nitro/.nitro/schema.json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# yaml-language-server: $schema=/home/nitro/.nitro/schema.json\nsites:\n  - hostname: example.nitro\n"
+	if string(content) != expected {
+		t.Errorf("expected the file to be %q, got %q", expected, string(content))
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SharedVolumeServices are the services with a data volume that can be shared
+// using the shared_volumes option.
+var SharedVolumeServices = []string{"minio", "redis"}
+
+// IsShared returns true if the data volume for the service (e.g. minio) is shared.
+func (s Services) IsShared(name string) bool {
+	for _, v := range s.SharedVolumes {
+		if v == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateSharedVolumes returns an error if a shared volume is not for one of
+// the services with a data volume.
+func (s Services) ValidateSharedVolumes() error {
+	for _, v := range s.SharedVolumes {
+		found := false
+		for _, name := range SharedVolumeServices {
+			if v == name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("unable to share the volume for %q, use one of %s", v, strings.Join(SharedVolumeServices, ", "))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestServices_ValidateSharedVolumes(t *testing.T) {
+	tests := []struct {
+		name     string
+		services Services
+		wantErr  bool
+	}{
+		{
+			name: "no shared volumes are valid",
+		},
+		{
+			name:     "services with a data volume are valid",
+			services: Services{SharedVolumes: []string{"minio", "redis"}},
+		},
+		{
+			name:     "services without a data volume are not valid",
+			services: Services{SharedVolumes: []string{"mailhog"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.services.ValidateSharedVolumes(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSharedVolumes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServices_IsShared(t *testing.T) {
+	s := Services{SharedVolumes: []string{"minio"}}
+
+	if !s.IsShared("minio") {
+		t.Error("expected the minio volume to be shared")
+	}
+
+	if s.IsShared("redis") {
+		t.Error("expected the redis volume to not be shared")
+	}
+}
@@ -1,6 +1,10 @@
 package containerlabels
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/craftcms/nitro/pkg/config"
@@ -16,6 +20,18 @@ const (
 	// NitroContainerPort is used to identify a custom containers port in the config
 	NitroContainerPort = "com.craftcms.nitro.container-port"
 
+	// NitroContainerHash is the hash of a custom containers command, entrypoint, env, ports, and volumes to determine if they changed
+	NitroContainerHash = "com.craftcms.nitro.container-hash"
+
+	// Aliases is a comma separated list of the network aliases for a container (e.g. database,mysql)
+	Aliases = "com.craftcms.nitro.aliases"
+
+	// BindAddress is the host address the ports for a container are bound to when it is not the default (e.g. 0.0.0.0)
+	BindAddress = "com.craftcms.nitro.bind-address"
+
+	// CustomLabels is a comma separated list of the custom labels from the sites config
+	CustomLabels = "com.craftcms.nitro.custom-labels"
+
 	// DatabaseCompatibility is the compatibility of the database (e.g. mariadb and mysql are compatible)
 	DatabaseCompatibility = "com.craftcms.nitro.database-compatibility"
 
@@ -28,9 +44,24 @@ const (
 	// DatabaseVersion is the version of the database the container is running (e.g. 11, 12, 5.7)
 	DatabaseVersion = "com.craftcms.nitro.database-version"
 
+	// DatabasePlatform is the platform forced for a database container in the config (e.g. linux/amd64)
+	DatabasePlatform = "com.craftcms.nitro.database-platform"
+
+	// DatabaseReplication is the role of a database container with a replica, either primary or replica
+	DatabaseReplication = "com.craftcms.nitro.database-replication"
+
+	// DockerfileHash is used to store the hash of a sites Dockerfile on the built image to determine if the image should be rebuilt
+	DockerfileHash = "com.craftcms.nitro.dockerfile-hash"
+
+	// DotenvHash is used to store the hash of a sites .env file to determine if the file has changed
+	DotenvHash = "com.craftcms.nitro.dotenv-hash"
+
 	// Extensions is used for a list of comma seperated extensions for a site
 	Extensions = "com.craftcms.nitro.extensions"
 
+	// ImageDigest is used to store the digest of the image a container was created from
+	ImageDigest = "com.craftcms.nitro.image-digest"
+
 	// Host is used to identify a web application by the hostname of the site (e.g demo.nitro)
 	Host = "com.craftcms.nitro.host"
 
@@ -43,23 +74,80 @@ const (
 	// Volume is used to identify a volume for an environment
 	Volume = "com.craftcms.nitro.volume"
 
+	// SharedVolume is used to identify a volume that is shared by environments and kept when nitro is destroyed
+	SharedVolume = "com.craftcms.nitro.shared-volume"
+
 	// Proxy is the label used to identify the proxy container
 	Proxy = "com.craftcms.nitro.proxy"
 
 	// ProxyVersion is used to label a proxy container with a specific version
 	ProxyVersion = "com.craftcms.nitro.proxy-version"
 
+	// Services is used to label a site container with the services it has environment variables for
+	Services = "com.craftcms.nitro.services"
+
+	// ServiceArgs is used to label a service container with the command args from the config
+	ServiceArgs = "com.craftcms.nitro.service-args"
+
 	// Type is used to identity the type of container
 	Type = "com.craftcms.nitro.type"
 )
 
-// ForSite takes a site and returns labels to use on the sites container.
+// ErrReservedLabel is returned when a custom label uses the nitro label prefix
+var ErrReservedLabel = fmt.Errorf("labels starting with %s are reserved for nitro", Nitro)
+
+// IsReserved returns true if the label is managed by nitro (e.g. com.craftcms.nitro.host).
+func IsReserved(label string) bool {
+	return label == Nitro || strings.HasPrefix(label, Nitro+".")
+}
+
+// Validate checks the custom labels from the config and returns
+// an error if any of the labels are reserved for nitro.
+func Validate(labels map[string]string) error {
+	for k := range labels {
+		if IsReserved(k) {
+			return fmt.Errorf("unable to use the label %q, %w", k, ErrReservedLabel)
+		}
+	}
+
+	return nil
+}
+
+// Custom returns the sorted keys of the custom labels that are not reserved.
+func Custom(labels map[string]string) []string {
+	var keys []string
+	for k := range labels {
+		if IsReserved(k) {
+			continue
+		}
+
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// ForSite takes a site and returns labels to use on the sites container. Custom
+// labels from the site are merged in but are never able to replace nitro labels.
 func ForSite(s config.Site) map[string]string {
-	labels := map[string]string{
-		Nitro: "true",
-		Host:  s.Hostname,
+	labels := map[string]string{}
+
+	// add the custom labels first, the reserved labels are skipped
+	keys := Custom(s.Labels)
+	for _, k := range keys {
+		labels[k] = s.Labels[k]
+	}
+
+	// keep track of the custom labels so removing one recreates the container
+	if len(keys) > 0 {
+		labels[CustomLabels] = strings.Join(keys, ",")
 	}
 
+	labels[Nitro] = "true"
+	labels[Host] = s.Hostname
+
 	// if there are extensions, add them as comma separated
 	if len(s.Extensions) > 0 {
 		labels[Extensions] = strings.Join(s.Extensions, ",")
@@ -77,5 +165,49 @@ func ForCustomContainer(c config.Container) map[string]string {
 		NitroContainer: c.Name,
 	}
 
+	// store the hash so changes to the config recreate the container
+	if len(c.Command) > 0 || len(c.Entrypoint) > 0 || len(c.Env) > 0 || len(c.Ports) > 0 || len(c.Volumes) > 0 {
+		content, _ := json.Marshal([]interface{}{c.Command, c.Entrypoint, c.Env, c.Ports, c.Volumes})
+		labels[NitroContainerHash] = fmt.Sprintf("%x", sha256.Sum256(content))
+	}
+
+	return labels
+}
+
+// SetBindAddress adds the bind address label when the address is not the default,
+// so containers created before the address could be changed are not recreated.
+func SetBindAddress(labels map[string]string, addr string) {
+	if addr != "" && addr != config.DefaultBindAddress {
+		labels[BindAddress] = addr
+	}
+}
+
+// GetBindAddress returns the host address the ports for a container are bound to
+// using the labels, containers without the label use the default address.
+func GetBindAddress(labels map[string]string) string {
+	if addr := labels[BindAddress]; addr != "" {
+		return addr
+	}
+
+	return config.DefaultBindAddress
+}
+
+// ForVolume returns the labels for the data volume of a service, shared
+// volumes are labeled so they are kept when nitro is destroyed.
+func ForVolume(name string, shared bool) map[string]string {
+	labels := map[string]string{
+		Nitro:  "true",
+		Volume: name,
+	}
+
+	if shared {
+		labels[SharedVolume] = "true"
+	}
+
 	return labels
 }
+
+// IsSharedVolume returns true if the labels are for a shared volume.
+func IsSharedVolume(labels map[string]string) bool {
+	return labels[SharedVolume] == "true"
+}
@@ -0,0 +1,110 @@
+package containerlabels
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func TestForSite(t *testing.T) {
+	tests := []struct {
+		name string
+		site config.Site
+		want map[string]string
+	}{
+		{
+			name: "sites without custom labels only use the nitro labels",
+			site: config.Site{Hostname: "mysite.nitro"},
+			want: map[string]string{
+				Nitro: "true",
+				Host:  "mysite.nitro",
+			},
+		},
+		{
+			name: "custom labels are merged with the nitro labels",
+			site: config.Site{
+				Hostname:   "mysite.nitro",
+				Extensions: []string{"xsl"},
+				Labels: map[string]string{
+					"traefik.enable": "true",
+					"com.example.a":  "b",
+				},
+			},
+			want: map[string]string{
+				Nitro:            "true",
+				Host:             "mysite.nitro",
+				Extensions:       "xsl",
+				CustomLabels:     "com.example.a,traefik.enable",
+				"traefik.enable": "true",
+				"com.example.a":  "b",
+			},
+		},
+		{
+			name: "custom labels can not replace reserved labels",
+			site: config.Site{
+				Hostname: "mysite.nitro",
+				Labels: map[string]string{
+					Host:             "another.nitro",
+					Nitro:            "false",
+					"traefik.enable": "true",
+				},
+			},
+			want: map[string]string{
+				Nitro:            "true",
+				Host:             "mysite.nitro",
+				CustomLabels:     "traefik.enable",
+				"traefik.enable": "true",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ForSite(tt.site); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForSite() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{
+			name: "empty labels are valid",
+		},
+		{
+			name: "custom labels are valid",
+			labels: map[string]string{
+				"traefik.enable":          "true",
+				"com.craftcms.nitrobuild": "true",
+			},
+		},
+		{
+			name:    "the nitro label is reserved",
+			labels:  map[string]string{Nitro: "false"},
+			wantErr: true,
+		},
+		{
+			name:    "labels with the nitro prefix are reserved",
+			labels:  map[string]string{"com.craftcms.nitro.something": "true"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.labels)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(err, ErrReservedLabel) {
+				t.Errorf("Validate() error = %v, want %v", err, ErrReservedLabel)
+			}
+		})
+	}
+}
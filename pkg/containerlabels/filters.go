@@ -0,0 +1,84 @@
+package containerlabels
+
+import (
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+// The filter funcs return new filter args each time they are called, so
+// callers should create a filter for each query instead of changing a
+// shared filter with Add and Del.
+
+// Environment returns the filter for all of the containers in the environment.
+func Environment() filters.Args {
+	return filters.NewArgs(filters.Arg("label", Nitro+"=true"))
+}
+
+// ByHost returns the filter for the container of a site using the hostname.
+func ByHost(hostname string) filters.Args {
+	return filters.NewArgs(
+		filters.Arg("label", Nitro+"=true"),
+		filters.Arg("label", Host+"="+hostname),
+	)
+}
+
+// ByType returns the filter for the containers of a type (e.g. database, mailhog, or proxy).
+func ByType(containerType string) filters.Args {
+	return filters.NewArgs(
+		filters.Arg("label", Nitro+"=true"),
+		filters.Arg("label", Type+"="+containerType),
+	)
+}
+
+// Databases returns the filter for all of the database containers.
+func Databases() filters.Args {
+	return ByType("database")
+}
+
+// ByDatabase returns the filter for the container of a database using the
+// engine, version, and port.
+func ByDatabase(db config.Database) filters.Args {
+	return filters.NewArgs(
+		filters.Arg("label", Type+"=database"),
+		filters.Arg("label", DatabaseEngine+"="+db.Engine),
+		filters.Arg("label", DatabaseVersion+"="+db.Version),
+		filters.Arg("label", DatabasePort+"="+db.Port),
+		filters.Arg("label", DatabaseCompatibility+"="+Compatibility(db.Engine)),
+	)
+}
+
+// ByReplica returns the filter for the container of a databases replica.
+func ByReplica(db config.Database) filters.Args {
+	f := filters.NewArgs(
+		filters.Arg("label", Type+"=database"),
+		filters.Arg("label", DatabaseEngine+"="+db.Engine),
+		filters.Arg("label", DatabaseVersion+"="+db.Version),
+		filters.Arg("label", DatabaseReplication+"=replica"),
+	)
+
+	if db.Replica != nil {
+		f.Add("label", DatabasePort+"="+db.Replica.Port)
+	}
+
+	return f
+}
+
+// ByPath returns the filter for the containers and volumes of a type that
+// mount a path (e.g. composer and npm).
+func ByPath(containerType, path string) filters.Args {
+	return filters.NewArgs(
+		filters.Arg("label", Type+"="+containerType),
+		filters.Arg("label", Path+"="+path),
+	)
+}
+
+// Compatibility returns the database compatibility for the engine, mariadb and
+// mysql are mysql compatible and everything else is postgres compatible.
+func Compatibility(engine string) string {
+	if engine == "mariadb" || engine == "mysql" {
+		return "mysql"
+	}
+
+	return "postgres"
+}
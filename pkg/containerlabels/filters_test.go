@@ -0,0 +1,85 @@
+package containerlabels
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func TestFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter filters.Args
+		want   []string
+	}{
+		{
+			name:   "environment",
+			filter: Environment(),
+			want:   []string{Nitro + "=true"},
+		},
+		{
+			name:   "host",
+			filter: ByHost("mysite.nitro"),
+			want:   []string{Host + "=mysite.nitro", Nitro + "=true"},
+		},
+		{
+			name:   "databases",
+			filter: Databases(),
+			want:   []string{Type + "=database", Nitro + "=true"},
+		},
+		{
+			name:   "database",
+			filter: ByDatabase(config.Database{Engine: "mariadb", Version: "10.5", Port: "3306"}),
+			want: []string{
+				DatabaseCompatibility + "=mysql",
+				DatabaseEngine + "=mariadb",
+				DatabasePort + "=3306",
+				DatabaseVersion + "=10.5",
+				Type + "=database",
+			},
+		},
+		{
+			name:   "replica",
+			filter: ByReplica(config.Database{Engine: "postgres", Version: "13", Port: "5432", Replica: &config.Replica{Port: "5433"}}),
+			want: []string{
+				DatabaseEngine + "=postgres",
+				DatabasePort + "=5433",
+				DatabaseReplication + "=replica",
+				DatabaseVersion + "=13",
+				Type + "=database",
+			},
+		},
+		{
+			name:   "path",
+			filter: ByPath("npm", "/home/nitro/site"),
+			want:   []string{Path + "=/home/nitro/site", Type + "=npm"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.filter.Get("label")
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected the labels to match, got %v, want %v", got, tt.want)
+			}
+
+			if keys := tt.filter.Keys(); len(keys) != 1 {
+				t.Errorf("expected only label filters, got %v", keys)
+			}
+		})
+	}
+}
+
+func TestFilters_AreNotShared(t *testing.T) {
+	first := Environment()
+	first.Add("reference", "craftcms/nitro-proxy")
+
+	if second := Environment(); second.Contains("reference") {
+		t.Errorf("expected a new filter for each call, got %v", second.Get("reference"))
+	}
+}
@@ -31,6 +31,10 @@ type ImportOptions struct {
 	Port            string
 	DatabaseName    string
 	File            string
+
+	// Charset and Collation are used to create and import mysql databases, they are ignored for postgres
+	Charset   string
+	Collation string
 }
 
 type importer struct{}
@@ -65,16 +69,7 @@ func (importer *importer) Import(opts *ImportOptions, find func(engine, version
 	}
 
 	// generate the commands to execute
-	var createCommand, importCommand []string
-	switch opts.Engine {
-	case "postgres":
-		createCommand = []string{fmt.Sprintf("--host=%s", opts.Hostname), "--port=" + opts.Port, "--username=nitro", fmt.Sprintf(`-c CREATE DATABASE %s;`, opts.DatabaseName)}
-		importCommand = []string{fmt.Sprintf("--host=%s", opts.Hostname), "--port=" + opts.Port, "--username=nitro", opts.DatabaseName, "--file=" + opts.File}
-	default:
-		createCommand = []string{"--user=nitro", fmt.Sprintf("--host=%s", opts.Hostname), "-pnitro", fmt.Sprintf(`-e CREATE DATABASE IF NOT EXISTS %s;`, opts.DatabaseName)}
-		// https://dev.mysql.com/doc/refman/8.0/en/mysql-command-options.html
-		importCommand = []string{"--user=nitro", fmt.Sprintf("--host=%s", opts.Hostname), "-pnitro", opts.DatabaseName, fmt.Sprintf(`-e source %s`, opts.File)}
-	}
+	createCommand, importCommand := commands(opts)
 
 	// if there is a create command, lets create the database
 	if createCommand != nil {
@@ -92,6 +87,42 @@ func (importer *importer) Import(opts *ImportOptions, find func(engine, version
 	return nil
 }
 
+// commands returns the arguments for the import tool to create the database
+// and to import the backup into the database.
+func commands(opts *ImportOptions) (createCommand []string, importCommand []string) {
+	switch opts.Engine {
+	case "postgres":
+		createCommand = []string{fmt.Sprintf("--host=%s", opts.Hostname), "--port=" + opts.Port, "--username=nitro", fmt.Sprintf(`-c CREATE DATABASE %s;`, opts.DatabaseName)}
+		importCommand = []string{fmt.Sprintf("--host=%s", opts.Hostname), "--port=" + opts.Port, "--username=nitro", opts.DatabaseName, "--file=" + opts.File}
+	default:
+		createCommand = []string{"--user=nitro", fmt.Sprintf("--host=%s", opts.Hostname), "-pnitro", "-e " + MySQLCreateStatement(opts.DatabaseName, opts.Charset, opts.Collation)}
+		// https://dev.mysql.com/doc/refman/8.0/en/mysql-command-options.html
+		importCommand = []string{"--user=nitro", fmt.Sprintf("--host=%s", opts.Hostname), "-pnitro"}
+		if opts.Charset != "" {
+			importCommand = append(importCommand, "--default-character-set="+opts.Charset)
+		}
+		importCommand = append(importCommand, opts.DatabaseName, fmt.Sprintf(`-e source %s`, opts.File))
+	}
+
+	return createCommand, importCommand
+}
+
+// MySQLCreateStatement returns the statement to create a mysql database, the
+// charset and collation are only added when they are set.
+func MySQLCreateStatement(db, charset, collation string) string {
+	stmt := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", db)
+
+	if charset != "" {
+		stmt += " CHARACTER SET " + charset
+	}
+
+	if collation != "" {
+		stmt += " COLLATE " + collation
+	}
+
+	return stmt + ";"
+}
+
 func (importer *importer) exec(tool string, commands []string) error {
 	c := exec.Command(tool, commands...)
 
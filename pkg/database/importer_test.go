@@ -2,6 +2,7 @@ package database
 
 import (
 	"os/exec"
+	"reflect"
 	"testing"
 )
 
@@ -93,3 +94,64 @@ func TestDefaultImportToolFinder(t *testing.T) {
 		})
 	}
 }
+
+func Test_commands(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       ImportOptions
+		wantCreate []string
+		wantImport []string
+	}{
+		{
+			name: "mysql without a charset uses the server defaults",
+			opts: ImportOptions{
+				Engine:       "mysql",
+				Hostname:     "mysql-8.0-3306.database.nitro",
+				Port:         "3306",
+				DatabaseName: "nitro",
+				File:         "/tmp/backup.sql",
+			},
+			wantCreate: []string{"--user=nitro", "--host=mysql-8.0-3306.database.nitro", "-pnitro", "-e CREATE DATABASE IF NOT EXISTS nitro;"},
+			wantImport: []string{"--user=nitro", "--host=mysql-8.0-3306.database.nitro", "-pnitro", "nitro", "-e source /tmp/backup.sql"},
+		},
+		{
+			name: "mysql with a charset and collation creates and imports using them",
+			opts: ImportOptions{
+				Engine:       "mysql",
+				Hostname:     "mysql-8.0-3306.database.nitro",
+				Port:         "3306",
+				DatabaseName: "nitro",
+				File:         "/tmp/backup.sql",
+				Charset:      "utf8mb4",
+				Collation:    "utf8mb4_unicode_ci",
+			},
+			wantCreate: []string{"--user=nitro", "--host=mysql-8.0-3306.database.nitro", "-pnitro", "-e CREATE DATABASE IF NOT EXISTS nitro CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;"},
+			wantImport: []string{"--user=nitro", "--host=mysql-8.0-3306.database.nitro", "-pnitro", "--default-character-set=utf8mb4", "nitro", "-e source /tmp/backup.sql"},
+		},
+		{
+			name: "postgres ignores the charset and collation",
+			opts: ImportOptions{
+				Engine:       "postgres",
+				Hostname:     "postgres-13-5432.database.nitro",
+				Port:         "5432",
+				DatabaseName: "nitro",
+				File:         "/tmp/backup.sql",
+				Charset:      "utf8mb4",
+				Collation:    "utf8mb4_unicode_ci",
+			},
+			wantCreate: []string{"--host=postgres-13-5432.database.nitro", "--port=5432", "--username=nitro", "-c CREATE DATABASE nitro;"},
+			wantImport: []string{"--host=postgres-13-5432.database.nitro", "--port=5432", "--username=nitro", "nitro", "--file=/tmp/backup.sql"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCreate, gotImport := commands(&tt.opts)
+			if !reflect.DeepEqual(gotCreate, tt.wantCreate) {
+				t.Errorf("commands() create = %v, want %v", gotCreate, tt.wantCreate)
+			}
+			if !reflect.DeepEqual(gotImport, tt.wantImport) {
+				t.Errorf("commands() import = %v, want %v", gotImport, tt.wantImport)
+			}
+		})
+	}
+}
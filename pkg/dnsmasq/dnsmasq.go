@@ -0,0 +1,44 @@
+package dnsmasq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TLD is the top level domain used for sites, services, and containers
+const TLD = "nitro"
+
+// Config takes a top level domain and the address and returns a dnsmasq
+// config fragment that resolves every hostname for the domain to the address.
+func Config(tld, addr string) string {
+	tld = strings.Trim(tld, ".")
+
+	return fmt.Sprintf("# generated by nitro, resolves all .%s hostnames to the nitro proxy\naddress=/.%s/%s\n", tld, tld, addr)
+}
+
+// Steps takes the operating system, the path to the dnsmasq config fragment,
+// and the top level domain and returns the steps to configure dnsmasq and the
+// system resolver.
+func Steps(goos, file, tld string) []string {
+	tld = strings.Trim(tld, ".")
+
+	switch goos {
+	case "darwin":
+		return []string{
+			"brew install dnsmasq",
+			fmt.Sprintf(`echo "conf-file=%s" >> $(brew --prefix)/etc/dnsmasq.conf`, file),
+			"sudo brew services restart dnsmasq",
+			"sudo mkdir -p /etc/resolver",
+			fmt.Sprintf(`echo "nameserver 127.0.0.1" | sudo tee /etc/resolver/%s`, tld),
+		}
+	case "linux":
+		return []string{
+			"sudo apt-get install dnsmasq (or use your distributions package manager)",
+			fmt.Sprintf("sudo cp %s /etc/dnsmasq.d/%s.conf", file, tld),
+			"sudo systemctl restart dnsmasq",
+			fmt.Sprintf(`if using systemd-resolved, add "DNS=127.0.0.1" and "Domains=~%s" to /etc/systemd/resolved.conf and run "sudo systemctl restart systemd-resolved"`, tld),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package dnsmasq
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		tld  string
+		addr string
+		want string
+	}{
+		{
+			name: "top level domains resolve to the address",
+			tld:  "nitro",
+			addr: "127.0.0.1",
+			want: "address=/.nitro/127.0.0.1\n",
+		},
+		{
+			name: "leading dots are removed from the top level domain",
+			tld:  ".nitro",
+			addr: "127.0.0.1",
+			want: "address=/.nitro/127.0.0.1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Config(tt.tld, tt.addr); !strings.HasSuffix(got, tt.want) {
+				t.Errorf("Config() = %v, want suffix %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		contains string
+	}{
+		{
+			name:     "macOS uses the resolver directory",
+			goos:     "darwin",
+			contains: "/etc/resolver/nitro",
+		},
+		{
+			name:     "linux copies the config to dnsmasq.d",
+			goos:     "linux",
+			contains: "/etc/dnsmasq.d/nitro.conf",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(Steps(tt.goos, "/home/nitro/.nitro/dnsmasq.conf", "nitro"), "\n")
+			if !strings.Contains(got, tt.contains) {
+				t.Errorf("Steps() = %v, want to contain %v", got, tt.contains)
+			}
+		})
+	}
+
+	if got := Steps("windows", "dnsmasq.conf", "nitro"); got != nil {
+		t.Errorf("Steps() = %v, want nil for unsupported systems", got)
+	}
+}
@@ -0,0 +1,177 @@
+package dockerclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"github.com/mitchellh/go-homedir"
+)
+
+// ErrNotRunning is returned when the docker daemon does not respond
+var ErrNotRunning = fmt.Errorf("Couldn’t connect to Docker; please make sure Docker is running.")
+
+// contextMeta is the metadata the docker cli stores for each context
+// in ~/.docker/contexts/meta/<sha256 of name>/meta.json
+type contextMeta struct {
+	Name      string `json:"Name"`
+	Endpoints struct {
+		Docker struct {
+			Host          string `json:"Host"`
+			SkipTLSVerify bool   `json:"SkipTLSVerify"`
+		} `json:"docker"`
+	} `json:"Endpoints"`
+}
+
+// New returns a docker client that respects the same environment variables as
+// the docker cli (DOCKER_HOST, DOCKER_TLS_VERIFY, DOCKER_CERT_PATH,
+// DOCKER_API_VERSION, and DOCKER_CONTEXT) as well as the current context
+// from the docker cli config.
+func New() (*client.Client, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := Options(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NewClientWithOpts(opts...)
+}
+
+// Ping checks that the docker daemon is running and returns
+// ErrNotRunning when it is not.
+func Ping(ctx context.Context, docker client.SystemAPIClient) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if _, err := docker.Ping(ctx); err != nil {
+		return ErrNotRunning
+	}
+
+	return nil
+}
+
+// ConfigDir returns the docker cli config directory, which defaults
+// to ~/.docker and can be changed using DOCKER_CONFIG.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir, nil
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get the home directory, %w", err)
+	}
+
+	return filepath.Join(home, ".docker"), nil
+}
+
+// Options takes the docker cli config directory and returns the options used
+// to create a docker client. DOCKER_HOST always takes priority over a context,
+// which matches the behavior of the docker cli.
+func Options(dir string) ([]client.Opt, error) {
+	opts := []client.Opt{client.FromEnv}
+
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		name, err := contextName(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		// the default context uses the environment
+		if name != "" && name != "default" {
+			ctxOpts, err := contextOptions(dir, name)
+			if err != nil {
+				return nil, err
+			}
+
+			opts = append(opts, ctxOpts...)
+
+			return opts, nil
+		}
+	}
+
+	// ssh hosts need to dial using the ssh command
+	if strings.HasPrefix(host, "ssh://") {
+		opts = append(opts, sshOptions(host)...)
+	}
+
+	return opts, nil
+}
+
+// contextName returns the name of the context to use from DOCKER_CONTEXT
+// or the current context in the docker cli config file.
+func contextName(dir string) (string, error) {
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name, nil
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read the docker config, %w", err)
+	}
+
+	var cfg struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return "", fmt.Errorf("unable to parse the docker config, %w", err)
+	}
+
+	return cfg.CurrentContext, nil
+}
+
+// contextOptions reads the context metadata and TLS files and returns the
+// options to connect to the contexts docker endpoint.
+func contextOptions(dir, name string) ([]client.Opt, error) {
+	sum := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(sum[:])
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "contexts", "meta", id, "meta.json"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the docker context %q, %w", name, err)
+	}
+
+	meta := contextMeta{}
+	if err := json.Unmarshal(content, &meta); err != nil {
+		return nil, fmt.Errorf("unable to parse the docker context %q, %w", name, err)
+	}
+
+	host := meta.Endpoints.Docker.Host
+	if host == "" {
+		return nil, fmt.Errorf("the docker context %q does not have a docker endpoint", name)
+	}
+
+	if strings.HasPrefix(host, "ssh://") {
+		return sshOptions(host), nil
+	}
+
+	opts := []client.Opt{client.WithHost(host)}
+
+	// use the contexts certificates when they exist
+	tls := filepath.Join(dir, "contexts", "tls", id, "docker")
+	if _, err := os.Stat(filepath.Join(tls, "cert.pem")); err == nil && !meta.Endpoints.Docker.SkipTLSVerify {
+		opts = append(opts, client.WithTLSClientConfig(
+			filepath.Join(tls, "ca.pem"),
+			filepath.Join(tls, "cert.pem"),
+			filepath.Join(tls, "key.pem"),
+		))
+	}
+
+	return opts, nil
+}
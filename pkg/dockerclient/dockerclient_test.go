@@ -0,0 +1,147 @@
+package dockerclient
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/client"
+)
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		dir     string
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "defaults to the local docker host",
+			dir:  "testdata/missing",
+			want: client.DefaultDockerHost,
+		},
+		{
+			name: "docker host is respected",
+			dir:  "testdata",
+			env:  map[string]string{"DOCKER_HOST": "tcp://10.0.0.5:2376"},
+			want: "tcp://10.0.0.5:2376",
+		},
+		{
+			name: "docker context is respected",
+			dir:  "testdata",
+			env:  map[string]string{"DOCKER_CONTEXT": "remote"},
+			want: "tcp://192.168.64.2:2375",
+		},
+		{
+			name: "docker host takes priority over the context",
+			dir:  "testdata",
+			env:  map[string]string{"DOCKER_HOST": "tcp://10.0.0.5:2376", "DOCKER_CONTEXT": "remote"},
+			want: "tcp://10.0.0.5:2376",
+		},
+		{
+			name: "current context from the config is respected",
+			dir:  "testdata/current",
+			want: "tcp://192.168.64.2:2375",
+		},
+		{
+			name: "default context uses the local docker host",
+			dir:  "testdata/current",
+			env:  map[string]string{"DOCKER_CONTEXT": "default"},
+			want: client.DefaultDockerHost,
+		},
+		{
+			name:    "unknown contexts return an error",
+			dir:     "testdata",
+			env:     map[string]string{"DOCKER_CONTEXT": "unknown"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setenv(t, tt.env)()
+
+			opts, err := Options(tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Options() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			docker, err := client.NewClientWithOpts(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := docker.DaemonHost(); got != tt.want {
+				t.Errorf("DaemonHost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sshArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "hosts with users and ports are parsed",
+			host: "ssh://nitro@192.168.64.2:2222",
+			want: []string{"-p", "2222", "--", "nitro@192.168.64.2", "docker", "system", "dial-stdio"},
+		},
+		{
+			name: "hosts without users are parsed",
+			host: "ssh://docker.local",
+			want: []string{"--", "docker.local", "docker", "system", "dial-stdio"},
+		},
+		{
+			name:    "hosts without a hostname return an error",
+			host:    "ssh://",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sshArgs(tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sshArgs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sshArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// setenv clears the docker environment variables, sets the provided
+// variables, and returns a func to restore the original values.
+func setenv(t *testing.T, env map[string]string) func() {
+	original := make(map[string]string)
+	for _, k := range []string{"DOCKER_HOST", "DOCKER_CONTEXT", "DOCKER_TLS_VERIFY", "DOCKER_CERT_PATH"} {
+		original[k] = os.Getenv(k)
+		os.Unsetenv(k)
+	}
+
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return func() {
+		for k, v := range original {
+			if v == "" {
+				os.Unsetenv(k)
+				continue
+			}
+
+			os.Setenv(k, v)
+		}
+	}
+}
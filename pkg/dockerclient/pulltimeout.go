@@ -0,0 +1,80 @@
+package dockerclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// DefaultPullTimeout is how long an image pull can take before it is cancelled.
+var DefaultPullTimeout = 10 * time.Minute
+
+// PullTimeout wraps a docker client and cancels each image pull that takes
+// longer than the timeout, so a pull on a flaky network can not hang apply.
+type PullTimeout struct {
+	client.CommonAPIClient
+
+	timeout time.Duration
+}
+
+// NewPullTimeout returns a client that cancels image pulls after the timeout.
+func NewPullTimeout(docker client.CommonAPIClient, timeout time.Duration) *PullTimeout {
+	return &PullTimeout{CommonAPIClient: docker, timeout: timeout}
+}
+
+// ImagePull pulls the image with a deadline. The pull continues while the
+// returned reader is read, so the deadline is kept until the reader is closed
+// or has been read to the end.
+func (p *PullTimeout) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+
+	rdr, err := p.CommonAPIClient.ImagePull(ctx, ref, options)
+	if err != nil {
+		cancel()
+
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, p.timeoutError(ref)
+		}
+
+		return nil, err
+	}
+
+	return &pullReader{ReadCloser: rdr, ctx: ctx, cancel: cancel, err: p.timeoutError(ref)}, nil
+}
+
+// timeoutError returns the error for an image that was not pulled in time.
+func (p *PullTimeout) timeoutError(ref string) error {
+	return fmt.Errorf("unable to pull the image %s within %s, check the network connection or increase --pull-timeout", ref, p.timeout)
+}
+
+// pullReader replaces the error from reading a pull that reached the deadline
+// with an error that names the image.
+type pullReader struct {
+	io.ReadCloser
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	err    error
+}
+
+func (r *pullReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	switch {
+	case err == io.EOF:
+		r.cancel()
+	case err != nil && r.ctx.Err() == context.DeadlineExceeded:
+		return n, r.err
+	}
+
+	return n, err
+}
+
+func (r *pullReader) Close() error {
+	r.cancel()
+
+	return r.ReadCloser.Close()
+}
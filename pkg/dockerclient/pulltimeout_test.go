@@ -0,0 +1,113 @@
+package dockerclient
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// blockingClient is a docker client with a pull that blocks until the context
+// is cancelled, either before returning (e.g. resolving the registry) or while
+// the pull output is read (e.g. downloading a layer).
+type blockingClient struct {
+	client.CommonAPIClient
+
+	blockBeforeReturn bool
+}
+
+func (c *blockingClient) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	if c.blockBeforeReturn {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	return ioutil.NopCloser(&blockingReader{ctx: ctx}), nil
+}
+
+type blockingReader struct {
+	ctx context.Context
+}
+
+func (r *blockingReader) Read(b []byte) (int, error) {
+	<-r.ctx.Done()
+	return 0, r.ctx.Err()
+}
+
+func TestPullTimeout(t *testing.T) {
+	tests := []struct {
+		name              string
+		blockBeforeReturn bool
+	}{
+		{
+			name:              "pulls that do not respond are cancelled",
+			blockBeforeReturn: true,
+		},
+		{
+			name: "pulls that stop sending progress are cancelled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			docker := NewPullTimeout(&blockingClient{blockBeforeReturn: tt.blockBeforeReturn}, 50*time.Millisecond)
+
+			done := make(chan error)
+
+			// Act
+			go func() {
+				rdr, err := docker.ImagePull(context.Background(), "docker.io/craftcms/nginx:7.4-dev", types.ImagePullOptions{})
+				if err != nil {
+					done <- err
+					return
+				}
+				defer rdr.Close()
+
+				_, err = ioutil.ReadAll(rdr)
+				done <- err
+			}()
+
+			// Assert
+			select {
+			case err := <-done:
+				if err == nil || !strings.Contains(err.Error(), "docker.io/craftcms/nginx:7.4-dev") || !strings.Contains(err.Error(), "--pull-timeout") {
+					t.Errorf("expected a timeout error with the image, got %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected the pull to be cancelled")
+			}
+		})
+	}
+}
+
+func TestPullTimeout_Completed(t *testing.T) {
+	docker := NewPullTimeout(&pullClient{}, time.Minute)
+
+	rdr, err := docker.ImagePull(context.Background(), "docker.io/library/redis:latest", types.ImagePullOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdr.Close()
+
+	content, err := ioutil.ReadAll(rdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != `{"status":"Downloaded newer image"}` {
+		t.Errorf("expected the pull output to be returned, got %q", content)
+	}
+}
+
+type pullClient struct {
+	client.CommonAPIClient
+}
+
+func (c *pullClient) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(`{"status":"Downloaded newer image"}`)), nil
+}
@@ -0,0 +1,74 @@
+package dockerclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// removeRetryDelay is how long to wait before retrying to stop or remove a container
+var removeRetryDelay = time.Second
+
+// RemoveContainer stops the container when it is not stopped and removes it. Stopping
+// and removing are retried once (e.g. when docker is still stopping the container),
+// and a container that is already gone is treated as removed. A nil timeout uses the
+// default timeout of the docker daemon.
+func RemoveContainer(ctx context.Context, docker client.ContainerAPIClient, c types.Container, timeout *time.Duration, opts types.ContainerRemoveOptions) error {
+	name := c.ID
+	if len(c.Names) > 0 {
+		name = strings.TrimLeft(c.Names[0], "/")
+	}
+
+	if !stopped(c.State) {
+		err := retryOnce(ctx, func() error {
+			return docker.ContainerStop(ctx, c.ID, timeout)
+		})
+		switch {
+		case errdefs.IsNotFound(err):
+			return nil
+		case err != nil:
+			return fmt.Errorf("unable to stop the container %s, %w", name, err)
+		}
+	}
+
+	err := retryOnce(ctx, func() error {
+		return docker.ContainerRemove(ctx, c.ID, opts)
+	})
+	if err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("unable to remove the container %s, %w", name, err)
+	}
+
+	return nil
+}
+
+// stopped returns true if the container state does not need to be stopped before removing it.
+func stopped(state string) bool {
+	switch state {
+	case "created", "exited", "dead":
+		return true
+	}
+
+	return false
+}
+
+// retryOnce calls fn and calls it again after the retry delay if it returns an
+// error, unless the container is gone or the context is done.
+func retryOnce(ctx context.Context, fn func() error) error {
+	err := fn()
+	if err == nil || errdefs.IsNotFound(err) {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return err
+	case <-time.After(removeRetryDelay):
+	}
+
+	return fn()
+}
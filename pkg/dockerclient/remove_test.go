@@ -0,0 +1,121 @@
+package dockerclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+// flakyClient returns an error for the first calls to stop and remove a container.
+type flakyClient struct {
+	client.ContainerAPIClient
+
+	failures      int
+	stops, remove int
+}
+
+func (c *flakyClient) ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error {
+	c.stops++
+
+	if c.stops <= c.failures {
+		return errors.New("container is restarting")
+	}
+
+	return nil
+}
+
+func (c *flakyClient) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+	c.remove++
+
+	if c.remove <= c.failures {
+		return errors.New("removal of container is already in progress")
+	}
+
+	return nil
+}
+
+func TestRemoveContainer(t *testing.T) {
+	defer func(d time.Duration) { removeRetryDelay = d }(removeRetryDelay)
+	removeRetryDelay = 0
+
+	tests := []struct {
+		name        string
+		failures    int
+		state       string
+		wantStops   int
+		wantRemoves int
+		wantErr     bool
+	}{
+		{
+			name:        "running containers are stopped and removed",
+			state:       "running",
+			wantStops:   1,
+			wantRemoves: 1,
+		},
+		{
+			name:        "exited containers are only removed",
+			state:       "exited",
+			wantRemoves: 1,
+		},
+		{
+			name:        "stopping and removing are retried once",
+			failures:    1,
+			state:       "running",
+			wantStops:   2,
+			wantRemoves: 2,
+		},
+		{
+			name:        "errors are returned after retrying",
+			failures:    2,
+			state:       "exited",
+			wantRemoves: 2,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			docker := &flakyClient{failures: tt.failures}
+
+			// Act
+			err := RemoveContainer(context.Background(), docker, types.Container{ID: "mailhog", Names: []string{"/mailhog.service.nitro"}, State: tt.state}, nil, types.ContainerRemoveOptions{})
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RemoveContainer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if docker.stops != tt.wantStops || docker.remove != tt.wantRemoves {
+				t.Errorf("expected %d stops and %d removes, got %d and %d", tt.wantStops, tt.wantRemoves, docker.stops, docker.remove)
+			}
+		})
+	}
+}
+
+func TestRemoveContainer_AlreadyRemoved(t *testing.T) {
+	for _, state := range []string{"running", "exited"} {
+		t.Run(state, func(t *testing.T) {
+			// Arrange
+			docker := dockertest.New(nil, nil)
+
+			// Act
+			err := RemoveContainer(context.Background(), docker, types.Container{ID: "gone", State: state}, nil, types.ContainerRemoveOptions{})
+
+			// Assert
+			if err != nil {
+				t.Errorf("expected a container that is already gone to be removed, got %v", err)
+			}
+
+			// the container is not retried once it is gone
+			if calls := len(docker.Calls("ContainerStop")) + len(docker.Calls("ContainerRemove")); calls != 1 {
+				t.Errorf("expected a single call for a container that is gone, got %d", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package dockerclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// sshOptions returns the options to connect to a docker daemon over ssh
+// (e.g. ssh://user@host). This uses the ssh command to run "docker system
+// dial-stdio" on the remote host, which is the same as the docker cli.
+func sshOptions(host string) []client.Opt {
+	return []client.Opt{
+		// the host is not used to connect, but is required to build the request urls
+		client.WithHost("http://docker.example.com"),
+		client.WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			args, err := sshArgs(host)
+			if err != nil {
+				return nil, err
+			}
+
+			return dialCommand(exec.Command("ssh", args...))
+		}),
+	}
+}
+
+// sshArgs takes an ssh host url and returns the arguments for the ssh command.
+func sshArgs(host string) ([]string, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the docker host %q, %w", host, err)
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("the docker host %q is missing a hostname", host)
+	}
+
+	var args []string
+	if u.Port() != "" {
+		args = append(args, "-p", u.Port())
+	}
+
+	dest := u.Hostname()
+	if u.User != nil {
+		dest = u.User.Username() + "@" + dest
+	}
+
+	return append(args, "--", dest, "docker", "system", "dial-stdio"), nil
+}
+
+// commandConn is a net.Conn that reads and writes to a commands stdout and stdin.
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+func dialCommand(cmd *exec.Cmd) (net.Conn, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("unable to start %s, %w", cmd.Path, err)
+	}
+
+	return &commandConn{cmd: cmd, stdin: stdin, stdout: stdout}, nil
+}
+
+func (c *commandConn) Read(p []byte) (int, error) {
+	return c.stdout.Read(p)
+}
+
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+func (c *commandConn) Close() error {
+	c.stdin.Close()
+	c.stdout.Close()
+
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+
+	// the command was killed, so ignore the exit error
+	c.cmd.Wait()
+
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr {
+	return dummyAddr{}
+}
+
+func (c *commandConn) RemoteAddr() net.Addr {
+	return dummyAddr{}
+}
+
+func (c *commandConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *commandConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *commandConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+type dummyAddr struct{}
+
+func (dummyAddr) Network() string {
+	return "dummy"
+}
+
+func (dummyAddr) String() string {
+	return "dummy"
+}
@@ -0,0 +1,213 @@
+package dockerclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Verbose wraps a docker client and writes each API call used to apply
+// changes with its parameters and the raw error from the daemon. It is
+// used by `nitro apply --verbose` to diagnose failures from a single run.
+type Verbose struct {
+	client.CommonAPIClient
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewVerbose returns a client that logs the API calls to w.
+func NewVerbose(docker client.CommonAPIClient, w io.Writer) *Verbose {
+	return &Verbose{CommonAPIClient: docker, w: w}
+}
+
+// log writes the method and its parameters, the error is written
+// as returned from the daemon so the context is not lost when the
+// error is wrapped.
+func (v *Verbose) log(method string, start time.Time, err error, params ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	line := fmt.Sprintf("docker %s %s (%s)", method, strings.Join(params, " "), time.Since(start).Round(time.Millisecond))
+	if err != nil {
+		line += fmt.Sprintf(" error: %v", err)
+	}
+
+	fmt.Fprintln(v.w, line)
+}
+
+// formatFilters returns the filters as sorted key=value pairs.
+func formatFilters(f filters.Args) string {
+	var pairs []string
+	for _, k := range f.Keys() {
+		for _, v := range f.Get(k) {
+			pairs = append(pairs, k+"="+v)
+		}
+	}
+
+	sort.Strings(pairs)
+
+	return "filters=[" + strings.Join(pairs, " ") + "]"
+}
+
+// ContainerList logs the filters and the number of containers found.
+func (v *Verbose) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	start := time.Now()
+	containers, err := v.CommonAPIClient.ContainerList(ctx, options)
+	v.log("ContainerList", start, err, formatFilters(options.Filters), fmt.Sprintf("found=%d", len(containers)))
+
+	return containers, err
+}
+
+// ContainerCreate logs the name, image, and platform of the new container.
+func (v *Verbose) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+	params := []string{"name=" + containerName}
+	if config != nil {
+		params = append(params, "image="+config.Image)
+	}
+
+	if platform != nil {
+		params = append(params, "platform="+platform.OS+"/"+platform.Architecture)
+	}
+
+	start := time.Now()
+	resp, err := v.CommonAPIClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, containerName)
+	v.log("ContainerCreate", start, err, append(params, "id="+resp.ID)...)
+
+	return resp, err
+}
+
+// ContainerStart logs the container being started.
+func (v *Verbose) ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error {
+	start := time.Now()
+	err := v.CommonAPIClient.ContainerStart(ctx, containerID, options)
+	v.log("ContainerStart", start, err, "id="+containerID)
+
+	return err
+}
+
+// ContainerStop logs the container being stopped.
+func (v *Verbose) ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error {
+	start := time.Now()
+	err := v.CommonAPIClient.ContainerStop(ctx, containerID, timeout)
+	v.log("ContainerStop", start, err, "id="+containerID)
+
+	return err
+}
+
+// ContainerRemove logs the container being removed.
+func (v *Verbose) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+	start := time.Now()
+	err := v.CommonAPIClient.ContainerRemove(ctx, containerID, options)
+	v.log("ContainerRemove", start, err, "id="+containerID)
+
+	return err
+}
+
+// ContainerInspect logs the name and image of the inspected container.
+func (v *Verbose) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	start := time.Now()
+	details, err := v.CommonAPIClient.ContainerInspect(ctx, containerID)
+
+	params := []string{"id=" + containerID}
+	if details.ContainerJSONBase != nil {
+		params = append(params, "name="+strings.TrimLeft(details.Name, "/"), "image="+details.Image)
+	}
+
+	v.log("ContainerInspect", start, err, params...)
+
+	return details, err
+}
+
+// ContainerUpdate logs the restart policy set for the container.
+func (v *Verbose) ContainerUpdate(ctx context.Context, containerID string, updateConfig container.UpdateConfig) (container.ContainerUpdateOKBody, error) {
+	start := time.Now()
+	resp, err := v.CommonAPIClient.ContainerUpdate(ctx, containerID, updateConfig)
+	v.log("ContainerUpdate", start, err, "id="+containerID, "restart="+string(updateConfig.RestartPolicy.Name))
+
+	return resp, err
+}
+
+// ContainerExecCreate logs the command run in the container.
+func (v *Verbose) ContainerExecCreate(ctx context.Context, containerID string, config types.ExecConfig) (types.IDResponse, error) {
+	start := time.Now()
+	resp, err := v.CommonAPIClient.ContainerExecCreate(ctx, containerID, config)
+	v.log("ContainerExecCreate", start, err, "id="+containerID, fmt.Sprintf("cmd=%q", strings.Join(config.Cmd, " ")))
+
+	return resp, err
+}
+
+// ImageList logs the filters and the number of images found.
+func (v *Verbose) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	start := time.Now()
+	images, err := v.CommonAPIClient.ImageList(ctx, options)
+	v.log("ImageList", start, err, formatFilters(options.Filters), fmt.Sprintf("found=%d", len(images)))
+
+	return images, err
+}
+
+// ImageInspectWithRaw logs the image being inspected.
+func (v *Verbose) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	start := time.Now()
+	info, raw, err := v.CommonAPIClient.ImageInspectWithRaw(ctx, imageID)
+	v.log("ImageInspectWithRaw", start, err, "image="+imageID)
+
+	return info, raw, err
+}
+
+// ImagePull logs the image and platform being pulled.
+func (v *Verbose) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	start := time.Now()
+	rdr, err := v.CommonAPIClient.ImagePull(ctx, ref, options)
+	v.log("ImagePull", start, err, "image="+ref, "platform="+options.Platform)
+
+	return rdr, err
+}
+
+// NetworkList logs the filters and the number of networks found.
+func (v *Verbose) NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	start := time.Now()
+	networks, err := v.CommonAPIClient.NetworkList(ctx, options)
+	v.log("NetworkList", start, err, formatFilters(options.Filters), fmt.Sprintf("found=%d", len(networks)))
+
+	return networks, err
+}
+
+// NetworkCreate logs the name of the new network.
+func (v *Verbose) NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	start := time.Now()
+	resp, err := v.CommonAPIClient.NetworkCreate(ctx, name, options)
+	v.log("NetworkCreate", start, err, "name="+name, "id="+resp.ID)
+
+	return resp, err
+}
+
+// VolumeList logs the filters and the number of volumes found.
+func (v *Verbose) VolumeList(ctx context.Context, filter filters.Args) (volumetypes.VolumeListOKBody, error) {
+	start := time.Now()
+	resp, err := v.CommonAPIClient.VolumeList(ctx, filter)
+	v.log("VolumeList", start, err, formatFilters(filter), fmt.Sprintf("found=%d", len(resp.Volumes)))
+
+	return resp, err
+}
+
+// VolumeCreate logs the name of the new volume.
+func (v *Verbose) VolumeCreate(ctx context.Context, options volumetypes.VolumeCreateBody) (types.Volume, error) {
+	start := time.Now()
+	vol, err := v.CommonAPIClient.VolumeCreate(ctx, options)
+	v.log("VolumeCreate", start, err, "name="+options.Name)
+
+	return vol, err
+}
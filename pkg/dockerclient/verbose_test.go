@@ -0,0 +1,61 @@
+package dockerclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+func TestVerbose(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	buf := &bytes.Buffer{}
+	fake := dockertest.New(nil, nil)
+	fake.Errors = map[string]error{"ContainerStart": errors.New("port is already allocated")}
+	docker := NewVerbose(fake, buf)
+
+	// Act
+	if _, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filters.NewArgs(filters.Arg("label", "com.craftcms.nitro=true"))}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := docker.ContainerCreate(ctx, &container.Config{Image: "docker.io/craftcms/nginx:7.4-dev"}, nil, nil, nil, "mysite.nitro")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	startErr := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{})
+
+	// Assert
+	if startErr == nil || startErr.Error() != "port is already allocated" {
+		t.Errorf("expected the raw error to be returned, got %v", startErr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a line for each call, got %q", buf.String())
+	}
+
+	want := []string{
+		"docker ContainerList filters=[label=com.craftcms.nitro=true] found=0",
+		"docker ContainerCreate name=mysite.nitro image=docker.io/craftcms/nginx:7.4-dev id=" + resp.ID,
+		"docker ContainerStart id=" + resp.ID,
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(lines[i], w) {
+			t.Errorf("expected line %d to start with %q, got %q", i, w, lines[i])
+		}
+	}
+
+	if !strings.HasSuffix(lines[2], "error: port is already allocated") {
+		t.Errorf("expected the error to be logged, got %q", lines[2])
+	}
+}
@@ -0,0 +1,786 @@
+// Package dockertest provides an in-memory fake of the docker client for
+// testing commands that take a client.CommonAPIClient.
+package dockertest
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Call is a request that was made to the fake client.
+type Call struct {
+	// Method is the name of the client method (e.g. ContainerCreate)
+	Method string
+
+	// Args are the arguments passed to the method, without the context
+	Args []interface{}
+}
+
+// Client is a fake docker client that keeps containers, networks, and volumes
+// in memory and records each call. Only the methods nitro uses are
+// implemented, calling any other method will panic.
+type Client struct {
+	client.CommonAPIClient
+
+	// Containers are the containers returned by ContainerList, created
+	// containers are added and removed containers are deleted.
+	Containers []types.Container
+
+	// Details are returned by ContainerInspect using the container ID, if a
+	// container does not have details they are created from the container.
+	Details map[string]types.ContainerJSON
+
+	// Images are the images returned by ImageList
+	Images []types.ImageSummary
+
+	// Networks are the networks returned by NetworkList
+	Networks []types.NetworkResource
+
+	// Volumes are the volumes returned by VolumeList
+	Volumes []*types.Volume
+
+	// ExecOutput is the output returned when attaching to an exec
+	ExecOutput string
+
+	// ExecExitCode is the exit code returned when inspecting an exec
+	ExecExitCode int
+
+	// ExecHangs keeps the output of an exec open until the connection is
+	// closed, like an interactive or long running command
+	ExecHangs bool
+
+	// CopyOutput is the content of the file returned by CopyFromContainer
+	CopyOutput string
+
+	// LogsOutput is the stdout returned by ContainerLogs
+	LogsOutput string
+
+	// VersionOutput is returned by ServerVersion
+	VersionOutput types.Version
+
+	// InfoOutput is returned by Info
+	InfoOutput types.Info
+
+	// Errors are returned by the method name (e.g. "ContainerCreate")
+	Errors map[string]error
+
+	mu    sync.Mutex
+	calls []Call
+	ids   int
+}
+
+// New returns a fake client with the containers and networks.
+func New(containers []types.Container, networks []types.NetworkResource) *Client {
+	return &Client{
+		Containers: containers,
+		Networks:   networks,
+		Details:    map[string]types.ContainerJSON{},
+		Errors:     map[string]error{},
+	}
+}
+
+// Calls returns the calls made to the method, if the method is empty all of
+// the calls are returned in the order they were made.
+func (c *Client) Calls(method string) []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var calls []Call
+	for _, call := range c.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// record saves the call and returns the error for the method, if any.
+func (c *Client) record(method string, args ...interface{}) error {
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+
+	return c.Errors[method]
+}
+
+// resolve returns the ID of the container with the name, if there is no
+// container with the name the ref is returned.
+func (c *Client) resolve(ref string) string {
+	for _, ctr := range c.Containers {
+		for _, n := range ctr.Names {
+			if n == "/"+strings.TrimLeft(ref, "/") {
+				return ctr.ID
+			}
+		}
+	}
+
+	return ref
+}
+
+// nextID returns a unique ID for a new resource.
+func (c *Client) nextID(prefix string) string {
+	c.ids++
+
+	return fmt.Sprintf("%s-%d", prefix, c.ids)
+}
+
+// Ping returns an empty ping response.
+func (c *Client) Ping(ctx context.Context) (types.Ping, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return types.Ping{}, c.record("Ping")
+}
+
+// ServerVersion returns the version output.
+func (c *Client) ServerVersion(ctx context.Context) (types.Version, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.VersionOutput, c.record("ServerVersion")
+}
+
+// Info returns the info output.
+func (c *Client) Info(ctx context.Context) (types.Info, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.InfoOutput, c.record("Info")
+}
+
+// ContainerList returns the containers matching the label and name filters.
+// Containers that are not running are only returned when All is true.
+func (c *Client) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerList", options); err != nil {
+		return nil, err
+	}
+
+	var containers []types.Container
+	for _, ctr := range c.Containers {
+		if !options.All && ctr.State != "running" {
+			continue
+		}
+
+		if !matchLabels(options.Filters, ctr.Labels) || !matchNames(options.Filters, ctr.Names) {
+			continue
+		}
+
+		containers = append(containers, ctr)
+	}
+
+	return containers, nil
+}
+
+// ContainerCreate adds a created container.
+func (c *Client) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerCreate", config, hostConfig, networkingConfig, platform, containerName); err != nil {
+		return container.ContainerCreateCreatedBody{}, err
+	}
+
+	// container names are unique, like the docker daemon
+	if containerName != "" {
+		if id := c.resolve(containerName); id != containerName {
+			return container.ContainerCreateCreatedBody{}, errdefs.Conflict(fmt.Errorf("Conflict. The container name \"/%s\" is already in use by container \"%s\"", containerName, id))
+		}
+	}
+
+	id := c.nextID("container")
+
+	c.Containers = append(c.Containers, types.Container{
+		ID:     id,
+		Names:  []string{"/" + containerName},
+		Image:  config.Image,
+		Labels: config.Labels,
+		State:  "created",
+	})
+
+	c.Details[id] = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:         id,
+			Name:       "/" + containerName,
+			State:      &types.ContainerState{Status: "created"},
+			HostConfig: hostConfig,
+		},
+		Config: config,
+	}
+
+	return container.ContainerCreateCreatedBody{ID: id}, nil
+}
+
+// ContainerStart sets the container state to running.
+func (c *Client) ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerStart", containerID, options); err != nil {
+		return err
+	}
+
+	return c.setState(containerID, "running")
+}
+
+// ContainerStop sets the container state to exited.
+func (c *Client) ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerStop", containerID, timeout); err != nil {
+		return err
+	}
+
+	return c.setState(containerID, "exited")
+}
+
+// ContainerLogs returns the logs output as stdout, multiplexed like the docker daemon.
+func (c *Client) ContainerLogs(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerLogs", containerID, options); err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := stdcopy.NewStdWriter(buf, stdcopy.Stdout).Write([]byte(c.LogsOutput)); err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(buf), nil
+}
+
+// ContainerPause sets the container state to paused.
+func (c *Client) ContainerPause(ctx context.Context, containerID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerPause", containerID); err != nil {
+		return err
+	}
+
+	return c.setState(containerID, "paused")
+}
+
+// ContainerUnpause sets the container state to running.
+func (c *Client) ContainerUnpause(ctx context.Context, containerID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerUnpause", containerID); err != nil {
+		return err
+	}
+
+	return c.setState(containerID, "running")
+}
+
+// ContainerRemove deletes the container.
+func (c *Client) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerRemove", containerID, options); err != nil {
+		return err
+	}
+
+	for i, ctr := range c.Containers {
+		if ctr.ID == containerID {
+			c.Containers = append(c.Containers[:i], c.Containers[i+1:]...)
+			delete(c.Details, containerID)
+
+			return nil
+		}
+	}
+
+	return notFound(containerID)
+}
+
+// ContainerInspect returns the details for the container using the ID or name.
+func (c *Client) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerInspect", containerID); err != nil {
+		return types.ContainerJSON{}, err
+	}
+
+	containerID = c.resolve(containerID)
+
+	if details, ok := c.Details[containerID]; ok {
+		return details, nil
+	}
+
+	for _, ctr := range c.Containers {
+		if ctr.ID != containerID {
+			continue
+		}
+
+		var name string
+		if len(ctr.Names) > 0 {
+			name = ctr.Names[0]
+		}
+
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:         ctr.ID,
+				Name:       name,
+				State:      &types.ContainerState{Status: ctr.State, Running: ctr.State == "running" || ctr.State == "paused", Paused: ctr.State == "paused"},
+				HostConfig: &container.HostConfig{},
+			},
+			Config: &container.Config{
+				Image:  ctr.Image,
+				Labels: ctr.Labels,
+			},
+		}, nil
+	}
+
+	return types.ContainerJSON{}, notFound(containerID)
+}
+
+// ContainerUpdate records the update.
+func (c *Client) ContainerUpdate(ctx context.Context, containerID string, updateConfig container.UpdateConfig) (container.ContainerUpdateOKBody, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return container.ContainerUpdateOKBody{}, c.record("ContainerUpdate", containerID, updateConfig)
+}
+
+// ContainerExecCreate returns a new exec ID.
+func (c *Client) ContainerExecCreate(ctx context.Context, containerID string, config types.ExecConfig) (types.IDResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerExecCreate", containerID, config); err != nil {
+		return types.IDResponse{}, err
+	}
+
+	return types.IDResponse{ID: c.nextID("exec")}, nil
+}
+
+// ContainerExecAttach returns a connection that reads the ExecOutput.
+func (c *Client) ContainerExecAttach(ctx context.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerExecAttach", execID, config); err != nil {
+		return types.HijackedResponse{}, err
+	}
+
+	// the connection is only used to write stdin and close the response
+	conn, remote := net.Pipe()
+	go io.Copy(ioutil.Discard, remote)
+
+	reader := bufio.NewReader(strings.NewReader(c.ExecOutput))
+	if c.ExecHangs {
+		// reading the connection blocks until it is closed
+		reader = bufio.NewReader(conn)
+	}
+
+	return types.HijackedResponse{
+		Conn:   conn,
+		Reader: reader,
+	}, nil
+}
+
+// ContainerExecStart records the start of the exec.
+func (c *Client) ContainerExecStart(ctx context.Context, execID string, config types.ExecStartCheck) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.record("ContainerExecStart", execID, config)
+}
+
+// ContainerExecInspect returns a completed exec with the ExecExitCode.
+func (c *Client) ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ContainerExecInspect", execID); err != nil {
+		return types.ContainerExecInspect{}, err
+	}
+
+	return types.ContainerExecInspect{ExecID: execID, ExitCode: c.ExecExitCode}, nil
+}
+
+// CopyToContainer records the copy with the content of the files in the tar
+// archive (e.g. name: content), so tests can check what was copied.
+func (c *Client) CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error {
+	var files []string
+
+	tr := tar.NewReader(content)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, hdr.Name+": "+string(b))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("CopyToContainer", containerID, dstPath, strings.Join(files, "\n"), options); err != nil {
+		return err
+	}
+
+	if c.resolve(containerID) == containerID {
+		for _, ctr := range c.Containers {
+			if ctr.ID == containerID {
+				return nil
+			}
+		}
+
+		return notFound(containerID)
+	}
+
+	return nil
+}
+
+// CopyFromContainer returns a tar archive with a single file that contains the CopyOutput.
+func (c *Client) CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("CopyFromContainer", containerID, srcPath); err != nil {
+		return nil, types.ContainerPathStat{}, err
+	}
+
+	stat := types.ContainerPathStat{Name: path.Base(srcPath), Size: int64(len(c.CopyOutput)), Mode: 0644}
+
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	if err := tw.WriteHeader(&tar.Header{Name: stat.Name, Mode: 0644, Size: stat.Size}); err != nil {
+		return nil, types.ContainerPathStat{}, err
+	}
+	if _, err := tw.Write([]byte(c.CopyOutput)); err != nil {
+		return nil, types.ContainerPathStat{}, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, types.ContainerPathStat{}, err
+	}
+
+	return ioutil.NopCloser(buf), stat, nil
+}
+
+// ImageList returns the images matching the label and reference filters.
+func (c *Client) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ImageList", options); err != nil {
+		return nil, err
+	}
+
+	var images []types.ImageSummary
+	for _, i := range c.Images {
+		if !matchLabels(options.Filters, i.Labels) || !matchReferences(options.Filters, i.RepoTags) {
+			continue
+		}
+
+		images = append(images, i)
+	}
+
+	return images, nil
+}
+
+// ImageInspectWithRaw returns the image matching the tag or ID, pulled images
+// that are not in Images are returned using the reference as the ID.
+func (c *Client) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ImageInspectWithRaw", imageID); err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+
+	for _, i := range c.Images {
+		if i.ID == imageID {
+			return inspectImage(i), nil, nil
+		}
+
+		for _, t := range i.RepoTags {
+			if t == imageID {
+				return inspectImage(i), nil, nil
+			}
+		}
+	}
+
+	for _, call := range c.calls {
+		if call.Method == "ImagePull" && call.Args[0] == imageID {
+			return types.ImageInspect{ID: imageID, RepoTags: []string{imageID}}, nil, nil
+		}
+	}
+
+	return types.ImageInspect{}, nil, errdefs.NotFound(fmt.Errorf("No such image: %s", imageID))
+}
+
+// inspectImage returns the details for an image, including the labels.
+func inspectImage(i types.ImageSummary) types.ImageInspect {
+	return types.ImageInspect{ID: i.ID, RepoTags: i.RepoTags, RepoDigests: i.RepoDigests, Config: &container.Config{Labels: i.Labels}}
+}
+
+// ImageBuild reads the build context and adds an image with the tags and
+// labels, the tags are removed from any existing images.
+func (c *Client) ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ImageBuild", options); err != nil {
+		return types.ImageBuildResponse{}, err
+	}
+
+	if _, err := io.Copy(ioutil.Discard, buildContext); err != nil {
+		return types.ImageBuildResponse{}, err
+	}
+
+	built := make(map[string]bool)
+	for _, t := range options.Tags {
+		built[t] = true
+	}
+
+	for i := range c.Images {
+		var tags []string
+		for _, t := range c.Images[i].RepoTags {
+			if !built[t] {
+				tags = append(tags, t)
+			}
+		}
+
+		c.Images[i].RepoTags = tags
+	}
+
+	id := c.nextID("image")
+	c.Images = append(c.Images, types.ImageSummary{ID: id, RepoTags: options.Tags, Labels: options.Labels})
+
+	return types.ImageBuildResponse{Body: ioutil.NopCloser(strings.NewReader(`{"stream":"Successfully built ` + id + `\n"}`))}, nil
+}
+
+// ImagePull returns an empty pull progress.
+func (c *Client) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("ImagePull", ref, options); err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(strings.NewReader("")), nil
+}
+
+// NetworkList returns the networks matching the label and name filters.
+func (c *Client) NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("NetworkList", options); err != nil {
+		return nil, err
+	}
+
+	var networks []types.NetworkResource
+	for _, n := range c.Networks {
+		if !matchLabels(options.Filters, n.Labels) || !matchNames(options.Filters, []string{n.Name}) {
+			continue
+		}
+
+		networks = append(networks, n)
+	}
+
+	return networks, nil
+}
+
+// NetworkInspect returns the network using the ID or name.
+func (c *Client) NetworkInspect(ctx context.Context, networkID string, options types.NetworkInspectOptions) (types.NetworkResource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("NetworkInspect", networkID, options); err != nil {
+		return types.NetworkResource{}, err
+	}
+
+	for _, n := range c.Networks {
+		if n.ID == networkID || n.Name == networkID {
+			return n, nil
+		}
+	}
+
+	return types.NetworkResource{}, errdefs.NotFound(fmt.Errorf("network %s not found", networkID))
+}
+
+// NetworkCreate adds a network, if CheckDuplicate is set and a network with the
+// name exists a conflict error is returned.
+func (c *Client) NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("NetworkCreate", name, options); err != nil {
+		return types.NetworkCreateResponse{}, err
+	}
+
+	if options.CheckDuplicate {
+		for _, n := range c.Networks {
+			if n.Name == name {
+				return types.NetworkCreateResponse{}, errdefs.Conflict(fmt.Errorf("network with name %s already exists", name))
+			}
+		}
+	}
+
+	id := c.nextID("network")
+
+	c.Networks = append(c.Networks, types.NetworkResource{
+		ID:         id,
+		Name:       name,
+		Driver:     options.Driver,
+		Attachable: options.Attachable,
+		Labels:     options.Labels,
+	})
+
+	return types.NetworkCreateResponse{ID: id}, nil
+}
+
+// VolumeCreate adds a volume.
+func (c *Client) VolumeCreate(ctx context.Context, options volumetypes.VolumeCreateBody) (types.Volume, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("VolumeCreate", options); err != nil {
+		return types.Volume{}, err
+	}
+
+	volume := types.Volume{Name: options.Name, Driver: options.Driver, Labels: options.Labels}
+
+	c.Volumes = append(c.Volumes, &volume)
+
+	return volume, nil
+}
+
+// VolumeList returns the volumes matching the label and name filters.
+func (c *Client) VolumeList(ctx context.Context, filter filters.Args) (volumetypes.VolumeListOKBody, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record("VolumeList", filter); err != nil {
+		return volumetypes.VolumeListOKBody{}, err
+	}
+
+	var volumes []*types.Volume
+	for _, v := range c.Volumes {
+		if !matchLabels(filter, v.Labels) || !matchNames(filter, []string{v.Name}) {
+			continue
+		}
+
+		volumes = append(volumes, v)
+	}
+
+	return volumetypes.VolumeListOKBody{Volumes: volumes}, nil
+}
+
+func (c *Client) setState(containerID, state string) error {
+	for i, ctr := range c.Containers {
+		if ctr.ID != containerID {
+			continue
+		}
+
+		c.Containers[i].State = state
+
+		if details, ok := c.Details[containerID]; ok && details.ContainerJSONBase != nil {
+			details.State = &types.ContainerState{Status: state, Running: state == "running" || state == "paused", Paused: state == "paused"}
+		}
+
+		return nil
+	}
+
+	return notFound(containerID)
+}
+
+// matchLabels returns true if the labels have each of the label filters,
+// filters can be a key (e.g. com.craftcms.nitro) or key=value.
+func matchLabels(filter filters.Args, labels map[string]string) bool {
+	for _, l := range filter.Get("label") {
+		parts := strings.SplitN(l, "=", 2)
+
+		v, ok := labels[parts[0]]
+		if !ok {
+			return false
+		}
+
+		if len(parts) == 2 && v != parts[1] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchNames returns true if any of the names contain the name filters.
+func matchNames(filter filters.Args, names []string) bool {
+	for _, f := range filter.Get("name") {
+		var found bool
+		for _, n := range names {
+			if strings.Contains(n, f) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchReferences returns true if the tags have each of the reference filters.
+func matchReferences(filter filters.Args, tags []string) bool {
+	for _, r := range filter.Get("reference") {
+		var found bool
+		for _, t := range tags {
+			if t == r {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func notFound(id string) error {
+	return errdefs.NotFound(fmt.Errorf("No such container: %s", id))
+}
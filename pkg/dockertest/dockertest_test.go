@@ -0,0 +1,126 @@
+package dockertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+func TestClient_ContainerList(t *testing.T) {
+	containers := []types.Container{
+		{ID: "site", Names: []string{"/mysite.nitro"}, State: "running", Labels: map[string]string{"com.craftcms.nitro": "true", "com.craftcms.nitro.host": "mysite.nitro"}},
+		{ID: "database", Names: []string{"/mysql-8.0-3306.database.nitro"}, State: "exited", Labels: map[string]string{"com.craftcms.nitro": "true", "com.craftcms.nitro.type": "database"}},
+		{ID: "other", Names: []string{"/other"}, State: "running"},
+	}
+
+	tests := []struct {
+		name    string
+		filters []string
+		all     bool
+		want    []string
+	}{
+		{
+			name: "only running containers are returned by default",
+			want: []string{"site", "other"},
+		},
+		{
+			name: "all containers are returned when all is set",
+			all:  true,
+			want: []string{"site", "database", "other"},
+		},
+		{
+			name:    "label keys filter containers",
+			filters: []string{"com.craftcms.nitro"},
+			all:     true,
+			want:    []string{"site", "database"},
+		},
+		{
+			name:    "label values filter containers",
+			filters: []string{"com.craftcms.nitro.type=database"},
+			all:     true,
+			want:    []string{"database"},
+		},
+		{
+			name:    "mismatched label values return no containers",
+			filters: []string{"com.craftcms.nitro.type=custom"},
+			all:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(containers, nil)
+
+			filter := filters.NewArgs()
+			for _, f := range tt.filters {
+				filter.Add("label", f)
+			}
+
+			got, err := c.ContainerList(context.Background(), types.ContainerListOptions{All: tt.all, Filters: filter})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []string
+			for _, c := range got {
+				ids = append(ids, c.ID)
+			}
+
+			if len(ids) != len(tt.want) {
+				t.Fatalf("ContainerList() = %v, want %v", ids, tt.want)
+			}
+
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Errorf("ContainerList() = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_ContainerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	c := New(nil, nil)
+
+	resp, err := c.ContainerCreate(ctx, &container.Config{Image: "nginx", Labels: map[string]string{"test": "true"}}, nil, nil, nil, "example")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	details, err := c.ContainerInspect(ctx, resp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !details.State.Running {
+		t.Errorf("expected the container to be running")
+	}
+
+	if details.Name != "/example" {
+		t.Errorf("expected the name to be /example, got %s", details.Name)
+	}
+
+	if err := c.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.ContainerInspect(ctx, resp.ID); !client.IsErrNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	if calls := c.Calls("ContainerInspect"); len(calls) != 2 {
+		t.Errorf("expected 2 inspect calls, got %d", len(calls))
+	}
+
+	if calls := c.Calls(""); len(calls) != 5 {
+		t.Errorf("expected 5 calls, got %d", len(calls))
+	}
+}
@@ -0,0 +1,135 @@
+package dotenv
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Read takes a file path and returns the parsed key/values from the
+// file. It returns an error if the file cannot be read.
+func Read(file string) (map[string]string, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the env file %q, %w", file, err)
+	}
+
+	return Parse(string(content)), nil
+}
+
+// Parse takes the content of a .env file and returns the key/values. It follows
+// common dotenv conventions: blank lines and comments are ignored, an optional
+// export prefix is removed, single quoted values are used as is, double quoted
+// values support escaped characters, and unquoted values are trimmed and may
+// contain trailing comments.
+func Parse(content string) map[string]string {
+	envs := make(map[string]string)
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+
+		// ignore blank lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// remove the export prefix
+		line = strings.TrimPrefix(line, "export ")
+
+		// lines without an equal sign are not valid
+		sp := strings.SplitN(line, "=", 2)
+		if len(sp) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(sp[0])
+		if key == "" || strings.ContainsAny(key, " \t") {
+			continue
+		}
+
+		envs[key] = parseValue(strings.TrimSpace(sp[1]))
+	}
+
+	return envs
+}
+
+// Hash takes a file and returns a sha256 hash of the files contents. It
+// is used to determine if a file has changed since a container was created.
+func Hash(file string) (string, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the env file %q, %w", file, err)
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+}
+
+// Envs takes the key/values and returns them as a sorted list of environment
+// variables (e.g. KEY=value) to use on a container.
+func Envs(envs map[string]string) []string {
+	var keys []string
+	for k := range envs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var vars []string
+	for _, k := range keys {
+		vars = append(vars, k+"="+envs[k])
+	}
+
+	return vars
+}
+
+func parseValue(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	switch value[0] {
+	case '\'':
+		// single quoted values are taken literally
+		if end := strings.Index(value[1:], "'"); end >= 0 {
+			return value[1 : end+1]
+		}
+	case '"':
+		// double quoted values support escape sequences
+		var b strings.Builder
+		for i := 1; i < len(value); i++ {
+			c := value[i]
+
+			if c == '"' {
+				return b.String()
+			}
+
+			if c == '\\' && i+1 < len(value) {
+				i++
+
+				switch value[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(value[i])
+				}
+
+				continue
+			}
+
+			b.WriteByte(c)
+		}
+	}
+
+	// remove trailing comments from unquoted values
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,89 @@
+package dotenv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	type args struct {
+		content string
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[string]string
+	}{
+		{
+			name: "comments and blank lines are ignored",
+			args: args{
+				content: "# a comment\n\nENVIRONMENT=dev\n   # another comment\n",
+			},
+			want: map[string]string{
+				"ENVIRONMENT": "dev",
+			},
+		},
+		{
+			name: "unquoted values are trimmed and trailing comments are removed",
+			args: args{
+				content: "DB_SERVER = mysql-8.0-3306.database.nitro  # the hostname\nDB_PORT=3306",
+			},
+			want: map[string]string{
+				"DB_SERVER": "mysql-8.0-3306.database.nitro",
+				"DB_PORT":   "3306",
+			},
+		},
+		{
+			name: "single quoted values are used as is",
+			args: args{
+				content: `SECURITY_KEY='abc # not a comment \n'`,
+			},
+			want: map[string]string{
+				"SECURITY_KEY": `abc # not a comment \n`,
+			},
+		},
+		{
+			name: "double quoted values support escaped characters",
+			args: args{
+				content: `MESSAGE="hello\nworld \"nitro\"" # a comment`,
+			},
+			want: map[string]string{
+				"MESSAGE": "hello\nworld \"nitro\"",
+			},
+		},
+		{
+			name: "export prefixes, empty values, and values with equal signs are supported",
+			args: args{
+				content: "export APP_ID=CraftCMS\nDB_PASSWORD=\nDSN=mysql:host=localhost;port=3306\r\n",
+			},
+			want: map[string]string{
+				"APP_ID":      "CraftCMS",
+				"DB_PASSWORD": "",
+				"DSN":         "mysql:host=localhost;port=3306",
+			},
+		},
+		{
+			name: "invalid lines are ignored",
+			args: args{
+				content: "NOT VALID=value\nmissing-equals\n=novalue",
+			},
+			want: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.args.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = \ngot:\n%v\nwant:\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvs(t *testing.T) {
+	got := Envs(map[string]string{"B": "2", "A": "1", "C": ""})
+	want := []string{"A=1", "B=2", "C="}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Envs() = %v, want %v", got, want)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/craftcms/nitro/pkg/releases"
 )
 
 // Getter is an interface for getting the contents of a url
@@ -23,12 +25,18 @@ type Downloader struct {
 	client *http.Client
 }
 
-// NewDownloader creates a downloader with a default HTTP client
-// which is used to download files from the net.
-func NewDownloader() *Downloader {
-	return &Downloader{
-		client: http.DefaultClient,
+// NewDownloader creates a downloader with an HTTP client that uses
+// the proxy and CA bundle from the environment, which is used to
+// download files from the net.
+func NewDownloader() (*Downloader, error) {
+	client, err := releases.NewHTTPClient(releases.CAFile())
+	if err != nil {
+		return nil, err
 	}
+
+	return &Downloader{
+		client: client,
+	}, nil
 }
 
 // Get takes a url and a directory where the contents should be
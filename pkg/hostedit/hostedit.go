@@ -109,6 +109,61 @@ func Remove(file string) (content string, err error) {
 	return strings.Join(new, "\n"), nil
 }
 
+// RemoveHosts takes a file and the hosts to remove from the nitro section of
+// the hosts file. Only the nitro section is changed and hosts that are not in
+// the file are ignored, so removing hosts more than once is safe. If there are
+// no hosts left in the section, the section is removed.
+func RemoveHosts(file string, hosts ...string) (content string, err error) {
+	f, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	// get the indexes for the nitro section
+	start, middle, end := indexes(f)
+
+	// if there are no entries, return a specific error
+	if start == 0 && middle == 0 && end == 0 {
+		return "", ErrNotNitroEntries
+	}
+
+	// create a map of the hosts to remove
+	remove := make(map[string]bool)
+	for _, h := range hosts {
+		remove[h] = true
+	}
+
+	// split the lines
+	lines := strings.Split(string(f), "\n")
+
+	// the first field is the address, the rest are the hosts
+	fields := strings.Fields(lines[middle])
+	if len(fields) == 0 {
+		return string(f), nil
+	}
+
+	var keep []string
+	for _, h := range fields[1:] {
+		if !remove[h] {
+			keep = append(keep, h)
+		}
+	}
+
+	// if nothing was removed, return the file as is
+	if len(keep) == len(fields)-1 {
+		return string(f), nil
+	}
+
+	// if there are no more hosts, remove the entire section
+	if len(keep) == 0 {
+		return Remove(file)
+	}
+
+	lines[middle] = fmt.Sprintf("%s\t%s", fields[0], strings.Join(keep, " "))
+
+	return strings.Join(lines, "\n"), nil
+}
+
 func indexes(content []byte) (start, middle, end int) {
 	// split the file into multiple lines
 	lines := strings.Split(string(content), "\n")
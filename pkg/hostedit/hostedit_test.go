@@ -246,3 +246,115 @@ func TestRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoveHosts(t *testing.T) {
+	type args struct {
+		file  string
+		hosts []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "removes only the hosts provided",
+			args: args{
+				file:  filepath.Join("testdata", "to-remove.txt"),
+				hosts: []string{"two", "kubernetes.docker.internal"},
+			},
+			want: `##
+# Host Database
+#
+# localhost is used to configure the loopback interface
+# when the system is booting.  Do not change this entry.
+##
+127.0.0.1        localhost
+255.255.255.255  broadcasthost
+::1              localhost
+
+# <nitro>
+127.0.0.1	one three
+# </nitro>
+
+127.0.0.1        kubernetes.docker.internal
+# Added by Docker Desktop
+# To allow the same kube context to work on the host and the container:
+127.0.0.1        kubernetes.docker.internal
+# End of section
+`,
+		},
+		{
+			name: "unknown hosts do not change the file",
+			args: args{
+				file:  filepath.Join("testdata", "to-remove.txt"),
+				hosts: []string{"four"},
+			},
+			want: `##
+# Host Database
+#
+# localhost is used to configure the loopback interface
+# when the system is booting.  Do not change this entry.
+##
+127.0.0.1        localhost
+255.255.255.255  broadcasthost
+::1              localhost
+
+# <nitro>
+127.0.0.1	one two three
+# </nitro>
+
+127.0.0.1        kubernetes.docker.internal
+# Added by Docker Desktop
+# To allow the same kube context to work on the host and the container:
+127.0.0.1        kubernetes.docker.internal
+# End of section
+`,
+		},
+		{
+			name: "removing all of the hosts removes the section",
+			args: args{
+				file:  filepath.Join("testdata", "to-remove.txt"),
+				hosts: []string{"one", "two", "three"},
+			},
+			want: `##
+# Host Database
+#
+# localhost is used to configure the loopback interface
+# when the system is booting.  Do not change this entry.
+##
+127.0.0.1        localhost
+255.255.255.255  broadcasthost
+::1              localhost
+
+
+127.0.0.1        kubernetes.docker.internal
+# Added by Docker Desktop
+# To allow the same kube context to work on the host and the container:
+127.0.0.1        kubernetes.docker.internal
+# End of section
+`,
+		},
+		{
+			name: "no nitro hosts returns an error",
+			args: args{
+				file:  filepath.Join("testdata", "no-section.txt"),
+				hosts: []string{"one"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RemoveHosts(tt.args.file, tt.args.hosts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RemoveHosts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("RemoveHosts() = \ngot:\n%v\nwant \n%v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package imageref
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	domainRegex    = regexp.MustCompile(`^(localhost|[a-z0-9]+([.-][a-z0-9]+)*)(:[0-9]+)?$`)
+	componentRegex = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`)
+)
+
+// Validate returns an error if the registry is not a hostname with an
+// optional port and path (e.g. mirror.example.com:5000/hub).
+func Validate(registry string) error {
+	if registry == "" {
+		return nil
+	}
+
+	if strings.Contains(registry, "://") {
+		return fmt.Errorf("the registry %q must not include a scheme (e.g. mirror.example.com)", registry)
+	}
+
+	parts := strings.Split(registry, "/")
+
+	// without a dot or port docker treats the first part as a docker hub namespace
+	if !domainRegex.MatchString(parts[0]) || (!strings.ContainsAny(parts[0], ".:") && parts[0] != "localhost") {
+		return fmt.Errorf("the registry %q must start with a hostname (e.g. mirror.example.com)", registry)
+	}
+
+	for _, p := range parts[1:] {
+		if !componentRegex.MatchString(p) {
+			return fmt.Errorf("the registry %q has an invalid path %q", registry, p)
+		}
+	}
+
+	return nil
+}
+
+// Resolve returns the image using the registry mirror instead of Docker Hub
+// (e.g. mirror.example.com or mirror.example.com:5000/hub). Images from other
+// registries, and all images when the registry is empty, are returned as is.
+//
+// mysql:8.0 => mirror.example.com/library/mysql:8.0
+// docker.io/craftcms/nginx:7.4-dev => mirror.example.com/craftcms/nginx:7.4-dev
+func Resolve(registry, image string) string {
+	if registry == "" {
+		return image
+	}
+
+	name := image
+	if sp := strings.SplitN(image, "/", 2); len(sp) == 2 && (strings.ContainsAny(sp[0], ".:") || sp[0] == "localhost") {
+		switch sp[0] {
+		case "docker.io", "index.docker.io", "registry-1.docker.io":
+			name = sp[1]
+		default:
+			return image
+		}
+	}
+
+	// official images are in the library namespace
+	if !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+
+	return registry + "/" + name
+}
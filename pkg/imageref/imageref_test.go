@@ -0,0 +1,79 @@
+package imageref
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry string
+		image    string
+		want     string
+	}{
+		{
+			name:  "images are not changed without a registry",
+			image: "docker.io/craftcms/nginx:7.4-dev",
+			want:  "docker.io/craftcms/nginx:7.4-dev",
+		},
+		{
+			name:     "docker.io images use the registry",
+			registry: "mirror.example.com",
+			image:    "docker.io/craftcms/nginx:7.4-dev",
+			want:     "mirror.example.com/craftcms/nginx:7.4-dev",
+		},
+		{
+			name:     "images without a registry use the registry",
+			registry: "mirror.example.com",
+			image:    "craftcms/nitro-proxy:2.0.0",
+			want:     "mirror.example.com/craftcms/nitro-proxy:2.0.0",
+		},
+		{
+			name:     "official images use the library namespace",
+			registry: "mirror.example.com:5000/hub",
+			image:    "mysql:8.0",
+			want:     "mirror.example.com:5000/hub/library/mysql:8.0",
+		},
+		{
+			name:     "official docker.io images use the library namespace",
+			registry: "mirror.example.com",
+			image:    "docker.io/library/redis:latest",
+			want:     "mirror.example.com/library/redis:latest",
+		},
+		{
+			name:     "images from other registries are not changed",
+			registry: "mirror.example.com",
+			image:    "ghcr.io/team/php:7.4",
+			want:     "ghcr.io/team/php:7.4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resolve(tt.registry, tt.image); got != tt.want {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		registry string
+		wantErr  bool
+	}{
+		{registry: ""},
+		{registry: "mirror.example.com"},
+		{registry: "mirror.example.com:5000"},
+		{registry: "localhost:5000/docker-hub"},
+		{registry: "https://mirror.example.com", wantErr: true},
+		{registry: "mirror", wantErr: true},
+		{registry: "mirror.example.com/", wantErr: true},
+		{registry: "Mirror.example.com", wantErr: true},
+		{registry: "mirror.example.com/hub:latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.registry, func(t *testing.T) {
+			if err := Validate(tt.registry); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/imageref"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/svc/dynamodb"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
+	"github.com/craftcms/nitro/pkg/svc/mailhog"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
+	"github.com/craftcms/nitro/pkg/svc/minio"
+	"github.com/craftcms/nitro/pkg/svc/redis"
+)
+
+var (
+	// NginxImage is the image used for sites, with the PHP version
+	NginxImage = "docker.io/craftcms/nginx:%s-dev"
+
+	// DatabaseImage is used for determining the engine and version
+	DatabaseImage = "%s:%s"
+
+	// BuildImage is the tag for sites built from a Dockerfile, with the hostname
+	BuildImage = "nitro/%s:latest"
+)
+
+// Site returns the image for a site based on the PHP version, sites with a
+// Dockerfile use the image that is built during apply and sites with an
+// image use the image as is. The PHP version images use the registry mirror.
+func Site(registry string, s config.Site) string {
+	if s.Image != "" {
+		return s.Image
+	}
+
+	if s.Dockerfile != "" {
+		return fmt.Sprintf(BuildImage, strings.ToLower(s.Hostname))
+	}
+
+	return imageref.Resolve(registry, fmt.Sprintf(NginxImage, s.Version))
+}
+
+// Database returns the image for a database based on the engine and version,
+// using the registry mirror.
+func Database(registry string, db config.Database) string {
+	return imageref.Resolve(registry, fmt.Sprintf(DatabaseImage, db.Engine, db.Version))
+}
+
+// Container returns the image for a custom container.
+func Container(c config.Container) string {
+	return fmt.Sprintf("%s:%s", c.Image, c.Tag)
+}
+
+// ForConfig returns the unique list of images required for the config. This
+// includes the proxy, each sites PHP version, the databases, the enabled
+// services, and any custom containers, using the registry mirror from the
+// config.
+func ForConfig(cfg *config.Config) []string {
+	var list []string
+	seen := make(map[string]bool)
+
+	add := func(image string) {
+		if seen[image] {
+			return
+		}
+
+		seen[image] = true
+		list = append(list, image)
+	}
+
+	add(imageref.Resolve(cfg.Registry, proxycontainer.ProxyImage))
+
+	for _, s := range cfg.Sites {
+		// sites with a Dockerfile are built, not pulled
+		if s.Dockerfile != "" {
+			continue
+		}
+
+		add(Site(cfg.Registry, s))
+	}
+
+	for _, db := range cfg.Databases {
+		add(Database(cfg.Registry, db))
+	}
+
+	if cfg.Services.DynamoDB {
+		add(imageref.Resolve(cfg.Registry, dynamodb.Image))
+	}
+
+	if cfg.Services.Elasticsearch {
+		add(imageref.Resolve(cfg.Registry, elasticsearch.Image))
+	}
+
+	if cfg.Services.Mailhog {
+		add(imageref.Resolve(cfg.Registry, mailhog.Image))
+	}
+
+	if cfg.Services.Meilisearch {
+		add(imageref.Resolve(cfg.Registry, meilisearch.Image))
+	}
+
+	if cfg.Services.Minio {
+		add(imageref.Resolve(cfg.Registry, minio.Image))
+	}
+
+	if cfg.Services.Redis {
+		add(imageref.Resolve(cfg.Registry, redis.Image))
+	}
+
+	for _, c := range cfg.Containers {
+		add(Container(c))
+	}
+
+	return list
+}
+
+// Exists returns true if the image is available locally.
+func Exists(ctx context.Context, docker client.ImageAPIClient, image string) (bool, error) {
+	filter := filters.NewArgs()
+	filter.Add("reference", image)
+
+	list, err := docker.ImageList(ctx, types.ImageListOptions{Filters: filter})
+	if err != nil {
+		return false, fmt.Errorf("unable to get a list of images, %w", err)
+	}
+
+	return len(list) > 0, nil
+}
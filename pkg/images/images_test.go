@@ -0,0 +1,113 @@
+package images
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+)
+
+func TestForConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want []string
+	}{
+		{
+			name: "empty configs only require the proxy",
+			cfg:  &config.Config{},
+			want: []string{proxycontainer.ProxyImage},
+		},
+		{
+			name: "sites, databases, services, and containers are included without duplicates",
+			cfg: &config.Config{
+				Sites: []config.Site{
+					{Hostname: "one.nitro", Version: "7.4"},
+					{Hostname: "two.nitro", Version: "7.4"},
+					{Hostname: "three.nitro", Version: "8.0"},
+				},
+				Databases: []config.Database{
+					{Engine: "mysql", Version: "8.0", Port: "3306"},
+					{Engine: "mysql", Version: "8.0", Port: "33061"},
+					{Engine: "postgres", Version: "13", Port: "5432"},
+				},
+				Services: config.Services{
+					Mailhog: true,
+					Redis:   true,
+				},
+				Containers: []config.Container{
+					{Name: "elasticsearch", Image: "elasticsearch", Tag: "7.10.1"},
+				},
+			},
+			want: []string{
+				proxycontainer.ProxyImage,
+				"docker.io/craftcms/nginx:7.4-dev",
+				"docker.io/craftcms/nginx:8.0-dev",
+				"mysql:8.0",
+				"postgres:13",
+				"docker.io/mailhog/mailhog:latest",
+				"docker.io/library/redis:latest",
+				"elasticsearch:7.10.1",
+			},
+		},
+		{
+			name: "sites with a Dockerfile are not pulled",
+			cfg: &config.Config{
+				Sites: []config.Site{
+					{Hostname: "one.nitro", Version: "7.4", Dockerfile: "Dockerfile"},
+				},
+			},
+			want: []string{proxycontainer.ProxyImage},
+		},
+		{
+			name: "sites with an image use the image",
+			cfg: &config.Config{
+				Sites: []config.Site{
+					{Hostname: "one.nitro", Version: "7.4", Image: "registry.example.com/team/php:7.4"},
+				},
+			},
+			want: []string{proxycontainer.ProxyImage, "registry.example.com/team/php:7.4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ForConfig(tt.cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForConfig_Registry(t *testing.T) {
+	cfg := &config.Config{
+		Registry: "mirror.example.com",
+		Sites: []config.Site{
+			{Hostname: "one.nitro", Version: "7.4"},
+			{Hostname: "two.nitro", Image: "ghcr.io/team/php:7.4"},
+		},
+		Databases: []config.Database{
+			{Engine: "mysql", Version: "8.0", Port: "3306"},
+		},
+		Services: config.Services{
+			Redis: true,
+		},
+		Containers: []config.Container{
+			{Name: "elasticsearch", Image: "elasticsearch", Tag: "7.10.1"},
+		},
+	}
+
+	want := []string{
+		"mirror.example.com/craftcms/nitro-proxy:" + strings.SplitN(proxycontainer.ProxyImage, ":", 2)[1],
+		"mirror.example.com/craftcms/nginx:7.4-dev",
+		"ghcr.io/team/php:7.4",
+		"mirror.example.com/library/mysql:8.0",
+		"mirror.example.com/library/redis:latest",
+		"elasticsearch:7.10.1",
+	}
+
+	if got := ForConfig(cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("ForConfig() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package packagecache
+
+import (
+	"github.com/docker/docker/api/types/mount"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+)
+
+const (
+	// Volume is the volume shared by the site containers to cache composer and npm downloads
+	Volume = "nitro_package_cache"
+
+	// Target is the path in the container the volume is mounted to
+	Target = "/var/cache/nitro"
+
+	// ComposerDir is the composer cache directory in the volume
+	ComposerDir = Target + "/composer"
+
+	// NpmDir is the npm cache directory in the volume
+	NpmDir = Target + "/npm"
+)
+
+// Mount returns the mount for the package cache volume, the volume is created
+// by docker the first time it is mounted and is kept when nitro is destroyed.
+func Mount() mount.Mount {
+	return mount.Mount{
+		Type:   mount.TypeVolume,
+		Source: Volume,
+		Target: Target,
+		VolumeOptions: &mount.VolumeOptions{
+			Labels: containerlabels.ForVolume(Volume, true),
+		},
+	}
+}
+
+// Envs returns the environment variables that point composer and npm to the
+// cache directories in the volume.
+func Envs() []string {
+	return []string{
+		"COMPOSER_CACHE_DIR=" + ComposerDir,
+		"npm_config_cache=" + NpmDir,
+	}
+}
+
+// Dirs returns the cache directories in the volume, they are created by root
+// and need to be owned by the user of the container to be writable.
+func Dirs() []string {
+	return []string{ComposerDir, NpmDir}
+}
+
+// ClearCommand returns the command to remove the contents of a cache
+// directory (e.g. ComposerDir) while keeping the directory and its owner.
+func ClearCommand(dir string) []string {
+	return []string{"find", dir, "-mindepth", "1", "-delete"}
+}
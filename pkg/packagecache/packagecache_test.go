@@ -0,0 +1,38 @@
+package packagecache
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+)
+
+func TestMount(t *testing.T) {
+	got := Mount()
+
+	if got.Type != mount.TypeVolume || got.Source != Volume || got.Target != Target {
+		t.Errorf("expected the %s volume mounted at %s, got %v", Volume, Target, got)
+	}
+
+	if got.VolumeOptions == nil || !containerlabels.IsSharedVolume(got.VolumeOptions.Labels) {
+		t.Errorf("expected the volume to be shared so destroy keeps it, got %v", got.VolumeOptions)
+	}
+}
+
+func TestEnvs(t *testing.T) {
+	want := []string{"COMPOSER_CACHE_DIR=/var/cache/nitro/composer", "npm_config_cache=/var/cache/nitro/npm"}
+
+	if got := Envs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Envs() = %v, want %v", got, want)
+	}
+}
+
+func TestClearCommand(t *testing.T) {
+	want := []string{"find", "/var/cache/nitro/composer", "-mindepth", "1", "-delete"}
+
+	if got := ClearCommand(ComposerDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("ClearCommand() = %v, want %v", got, want)
+	}
+}
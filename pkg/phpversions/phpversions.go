@@ -1,6 +1,8 @@
 package phpversions
 
-// Versions is the known PHP versions we support
+// Versions is the known PHP versions we support, this is the only place the
+// supported versions are defined and each version has a craftcms/nginx image
+// (e.g. docker.io/craftcms/nginx:8.0-dev).
 var Versions = []string{
 	"8.0",
 	"7.4",
@@ -9,3 +11,18 @@ var Versions = []string{
 	"7.1",
 	"7.0",
 }
+
+// Default is the PHP version used for sites that do not set a version, it can
+// be changed using default_php in the config.
+const Default = "7.4"
+
+// IsSupported returns true if the PHP version is in the supported versions.
+func IsSupported(version string) bool {
+	for _, v := range Versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
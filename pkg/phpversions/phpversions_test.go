@@ -0,0 +1,24 @@
+package phpversions
+
+import "testing"
+
+func TestIsSupported(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "8.0", want: true},
+		{version: "7.4", want: true},
+		{version: "7.0", want: true},
+		{version: "5.6", want: false},
+		{version: "8", want: false},
+		{version: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := IsSupported(tt.version); got != tt.want {
+				t.Errorf("IsSupported(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
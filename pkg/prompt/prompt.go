@@ -212,8 +212,12 @@ func CreateSite(home, dir string, output terminal.Outputer) (*config.Site, error
 
 	// append the test domain if there are no periods
 	if !strings.Contains(site.Hostname, ".") {
-		// set the default tld
-		tld := "nitro"
+		// set the default tld, using the tld from the config
+		tld := config.DefaultTLD
+		if cfg, err := config.Load(home); err == nil {
+			tld = cfg.GetTLD()
+		}
+
 		if os.Getenv("NITRO_DEFAULT_TLD") != "" {
 			tld = os.Getenv("NITRO_DEFAULT_TLD")
 		}
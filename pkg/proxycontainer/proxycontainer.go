@@ -1,19 +1,22 @@
 package proxycontainer
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
 	volumetypes "github.com/docker/docker/api/types/volume"
 
 	"github.com/craftcms/nitro/command/version"
+	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockerclient"
+	"github.com/craftcms/nitro/pkg/imageref"
+	"github.com/craftcms/nitro/pkg/pullprogress"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
@@ -28,20 +31,35 @@ var (
 	ProxyName = "nitro-proxy"
 
 	// ErrNoProxyContainer is returned when the proxy container is not found
-	ErrNoProxyContainer = fmt.Errorf("unable to locate the proxy container")
+	ErrNoProxyContainer = fmt.Errorf("unable to locate the proxy container, run `nitro apply` without --no-proxy to create it")
+
+	// ErrProxyNotRunning is returned when the API is used and the proxy container is not running
+	ErrProxyNotRunning = fmt.Errorf("the proxy container is not running, run `nitro start` to start it")
+
+	// ErrNoAPIPort is returned when the proxy container does not publish the port for the gRPC API
+	ErrNoAPIPort = fmt.Errorf("the proxy container does not publish the API port %s, remove the %s container and run `nitro apply` to create it", APIPort, ProxyName)
 )
 
-// Create is used to create a new proxy container for the nitro development environment.
-func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.Outputer, networkID string) error {
+// APIPort is the port the gRPC API listens on in the proxy container
+const APIPort nat.Port = "5000/tcp"
+
+// Create is used to create a new proxy container for the nitro development environment. The proxy
+// config is used to determine the HTTP and HTTPS ports to bind on the host machine, and the ports
+// are bound to the bind address. The API port is always bound to localhost. The name is the name
+// of the proxy container, which is ProxyName unless applying more than one environment. The
+// proxy image is pulled from the registry mirror, unless the registry is empty.
+func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.Outputer, name, networkID string, proxy config.Proxy, bindAddress, registry string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	filter := filters.NewArgs()
-	filter.Add("label", containerlabels.Nitro+"=true")
-	filter.Add("reference", ProxyImage)
+	// the proxy image may be pulled from a registry mirror
+	image := imageref.Resolve(registry, ProxyImage)
 
 	// check for the proxy image
-	images, err := docker.ImageList(ctx, types.ImageListOptions{Filters: filter})
+	imageFilter := containerlabels.Environment()
+	imageFilter.Add("reference", image)
+
+	images, err := docker.ImageList(ctx, types.ImageListOptions{Filters: imageFilter})
 	if err != nil {
 		return fmt.Errorf("unable to get a list of images, %w", err)
 	}
@@ -50,22 +68,21 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 	if len(images) == 0 && os.Getenv("NITRO_DEVELOPMENT") != "true" {
 		output.Pending("pulling image")
 
-		rdr, err := docker.ImagePull(ctx, ProxyImage, types.ImagePullOptions{All: false})
+		rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false})
 		if err != nil {
 			return fmt.Errorf("unable to pull the nitro-proxy from docker hub, %w", err)
 		}
 
-		buf := &bytes.Buffer{}
-		if _, err := buf.ReadFrom(rdr); err != nil {
+		// wait for the pull to complete and show progress
+		if err := pullprogress.Wait(rdr, terminal.ProgressWriter(output)); err != nil {
 			return fmt.Errorf("unable to read the output from pulling the image, %w", err)
 		}
 
 		output.Done()
 	}
 
-	filter.Del("reference", ProxyImage)
 	// check if the volume needs to be created
-	volumes, err := docker.VolumeList(ctx, filter)
+	volumes, err := docker.VolumeList(ctx, containerlabels.Environment())
 	if err != nil {
 		return fmt.Errorf("unable to list volumes, %w", err)
 	}
@@ -106,14 +123,11 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 		output.Done()
 	}
 
-	// remove the reference filter
-	filter.Del("reference", ProxyImage)
-
-	// create a filter for the nitro proxy
-	filter.Add("label", containerlabels.Proxy+"=true")
-
 	// check if there is an existing container for the nitro-proxy
-	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter, All: true})
+	containerFilter := containerlabels.Environment()
+	containerFilter.Add("label", containerlabels.Proxy+"=true")
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: containerFilter, All: true})
 	if err != nil {
 		return fmt.Errorf("unable to list the containers\n%w", err)
 	}
@@ -121,7 +135,7 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 	// check the containers and verify its running
 	for _, c := range containers {
 		for _, n := range c.Names {
-			if n == "nitro-proxy" || n == "/nitro-proxy" {
+			if n == name || n == "/"+name {
 				// check if it is running
 				if c.State != "running" {
 					if err := docker.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
@@ -139,23 +153,9 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 	// if we do not have a proxy, it needs to be create
 	output.Pending("creating proxy")
 
-	// check for a custom HTTP port
-	httpPort := "80"
-	if _, defined := os.LookupEnv("NITRO_HTTP_PORT"); defined {
-		httpPort = os.Getenv("NITRO_HTTP_PORT")
-	}
-
-	// check for a custom HTTPS port
-	httpsPort := "443"
-	if _, defined := os.LookupEnv("NITRO_HTTPS_PORT"); defined {
-		httpsPort = os.Getenv("NITRO_HTTPS_PORT")
-	}
-
-	// check for a custom API port
-	apiPort := "5000"
-	if _, defined := os.LookupEnv("NITRO_API_PORT"); defined {
-		apiPort = os.Getenv("NITRO_API_PORT")
-	}
+	// get the HTTP, HTTPS, and API ports for the host
+	httpPort, httpsPort := HostPorts(proxy)
+	apiPort := APIHostPort(proxy)
 
 	httpPortNat, err := nat.NewPort("tcp", "80")
 	if err != nil {
@@ -175,7 +175,7 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 	// create a container
 	resp, err := docker.ContainerCreate(ctx,
 		&container.Config{
-			Image: ProxyImage,
+			Image: image,
 			ExposedPorts: nat.PortSet{
 				httpPortNat:  struct{}{},
 				httpsPortNat: struct{}{},
@@ -201,13 +201,13 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 			PortBindings: map[nat.Port][]nat.PortBinding{
 				httpPortNat: {
 					{
-						HostIP:   "127.0.0.1",
+						HostIP:   bindAddress,
 						HostPort: httpPort,
 					},
 				},
 				httpsPortNat: {
 					{
-						HostIP:   "127.0.0.1",
+						HostIP:   bindAddress,
 						HostPort: httpsPort,
 					},
 				},
@@ -221,16 +221,16 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 		},
 		&network.NetworkingConfig{
 			EndpointsConfig: map[string]*network.EndpointSettings{
-				"nitro-network": {
+				networkID: {
 					NetworkID: networkID,
 				},
 			},
 		},
 		nil,
-		ProxyName,
+		name,
 	)
 	if err != nil {
-		return fmt.Errorf("unable to create proxy container: %s\n%w", ProxyImage, err)
+		return fmt.Errorf("unable to create proxy container: %s\n%w", image, err)
 	}
 
 	if err := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
@@ -244,21 +244,22 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 
 // FindAndStart will look for the proxy container and verify the container is started. It will return the
 // ErrNoProxyContainer error if it is unable to locate the proxy container. It is NOT responsible for
-// creating the proxy container as that is handled in the initialize package.
+// creating the proxy container, use FindOrCreate to create the proxy when it does not exist.
 func FindAndStart(ctx context.Context, docker client.ContainerAPIClient) (types.Container, error) {
-	// create the filters for the proxy
-	f := filters.NewArgs()
-	f.Add("label", containerlabels.Type+"=proxy")
+	return findAndStart(ctx, docker, ProxyName)
+}
 
+// findAndStart looks for the proxy container with the name and starts it.
+func findAndStart(ctx context.Context, docker client.ContainerAPIClient, name string) (types.Container, error) {
 	// check if there is an existing container for the nitro-proxy
-	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: f, All: true})
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: containerlabels.ByType("proxy"), All: true})
 	if err != nil {
 		return types.Container{}, fmt.Errorf("unable to list the containers: %w", err)
 	}
 
 	for _, c := range containers {
 		for _, n := range c.Names {
-			if n == "nitro-proxy" || n == "/nitro-proxy" {
+			if n == name || n == "/"+name {
 				// check if it is running
 				if c.State != "running" {
 					if err := docker.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
@@ -274,3 +275,173 @@ func FindAndStart(ctx context.Context, docker client.ContainerAPIClient) (types.
 
 	return types.Container{}, ErrNoProxyContainer
 }
+
+// FindOrCreate will look for the proxy container with the name and start it, if the proxy container
+// does not exist it is created on the network. The proxy container is returned in both cases.
+func FindOrCreate(ctx context.Context, docker client.CommonAPIClient, output terminal.Outputer, name, networkID string, proxy config.Proxy, bindAddress, registry string) (types.Container, error) {
+	c, err := findAndStart(ctx, docker, name)
+	if err == nil || !errors.Is(err, ErrNoProxyContainer) {
+		return c, err
+	}
+
+	if err := Create(ctx, docker, output, name, networkID, proxy, bindAddress, registry); err != nil {
+		return types.Container{}, err
+	}
+
+	return findAndStart(ctx, docker, name)
+}
+
+// HostPorts returns the HTTP and HTTPS ports the proxy container should bind to on
+// the host machine. The ports from the config take priority, followed by the
+// NITRO_HTTP_PORT and NITRO_HTTPS_PORT environment variables, and fall back
+// to the default ports 80 and 443.
+func HostPorts(proxy config.Proxy) (string, string) {
+	// check for a custom HTTP port
+	httpPort := "80"
+	if _, defined := os.LookupEnv("NITRO_HTTP_PORT"); defined {
+		httpPort = os.Getenv("NITRO_HTTP_PORT")
+	}
+	if proxy.HTTPPort != "" {
+		httpPort = proxy.HTTPPort
+	}
+
+	// check for a custom HTTPS port
+	httpsPort := "443"
+	if _, defined := os.LookupEnv("NITRO_HTTPS_PORT"); defined {
+		httpsPort = os.Getenv("NITRO_HTTPS_PORT")
+	}
+	if proxy.HTTPSPort != "" {
+		httpsPort = proxy.HTTPSPort
+	}
+
+	return httpPort, httpsPort
+}
+
+// APIHostPort returns the port the gRPC API is published on for the host machine. The
+// port from the config takes priority, followed by the NITRO_API_PORT environment
+// variable, and falls back to the default port 5000.
+func APIHostPort(proxy config.Proxy) string {
+	apiPort := "5000"
+	if _, defined := os.LookupEnv("NITRO_API_PORT"); defined {
+		apiPort = os.Getenv("NITRO_API_PORT")
+	}
+	if proxy.APIPort != "" {
+		apiPort = proxy.APIPort
+	}
+
+	return apiPort
+}
+
+// PortsMatch takes the details of the proxy container and verifies the HTTP and
+// HTTPS port bindings on the host match the ports for the proxy config and the
+// bind address.
+func PortsMatch(details types.ContainerJSON, proxy config.Proxy, bindAddress string) bool {
+	if details.ContainerJSONBase == nil || details.HostConfig == nil {
+		return false
+	}
+
+	httpPort, httpsPort := HostPorts(proxy)
+
+	expected := map[nat.Port]string{
+		"80/tcp":  httpPort,
+		"443/tcp": httpsPort,
+	}
+
+	for port, hostPort := range expected {
+		bindings := details.HostConfig.PortBindings[port]
+		if len(bindings) == 0 || bindings[0].HostPort != hostPort || bindings[0].HostIP != bindAddress {
+			return false
+		}
+	}
+
+	// the API port is only checked when it is set in the config, so proxies
+	// created with NITRO_API_PORT are not recreated
+	if proxy.APIPort != "" {
+		bindings := details.HostConfig.PortBindings[APIPort]
+		if len(bindings) == 0 || bindings[0].HostPort != proxy.APIPort {
+			return false
+		}
+	}
+
+	return true
+}
+
+// VerifyPorts inspects the proxy container and recreates it when the HTTP and
+// HTTPS port bindings do not match the proxy config or the bind address. The
+// volume is kept so the certificates persist.
+func VerifyPorts(ctx context.Context, docker client.CommonAPIClient, output terminal.Outputer, name, id, networkID string, proxy config.Proxy, bindAddress, registry string) error {
+	details, err := docker.ContainerInspect(ctx, id)
+	if err != nil {
+		return fmt.Errorf("unable to inspect the proxy, %w", err)
+	}
+
+	if PortsMatch(details, proxy, bindAddress) {
+		return nil
+	}
+
+	output.Pending("updating proxy ports")
+
+	if err := dockerclient.RemoveContainer(ctx, docker, types.Container{ID: id, Names: []string{name}}, nil, types.ContainerRemoveOptions{}); err != nil {
+		output.Warning()
+		return err
+	}
+
+	output.Done()
+
+	return Create(ctx, docker, output, name, networkID, proxy, bindAddress, registry)
+}
+
+// APIAddress inspects the running proxy container with the name and returns the host
+// address and port the gRPC API is published on. The port is read from the container
+// instead of the environment, so a proxy created with a different port is still
+// reachable. It returns ErrNoAPIPort when the proxy does not publish the API port.
+func APIAddress(ctx context.Context, docker client.ContainerAPIClient, name string) (string, string, error) {
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: containerlabels.ByType("proxy"), All: true})
+	if err != nil {
+		return "", "", fmt.Errorf("unable to list the containers: %w", err)
+	}
+
+	var id string
+	for _, c := range containers {
+		for _, n := range c.Names {
+			if n == name || n == "/"+name {
+				id = c.ID
+			}
+		}
+	}
+
+	if id == "" {
+		return "", "", ErrNoProxyContainer
+	}
+
+	details, err := docker.ContainerInspect(ctx, id)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to inspect the proxy container: %w", err)
+	}
+
+	if details.ContainerJSONBase == nil || details.State == nil || !details.State.Running {
+		return "", "", ErrProxyNotRunning
+	}
+
+	if details.NetworkSettings == nil {
+		return "", "", ErrNoAPIPort
+	}
+
+	for _, b := range details.NetworkSettings.Ports[APIPort] {
+		if b.HostPort == "" {
+			continue
+		}
+
+		// ports published on every address are reachable using localhost
+		switch b.HostIP {
+		case "", "0.0.0.0":
+			return "127.0.0.1", b.HostPort, nil
+		case "::":
+			return "::1", b.HostPort, nil
+		}
+
+		return b.HostIP, b.HostPort, nil
+	}
+
+	return "", "", ErrNoAPIPort
+}
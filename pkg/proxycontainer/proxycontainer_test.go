@@ -0,0 +1,439 @@
+package proxycontainer
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+type mockOutputer struct {
+	terminal.Outputer
+}
+
+func (m mockOutputer) Pending(s ...string) {}
+
+func (m mockOutputer) Success(s ...string) {}
+
+func (m mockOutputer) Done() {}
+
+func TestHostPorts(t *testing.T) {
+	tests := []struct {
+		name      string
+		proxy     config.Proxy
+		envs      map[string]string
+		wantHTTP  string
+		wantHTTPS string
+	}{
+		{
+			name:      "the default ports are used",
+			wantHTTP:  "80",
+			wantHTTPS: "443",
+		},
+		{
+			name:      "the environment variables are used",
+			envs:      map[string]string{"NITRO_HTTP_PORT": "8000", "NITRO_HTTPS_PORT": "8443"},
+			wantHTTP:  "8000",
+			wantHTTPS: "8443",
+		},
+		{
+			name:      "the config takes priority over the environment variables",
+			proxy:     config.Proxy{HTTPPort: "8080", HTTPSPort: "9443"},
+			envs:      map[string]string{"NITRO_HTTP_PORT": "8000", "NITRO_HTTPS_PORT": "8443"},
+			wantHTTP:  "8080",
+			wantHTTPS: "9443",
+		},
+		{
+			name:      "ports that are not in the config use the default",
+			proxy:     config.Proxy{HTTPSPort: "9443"},
+			wantHTTP:  "80",
+			wantHTTPS: "9443",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envs {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
+
+			httpPort, httpsPort := HostPorts(tt.proxy)
+			if httpPort != tt.wantHTTP || httpsPort != tt.wantHTTPS {
+				t.Errorf("HostPorts() = %s, %s, want %s, %s", httpPort, httpsPort, tt.wantHTTP, tt.wantHTTPS)
+			}
+		})
+	}
+}
+
+func TestPortsMatch(t *testing.T) {
+	// details returns the inspect response for a proxy with the port bindings
+	details := func(ip, http, https string) types.ContainerJSON {
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				HostConfig: &container.HostConfig{
+					PortBindings: nat.PortMap{
+						"80/tcp":  {{HostIP: ip, HostPort: http}},
+						"443/tcp": {{HostIP: ip, HostPort: https}},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		details types.ContainerJSON
+		proxy   config.Proxy
+		addr    string
+		want    bool
+	}{
+		{
+			name:    "the default ports match",
+			details: details("127.0.0.1", "80", "443"),
+			addr:    "127.0.0.1",
+			want:    true,
+		},
+		{
+			name:    "the ports from the config match",
+			details: details("127.0.0.1", "8080", "8443"),
+			proxy:   config.Proxy{HTTPPort: "8080", HTTPSPort: "8443"},
+			addr:    "127.0.0.1",
+			want:    true,
+		},
+		{
+			name:    "a different HTTP port does not match",
+			details: details("127.0.0.1", "80", "8443"),
+			proxy:   config.Proxy{HTTPPort: "8080", HTTPSPort: "8443"},
+			addr:    "127.0.0.1",
+		},
+		{
+			name:    "a different HTTPS port does not match",
+			details: details("127.0.0.1", "80", "443"),
+			proxy:   config.Proxy{HTTPSPort: "8443"},
+			addr:    "127.0.0.1",
+		},
+		{
+			name:    "a different API port does not match",
+			details: details("127.0.0.1", "80", "443"),
+			proxy:   config.Proxy{APIPort: "5001"},
+			addr:    "127.0.0.1",
+		},
+		{
+			name:    "a different bind address does not match",
+			details: details("0.0.0.0", "80", "443"),
+			addr:    "127.0.0.1",
+		},
+		{
+			name:    "missing port bindings do not match",
+			details: types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{HostConfig: &container.HostConfig{}}},
+			addr:    "127.0.0.1",
+		},
+		{
+			name: "missing details do not match",
+			addr: "127.0.0.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PortsMatch(tt.details, tt.proxy, tt.addr); got != tt.want {
+				t.Errorf("PortsMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyPorts(t *testing.T) {
+	tests := []struct {
+		name         string
+		proxy        config.Proxy
+		wantRecreate bool
+	}{
+		{
+			name:  "proxies with matching ports are kept",
+			proxy: config.Proxy{HTTPPort: "8080", HTTPSPort: "8443"},
+		},
+		{
+			name:         "proxies with different ports are recreated",
+			proxy:        config.Proxy{HTTPPort: "8081", HTTPSPort: "8444"},
+			wantRecreate: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			docker := dockertest.New(nil, nil)
+			docker.Images = []types.ImageSummary{
+				{ID: "proxy", RepoTags: []string{ProxyImage}, Labels: map[string]string{containerlabels.Nitro: "true"}},
+			}
+
+			proxy, err := FindOrCreate(context.Background(), docker, mockOutputer{}, ProxyName, "network-id", config.Proxy{HTTPPort: "8080", HTTPSPort: "8443"}, "127.0.0.1", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			err = VerifyPorts(context.Background(), docker, mockOutputer{}, ProxyName, proxy.ID, "network-id", tt.proxy, "127.0.0.1", "")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("VerifyPorts() error = %v", err)
+			}
+
+			removed := len(docker.Calls("ContainerRemove")) > 0
+			if removed != tt.wantRecreate {
+				t.Errorf("expected the proxy to be removed %v, got %v", tt.wantRecreate, removed)
+			}
+
+			created := docker.Calls("ContainerCreate")
+			if tt.wantRecreate != (len(created) == 2) {
+				t.Fatalf("expected the proxy to be recreated %v, got %d creates", tt.wantRecreate, len(created))
+			}
+
+			// the new proxy uses the ports from the config
+			last := created[len(created)-1].Args[1].(*container.HostConfig)
+			if got := last.PortBindings["443/tcp"][0].HostPort; got != tt.proxy.HTTPSPort {
+				t.Errorf("expected the HTTPS port %s, got %s", tt.proxy.HTTPSPort, got)
+			}
+		})
+	}
+}
+
+func TestFindOrCreate(t *testing.T) {
+	// Arrange
+	docker := dockertest.New(nil, nil)
+	docker.Images = []types.ImageSummary{
+		{ID: "proxy", RepoTags: []string{ProxyImage}, Labels: map[string]string{containerlabels.Nitro: "true"}},
+	}
+	proxy := config.Proxy{HTTPPort: "8080", HTTPSPort: "8443"}
+
+	// Act
+	first, err := FindOrCreate(context.Background(), docker, mockOutputer{}, ProxyName, "network-id", proxy, "127.0.0.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := FindOrCreate(context.Background(), docker, mockOutputer{}, ProxyName, "network-id", proxy, "127.0.0.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if first.ID == "" || first.ID != second.ID {
+		t.Errorf("expected the same proxy container, got %q and %q", first.ID, second.ID)
+	}
+
+	if first.State != "running" {
+		t.Errorf("expected the proxy to be running, got %s", first.State)
+	}
+
+	calls := docker.Calls("ContainerCreate")
+	if len(calls) != 1 {
+		t.Fatalf("expected the proxy to be created once, got %d", len(calls))
+	}
+
+	cfg := calls[0].Args[0].(*container.Config)
+	if cfg.Labels[containerlabels.Proxy] != "true" || cfg.Labels[containerlabels.ProxyVersion] == "" {
+		t.Errorf("expected the proxy labels to be set, got %v", cfg.Labels)
+	}
+
+	details, err := docker.ContainerInspect(context.Background(), first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !PortsMatch(details, proxy, "127.0.0.1") {
+		t.Errorf("expected the proxy ports to match the config, got %v", details.HostConfig.PortBindings)
+	}
+
+	networking := calls[0].Args[2].(*network.NetworkingConfig)
+	if networking.EndpointsConfig["network-id"].NetworkID != "network-id" {
+		t.Errorf("expected the proxy to be attached to the network")
+	}
+}
+
+func TestFindOrCreate_Named(t *testing.T) {
+	// Arrange
+	docker := dockertest.New([]types.Container{
+		{ID: "default", Names: []string{"/" + ProxyName}, State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "proxy", containerlabels.Proxy: "true"}},
+	}, nil)
+	docker.Images = []types.ImageSummary{
+		{ID: "proxy", RepoTags: []string{ProxyImage}, Labels: map[string]string{containerlabels.Nitro: "true"}},
+	}
+	proxy := config.Proxy{HTTPPort: "8080", HTTPSPort: "8443", APIPort: "5001"}
+
+	// Act
+	c, err := FindOrCreate(context.Background(), docker, mockOutputer{}, "nitro-work-proxy", "work-network-id", proxy, "127.0.0.1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if c.ID == "default" {
+		t.Fatalf("expected a new proxy for the environment, got the default proxy")
+	}
+
+	calls := docker.Calls("ContainerCreate")
+	if len(calls) != 1 || calls[0].Args[4] != "nitro-work-proxy" {
+		t.Fatalf("expected the proxy to be created with the name, got %v", calls)
+	}
+
+	bindings := calls[0].Args[1].(*container.HostConfig).PortBindings[APIPort]
+	if len(bindings) != 1 || bindings[0].HostPort != "5001" {
+		t.Errorf("expected the API port from the config, got %v", bindings)
+	}
+
+	if _, ok := calls[0].Args[2].(*network.NetworkingConfig).EndpointsConfig["work-network-id"]; !ok {
+		t.Errorf("expected the proxy to be attached to the network for the environment")
+	}
+}
+
+func TestCreate_BindAddress(t *testing.T) {
+	tests := []struct {
+		name  string
+		addr  string
+		other string
+	}{
+		{
+			name:  "ports are bound to localhost",
+			addr:  "127.0.0.1",
+			other: "0.0.0.0",
+		},
+		{
+			name:  "ports are bound to all addresses",
+			addr:  "0.0.0.0",
+			other: "127.0.0.1",
+		},
+		{
+			name:  "ports are bound to IPv6 addresses",
+			addr:  "::1",
+			other: "127.0.0.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			docker := dockertest.New(nil, nil)
+			docker.Images = []types.ImageSummary{
+				{ID: "proxy", RepoTags: []string{ProxyImage}, Labels: map[string]string{containerlabels.Nitro: "true"}},
+			}
+
+			// Act
+			c, err := FindOrCreate(context.Background(), docker, mockOutputer{}, ProxyName, "network-id", config.Proxy{}, tt.addr, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Assert
+			details, err := docker.ContainerInspect(context.Background(), c.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, port := range []string{"80/tcp", "443/tcp"} {
+				if got := details.HostConfig.PortBindings[nat.Port(port)][0].HostIP; got != tt.addr {
+					t.Errorf("expected the port %s to be bound to %s, got %s", port, tt.addr, got)
+				}
+			}
+
+			// the API is only used by the nitro CLI
+			if got := details.HostConfig.PortBindings["5000/tcp"][0].HostIP; got != "127.0.0.1" {
+				t.Errorf("expected the API port to be bound to localhost, got %s", got)
+			}
+
+			if !PortsMatch(details, config.Proxy{}, tt.addr) {
+				t.Errorf("expected the ports to match the bind address %s", tt.addr)
+			}
+
+			if PortsMatch(details, config.Proxy{}, tt.other) {
+				t.Errorf("expected the ports to not match the bind address %s", tt.other)
+			}
+		})
+	}
+}
+
+func TestAPIAddress(t *testing.T) {
+	proxy := types.Container{ID: "proxy", Names: []string{"/nitro-proxy"}, State: "running", Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "proxy"}}
+
+	// details returns the inspect response for a running proxy with the port bindings
+	details := func(running bool, ports nat.PortMap) types.ContainerJSON {
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{ID: "proxy", State: &types.ContainerState{Running: running}},
+			NetworkSettings:   &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: ports}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		containers []types.Container
+		details    types.ContainerJSON
+		wantIP     string
+		wantPort   string
+		wantErr    error
+	}{
+		{
+			name:       "the published port is used",
+			containers: []types.Container{proxy},
+			details:    details(true, nat.PortMap{APIPort: {{HostIP: "127.0.0.1", HostPort: "5001"}}}),
+			wantIP:     "127.0.0.1",
+			wantPort:   "5001",
+		},
+		{
+			name:       "ports published on every address use localhost",
+			containers: []types.Container{proxy},
+			details:    details(true, nat.PortMap{APIPort: {{HostIP: "0.0.0.0", HostPort: "5000"}, {HostIP: "::", HostPort: "5000"}}}),
+			wantIP:     "127.0.0.1",
+			wantPort:   "5000",
+		},
+		{
+			name:       "IPv6 addresses are returned",
+			containers: []types.Container{proxy},
+			details:    details(true, nat.PortMap{APIPort: {{HostIP: "::1", HostPort: "5000"}}}),
+			wantIP:     "::1",
+			wantPort:   "5000",
+		},
+		{
+			name:       "proxies without the API port return an error",
+			containers: []types.Container{proxy},
+			details:    details(true, nat.PortMap{"80/tcp": {{HostIP: "127.0.0.1", HostPort: "80"}}}),
+			wantErr:    ErrNoAPIPort,
+		},
+		{
+			name:       "stopped proxies return an error",
+			containers: []types.Container{proxy},
+			details:    details(false, nil),
+			wantErr:    ErrProxyNotRunning,
+		},
+		{
+			name:    "missing proxies return an error",
+			wantErr: ErrNoProxyContainer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			docker := dockertest.New(tt.containers, nil)
+			docker.Details = map[string]types.ContainerJSON{"proxy": tt.details}
+
+			// Act
+			ip, port, err := APIAddress(context.Background(), docker, ProxyName)
+
+			// Assert
+			if err != tt.wantErr {
+				t.Fatalf("APIAddress() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if ip != tt.wantIP || port != tt.wantPort {
+				t.Errorf("APIAddress() = %s:%s, want %s:%s", ip, port, tt.wantIP, tt.wantPort)
+			}
+		})
+	}
+}
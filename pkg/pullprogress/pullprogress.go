@@ -0,0 +1,141 @@
+package pullprogress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Interval is how often a heartbeat is written when there are no new pull events
+var Interval = 3 * time.Second
+
+// Event is a message from the JSON stream Docker returns when pulling an image.
+type Event struct {
+	ID             string `json:"id,omitempty"`
+	Status         string `json:"status,omitempty"`
+	Progress       string `json:"progress,omitempty"`
+	ProgressDetail struct {
+		Current int64 `json:"current,omitempty"`
+		Total   int64 `json:"total,omitempty"`
+	} `json:"progressDetail,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Decode takes the JSON stream from an image pull and calls the func for each
+// event in the stream. It returns an error if the stream cannot be decoded or
+// if Docker reports an error while pulling the image.
+func Decode(r io.Reader, fn func(Event)) error {
+	dec := json.NewDecoder(r)
+
+	for {
+		var e Event
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return fmt.Errorf("unable to decode the pull output, %w", err)
+		}
+
+		if e.Error != "" {
+			return fmt.Errorf("unable to pull the image, %s", e.Error)
+		}
+
+		fn(e)
+	}
+}
+
+// Wait reads the JSON stream from an image pull until the pull is complete. It
+// writes a dot to the writer for each completed layer and a heartbeat dot if
+// there has been no progress for the Interval so users can see the pull is
+// still running.
+func Wait(r io.Reader, w io.Writer) error {
+	var mu sync.Mutex
+	last := time.Now()
+
+	// write a dot and track the time
+	dot := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		fmt.Fprint(w, ".")
+		last = time.Now()
+	}
+
+	// start the heartbeat and stop it once the pull is complete
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(done)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(Interval / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				mu.Lock()
+				idle := time.Since(last) >= Interval
+				mu.Unlock()
+
+				if idle {
+					dot()
+				}
+			}
+		}
+	}()
+
+	return Decode(r, func(e Event) {
+		if e.Status == "Pull complete" {
+			dot()
+		}
+	})
+}
+
+// Tracker computes the percent complete of a pull from the events for each layer.
+type Tracker struct {
+	current map[string]int64
+	total   map[string]int64
+}
+
+// NewTracker returns a tracker for a single image pull.
+func NewTracker() *Tracker {
+	return &Tracker{current: make(map[string]int64), total: make(map[string]int64)}
+}
+
+// Add records the event and returns the percent of the layers that have
+// been downloaded. Layers are only counted once the size is known, so the
+// percent can go down when a new layer starts downloading.
+func (t *Tracker) Add(e Event) int {
+	switch e.Status {
+	case "Downloading":
+		if e.ProgressDetail.Total > 0 {
+			t.current[e.ID] = e.ProgressDetail.Current
+			t.total[e.ID] = e.ProgressDetail.Total
+		}
+	case "Download complete", "Extracting", "Pull complete":
+		if total, ok := t.total[e.ID]; ok {
+			t.current[e.ID] = total
+		}
+	}
+
+	var current, total int64
+	for id, size := range t.total {
+		current += t.current[id]
+		total += size
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return int(current * 100 / total)
+}
@@ -0,0 +1,110 @@
+package pullprogress
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	type args struct {
+		stream string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "events are decoded in order",
+			args: args{
+				stream: `{"status":"Pulling from craftcms/nginx","id":"7.4-dev"}
+{"status":"Downloading","progressDetail":{"current":1024,"total":2048},"progress":"[=====>     ]","id":"a1b2c3"}
+{"status":"Pull complete","progressDetail":{},"id":"a1b2c3"}
+{"status":"Status: Downloaded newer image for craftcms/nginx:7.4-dev"}
+`,
+			},
+			want: []string{
+				"Pulling from craftcms/nginx",
+				"Downloading",
+				"Pull complete",
+				"Status: Downloaded newer image for craftcms/nginx:7.4-dev",
+			},
+		},
+		{
+			name: "errors in the stream are returned",
+			args: args{
+				stream: `{"status":"Pulling from craftcms/nginx","id":"7.4-dev"}
+{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}
+`,
+			},
+			want:    []string{"Pulling from craftcms/nginx"},
+			wantErr: true,
+		},
+		{
+			name: "invalid json returns an error",
+			args: args{
+				stream: `{"status":`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := Decode(strings.NewReader(tt.args.stream), func(e Event) {
+				got = append(got, e.Status)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = \ngot:\n%v\nwant:\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWait(t *testing.T) {
+	stream := `{"status":"Pull complete","id":"a1b2c3"}
+{"status":"Pull complete","id":"d4e5f6"}
+`
+
+	buf := &bytes.Buffer{}
+	if err := Wait(strings.NewReader(stream), buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != ".." {
+		t.Errorf("Wait() = %q, want %q", got, "..")
+	}
+}
+
+func TestTracker(t *testing.T) {
+	stream := `{"status":"Pulling from craftcms/nginx","id":"7.4-dev"}
+{"status":"Already exists","progressDetail":{},"id":"z9y8x7"}
+{"status":"Downloading","progressDetail":{"current":512,"total":1024},"id":"a1b2c3"}
+{"status":"Downloading","progressDetail":{"current":0,"total":3072},"id":"d4e5f6"}
+{"status":"Download complete","progressDetail":{},"id":"a1b2c3"}
+{"status":"Downloading","progressDetail":{"current":3072,"total":3072},"id":"d4e5f6"}
+{"status":"Pull complete","progressDetail":{},"id":"d4e5f6"}
+`
+
+	tracker := NewTracker()
+
+	var got []int
+	if err := Decode(strings.NewReader(stream), func(e Event) {
+		got = append(got, tracker.Add(e))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []int{0, 0, 50, 12, 25, 100, 100}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the percent to be %v, got %v", want, got)
+	}
+}
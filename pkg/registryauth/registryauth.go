@@ -0,0 +1,216 @@
+// Package registryauth reads the credentials for private registries from the
+// docker config file so images can be pulled with the ImagePullOptions.
+package registryauth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// DefaultServer is the server docker uses to store the credentials for Docker Hub.
+const DefaultServer = "https://index.docker.io/v1/"
+
+// ErrCredentialsNotFound is returned by a helper when there are no credentials for the server.
+var ErrCredentialsNotFound = errors.New("credentials not found")
+
+// Credentials are returned by a docker credential helper.
+type Credentials struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// Helper returns the credentials for the server from a credential helper
+// (e.g. desktop, osxkeychain, or ecr-login).
+type Helper func(helper, server string) (Credentials, error)
+
+// Store reads the registry credentials from the docker config file.
+type Store struct {
+	// File is the path to the docker config.json
+	File string
+
+	// Helper is used when the config has a credsStore or credHelpers
+	Helper Helper
+}
+
+// dockerConfig is the part of the docker config.json used for credentials.
+type dockerConfig struct {
+	Auths       map[string]types.AuthConfig `json:"auths"`
+	CredsStore  string                      `json:"credsStore"`
+	CredHelpers map[string]string           `json:"credHelpers"`
+}
+
+// New returns the store for the docker config in the users home directory, the
+// DOCKER_CONFIG environment variable is used when set.
+func New(home string) *Store {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		dir = filepath.Join(home, ".docker")
+	}
+
+	return &Store{File: filepath.Join(dir, "config.json"), Helper: execHelper}
+}
+
+// ForImage returns the encoded auth for the registry of the image, which is
+// used as the RegistryAuth when pulling images. If there are no credentials for
+// the registry an empty string is returned.
+func (s *Store) ForImage(image string) (string, error) {
+	auth, err := s.Lookup(Server(image))
+	if err != nil {
+		return "", err
+	}
+
+	if auth == nil {
+		return "", nil
+	}
+
+	return Encode(*auth)
+}
+
+// Lookup returns the credentials for the server, it returns nil if there are
+// no credentials for the server.
+func (s *Store) Lookup(server string) (*types.AuthConfig, error) {
+	b, err := ioutil.ReadFile(s.File)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the docker config %s, %w", s.File, err)
+	}
+
+	var cfg dockerConfig
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse the docker config %s, %w", s.File, err)
+	}
+
+	// credential helpers for the registry are used before the default store
+	helper := cfg.CredsStore
+	for k, v := range cfg.CredHelpers {
+		if hostname(k) == hostname(server) {
+			helper = v
+		}
+	}
+
+	if helper != "" && s.Helper != nil {
+		creds, err := s.Helper(helper, server)
+		switch {
+		case errors.Is(err, ErrCredentialsNotFound):
+			// fall back to the credentials in the file
+		case err != nil:
+			return nil, fmt.Errorf("unable to get the credentials for %s from docker-credential-%s, %w", server, helper, err)
+		default:
+			auth := &types.AuthConfig{Username: creds.Username, Password: creds.Secret, ServerAddress: server}
+
+			// helpers return identity tokens using the <token> username
+			if creds.Username == "<token>" {
+				auth = &types.AuthConfig{IdentityToken: creds.Secret, ServerAddress: server}
+			}
+
+			return auth, nil
+		}
+	}
+
+	for k, v := range cfg.Auths {
+		if hostname(k) != hostname(server) {
+			continue
+		}
+
+		auth := v
+		auth.ServerAddress = server
+
+		// the auth is the base64 encoded username and password
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode the auth for %s, %w", k, err)
+			}
+
+			sp := strings.SplitN(string(decoded), ":", 2)
+			if len(sp) != 2 {
+				return nil, fmt.Errorf("invalid auth for %s in the docker config", k)
+			}
+
+			auth.Username, auth.Password, auth.Auth = sp[0], sp[1], ""
+		}
+
+		return &auth, nil
+	}
+
+	return nil, nil
+}
+
+// Encode returns the auth as base64 encoded json, which is the format of the
+// X-Registry-Auth header.
+func Encode(auth types.AuthConfig) (string, error) {
+	b, err := json.Marshal(auth)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode the registry auth, %w", err)
+	}
+
+	return base64.URLEncoding.EncodeToString(b), nil
+}
+
+// Server returns the registry server for the image, images without a
+// registry (e.g. craftcms/nginx:7.4-dev) use Docker Hub.
+func Server(image string) string {
+	sp := strings.SplitN(image, "/", 2)
+	if len(sp) == 1 {
+		return DefaultServer
+	}
+
+	// the first part is only a registry if it looks like a hostname
+	if !strings.ContainsAny(sp[0], ".:") && sp[0] != "localhost" {
+		return DefaultServer
+	}
+
+	switch sp[0] {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		return DefaultServer
+	}
+
+	return sp[0]
+}
+
+// hostname removes the scheme and path from a server in the docker config
+// (e.g. https://index.docker.io/v1/ is index.docker.io).
+func hostname(server string) string {
+	server = strings.TrimPrefix(server, "https://")
+	server = strings.TrimPrefix(server, "http://")
+
+	return strings.SplitN(server, "/", 2)[0]
+}
+
+// execHelper runs docker-credential-<helper> get to return the credentials for the server.
+func execHelper(helper, server string) (Credentials, error) {
+	stdout := &bytes.Buffer{}
+
+	c := exec.Command("docker-credential-"+helper, "get")
+	c.Stdin = strings.NewReader(server)
+	c.Stdout = stdout
+
+	if err := c.Run(); err != nil {
+		// helpers write the error to stdout
+		if strings.Contains(strings.ToLower(stdout.String()), "credentials not found") {
+			return Credentials{}, ErrCredentialsNotFound
+		}
+
+		return Credentials{}, fmt.Errorf("%s, %w", strings.TrimSpace(stdout.String()), err)
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return Credentials{}, fmt.Errorf("unable to parse the credentials, %w", err)
+	}
+
+	return creds, nil
+}
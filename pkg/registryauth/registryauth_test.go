@@ -0,0 +1,145 @@
+package registryauth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestStore_ForImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		helper  Helper
+		image   string
+		want    *types.AuthConfig
+		wantErr bool
+	}{
+		{
+			name:   "credentials from a credential store are encoded",
+			config: `{"credsStore": "desktop"}`,
+			helper: func(helper, server string) (Credentials, error) {
+				if helper != "desktop" || server != "registry.example.com" {
+					t.Errorf("unexpected helper %s for %s", helper, server)
+				}
+
+				return Credentials{ServerURL: server, Username: "oli", Secret: "s3cret"}, nil
+			},
+			image: "registry.example.com/team/php:7.4",
+			want:  &types.AuthConfig{Username: "oli", Password: "s3cret", ServerAddress: "registry.example.com"},
+		},
+		{
+			name:   "credential helpers for the registry are used before the store",
+			config: `{"credsStore": "desktop", "credHelpers": {"123.dkr.ecr.us-east-1.amazonaws.com": "ecr-login"}}`,
+			helper: func(helper, server string) (Credentials, error) {
+				if helper != "ecr-login" {
+					t.Errorf("expected the ecr-login helper, got %s", helper)
+				}
+
+				return Credentials{Username: "<token>", Secret: "token"}, nil
+			},
+			image: "123.dkr.ecr.us-east-1.amazonaws.com/php:7.4",
+			want:  &types.AuthConfig{IdentityToken: "token", ServerAddress: "123.dkr.ecr.us-east-1.amazonaws.com"},
+		},
+		{
+			name:   "credentials not found in the store use the auths",
+			config: `{"credsStore": "desktop", "auths": {"https://index.docker.io/v1/": {"auth": "b2xpOnMzY3JldA=="}}}`,
+			helper: func(helper, server string) (Credentials, error) {
+				return Credentials{}, ErrCredentialsNotFound
+			},
+			image: "team/php:7.4",
+			want:  &types.AuthConfig{Username: "oli", Password: "s3cret", ServerAddress: DefaultServer},
+		},
+		{
+			name:   "registries without credentials are empty",
+			config: `{"auths": {"registry.example.com": {"auth": "b2xpOnMzY3JldA=="}}}`,
+			image:  "craftcms/nginx:7.4-dev",
+		},
+		{
+			name:    "invalid auths return an error",
+			config:  `{"auths": {"registry.example.com": {"auth": "b2xp"}}}`,
+			image:   "registry.example.com/team/php:7.4",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			file := filepath.Join(t.TempDir(), "config.json")
+			if err := ioutil.WriteFile(file, []byte(tt.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			s := &Store{File: file, Helper: tt.helper}
+
+			// Act
+			got, err := s.ForImage(tt.image)
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ForImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.want == nil {
+				if got != "" {
+					t.Errorf("expected no auth, got %q", got)
+				}
+
+				return
+			}
+
+			b, err := base64.URLEncoding.DecodeString(got)
+			if err != nil {
+				t.Fatalf("expected the auth to be base64 encoded, %v", err)
+			}
+
+			var auth types.AuthConfig
+			if err := json.Unmarshal(b, &auth); err != nil {
+				t.Fatalf("expected the auth to be json, %v", err)
+			}
+
+			if !reflect.DeepEqual(&auth, tt.want) {
+				t.Errorf("ForImage() = %+v, want %+v", auth, *tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_ForImageWithoutConfig(t *testing.T) {
+	s := &Store{File: filepath.Join(t.TempDir(), "config.json")}
+
+	got, err := s.ForImage("registry.example.com/team/php:7.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "" {
+		t.Errorf("expected no auth without a docker config, got %q", got)
+	}
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "nginx", want: DefaultServer},
+		{image: "craftcms/nginx:7.4-dev", want: DefaultServer},
+		{image: "docker.io/craftcms/nginx:7.4-dev", want: DefaultServer},
+		{image: "registry.example.com/team/php:7.4", want: "registry.example.com"},
+		{image: "localhost:5000/php", want: "localhost:5000"},
+		{image: "localhost/php", want: "localhost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := Server(tt.image); got != tt.want {
+				t.Errorf("Server() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package releases
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+// CAFile returns the path to an extra CA bundle that should be trusted when
+// making requests, NITRO_CA_FILE takes priority over SSL_CERT_FILE. It returns
+// an empty string when neither is set.
+func CAFile() string {
+	if os.Getenv("NITRO_CA_FILE") != "" {
+		return os.Getenv("NITRO_CA_FILE")
+	}
+
+	return os.Getenv("SSL_CERT_FILE")
+}
+
+// NewHTTPClient returns an HTTP client that uses the proxy from the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables. If caFile is
+// not empty, the certificates in the file are trusted in addition to the
+// system certificates (e.g. for corporate proxies that inspect TLS).
+func NewHTTPClient(caFile string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA bundle %s, %w", caFile, err)
+		}
+
+		// the system pool is not available on windows for older versions of go
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("unable to find any certificates in the CA bundle %s", caFile)
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
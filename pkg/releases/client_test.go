@@ -0,0 +1,182 @@
+package releases
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewHTTPClient_UsesTheProxyFromTheEnvironment(t *testing.T) {
+	// Arrange
+	var requested []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// requests sent to a proxy use the absolute url
+		requested = append(requested, r.URL.String())
+
+		if strings.HasSuffix(r.URL.Path, "/latest") {
+			fmt.Fprint(w, `{"tag_name": "2.0.0", "assets": [{"name": "nitro_linux_x86_64.tar.gz", "content_type": "application/gzip", "browser_download_url": "http://github.test/nitro_linux_x86_64.tar.gz"}]}`)
+			return
+		}
+
+		fmt.Fprint(w, "release")
+	}))
+	defer proxy.Close()
+
+	// the proxy environment is only read once by the transport
+	os.Setenv("HTTP_PROXY", proxy.URL)
+	defer os.Unsetenv("HTTP_PROXY")
+
+	client, err := NewHTTPClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "nitro.tar.gz")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	release, err := NewFinder(client).Find("http://api.github.test/repos/craftcms/nitro/releases/latest", "linux", "amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewDownloader(client).Download(release.URL, file); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	want := []string{"http://api.github.test/repos/craftcms/nitro/releases/latest", "http://github.test/nitro_linux_x86_64.tar.gz"}
+	if strings.Join(requested, " ") != strings.Join(want, " ") {
+		t.Errorf("expected the requests %v to be sent through the proxy, got %v", want, requested)
+	}
+
+	if release.Version != "2.0.0" {
+		t.Errorf("expected the version 2.0.0, got %s", release.Version)
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "release" {
+		t.Errorf("expected the release to be downloaded, got %q", content)
+	}
+}
+
+func TestNewHTTPClient_TrustsTheCAFile(t *testing.T) {
+	// Arrange
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, cert, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.pem")
+	if err := ioutil.WriteFile(invalid, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		caFile  string
+		wantErr string
+	}{
+		{
+			name:    "requests fail without the CA file",
+			wantErr: "certificate",
+		},
+		{
+			name:   "requests succeed with the CA file",
+			caFile: caFile,
+		},
+		{
+			name:    "missing CA files return an error",
+			caFile:  filepath.Join(dir, "missing.pem"),
+			wantErr: "unable to read the CA bundle",
+		},
+		{
+			name:    "CA files without certificates return an error",
+			caFile:  invalid,
+			wantErr: "unable to find any certificates",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			client, err := NewHTTPClient(tt.caFile)
+			if err == nil {
+				var resp *http.Response
+				resp, err = client.Get(server.URL)
+				if err == nil {
+					resp.Body.Close()
+				}
+			}
+
+			// Assert
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatal(err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("expected the error to contain %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCAFile(t *testing.T) {
+	tests := []struct {
+		name string
+		envs map[string]string
+		want string
+	}{
+		{
+			name: "returns an empty string by default",
+		},
+		{
+			name: "uses SSL_CERT_FILE",
+			envs: map[string]string{"SSL_CERT_FILE": "/etc/ssl/corp.pem"},
+			want: "/etc/ssl/corp.pem",
+		},
+		{
+			name: "NITRO_CA_FILE takes priority",
+			envs: map[string]string{"SSL_CERT_FILE": "/etc/ssl/corp.pem", "NITRO_CA_FILE": "/home/nitro/ca.pem"},
+			want: "/home/nitro/ca.pem",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// keep the environment of the machine running the tests
+			for _, k := range []string{"NITRO_CA_FILE", "SSL_CERT_FILE"} {
+				if v, ok := os.LookupEnv(k); ok {
+					defer os.Setenv(k, v)
+				}
+
+				os.Unsetenv(k)
+				defer os.Unsetenv(k)
+			}
+
+			for k, v := range tt.envs {
+				os.Setenv(k, v)
+			}
+
+			if got := CAFile(); got != tt.want {
+				t.Errorf("CAFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
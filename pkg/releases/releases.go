@@ -176,10 +176,11 @@ func (r *githubReleaseFinder) Find(url, system, arch string) (*Release, error) {
 	return nil, fmt.Errorf("unable to find a release")
 }
 
-// NewFinder returns a new github release finder with the default HTTP client.
-func NewFinder() Finder {
+// NewFinder returns a new github release finder that uses the HTTP client,
+// if the client is nil the default HTTP client is used.
+func NewFinder(client *http.Client) Finder {
 	return &githubReleaseFinder{
-		HTTPClient: http.DefaultClient,
+		HTTPClient: client,
 	}
 }
 
@@ -188,6 +189,10 @@ type githubReleaseDownloader struct {
 }
 
 func (d *githubReleaseDownloader) Download(url, file string) error {
+	if d.HTTPClient == nil {
+		d.HTTPClient = http.DefaultClient
+	}
+
 	resp, err := d.HTTPClient.Get(url)
 	if err != nil {
 		return err
@@ -212,8 +217,10 @@ func (d *githubReleaseDownloader) Download(url, file string) error {
 	return nil
 }
 
-func NewDownloader() Downloader {
+// NewDownloader returns a new github release downloader that uses the HTTP
+// client, if the client is nil the default HTTP client is used.
+func NewDownloader(client *http.Client) Downloader {
 	return &githubReleaseDownloader{
-		HTTPClient: http.DefaultClient,
+		HTTPClient: client,
 	}
 }
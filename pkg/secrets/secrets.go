@@ -0,0 +1,168 @@
+// Package secrets stores values such as the blackfire credentials in the OS
+// keychain so they are not written to the config file as plaintext.
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Service is the name the secrets are stored under in the keychain
+const Service = "nitro"
+
+var (
+	// ErrNotFound is returned when a secret does not exist in the store
+	ErrNotFound = errors.New("the secret does not exist")
+
+	// ErrUnavailable is returned when there is no keychain for the OS
+	ErrUnavailable = errors.New("no keychain is available")
+)
+
+// Store is used to get and set secrets by name.
+type Store interface {
+	Get(name string) (string, error)
+	Set(name, value string) error
+}
+
+// runner runs a command with the input and returns the output, it is used to
+// test the commands without a keychain.
+type runner func(input, name string, args ...string) (string, error)
+
+// New returns the keychain for the OS, macOS uses the security command and
+// linux uses secret-tool to access the secret service. ErrUnavailable is
+// returned for other systems or when the command is not installed.
+func New() (Store, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return &Keychain{run: run}, nil
+		}
+	case "linux":
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return &SecretService{run: run}, nil
+		}
+	}
+
+	return nil, ErrUnavailable
+}
+
+// Keychain stores secrets in the macOS keychain.
+type Keychain struct {
+	run runner
+}
+
+// Get returns the secret from the keychain.
+func (k *Keychain) Get(name string) (string, error) {
+	out, err := k.run("", "security", "find-generic-password", "-s", Service, "-a", name, "-w")
+	if err != nil {
+		// the security command exits with 44 when the item does not exist
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && exit.ExitCode() == 44 {
+			return "", ErrNotFound
+		}
+
+		return "", fmt.Errorf("unable to get the secret %s from the keychain, %w", name, err)
+	}
+
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+// Set adds or updates the secret in the keychain. The security command only
+// accepts the value as an argument, which is visible in the process list, so
+// the command is sent using stdin to the interactive mode of security instead.
+// The interactive mode does not report errors with the exit code, so the
+// secret is read back to make sure it was saved.
+func (k *Keychain) Set(name, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("unable to save the secret %s to the keychain, the value can not contain a new line", name)
+	}
+
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(Service), quote(name), quote(value))
+	if _, err := k.run(command, "security", "-i"); err != nil {
+		return fmt.Errorf("unable to save the secret %s to the keychain, %w", name, err)
+	}
+
+	saved, err := k.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if saved != value {
+		return fmt.Errorf("unable to save the secret %s to the keychain", name)
+	}
+
+	return nil
+}
+
+// quote returns the argument in double quotes for the interactive mode of
+// the security command, backslashes and double quotes are escaped.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// SecretService stores secrets using the freedesktop secret service (e.g. GNOME Keyring).
+type SecretService struct {
+	run runner
+}
+
+// Get returns the secret from the secret service.
+func (s *SecretService) Get(name string) (string, error) {
+	out, err := s.run("", "secret-tool", "lookup", "service", Service, "name", name)
+	if err != nil {
+		// secret-tool exits with 1 and no output when the item does not exist
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && exit.ExitCode() == 1 && out == "" {
+			return "", ErrNotFound
+		}
+
+		return "", fmt.Errorf("unable to get the secret %s from the secret service, %w", name, err)
+	}
+
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+// Set adds or updates the secret in the secret service, the value is passed
+// using stdin so it is not visible in the process list.
+func (s *SecretService) Set(name, value string) error {
+	if _, err := s.run(value, "secret-tool", "store", "--label", Service+" "+name, "service", Service, "name", name); err != nil {
+		return fmt.Errorf("unable to save the secret %s to the secret service, %w", name, err)
+	}
+
+	return nil
+}
+
+// Memory stores secrets in memory, it is used for testing.
+type Memory map[string]string
+
+// Get returns the secret or ErrNotFound.
+func (m Memory) Get(name string) (string, error) {
+	v, ok := m[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return v, nil
+}
+
+// Set stores the secret.
+func (m Memory) Set(name, value string) error {
+	m[name] = value
+
+	return nil
+}
+
+func run(input, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+
+	out := &bytes.Buffer{}
+	cmd.Stdout = out
+
+	err := cmd.Run()
+
+	return out.String(), err
+}
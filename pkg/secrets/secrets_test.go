@@ -0,0 +1,123 @@
+package secrets
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type call struct {
+	input string
+	args  []string
+}
+
+func fakeRunner(out string, err error, calls *[]call) runner {
+	return func(input, name string, args ...string) (string, error) {
+		*calls = append(*calls, call{input: input, args: append([]string{name}, args...)})
+		return out, err
+	}
+}
+
+func TestKeychain(t *testing.T) {
+	var calls []call
+	k := &Keychain{run: fakeRunner("my-token\n", nil, &calls)}
+
+	if err := k.Set("blackfire.server_token", "my-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := k.Get("blackfire.server_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "my-token" {
+		t.Errorf("expected the secret to be my-token, got %q", got)
+	}
+
+	// the value is passed using stdin and read back after saving
+	want := []call{
+		{input: "add-generic-password -U -s \"nitro\" -a \"blackfire.server_token\" -w \"my-token\"\n", args: []string{"security", "-i"}},
+		{args: []string{"security", "find-generic-password", "-s", "nitro", "-a", "blackfire.server_token", "-w"}},
+		{args: []string{"security", "find-generic-password", "-s", "nitro", "-a", "blackfire.server_token", "-w"}},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected the calls to match, got %v, want %v", calls, want)
+	}
+}
+
+func TestKeychain_SetQuotesTheValue(t *testing.T) {
+	var calls []call
+	k := &Keychain{run: fakeRunner(`my "token" \ value`+"\n", nil, &calls)}
+
+	if err := k.Set("blackfire.server_token", `my "token" \ value`); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `add-generic-password -U -s "nitro" -a "blackfire.server_token" -w "my \"token\" \\ value"` + "\n"; calls[0].input != want {
+		t.Errorf("expected the command %q, got %q", want, calls[0].input)
+	}
+
+	// a new line would start another command
+	if err := k.Set("blackfire.server_token", "my-token\ndelete-keychain"); err == nil {
+		t.Error("expected an error for a value with a new line")
+	}
+
+	// the secret is not saved when it does not match after saving
+	k = &Keychain{run: fakeRunner("other-token\n", nil, &calls)}
+	if err := k.Set("blackfire.server_token", "my-token"); err == nil {
+		t.Error("expected an error when the saved secret does not match")
+	}
+}
+
+func TestSecretService(t *testing.T) {
+	var calls []call
+	s := &SecretService{run: fakeRunner("my-token", nil, &calls)}
+
+	if err := s.Set("blackfire.server_token", "my-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Get("blackfire.server_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "my-token" {
+		t.Errorf("expected the secret to be my-token, got %q", got)
+	}
+
+	// the value is passed using stdin
+	want := []call{
+		{input: "my-token", args: []string{"secret-tool", "store", "--label", "nitro blackfire.server_token", "service", "nitro", "name", "blackfire.server_token"}},
+		{args: []string{"secret-tool", "lookup", "service", "nitro", "name", "blackfire.server_token"}},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected the calls to match, got %v, want %v", calls, want)
+	}
+}
+
+func TestSecretService_Error(t *testing.T) {
+	var calls []call
+	s := &SecretService{run: fakeRunner("", errors.New("no dbus session"), &calls)}
+
+	if _, err := s.Get("missing"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the command error, got %v", err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	m := Memory{}
+
+	if _, err := m.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := m.Set("name", "value"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := m.Get("name"); got != "value" {
+		t.Errorf("expected value, got %q", got)
+	}
+}
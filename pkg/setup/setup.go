@@ -1,11 +1,12 @@
 package setup
 
 import (
+	"fmt"
 	"io"
-	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/portavail"
@@ -17,13 +18,64 @@ var (
 	postgresDefaultPort = 5432
 )
 
+// Engines are the database engines the first time setup is able to add.
+var Engines = []string{"mysql", "mariadb", "postgres"}
+
+// Options are used to choose the databases the first time setup adds to the
+// config. When no databases are chosen, the user is asked for each engine.
+type Options struct {
+	// Databases are the engines to add (e.g. mysql), the user is only asked
+	// for the version of each engine
+	Databases []string
+
+	// NoPostgres skips the PostgreSQL database
+	NoPostgres bool
+}
+
+// Validate returns an error if a database is not one of the engines or
+// postgres is requested and skipped.
+func (o Options) Validate() error {
+	for _, db := range o.Databases {
+		if !contains(Engines, db) {
+			return fmt.Errorf("unknown database engine %q, use one of %s", db, strings.Join(Engines, ", "))
+		}
+
+		if db == "postgres" && o.NoPostgres {
+			return fmt.Errorf("unable to add the postgres database and skip postgres")
+		}
+	}
+
+	return nil
+}
+
+// wants returns true if the database for an engine should be added. The user
+// is only asked when no databases were chosen using the options.
+func (o Options) wants(engine, message string, output terminal.Outputer) (bool, error) {
+	if engine == "postgres" && o.NoPostgres {
+		return false, nil
+	}
+
+	if len(o.Databases) > 0 {
+		return contains(o.Databases, engine), nil
+	}
+
+	return output.Confirm(message, true, "?")
+}
+
 // FirstTime is used when there is no configuration file found in a users
 // home/.nitro directory. We do not prompt for input such as memory, cpu,
 // disk space in version 2 as that is defined and managed at the docker
 // level. If anything fails, we return an error.
-func FirstTime(home string, reader io.Reader, output terminal.Outputer) error {
+func FirstTime(home string, reader io.Reader, opts Options, output terminal.Outputer) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
 	c := config.Config{File: filepath.Join(home, config.DirectoryName, config.FileName)}
 
+	// the ports for the next database of each engine
+	mysqlPort, postgresPort := mysqlDefaultPort, postgresDefaultPort
+
 	output.Info("Setting up Nitro…")
 
 	// if this is running on Apple Silicon, we need to prompt for mariadb instead until this issue is resolved: https://docs.docker.com/docker-for-mac/apple-m1/
@@ -35,113 +87,46 @@ func FirstTime(home string, reader io.Reader, output terminal.Outputer) error {
 			output.Info("ARM computers do not work with mysql images at this time...")
 		}
 
-		mariadb, err := output.Confirm("Would you like to use MariaDB", true, "?")
+		mariadb, err := opts.wants("mariadb", "Would you like to use MariaDB", output)
 		if err != nil {
 			return err
 		}
 
-		if mariadb {
-			// prompt for the version
-			opts := []string{"10.5", "10.4", "10.3", "10.2", "10.1", "10"}
-			selected, err := output.Select(os.Stdin, "Select the version of MariaDB ", opts)
-			if err != nil {
+		// use mariadb in place of mysql when mysql was requested
+		if mariadb || contains(opts.Databases, "mysql") {
+			if err := addDatabase(&c, reader, "mariadb", "MariaDB", []string{"10.5", "10.4", "10.3", "10.2", "10.1", "10"}, &mysqlPort, output); err != nil {
 				return err
 			}
-
-			version := opts[selected]
-
-			// check if the port is available
-			var port string
-			for {
-				if err := portavail.Check("", strconv.Itoa(mysqlDefaultPort)); err != nil {
-					mysqlDefaultPort = mysqlDefaultPort + 1
-					continue
-				}
-
-				port = strconv.Itoa(mysqlDefaultPort)
-
-				break
-			}
-
-			// add a default mariadb database
-			c.Databases = append(c.Databases, config.Database{
-				Engine:  "mariadb",
-				Version: version,
-				Port:    port,
-			})
 		}
 	default:
-		mysql, err := output.Confirm("Would you like to use MySQL", true, "?")
+		mysql, err := opts.wants("mysql", "Would you like to use MySQL", output)
 		if err != nil {
 			return err
 		}
 
 		if mysql {
-			// prompt for the version
-			opts := []string{"8.0", "5.7", "5.6"}
-			selected, err := output.Select(os.Stdin, "Select the version of MySQL ", opts)
-			if err != nil {
+			if err := addDatabase(&c, reader, "mysql", "MySQL", []string{"8.0", "5.7", "5.6"}, &mysqlPort, output); err != nil {
 				return err
 			}
+		}
 
-			version := opts[selected]
-
-			// check if the port is available
-			var port string
-			for {
-				if err := portavail.Check("", strconv.Itoa(mysqlDefaultPort)); err != nil {
-					mysqlDefaultPort = mysqlDefaultPort + 1
-					continue
-				}
-
-				port = strconv.Itoa(mysqlDefaultPort)
-
-				break
+		// mariadb is only added when it was requested, the user is asked for mysql
+		if contains(opts.Databases, "mariadb") {
+			if err := addDatabase(&c, reader, "mariadb", "MariaDB", []string{"10.5", "10.4", "10.3", "10.2", "10.1", "10"}, &mysqlPort, output); err != nil {
+				return err
 			}
-
-			// add a default mysql database
-			c.Databases = append(c.Databases, config.Database{
-				Engine:  "mysql",
-				Version: version,
-				Port:    port,
-			})
 		}
 	}
 
-	postgres, err := output.Confirm("Would you like to use PostgreSQL", true, "?")
+	postgres, err := opts.wants("postgres", "Would you like to use PostgreSQL", output)
 	if err != nil {
 		return err
 	}
 
 	if postgres {
-		// prompt for the version
-		opts := []string{"13", "12", "11", "10", "9"}
-		selected, err := output.Select(os.Stdin, "Select the version of PostgreSQL ", opts)
-		if err != nil {
+		if err := addDatabase(&c, reader, "postgres", "PostgreSQL", []string{"13", "12", "11", "10", "9"}, &postgresPort, output); err != nil {
 			return err
 		}
-
-		version := opts[selected]
-
-		// check if the port is available
-		var port string
-		for {
-			if err := portavail.Check("", strconv.Itoa(postgresDefaultPort)); err != nil {
-				postgresDefaultPort = postgresDefaultPort + 1
-				continue
-			}
-
-			port = strconv.Itoa(postgresDefaultPort)
-
-			break
-		}
-
-		// add a default postgres database
-		c.Databases = append(c.Databases, config.Database{
-			Engine:  "postgres",
-			Version: version,
-			Port:    port,
-		})
 	}
 
 	redis, err := output.Confirm("Would you like to use Redis", true, "?")
@@ -164,3 +149,39 @@ func FirstTime(home string, reader io.Reader, output terminal.Outputer) error {
 
 	return nil
 }
+
+// addDatabase prompts for the version of the engine and adds the database to
+// the config using the first available port. The port is incremented so the
+// next database for the engine does not use the same port.
+func addDatabase(c *config.Config, reader io.Reader, engine, name string, versions []string, port *int, output terminal.Outputer) error {
+	// prompt for the version
+	selected, err := output.Select(reader, fmt.Sprintf("Select the version of %s ", name), versions)
+	if err != nil {
+		return err
+	}
+
+	// check if the port is available
+	for portavail.Check("", strconv.Itoa(*port)) != nil {
+		*port++
+	}
+
+	db := config.Database{
+		Engine:  engine,
+		Version: versions[selected],
+		Port:    strconv.Itoa(*port),
+	}
+
+	*port++
+
+	return c.AddDatabase(db)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,140 @@
+package setup
+
+import (
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// confirmOutputer accepts the defaults for every prompt and records the questions.
+type confirmOutputer struct {
+	terminal.Outputer
+
+	confirms []string
+}
+
+func (o *confirmOutputer) Confirm(message string, fallback bool, sep string) (bool, error) {
+	o.confirms = append(o.confirms, message)
+
+	return fallback, nil
+}
+
+func (o *confirmOutputer) Select(r io.Reader, msg string, opts []string) (int, error) {
+	return 0, nil
+}
+
+func (o *confirmOutputer) Info(s ...string) {}
+
+func (o *confirmOutputer) Pending(s ...string) {}
+
+func (o *confirmOutputer) Done() {}
+
+func TestFirstTime(t *testing.T) {
+	if runtime.GOARCH == "arm64" || runtime.GOARCH == "arm" {
+		t.Skip("mysql is replaced with mariadb on ARM")
+	}
+
+	tests := []struct {
+		name         string
+		opts         Options
+		wantEngines  []string
+		wantConfirms []string
+		wantErr      bool
+	}{
+		{
+			name:         "the user is asked for each database without options",
+			wantEngines:  []string{"mysql", "postgres"},
+			wantConfirms: []string{"Would you like to use MySQL", "Would you like to use PostgreSQL", "Would you like to use Redis"},
+		},
+		{
+			name:         "only the requested databases are added",
+			opts:         Options{Databases: []string{"mysql"}},
+			wantEngines:  []string{"mysql"},
+			wantConfirms: []string{"Would you like to use Redis"},
+		},
+		{
+			name:         "postgres can be skipped",
+			opts:         Options{NoPostgres: true},
+			wantEngines:  []string{"mysql"},
+			wantConfirms: []string{"Would you like to use MySQL", "Would you like to use Redis"},
+		},
+		{
+			name:         "mariadb can be requested with mysql",
+			opts:         Options{Databases: []string{"mysql", "mariadb", "postgres"}},
+			wantEngines:  []string{"mysql", "mariadb", "postgres"},
+			wantConfirms: []string{"Would you like to use Redis"},
+		},
+		{
+			name:    "unknown engines return an error",
+			opts:    Options{Databases: []string{"mongodb"}},
+			wantErr: true,
+		},
+		{
+			name:    "postgres can not be requested and skipped",
+			opts:    Options{Databases: []string{"postgres"}, NoPostgres: true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			home := t.TempDir()
+			output := &confirmOutputer{}
+
+			// Act
+			err := FirstTime(home, strings.NewReader(""), tt.opts, output)
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FirstTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			content, err := ioutil.ReadFile(filepath.Join(home, config.DirectoryName, config.FileName))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &config.Config{}
+			if err := yaml.Unmarshal(content, cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			var engines []string
+			ports := make(map[string]bool)
+			for _, db := range cfg.Databases {
+				engines = append(engines, db.Engine)
+
+				if ports[db.Port] {
+					t.Errorf("expected each database to use a different port, got %s more than once", db.Port)
+				}
+
+				ports[db.Port] = true
+			}
+
+			if !reflect.DeepEqual(engines, tt.wantEngines) {
+				t.Errorf("expected the databases %v, got %v", tt.wantEngines, engines)
+			}
+
+			if !reflect.DeepEqual(output.confirms, tt.wantConfirms) {
+				t.Errorf("expected the prompts %v, got %v", tt.wantConfirms, output.confirms)
+			}
+
+			if !cfg.Services.Redis {
+				t.Error("expected redis to be added")
+			}
+		})
+	}
+}
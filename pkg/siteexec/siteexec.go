@@ -0,0 +1,199 @@
+package siteexec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/moby/term"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// AppPath is the path sites are mounted to in the container
+const AppPath = "/app"
+
+// ErrNoSites is returned when there are no sites in the config
+var ErrNoSites = fmt.Errorf("there are no sites in the config, run `nitro add` to add a site")
+
+// Options are used to run a command in a sites container.
+type Options struct {
+	// Cmd is the command and arguments to run
+	Cmd []string
+
+	// User is the user to run the command as, defaults to www-data
+	User string
+
+	// WorkingDir is the directory to run the command in
+	WorkingDir string
+}
+
+// FindSite returns the site by the hostname, if the hostname is empty the
+// site is found using the current directory. If the directory matches
+// multiple sites, or no sites, the user is prompted to select a site.
+func FindSite(cmd *cobra.Command, home, hostname string, cfg *config.Config, output terminal.Outputer) (config.Site, error) {
+	if hostname != "" {
+		site, err := cfg.FindSiteByHostName(hostname)
+		if err != nil {
+			return config.Site{}, err
+		}
+
+		return *site, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return config.Site{}, err
+	}
+
+	// get a context aware list of sites
+	sites := cfg.ListOfSitesByDirectory(home, wd)
+
+	switch len(sites) {
+	case 1:
+		return sites[0], nil
+	case 0:
+		// prompt from all of the sites
+		sites = cfg.Sites
+	}
+
+	if len(sites) == 0 {
+		return config.Site{}, ErrNoSites
+	}
+
+	// create the options for the sites
+	var options []string
+	for _, s := range sites {
+		options = append(options, s.Hostname)
+	}
+
+	selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
+	if err != nil {
+		return config.Site{}, err
+	}
+
+	return sites[selected], nil
+}
+
+// WorkingDir returns the default working directory for a site, which is the
+// directory containing the sites web root (e.g. /app).
+func WorkingDir(site config.Site) string {
+	return path.Join(AppPath, site.GetContainerPath())
+}
+
+// Container finds the container for the site hostname and starts the container if it is not running.
+func Container(ctx context.Context, docker client.ContainerAPIClient, hostname string) (string, error) {
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro)
+	filter.Add("label", containerlabels.Host+"="+hostname)
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter, All: true})
+	if err != nil {
+		return "", fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	if len(containers) == 0 {
+		return "", fmt.Errorf("unable to find the container for %s, run `nitro apply` to create it", hostname)
+	}
+
+	// start the container if its not running
+	if containers[0].State != "running" {
+		if err := docker.ContainerStart(ctx, containers[0].ID, types.ContainerStartOptions{}); err != nil {
+			return "", fmt.Errorf("unable to start the container for %s, %w", hostname, err)
+		}
+	}
+
+	return containers[0].ID, nil
+}
+
+// Run executes the command in the container using the exec API and streams
+// the stdin, stdout, and stderr. When stdin is a terminal, a tty is used so
+// interactive commands work as expected. The exit code of the command is
+// returned.
+func Run(ctx context.Context, docker client.ContainerAPIClient, containerID string, opts Options, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	user := opts.User
+	if user == "" {
+		user = "www-data"
+	}
+
+	// use a tty when stdin is a terminal
+	fd, tty := term.GetFdInfo(stdin)
+
+	exec, err := docker.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		User:         user,
+		WorkingDir:   opts.WorkingDir,
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          tty,
+		Cmd:          opts.Cmd,
+	})
+	if err != nil {
+		return 1, fmt.Errorf("unable to create the exec, %w", err)
+	}
+
+	// attaching will start the exec
+	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{Tty: tty})
+	if err != nil {
+		return 1, fmt.Errorf("unable to attach to the exec, %w", err)
+	}
+	defer resp.Close()
+
+	// detach from the exec when the command is interrupted, so the terminal is restored
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			resp.Close()
+		case <-done:
+		}
+	}()
+
+	if tty {
+		state, err := term.SetRawTerminal(fd)
+		if err != nil {
+			return 1, err
+		}
+		defer term.RestoreTerminal(fd, state)
+	}
+
+	// copy stdin to the container
+	if stdin != nil {
+		go func() {
+			io.Copy(resp.Conn, stdin)
+			resp.CloseWrite()
+		}()
+	}
+
+	// a tty combines stdout and stderr
+	if tty {
+		_, err = io.Copy(stdout, resp.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, resp.Reader)
+	}
+	if err != nil {
+		return 1, fmt.Errorf("unable to copy the output of the container, %w", err)
+	}
+
+	// wait for the exec to complete and get the exit code
+	for {
+		inspect, err := docker.ContainerExecInspect(ctx, exec.ID)
+		if err != nil {
+			return 1, err
+		}
+
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+	}
+}
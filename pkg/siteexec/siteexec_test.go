@@ -0,0 +1,129 @@
+package siteexec
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/dockertest"
+)
+
+func TestWorkingDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		webroot string
+		want    string
+	}{
+		{
+			name:    "default web roots use the app path",
+			webroot: "web",
+			want:    "/app",
+		},
+		{
+			name:    "nested web roots use the parent directory",
+			webroot: "craft/web",
+			want:    "/app/craft",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WorkingDir(config.Site{Webroot: tt.webroot}); got != tt.want {
+				t.Errorf("WorkingDir() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainer(t *testing.T) {
+	tests := []struct {
+		name        string
+		containers  []types.Container
+		want        string
+		wantStarted bool
+		wantErr     bool
+	}{
+		{
+			name:       "running containers are returned",
+			containers: []types.Container{{ID: "abc", State: "running"}},
+			want:       "abc",
+		},
+		{
+			name:        "stopped containers are started",
+			containers:  []types.Container{{ID: "abc", State: "exited"}},
+			want:        "abc",
+			wantStarted: true,
+		},
+		{
+			name:    "missing containers return an error",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockContainerClient{containers: tt.containers}
+
+			got, err := Container(context.Background(), mock, "mysite.nitro")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Container() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Container() = %v, want %v", got, tt.want)
+			}
+
+			if mock.started != tt.wantStarted {
+				t.Errorf("expected started to be %v, got %v", tt.wantStarted, mock.started)
+			}
+		})
+	}
+}
+
+func TestRun_Cancel(t *testing.T) {
+	// Arrange
+	docker := dockertest.New(nil, nil)
+	docker.ExecHangs = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act
+	done := make(chan error, 1)
+	go func() {
+		_, err := Run(ctx, docker, "abc", Options{Cmd: []string{"php", "craft", "queue/listen"}}, nil, &bytes.Buffer{}, &bytes.Buffer{})
+		done <- err
+	}()
+
+	cancel()
+
+	// Assert
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected an error when the exec is interrupted")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the exec to be detached when the context is canceled")
+	}
+}
+
+type mockContainerClient struct {
+	client.ContainerAPIClient
+
+	containers []types.Container
+	started    bool
+}
+
+func (m *mockContainerClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	return m.containers, nil
+}
+
+func (m *mockContainerClient) ContainerStart(ctx context.Context, container string, options types.ContainerStartOptions) error {
+	m.started = true
+
+	return nil
+}
@@ -93,8 +93,9 @@ func TestVerifyCreated(t *testing.T) {
 				},
 				NetworkingConfig: &network.NetworkingConfig{
 					EndpointsConfig: map[string]*network.EndpointSettings{
-						"nitro-network": {
+						"some-network-id": {
 							NetworkID: "some-network-id",
+							Aliases:   []string{"dynamodb"},
 						},
 					},
 				},
@@ -151,8 +152,9 @@ func TestVerifyCreated(t *testing.T) {
 				},
 				NetworkingConfig: &network.NetworkingConfig{
 					EndpointsConfig: map[string]*network.EndpointSettings{
-						"nitro-network": {
+						"some-network-id": {
 							NetworkID: "some-network-id",
+							Aliases:   []string{"dynamodb"},
 						},
 					},
 				},
@@ -216,7 +218,7 @@ func TestVerifyCreated(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, tt.args.output)
+			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, "127.0.0.1", "", tt.args.output)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("VerifyCreated() error = %v, wantErr %v", err, tt.wantErr)
 				return
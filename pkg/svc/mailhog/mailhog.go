@@ -1,13 +1,15 @@
 package mailhog
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockerclient"
+	"github.com/craftcms/nitro/pkg/imageref"
+	"github.com/craftcms/nitro/pkg/pullprogress"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -28,8 +30,9 @@ const (
 	Label = "mailhog"
 )
 
-// VerifyCreated will verify that the mailhog service container exists and is started
-func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID string, output terminal.Outputer) (string, string, error) {
+// VerifyCreated will verify that the mailhog service container exists and is started.
+// The ports are bound to the bind address on the host, and the container is recreated when the address changes.
+func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID, bindAddress, registry string, output terminal.Outputer) (string, string, error) {
 	// add the filter
 	filter := filters.NewArgs()
 	filter.Add("label", containerlabels.Nitro+"=true")
@@ -44,17 +47,25 @@ func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID st
 		return "", "", err
 	}
 
+	// remove the container when the bind address changed so it is recreated
+	if len(containers) > 0 && containerlabels.GetBindAddress(containers[0].Labels) != bindAddress {
+		if err := cli.ContainerRemove(ctx, containers[0].ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return "", "", fmt.Errorf("unable to remove the container, %w", err)
+		}
+
+		containers = nil
+	}
+
 	// if there is not a container, create one
 	if len(containers) == 0 {
 		// pull the image
-		r, err := cli.ImagePull(ctx, Image, types.ImagePullOptions{})
+		r, err := cli.ImagePull(ctx, imageref.Resolve(registry, Image), types.ImagePullOptions{})
 		if err != nil {
 			return "", "", err
 		}
 
-		// read from the buffer to pull the image
-		buf := &bytes.Buffer{}
-		if _, err := buf.ReadFrom(r); err != nil {
+		// wait for the pull to complete and show progress
+		if err := pullprogress.Wait(r, terminal.ProgressWriter(output)); err != nil {
 			return "", "", fmt.Errorf("unable to read output while pulling image, %w", err)
 		}
 
@@ -81,7 +92,7 @@ func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID st
 		}
 
 		containerConfig := &container.Config{
-			Image: Image,
+			Image: imageref.Resolve(registry, Image),
 			Labels: map[string]string{
 				containerlabels.Nitro: "true",
 				containerlabels.Type:  Label,
@@ -92,17 +103,19 @@ func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID st
 			},
 		}
 
+		containerlabels.SetBindAddress(containerConfig.Labels, bindAddress)
+
 		hostconfig := &container.HostConfig{
 			PortBindings: map[nat.Port][]nat.PortBinding{
 				smtpPortNat: {
 					{
-						HostIP:   "127.0.0.1",
+						HostIP:   bindAddress,
 						HostPort: smtpPort,
 					},
 				},
 				httpPortNat: {
 					{
-						HostIP:   "127.0.0.1",
+						HostIP:   bindAddress,
 						HostPort: httpPort,
 					},
 				},
@@ -111,8 +124,9 @@ func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID st
 
 		networkConfig := &network.NetworkingConfig{
 			EndpointsConfig: map[string]*network.EndpointSettings{
-				"nitro-network": {
+				networkID: {
 					NetworkID: networkID,
+					Aliases:   []string{Label},
 				},
 			},
 		}
@@ -167,19 +181,9 @@ func VerifyRemoved(ctx context.Context, cli client.CommonAPIClient, output termi
 
 	timeout := time.Duration(time.Second * 30)
 
-	// remove all of the containers
+	// remove all of the containers, containers that are already gone are skipped
 	for _, c := range containers {
-		// stop the container if its running
-		if c.State == "running" {
-			if err := cli.ContainerStop(ctx, c.ID, &timeout); err != nil {
-				return err
-			}
-		}
-
-		// remove the container
-		if err := cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
-			RemoveVolumes: true,
-		}); err != nil {
+		if err := dockerclient.RemoveContainer(ctx, cli, c, &timeout, types.ContainerRemoveOptions{RemoveVolumes: true}); err != nil {
 			return err
 		}
 	}
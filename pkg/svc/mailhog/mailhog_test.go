@@ -18,6 +18,7 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/go-connections/nat"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -100,8 +101,9 @@ func TestVerifyCreated(t *testing.T) {
 				},
 				NetworkingConfig: &network.NetworkingConfig{
 					EndpointsConfig: map[string]*network.EndpointSettings{
-						"nitro-network": {
+						"some-network-id": {
 							NetworkID: "some-network-id",
+							Aliases:   []string{"mailhog"},
 						},
 					},
 				},
@@ -166,8 +168,9 @@ func TestVerifyCreated(t *testing.T) {
 				},
 				NetworkingConfig: &network.NetworkingConfig{
 					EndpointsConfig: map[string]*network.EndpointSettings{
-						"nitro-network": {
+						"some-network-id": {
 							NetworkID: "some-network-id",
+							Aliases:   []string{"mailhog"},
 						},
 					},
 				},
@@ -231,7 +234,7 @@ func TestVerifyCreated(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, tt.args.output)
+			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, "127.0.0.1", "", tt.args.output)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("VerifyCreated() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -337,6 +340,25 @@ func TestVerifyRemoved(t *testing.T) {
 			wantContainerRemoveOptions: types.ContainerRemoveOptions{RemoveVolumes: true},
 			wantErr:                    true,
 		},
+		{
+			name: "containers that are already removed are not an error",
+			args: args{
+				ctx: context.TODO(),
+				spy: &mockClient{
+					containers: []types.Container{
+						{
+							ID:    "some-random-id",
+							State: "running",
+						},
+					},
+					containerRemoveError: errdefs.NotFound(fmt.Errorf("No such container: some-random-id")),
+				},
+			},
+			wantContainerStopID:        "some-random-id",
+			wantContainerRemoveID:      "some-random-id",
+			wantContainerRemoveOptions: types.ContainerRemoveOptions{RemoveVolumes: true},
+			wantErr:                    false,
+		},
 		{
 			name: "non running containers do not get a stop request",
 			args: args{
@@ -0,0 +1,209 @@
+package meilisearch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockerclient"
+	"github.com/craftcms/nitro/pkg/imageref"
+	"github.com/craftcms/nitro/pkg/pullprogress"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	// Image is the image to use for the meilisearch container
+	Image = "docker.io/getmeili/meilisearch:latest"
+
+	// Host is the hostname for the meilisearch container
+	Host = "meilisearch.service.nitro"
+
+	// Port is the port meilisearch listens on in the container
+	Port = "7700"
+
+	// Label is the label value used to mark a container as a "meilisearch" service
+	Label = "meilisearch"
+)
+
+// VerifyCreated will verify that the meilisearch service container exists and is started. The data is
+// stored in a named volume so it is kept when the service is disabled.
+// The ports are bound to the bind address on the host, and the container is recreated when the address changes.
+func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID, bindAddress, registry string, output terminal.Outputer) (string, string, error) {
+	// add the filter
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("label", containerlabels.Type+"="+Label)
+
+	// get a list of containers
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filter,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// remove the container when the bind address changed so it is recreated
+	if len(containers) > 0 && containerlabels.GetBindAddress(containers[0].Labels) != bindAddress {
+		if err := cli.ContainerRemove(ctx, containers[0].ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return "", "", fmt.Errorf("unable to remove the container, %w", err)
+		}
+
+		containers = nil
+	}
+
+	// start the existing container
+	if len(containers) > 0 {
+		if containers[0].State != "running" {
+			if err := cli.ContainerStart(ctx, containers[0].ID, types.ContainerStartOptions{}); err != nil {
+				return "", "", fmt.Errorf("unable to start the container, %w", err)
+			}
+		}
+
+		return containers[0].ID, Host, nil
+	}
+
+	// set the nitro env overrides
+	httpPort := Port
+	if os.Getenv("NITRO_MEILISEARCH_PORT") != "" {
+		httpPort = os.Getenv("NITRO_MEILISEARCH_PORT")
+	}
+
+	if _, err := strconv.Atoi(httpPort); err != nil {
+		return "", "", fmt.Errorf("the port %q for meilisearch must be a number", httpPort)
+	}
+
+	// pull the image
+	r, err := cli.ImagePull(ctx, imageref.Resolve(registry, Image), types.ImagePullOptions{})
+	if err != nil {
+		return "", "", err
+	}
+
+	// wait for the pull to complete and show progress
+	if err := pullprogress.Wait(r, terminal.ProgressWriter(output)); err != nil {
+		return "", "", fmt.Errorf("unable to read output while pulling image, %w", err)
+	}
+
+	labels := map[string]string{
+		containerlabels.Nitro: "true",
+		containerlabels.Type:  Label,
+	}
+
+	// find or create the volume for the data
+	volumes, err := cli.VolumeList(ctx, filters.NewArgs(filters.Arg("name", Host)))
+	if err != nil {
+		return "", "", fmt.Errorf("unable to list the volumes, %w", err)
+	}
+
+	volume := ""
+	for _, v := range volumes.Volumes {
+		if v.Name == Host {
+			volume = v.Name
+		}
+	}
+
+	if volume == "" {
+		v, err := cli.VolumeCreate(ctx, volumetypes.VolumeCreateBody{Driver: "local", Name: Host, Labels: labels})
+		if err != nil {
+			return "", "", fmt.Errorf("unable to create the volume, %w", err)
+		}
+
+		volume = v.Name
+	}
+
+	httpPortNat, err := nat.NewPort("tcp", Port)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the port, %w", err)
+	}
+
+	containerConfig := &container.Config{
+		Image:  imageref.Resolve(registry, Image),
+		Labels: labels,
+		ExposedPorts: nat.PortSet{
+			httpPortNat: struct{}{},
+		},
+		Env: []string{"MEILI_ENV=development", "MEILI_NO_ANALYTICS=true"},
+	}
+
+	containerlabels.SetBindAddress(containerConfig.Labels, bindAddress)
+
+	hostconfig := &container.HostConfig{
+		Mounts: []mount.Mount{
+			{
+				Type:   mount.TypeVolume,
+				Source: volume,
+				Target: "/meili_data",
+			},
+		},
+		PortBindings: map[nat.Port][]nat.PortBinding{
+			httpPortNat: {
+				{
+					HostIP:   bindAddress,
+					HostPort: httpPort,
+				},
+			},
+		},
+	}
+
+	networkConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			networkID: {
+				NetworkID: networkID,
+				Aliases:   []string{Label},
+			},
+		},
+	}
+
+	// create the container
+	resp, err := cli.ContainerCreate(ctx, containerConfig, hostconfig, networkConfig, nil, Host)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the container, %w", err)
+	}
+
+	// start the container
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", "", fmt.Errorf("unable to start the container, %w", err)
+	}
+
+	return resp.ID, Host, nil
+}
+
+// VerifyRemoved will verify the container for the meilisearch service is removed, the volume
+// is kept so the indexes are available if the service is enabled again.
+func VerifyRemoved(ctx context.Context, cli client.CommonAPIClient, output terminal.Outputer) error {
+	// add the filter
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("label", containerlabels.Type+"="+Label)
+
+	// get a list of containers
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(time.Second * 30)
+
+	// remove all of the containers, containers that are already gone are skipped
+	for _, c := range containers {
+		if err := dockerclient.RemoveContainer(ctx, cli, c, &timeout, types.ContainerRemoveOptions{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
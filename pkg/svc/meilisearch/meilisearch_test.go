@@ -0,0 +1,95 @@
+package meilisearch
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockertest"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+func TestLifecycle(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	docker := dockertest.New(nil, nil)
+
+	// Act
+	id, hostname, err := VerifyCreated(ctx, docker, "network", "127.0.0.1", "", terminal.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if hostname != Host {
+		t.Errorf("expected the hostname %s, got %s", Host, hostname)
+	}
+
+	details, err := docker.ContainerInspect(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := details.Config.Labels[containerlabels.Type]; got != Label {
+		t.Errorf("expected the type label %s, got %s", Label, got)
+	}
+
+	if got := details.HostConfig.PortBindings["7700/tcp"][0]; got.HostIP != "127.0.0.1" || got.HostPort != "7700" {
+		t.Errorf("expected the port to be bound to localhost, got %v", got)
+	}
+
+	if len(docker.Volumes) != 1 || details.HostConfig.Mounts[0].Source != Host {
+		t.Fatalf("expected the data to use a named volume, got %v", details.HostConfig.Mounts)
+	}
+
+	// the existing container is used
+	again, _, err := VerifyCreated(ctx, docker, "network", "127.0.0.1", "", terminal.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again != id || len(docker.Calls("ContainerCreate")) != 1 {
+		t.Errorf("expected the existing container to be used")
+	}
+
+	// disabling removes the container and keeps the volume
+	if err := VerifyRemoved(ctx, docker, terminal.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := docker.Calls("ContainerRemove")
+	if len(calls) != 1 || calls[0].Args[1].(types.ContainerRemoveOptions).RemoveVolumes {
+		t.Errorf("expected the container to be removed without the volumes, got %v", calls)
+	}
+
+	if len(docker.Containers) != 0 || len(docker.Volumes) != 1 {
+		t.Errorf("expected the volume to be kept without the container")
+	}
+
+	// enabling again uses the existing volume
+	if _, _, err := VerifyCreated(ctx, docker, "network", "127.0.0.1", "", terminal.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(docker.Calls("VolumeCreate")) != 1 {
+		t.Errorf("expected the existing volume to be used")
+	}
+}
+
+func TestVerifyCreated_InvalidPort(t *testing.T) {
+	os.Setenv("NITRO_MEILISEARCH_PORT", "seven-thousand")
+	defer os.Unsetenv("NITRO_MEILISEARCH_PORT")
+
+	docker := dockertest.New(nil, nil)
+
+	if _, _, err := VerifyCreated(context.Background(), docker, "network", "127.0.0.1", "", terminal.New()); err == nil {
+		t.Fatal("expected an error for the port")
+	}
+
+	if len(docker.Calls("ImagePull")) != 0 {
+		t.Errorf("expected the image to not be pulled")
+	}
+}
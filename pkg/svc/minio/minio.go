@@ -1,17 +1,20 @@
 package minio
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dockerclient"
+	"github.com/craftcms/nitro/pkg/imageref"
+	"github.com/craftcms/nitro/pkg/pullprogress"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
@@ -26,10 +29,20 @@ const (
 
 	// Label is the label value used to mark a container as a "minio" service
 	Label = "minio"
+
+	// SharedVolume is the volume for the data when the volume is shared by environments
+	SharedVolume = "nitro_shared_minio"
 )
 
-// VerifyCreated will verify that the minio service container exists and is started
-func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID string, output terminal.Outputer) (string, string, error) {
+// VerifyCreated will verify that the minio service container exists and is started.
+// The ports are bound to the bind address on the host, and the container is recreated when the address changes.
+// When the volume is shared the data is stored in a volume other environments are able to use.
+func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID, bindAddress, registry string, shared bool, output terminal.Outputer) (string, string, error) {
+	volume := ""
+	if shared {
+		volume = SharedVolume
+	}
+
 	// add the filter
 	filter := filters.NewArgs()
 	filter.Add("label", containerlabels.Nitro+"=true")
@@ -44,17 +57,25 @@ func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID st
 		return "", "", err
 	}
 
+	// remove the container when the bind address or volume changed so it is recreated
+	if len(containers) > 0 && (containerlabels.GetBindAddress(containers[0].Labels) != bindAddress || containers[0].Labels[containerlabels.Volume] != volume) {
+		if err := cli.ContainerRemove(ctx, containers[0].ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return "", "", fmt.Errorf("unable to remove the container, %w", err)
+		}
+
+		containers = nil
+	}
+
 	// if there is not a container, create one
 	if len(containers) == 0 {
 		// pull the image
-		r, err := cli.ImagePull(ctx, Image, types.ImagePullOptions{})
+		r, err := cli.ImagePull(ctx, imageref.Resolve(registry, Image), types.ImagePullOptions{})
 		if err != nil {
 			return "", "", err
 		}
 
-		// read from the buffer to pull the image
-		buf := &bytes.Buffer{}
-		if _, err := buf.ReadFrom(r); err != nil {
+		// wait for the pull to complete and show progress
+		if err := pullprogress.Wait(r, terminal.ProgressWriter(output)); err != nil {
 			return "", "", fmt.Errorf("unable to read output while pulling image, %w", err)
 		}
 
@@ -70,7 +91,7 @@ func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID st
 		}
 
 		containerConfig := &container.Config{
-			Image: Image,
+			Image: imageref.Resolve(registry, Image),
 			Labels: map[string]string{
 				containerlabels.Nitro: "true",
 				containerlabels.Type:  Label,
@@ -82,21 +103,40 @@ func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID st
 			Env: []string{"MINIO_ROOT_USER=nitro", "MINIO_ROOT_PASSWORD=nitropassword"},
 		}
 
+		containerlabels.SetBindAddress(containerConfig.Labels, bindAddress)
+
 		hostconfig := &container.HostConfig{
 			PortBindings: map[nat.Port][]nat.PortBinding{
 				httpPortNat: {
 					{
-						HostIP:   "127.0.0.1",
+						HostIP:   bindAddress,
 						HostPort: httpPort,
 					},
 				},
 			},
 		}
 
+		// store the buckets in the shared volume
+		if volume != "" {
+			containerConfig.Labels[containerlabels.Volume] = volume
+
+			hostconfig.Mounts = []mount.Mount{
+				{
+					Type:   mount.TypeVolume,
+					Source: volume,
+					Target: "/data",
+					VolumeOptions: &mount.VolumeOptions{
+						Labels: containerlabels.ForVolume(volume, true),
+					},
+				},
+			}
+		}
+
 		networkConfig := &network.NetworkingConfig{
 			EndpointsConfig: map[string]*network.EndpointSettings{
-				"nitro-network": {
+				networkID: {
 					NetworkID: networkID,
+					Aliases:   []string{Label},
 				},
 			},
 		}
@@ -151,19 +191,9 @@ func VerifyRemoved(ctx context.Context, cli client.CommonAPIClient, output termi
 
 	timeout := time.Duration(time.Second * 30)
 
-	// remove all of the containers
+	// remove all of the containers, containers that are already gone are skipped
 	for _, c := range containers {
-		// stop the container if its running
-		if c.State == "running" {
-			if err := cli.ContainerStop(ctx, c.ID, &timeout); err != nil {
-				return err
-			}
-		}
-
-		// remove the container
-		if err := cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
-			RemoveVolumes: true,
-		}); err != nil {
+		if err := dockerclient.RemoveContainer(ctx, cli, c, &timeout, types.ContainerRemoveOptions{RemoveVolumes: true}); err != nil {
 			return err
 		}
 	}
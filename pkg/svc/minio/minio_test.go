@@ -95,8 +95,9 @@ func TestVerifyCreated(t *testing.T) {
 				},
 				NetworkingConfig: &network.NetworkingConfig{
 					EndpointsConfig: map[string]*network.EndpointSettings{
-						"nitro-network": {
+						"some-network-id": {
 							NetworkID: "some-network-id",
+							Aliases:   []string{"minio"},
 						},
 					},
 				},
@@ -154,8 +155,9 @@ func TestVerifyCreated(t *testing.T) {
 				},
 				NetworkingConfig: &network.NetworkingConfig{
 					EndpointsConfig: map[string]*network.EndpointSettings{
-						"nitro-network": {
+						"some-network-id": {
 							NetworkID: "some-network-id",
+							Aliases:   []string{"minio"},
 						},
 					},
 				},
@@ -219,7 +221,7 @@ func TestVerifyCreated(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, tt.args.output)
+			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, "127.0.0.1", "", false, tt.args.output)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("VerifyCreated() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -257,6 +259,40 @@ func TestVerifyCreated(t *testing.T) {
 	}
 }
 
+func TestVerifyCreated_SharedVolume(t *testing.T) {
+	// Arrange
+	spy := &mockClient{
+		containers: []types.Container{
+			{
+				ID:     "existing-container-id",
+				State:  "running",
+				Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "minio"},
+			},
+		},
+		containerCreateResponse: container.ContainerCreateCreatedBody{ID: "someid"},
+	}
+
+	// Act
+	id, _, err := VerifyCreated(context.Background(), spy, "some-network-id", "127.0.0.1", "", true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if spy.containerRemoveID != "existing-container-id" || id != "someid" {
+		t.Errorf("expected the container to be recreated when the volume is shared, removed %q and got %q", spy.containerRemoveID, id)
+	}
+
+	mounts := spy.containerCreateConfig.HostConfig.Mounts
+	if len(mounts) != 1 || mounts[0].Source != SharedVolume || mounts[0].Target != "/data" {
+		t.Fatalf("expected the data to be stored in the %s volume, got %v", SharedVolume, mounts)
+	}
+
+	if !containerlabels.IsSharedVolume(mounts[0].VolumeOptions.Labels) {
+		t.Errorf("expected the volume to be labeled as shared, got %v", mounts[0].VolumeOptions.Labels)
+	}
+}
+
 func TestVerifyRemoved(t *testing.T) {
 	type args struct {
 		ctx    context.Context
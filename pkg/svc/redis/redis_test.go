@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/docker/docker/api/types"
@@ -92,8 +93,9 @@ func TestVerifyCreated(t *testing.T) {
 				},
 				NetworkingConfig: &network.NetworkingConfig{
 					EndpointsConfig: map[string]*network.EndpointSettings{
-						"nitro-network": {
+						"some-network-id": {
 							NetworkID: "some-network-id",
+							Aliases:   []string{"redis"},
 						},
 					},
 				},
@@ -149,8 +151,9 @@ func TestVerifyCreated(t *testing.T) {
 				},
 				NetworkingConfig: &network.NetworkingConfig{
 					EndpointsConfig: map[string]*network.EndpointSettings{
-						"nitro-network": {
+						"some-network-id": {
 							NetworkID: "some-network-id",
+							Aliases:   []string{"redis"},
 						},
 					},
 				},
@@ -214,7 +217,7 @@ func TestVerifyCreated(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, tt.args.output)
+			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, "127.0.0.1", "", config.RedisConfig{}, false, tt.args.output)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("VerifyCreated() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -252,6 +255,82 @@ func TestVerifyCreated(t *testing.T) {
 	}
 }
 
+func TestVerifyCreated_Options(t *testing.T) {
+	// Arrange
+	spy := &mockClient{
+		containers: []types.Container{
+			{
+				ID:     "existing-container-id",
+				State:  "running",
+				Labels: map[string]string{containerlabels.Nitro: "true", containerlabels.Type: "redis"},
+			},
+		},
+		containerCreateResponse: container.ContainerCreateCreatedBody{ID: "someid"},
+	}
+	opts := config.RedisConfig{AppendOnly: true, MaxMemory: "100mb", MaxMemoryPolicy: "allkeys-lru"}
+
+	// Act
+	id, _, err := VerifyCreated(context.Background(), spy, "some-network-id", "127.0.0.1", "", opts, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if spy.containerRemoveID != "existing-container-id" || id != "someid" {
+		t.Errorf("expected the container to be recreated when the options change, removed %q and got %q", spy.containerRemoveID, id)
+	}
+
+	want := []string{"redis-server", "--appendonly", "yes", "--maxmemory", "100mb", "--maxmemory-policy", "allkeys-lru"}
+	if got := spy.containerCreateConfig.Config.Cmd; !reflect.DeepEqual([]string(got), want) {
+		t.Errorf("expected the command %v, got %v", want, got)
+	}
+
+	if got := spy.containerCreateConfig.Config.Labels[containerlabels.ServiceArgs]; got != strings.Join(want, " ") {
+		t.Errorf("expected the args label to be set, got %q", got)
+	}
+
+	mounts := spy.containerCreateConfig.HostConfig.Mounts
+	if len(mounts) != 1 || mounts[0].Source != Volume || mounts[0].Target != "/data" {
+		t.Errorf("expected the data to be stored in the %s volume, got %v", Volume, mounts)
+	}
+}
+
+func Test_volumeName(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   config.RedisConfig
+		shared bool
+		want   string
+	}{
+		{
+			name: "data is not stored without persistence",
+		},
+		{
+			name: "append only files are stored in the volume",
+			opts: config.RedisConfig{AppendOnly: true},
+			want: Volume,
+		},
+		{
+			name:   "shared volumes always store the data",
+			shared: true,
+			want:   SharedVolume,
+		},
+		{
+			name:   "shared volumes are used for append only files",
+			opts:   config.RedisConfig{AppendOnly: true},
+			shared: true,
+			want:   SharedVolume,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := volumeName(tt.opts, tt.shared); got != tt.want {
+				t.Errorf("volumeName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestVerifyRemoved(t *testing.T) {
 	type args struct {
 		ctx    context.Context
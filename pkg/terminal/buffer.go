@@ -0,0 +1,88 @@
+package terminal
+
+import (
+	"io"
+	"sync"
+)
+
+// Buffer is an outputer that keeps the output until Flush is called, it is
+// used by tasks that run at the same time (e.g. checking sites) so the output
+// of each task is shown together instead of interleaved. Prompts are not
+// buffered and are passed to the outputer.
+type Buffer struct {
+	mu     sync.Mutex
+	output Outputer
+	calls  []func(Outputer)
+}
+
+// NewBuffer returns a buffer that writes to the outputer when flushed.
+func NewBuffer(output Outputer) *Buffer {
+	return &Buffer{output: output}
+}
+
+// Flush writes the buffered output to the outputer and empties the buffer.
+func (b *Buffer) Flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, fn := range b.calls {
+		fn(b.output)
+	}
+
+	b.calls = nil
+}
+
+// Write buffers the progress output (e.g. pulling an image), it is written to
+// the progress writer of the outputer when flushed.
+func (b *Buffer) Write(p []byte) (int, error) {
+	content := append([]byte(nil), p...)
+
+	b.add(func(o Outputer) {
+		ProgressWriter(o).Write(content)
+	})
+
+	return len(p), nil
+}
+
+func (b *Buffer) Ask(message, fallback, sep string, validator Validator) (string, error) {
+	return b.output.Ask(message, fallback, sep, validator)
+}
+
+func (b *Buffer) Confirm(message string, fallback bool, sep string) (bool, error) {
+	return b.output.Confirm(message, fallback, sep)
+}
+
+func (b *Buffer) Select(r io.Reader, msg string, opts []string) (int, error) {
+	return b.output.Select(r, msg, opts)
+}
+
+func (b *Buffer) Info(s ...string) {
+	b.add(func(o Outputer) { o.Info(s...) })
+}
+
+func (b *Buffer) Success(s ...string) {
+	b.add(func(o Outputer) { o.Success(s...) })
+}
+
+func (b *Buffer) Pending(s ...string) {
+	b.add(func(o Outputer) { o.Pending(s...) })
+}
+
+func (b *Buffer) Warning() {
+	b.add(func(o Outputer) { o.Warning() })
+}
+
+func (b *Buffer) Done() {
+	b.add(func(o Outputer) { o.Done() })
+}
+
+func (b *Buffer) Record(r Record) {
+	b.add(func(o Outputer) { o.Record(r) })
+}
+
+func (b *Buffer) add(fn func(Outputer)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.calls = append(b.calls, fn)
+}
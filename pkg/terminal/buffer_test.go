@@ -0,0 +1,36 @@
+package terminal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	// Arrange
+	output := &recordingOutputer{}
+	b := NewBuffer(output)
+
+	// Act
+	b.Info("updating", "site.nitro")
+	ProgressWriter(b).Write([]byte("."))
+	b.Success("site.nitro", "ready")
+
+	// Assert
+	if len(output.lines) != 0 {
+		t.Fatalf("expected the output to be buffered until it is flushed, got %q", output.lines)
+	}
+
+	b.Flush()
+
+	want := []string{"info: updating site.nitro", "success: site.nitro ready"}
+	if !reflect.DeepEqual(output.lines, want) {
+		t.Errorf("expected the buffered output in order, got %q, want %q", output.lines, want)
+	}
+
+	// flushing again does not repeat the output
+	b.Flush()
+
+	if len(output.lines) != len(want) {
+		t.Errorf("expected the buffer to be empty after flushing, got %q", output.lines)
+	}
+}
@@ -0,0 +1,69 @@
+package terminal
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+)
+
+// jsonOutputer writes each message as a line of JSON so the output of
+// a command can be used by other tools. Prompts are not structured and
+// are handled by the terminal.
+type jsonOutputer struct {
+	terminal
+
+	mu      sync.Mutex
+	enc     *json.Encoder
+	pending string
+}
+
+// NewJSON takes a writer and returns an Outputer that writes JSON records
+// to the writer.
+func NewJSON(w io.Writer) *jsonOutputer {
+	return &jsonOutputer{enc: json.NewEncoder(w)}
+}
+
+func (j *jsonOutputer) Info(s ...string) {
+	j.Record(Record{Level: "info", Message: strings.Join(s, " ")})
+}
+
+func (j *jsonOutputer) Success(s ...string) {
+	j.Record(Record{Level: "success", Message: strings.Join(s, " ")})
+}
+
+func (j *jsonOutputer) Pending(s ...string) {
+	j.mu.Lock()
+	j.pending = strings.Join(s, " ")
+	j.mu.Unlock()
+
+	j.Record(Record{Level: "pending", Message: strings.Join(s, " ")})
+}
+
+// Done writes the pending message as done
+func (j *jsonOutputer) Done() {
+	j.Record(Record{Level: "done", Message: j.lastPending()})
+}
+
+// Warning writes the pending message as a warning
+func (j *jsonOutputer) Warning() {
+	j.Record(Record{Level: "warning", Message: j.lastPending()})
+}
+
+func (j *jsonOutputer) Record(r Record) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// errors writing to the output cannot be shown
+	_ = j.enc.Encode(r)
+}
+
+func (j *jsonOutputer) lastPending() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	p := j.pending
+	j.pending = ""
+
+	return p
+}
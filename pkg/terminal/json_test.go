@@ -0,0 +1,32 @@
+package terminal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestJSONOutputer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	j := NewJSON(buf)
+
+	j.Info("Checking network…")
+	j.Pending("checking", "mysite.nitro")
+	j.Done()
+	j.Pending("checking", "othersite.nitro")
+	j.Warning()
+	j.Success("proxy ready")
+	j.Record(Record{Level: "info", Message: "site added", Fields: map[string]string{"hostname": "mysite.nitro"}})
+
+	want := `{"level":"info","message":"Checking network…"}
+{"level":"pending","message":"checking mysite.nitro"}
+{"level":"done","message":"checking mysite.nitro"}
+{"level":"pending","message":"checking othersite.nitro"}
+{"level":"warning","message":"checking othersite.nitro"}
+{"level":"success","message":"proxy ready"}
+{"level":"info","message":"site added","fields":{"hostname":"mysite.nitro"}}
+`
+
+	if got := buf.String(); got != want {
+		t.Errorf("JSON output = \ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
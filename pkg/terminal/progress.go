@@ -0,0 +1,112 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/moby/term"
+)
+
+// Progress shows the progress of tasks that run at the same time (e.g. image
+// pulls) with one line per task. When stdout is a terminal the lines are
+// updated in place, otherwise each task is shown using the outputer once it
+// completes. It is safe to call from multiple goroutines, but nothing else
+// should write to stdout until every task is done.
+type Progress struct {
+	mu     sync.Mutex
+	output Outputer
+
+	// w is nil when the lines can not be updated in place
+	w       io.Writer
+	names   []string
+	lines   map[string]string
+	percent map[string]int
+	drawn   int
+}
+
+// NewProgress returns the progress for the outputer, the lines are only
+// updated in place for the human readable terminal when stdout is a TTY.
+func NewProgress(output Outputer) *Progress {
+	var w io.Writer
+	if t, ok := output.(*terminal); ok && t.json == nil && !t.quiet && term.IsTerminal(os.Stdout.Fd()) {
+		w = os.Stdout
+	}
+
+	return newProgress(output, w)
+}
+
+func newProgress(output Outputer, w io.Writer) *Progress {
+	return &Progress{
+		output:  output,
+		w:       w,
+		lines:   make(map[string]string),
+		percent: make(map[string]int),
+	}
+}
+
+// Update sets the percent complete for a task, a new line is added the
+// first time a task is updated.
+func (p *Progress) Update(name string, percent int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.w == nil {
+		return
+	}
+
+	if last, ok := p.percent[name]; ok && last == percent {
+		return
+	}
+
+	p.percent[name] = percent
+	p.set(name, fmt.Sprintf("  … %s %d%%", name, percent))
+}
+
+// Done marks the task as complete with a message (e.g. the size of the image).
+func (p *Progress) Done(name string, s ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.w == nil {
+		p.output.Success(append([]string{name}, s...)...)
+		return
+	}
+
+	p.set(name, strings.TrimRight(fmt.Sprintf("  ✓ %s %s", name, strings.Join(s, " ")), " "))
+}
+
+// Fail marks the task as failed with the error.
+func (p *Progress) Fail(name string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.w == nil {
+		p.output.Info("  ✗", name, err.Error())
+		return
+	}
+
+	p.set(name, fmt.Sprintf("  ✗ %s %s", name, err.Error()))
+}
+
+// set changes the line for the task and redraws every line.
+func (p *Progress) set(name, line string) {
+	if _, ok := p.lines[name]; !ok {
+		p.names = append(p.names, name)
+	}
+
+	p.lines[name] = line
+
+	// move the cursor to the first line and clear each line as it is drawn
+	if p.drawn > 0 {
+		fmt.Fprintf(p.w, "\x1b[%dA", p.drawn)
+	}
+
+	for _, n := range p.names {
+		fmt.Fprintf(p.w, "\x1b[2K%s\n", p.lines[n])
+	}
+
+	p.drawn = len(p.names)
+}
@@ -0,0 +1,89 @@
+package terminal
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordingOutputer struct {
+	Outputer
+	lines []string
+}
+
+func (r *recordingOutputer) Success(s ...string) {
+	r.lines = append(r.lines, "success: "+strings.Join(s, " "))
+}
+
+func (r *recordingOutputer) Info(s ...string) {
+	r.lines = append(r.lines, "info: "+strings.Join(s, " "))
+}
+
+func TestProgress_UpdatesLinesInPlace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	p := newProgress(nil, buf)
+
+	p.Update("nginx", 10)
+	p.Update("mysql", 50)
+	p.Update("nginx", 10)
+	p.Done("nginx", "(10 MB)")
+
+	want := "\x1b[2K  … nginx 10%\n" +
+		"\x1b[1A\x1b[2K  … nginx 10%\n\x1b[2K  … mysql 50%\n" +
+		"\x1b[2A\x1b[2K  ✓ nginx (10 MB)\n\x1b[2K  … mysql 50%\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("expected the lines to be redrawn, got %q, want %q", got, want)
+	}
+}
+
+func TestProgress_FallsBackToSequentialLines(t *testing.T) {
+	output := &recordingOutputer{}
+	p := newProgress(output, nil)
+
+	p.Update("nginx", 10)
+	p.Done("nginx", "(10 MB)")
+	p.Fail("mysql", errors.New("manifest unknown"))
+
+	want := []string{
+		"success: nginx (10 MB)",
+		"info:   ✗ mysql manifest unknown",
+	}
+	if !reflect.DeepEqual(output.lines, want) {
+		t.Errorf("expected a line for each completed task, got %q, want %q", output.lines, want)
+	}
+}
+
+func TestProgress_Concurrent(t *testing.T) {
+	buf := &bytes.Buffer{}
+	p := newProgress(nil, buf)
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"nginx", "mysql", "postgres", "redis"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+
+			for i := 0; i <= 100; i += 10 {
+				p.Update(name, i)
+			}
+
+			p.Done(name)
+		}(name)
+	}
+
+	wg.Wait()
+
+	if len(p.names) != 4 || p.drawn != 4 {
+		t.Errorf("expected four lines, got %d lines and drew %d", len(p.names), p.drawn)
+	}
+
+	for _, n := range p.names {
+		if p.lines[n] != "  ✓ "+n {
+			t.Errorf("expected %s to be done, got %q", n, p.lines[n])
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package terminal
+
+import (
+	"io"
+
+	"github.com/moby/term"
+)
+
+// SaveState saves the state of the terminal for the reader (e.g. stdin) and
+// returns a func that restores it. Interactive commands (e.g. docker exec -it)
+// put the terminal in raw mode and do not restore it when they are killed, so
+// the state should be restored once the command stops. When the reader is not
+// a terminal the func does nothing.
+func SaveState(in io.Reader) func() {
+	fd, ok := term.GetFdInfo(in)
+	if !ok {
+		return func() {}
+	}
+
+	state, err := term.SaveState(fd)
+	if err != nil {
+		return func() {}
+	}
+
+	return func() { term.RestoreTerminal(fd, state) }
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -20,6 +22,16 @@ type Outputer interface {
 	Select(r io.Reader, msg string, opts []string) (int, error)
 	Warning()
 	Done()
+	Record(r Record)
+}
+
+// Record is a structured event that commands can emit in addition to the
+// human readable output. The JSON outputer writes each record as a line
+// of JSON so the output can be used by other tools.
+type Record struct {
+	Level   string            `json:"level"`
+	Message string            `json:"message,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
 }
 
 type Asker interface {
@@ -32,13 +44,38 @@ type Validator interface {
 	Validate(input string) error
 }
 
-type terminal struct{}
+type terminal struct {
+	// quiet is used to hide progress output (e.g. pending and done)
+	quiet bool
+
+	// json is set when the output should be machine readable
+	json *jsonOutputer
+}
 
 // New returns an Outputer interface
 func New() *terminal {
 	return &terminal{}
 }
 
+// Configure takes the quiet and output format options and changes
+// how the terminal displays output. The format can be "text" (the
+// default) or "json". It is used by the global command flags, which
+// are parsed after the terminal is passed to every command.
+func (t *terminal) Configure(quiet bool, format string) error {
+	t.quiet = quiet
+
+	switch format {
+	case "", "text":
+		t.json = nil
+	case "json":
+		t.json = NewJSON(os.Stdout)
+	default:
+		return fmt.Errorf("unknown output format %q, expected text or json", format)
+	}
+
+	return nil
+}
+
 func (t *terminal) Ask(message, fallback, sep string, validator Validator) (string, error) {
 	t.printStrMessage(message, fallback, sep)
 
@@ -141,26 +178,102 @@ func (t *terminal) printValidatorError(err error) {
 	fmt.Fprintf(os.Stdin, " \u2717 %s\n", err.Error())
 }
 
+// ProgressWriter takes an outputer and returns a writer for showing progress
+// on the current pending line (e.g. a dot while an image is pulled). Progress is
+// only shown for the human readable terminal and is discarded when the output
+// is quiet or JSON.
+func ProgressWriter(output Outputer) io.Writer {
+	if t, ok := output.(*terminal); ok && t.json == nil && !t.quiet {
+		return os.Stdout
+	}
+
+	// buffered progress is written to the outputer when the buffer is flushed
+	if b, ok := output.(*Buffer); ok {
+		return b
+	}
+
+	return ioutil.Discard
+}
+
 func (t terminal) Info(s ...string) {
+	if t.json != nil {
+		t.json.Info(s...)
+		return
+	}
+
 	fmt.Printf("%s\n", strings.Join(s, " "))
 }
 
 func (t terminal) Success(s ...string) {
+	if t.json != nil {
+		t.json.Success(s...)
+		return
+	}
+
 	fmt.Printf("  \u2713 %s\n", strings.Join(s, " "))
 }
 
 func (t terminal) Pending(s ...string) {
+	if t.json != nil {
+		t.json.Pending(s...)
+		return
+	}
+
+	if t.quiet {
+		return
+	}
+
 	fmt.Printf("  … %s ", strings.Join(s, " "))
 }
 
 func (t terminal) Done() {
+	if t.json != nil {
+		t.json.Done()
+		return
+	}
+
+	if t.quiet {
+		return
+	}
+
 	fmt.Print("\u2713\n")
 }
 
 func (t terminal) Warning() {
+	if t.json != nil {
+		t.json.Warning()
+		return
+	}
+
+	if t.quiet {
+		return
+	}
+
 	fmt.Print("\u2717\n")
 }
 
+func (t terminal) Record(r Record) {
+	if t.json != nil {
+		t.json.Record(r)
+		return
+	}
+
+	// show the message with the fields sorted by key
+	var keys []string
+	for k := range r.Fields {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	parts := []string{r.Message}
+	for _, k := range keys {
+		parts = append(parts, k+"="+r.Fields[k])
+	}
+
+	fmt.Printf("%s\n", strings.TrimSpace(strings.Join(parts, " ")))
+}
+
 func (t terminal) Select(r io.Reader, msg string, opts []string) (int, error) {
 	// if the options only have one item, return it
 	if len(opts) == 1 {
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/craftcms/nitro/pkg/phpversions"
 )
 
 type Validator interface {
@@ -55,15 +57,43 @@ func (v *HostnameValidator) Validate(input string) error {
 	return nil
 }
 
+// AliasValidator is used to validate one or more comma separated alias
+// domains. Aliases may use a wildcard for subdomains (e.g. *.mysite.nitro).
+type AliasValidator struct{}
+
+func (v *AliasValidator) Validate(input string) error {
+	hostname := &HostnameValidator{}
+
+	for _, alias := range strings.Split(input, ",") {
+		// remove the wildcard to validate the rest of the domain
+		if strings.Contains(alias, "*") {
+			if !strings.HasPrefix(alias, "*.") || strings.Count(alias, "*") > 1 {
+				return fmt.Errorf("wildcards must only be used as the first part of the alias (e.g. *.mysite.nitro)")
+			}
+
+			alias = strings.TrimPrefix(alias, "*.")
+
+			if !strings.Contains(alias, ".") {
+				return fmt.Errorf("wildcards must include a domain (e.g. *.mysite.nitro)")
+			}
+		}
+
+		if err := hostname.Validate(alias); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type PHPVersionValidator struct{}
 
 func (v *PHPVersionValidator) Validate(input string) error {
-	switch input {
-	case "8.0", "7.4", "7.3", "7.2", "7.1", "7.0":
+	if phpversions.IsSupported(input) {
 		return nil
 	}
 
-	return fmt.Errorf("the PHP inputrsion %q is not valid", input)
+	return fmt.Errorf("the PHP version %q is not supported, use one of %s", input, strings.Join(phpversions.Versions, ", "))
 }
 
 type IsBoolean struct{}
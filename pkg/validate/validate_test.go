@@ -82,6 +82,68 @@ func TestHostnameValidator_Validate(t *testing.T) {
 	}
 }
 
+func TestAliasValidator_Validate(t *testing.T) {
+	type args struct {
+		input string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "valid aliases do not return an err",
+			args: args{
+				input: "mysite.nitro,othersite.nitro",
+			},
+			wantErr: false,
+		},
+		{
+			name: "wildcard aliases do not return an err",
+			args: args{
+				input: "*.mysite.nitro",
+			},
+			wantErr: false,
+		},
+		{
+			name: "wildcards that are not the first part return an err",
+			args: args{
+				input: "api.*.mysite.nitro",
+			},
+			wantErr: true,
+		},
+		{
+			name: "multiple wildcards return an err",
+			args: args{
+				input: "*.*.mysite.nitro",
+			},
+			wantErr: true,
+		},
+		{
+			name: "wildcards without a domain return an err",
+			args: args{
+				input: "*.nitro",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid aliases return an err",
+			args: args{
+				input: "mysite.nitro,other site.nitro",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &AliasValidator{}
+			if err := v.Validate(tt.args.input); (err != nil) != tt.wantErr {
+				t.Errorf("AliasValidator.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestPHPVersionValidator_Validate(t *testing.T) {
 	type args struct {
 		input string
@@ -0,0 +1,7 @@
+package protob
+
+// APIVersion is the version of the API implemented by this package. It is
+// increased when a request adds a field that an older proxy would ignore, so
+// the CLI can warn when the proxy needs to be recreated. Proxies created
+// before the version was added report 0.
+const APIVersion int32 = 1